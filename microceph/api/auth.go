@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/ceph"
+	"github.com/canonical/microceph/microceph/interfaces"
+)
+
+// /1.0/auth/clients endpoint.
+var authClientsCmd = mcTypes.Endpoint{
+	Path: "auth/clients",
+	Get:  mcTypes.EndpointAction{Handler: cmdAuthClientsGet, ProxyTarget: true},
+	Post: mcTypes.EndpointAction{Handler: cmdAuthClientsPost, ProxyTarget: true},
+}
+
+// /1.0/auth/clients/{name} endpoint.
+var authClientCmd = mcTypes.Endpoint{
+	Path:   "auth/clients/{name}",
+	Get:    mcTypes.EndpointAction{Handler: cmdAuthClientGet, ProxyTarget: true},
+	Delete: mcTypes.EndpointAction{Handler: cmdAuthClientDelete, ProxyTarget: true},
+}
+
+// /1.0/auth/clients/{name}/rotate endpoint.
+var authClientRotateCmd = mcTypes.Endpoint{
+	Path: "auth/clients/{name}/rotate",
+	Post: mcTypes.EndpointAction{Handler: cmdAuthClientRotatePost, ProxyTarget: true},
+}
+
+// cmdAuthClientsGet lists the cephx clients and their capabilities.
+func cmdAuthClientsGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	clients, err := ceph.ListAuthClients(r.Context(), interfaces.CephState{State: s})
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, clients)
+}
+
+// cmdAuthClientsPost creates a cephx client and returns it along with its key.
+func cmdAuthClientsPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.AuthClientRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	if !types.AuthClientNameRegex.MatchString(req.Name) {
+		return mcTypes.BadRequest(fmt.Errorf("expected client name to be valid (regex: '%s')", types.AuthClientNameRegex.String()))
+	}
+
+	client, err := ceph.CreateAuthClient(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, client)
+}
+
+// cmdAuthClientGet fetches a cephx client along with its key and keyring.
+func cmdAuthClientGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	name, err := authPathClientName(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	client, err := ceph.GetAuthClient(r.Context(), interfaces.CephState{State: s}, name)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, client)
+}
+
+// cmdAuthClientDelete removes a cephx client.
+func cmdAuthClientDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
+	name, err := authPathClientName(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.RemoveAuthClient(r.Context(), interfaces.CephState{State: s}, name)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdAuthClientRotatePost replaces the key of a cephx client and returns the new one.
+func cmdAuthClientRotatePost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	name, err := authPathClientName(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	client, err := ceph.RotateAuthClientKey(r.Context(), interfaces.CephState{State: s}, name)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, client)
+}
+
+// authPathClientName unescapes and validates the {name} path variable.
+func authPathClientName(r *http.Request) (string, error) {
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return "", err
+	}
+
+	if !types.AuthClientNameRegex.MatchString(name) {
+		return "", fmt.Errorf("expected client name to be valid (regex: '%s')", types.AuthClientNameRegex.String())
+	}
+
+	return name, nil
+}
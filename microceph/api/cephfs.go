@@ -0,0 +1,517 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/ceph"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// /1.0/fs/volumes endpoint.
+var fsVolumesCmd = mcTypes.Endpoint{
+	Path: "fs/volumes",
+	Get:  mcTypes.EndpointAction{Handler: cmdFsVolumesGet, ProxyTarget: true},
+	Post: mcTypes.EndpointAction{Handler: cmdFsVolumesPost, ProxyTarget: true},
+}
+
+// /1.0/fs/volumes/{name} endpoint.
+var fsVolumeCmd = mcTypes.Endpoint{
+	Path:   "fs/volumes/{name}",
+	Put:    mcTypes.EndpointAction{Handler: cmdFsVolumePut, ProxyTarget: true},
+	Delete: mcTypes.EndpointAction{Handler: cmdFsVolumeDelete, ProxyTarget: true},
+}
+
+// /1.0/fs/volumes/{name}/subvolumes endpoint.
+var fsSubvolumesCmd = mcTypes.Endpoint{
+	Path: "fs/volumes/{name}/subvolumes",
+	Get:  mcTypes.EndpointAction{Handler: cmdFsSubvolumesGet, ProxyTarget: true},
+	Post: mcTypes.EndpointAction{Handler: cmdFsSubvolumesPost, ProxyTarget: true},
+}
+
+// /1.0/fs/volumes/{name}/subvolumes/{subvolume} endpoint.
+var fsSubvolumeCmd = mcTypes.Endpoint{
+	Path:   "fs/volumes/{name}/subvolumes/{subvolume}",
+	Get:    mcTypes.EndpointAction{Handler: cmdFsSubvolumeGet, ProxyTarget: true},
+	Put:    mcTypes.EndpointAction{Handler: cmdFsSubvolumePut, ProxyTarget: true},
+	Delete: mcTypes.EndpointAction{Handler: cmdFsSubvolumeDelete, ProxyTarget: true},
+}
+
+// /1.0/fs/volumes/{name}/subvolumegroups endpoint.
+var fsSubvolumeGroupsCmd = mcTypes.Endpoint{
+	Path: "fs/volumes/{name}/subvolumegroups",
+	Get:  mcTypes.EndpointAction{Handler: cmdFsSubvolumeGroupsGet, ProxyTarget: true},
+	Post: mcTypes.EndpointAction{Handler: cmdFsSubvolumeGroupsPost, ProxyTarget: true},
+}
+
+// /1.0/fs/volumes/{name}/subvolumegroups/{group} endpoint.
+var fsSubvolumeGroupCmd = mcTypes.Endpoint{
+	Path:   "fs/volumes/{name}/subvolumegroups/{group}",
+	Get:    mcTypes.EndpointAction{Handler: cmdFsSubvolumeGroupGet, ProxyTarget: true},
+	Put:    mcTypes.EndpointAction{Handler: cmdFsSubvolumeGroupPut, ProxyTarget: true},
+	Delete: mcTypes.EndpointAction{Handler: cmdFsSubvolumeGroupDelete, ProxyTarget: true},
+}
+
+// /1.0/fs/volumes/{name}/snap-schedules endpoint.
+var fsSnapSchedulesCmd = mcTypes.Endpoint{
+	Path:   "fs/volumes/{name}/snap-schedules",
+	Get:    mcTypes.EndpointAction{Handler: cmdFsSnapSchedulesGet, ProxyTarget: true},
+	Post:   mcTypes.EndpointAction{Handler: cmdFsSnapSchedulesPost, ProxyTarget: true},
+	Delete: mcTypes.EndpointAction{Handler: cmdFsSnapSchedulesDelete, ProxyTarget: true},
+}
+
+// cmdFsVolumesGet lists CephFS volumes with their pools.
+func cmdFsVolumesGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	volumes, err := ceph.ListCephFSVolumeInfo(r.Context())
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, volumes)
+}
+
+// cmdFsVolumesPost creates a CephFS volume, placing an MDS on this node if requested.
+func cmdFsVolumesPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.FsVolumeCreateRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = validateFsName(req.Name)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	mdsPlaced, err := ceph.IsServicePlaced(r.Context(), interfaces.CephState{State: s}, "mds")
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	if !mdsPlaced {
+		if !req.EnableMDS {
+			return mcTypes.BadRequest(fmt.Errorf("no MDS service is placed in the cluster, enable one with 'microceph enable mds' before creating volume %s", req.Name))
+		}
+
+		logger.Infof("FSVOL: no MDS placed, enabling MDS on %s for volume %s", s.Name(), req.Name)
+		err = ceph.ServicePlacementHandler(r.Context(), interfaces.CephState{State: s}, types.EnableService{Name: "mds", Wait: true})
+		if err != nil {
+			return mcTypes.SmartError(err)
+		}
+	}
+
+	err = ceph.CreateCephFSVolume(r.Context(), req.Name)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsVolumePut updates the MDS settings of a CephFS volume.
+func cmdFsVolumePut(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.FsVolumeSetRequest
+
+	volume, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = validateFsName(volume)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	if req.MaxMDS != nil {
+		err = ceph.SetCephFSMaxMDS(r.Context(), volume, *req.MaxMDS)
+		if err != nil {
+			return mcTypes.SmartError(err)
+		}
+	}
+
+	if req.StandbyReplay != nil {
+		err = ceph.SetCephFSStandbyReplay(r.Context(), volume, *req.StandbyReplay)
+		if err != nil {
+			return mcTypes.SmartError(err)
+		}
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsVolumeDelete removes a CephFS volume along with its pools.
+func cmdFsVolumeDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.FsVolumeDeleteRequest
+
+	volume, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = validateFsName(volume)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	if !req.IsForceOp {
+		return mcTypes.BadRequest(fmt.Errorf("removing volume %s deletes all of its data and pools, force is required", volume))
+	}
+
+	err = ceph.RemoveCephFSVolume(r.Context(), volume)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsSubvolumesGet lists the subvolumes of a volume, optionally within a group.
+func cmdFsSubvolumesGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, err := fsPathVars(r, "name")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	group := r.URL.Query().Get("group")
+	if len(group) != 0 {
+		err = validateFsName(group)
+		if err != nil {
+			return mcTypes.BadRequest(err)
+		}
+	}
+
+	subvolumes, err := ceph.GetCephFSSubvolumes(ceph.Volume(names[0]), group)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	response := make(types.FsSubvolumes, 0, len(subvolumes))
+	for _, subvolume := range subvolumes {
+		response = append(response, types.FsSubvolume{Name: string(subvolume), Group: group})
+	}
+
+	return mcTypes.SyncResponse(true, response)
+}
+
+// cmdFsSubvolumesPost creates a subvolume.
+func cmdFsSubvolumesPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, req, err := decodeFsSubvolumeRequest(r, "name")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = validateFsName(req.Name)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.CreateCephFSSubvolume(r.Context(), names[0], req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsSubvolumeGet fetches the path of a subvolume.
+func cmdFsSubvolumeGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, err := fsPathVars(r, "name", "subvolume")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	group := r.URL.Query().Get("group")
+	if len(group) != 0 {
+		err = validateFsName(group)
+		if err != nil {
+			return mcTypes.BadRequest(err)
+		}
+	}
+
+	path, err := ceph.GetCephFSSubvolumeAbsPath(r.Context(), names[0], names[1], group)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, types.FsSubvolume{Name: names[1], Group: group, Path: path})
+}
+
+// cmdFsSubvolumePut resizes a subvolume.
+func cmdFsSubvolumePut(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, req, err := decodeFsSubvolumeRequest(r, "name", "subvolume")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.ValidateFsResizeRequest(req)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	req.Name = names[1]
+	err = ceph.ResizeCephFSSubvolume(r.Context(), names[0], req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsSubvolumeDelete removes a subvolume.
+func cmdFsSubvolumeDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, req, err := decodeFsSubvolumeRequest(r, "name", "subvolume")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	req.Name = names[1]
+	err = ceph.RemoveCephFSSubvolume(r.Context(), names[0], req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsSubvolumeGroupsGet lists the subvolume groups of a volume.
+func cmdFsSubvolumeGroupsGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, err := fsPathVars(r, "name")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	groups, err := ceph.ListCephFSSubvolumeGroups(r.Context(), names[0])
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	response := make(types.FsSubvolumes, 0, len(groups))
+	for _, group := range groups {
+		response = append(response, types.FsSubvolume{Name: group})
+	}
+
+	return mcTypes.SyncResponse(true, response)
+}
+
+// cmdFsSubvolumeGroupsPost creates a subvolume group.
+func cmdFsSubvolumeGroupsPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, req, err := decodeFsSubvolumeRequest(r, "name")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = validateFsName(req.Name)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.CreateCephFSSubvolumeGroup(r.Context(), names[0], req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsSubvolumeGroupGet fetches the path of a subvolume group.
+func cmdFsSubvolumeGroupGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, err := fsPathVars(r, "name", "group")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	path, err := ceph.GetCephFSSubvolumeGroupAbsPath(r.Context(), names[0], names[1])
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, types.FsSubvolume{Name: names[1], Path: path})
+}
+
+// cmdFsSubvolumeGroupPut resizes a subvolume group.
+func cmdFsSubvolumeGroupPut(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, req, err := decodeFsSubvolumeRequest(r, "name", "group")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.ValidateFsResizeRequest(req)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	req.Name = names[1]
+	err = ceph.ResizeCephFSSubvolumeGroup(r.Context(), names[0], req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsSubvolumeGroupDelete removes a subvolume group.
+func cmdFsSubvolumeGroupDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, req, err := decodeFsSubvolumeRequest(r, "name", "group")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	req.Name = names[1]
+	err = ceph.RemoveCephFSSubvolumeGroup(r.Context(), names[0], req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsSnapSchedulesGet lists the snapshot schedules of a path or subvolume.
+func cmdFsSnapSchedulesGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	names, err := fsPathVars(r, "name")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	req := types.FsSnapScheduleRequest{
+		Path:      r.URL.Query().Get("path"),
+		Subvolume: r.URL.Query().Get("subvolume"),
+		Group:     r.URL.Query().Get("group"),
+	}
+
+	schedules, err := ceph.ListCephFSSnapSchedules(r.Context(), names[0], req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, schedules)
+}
+
+// cmdFsSnapSchedulesPost adds a snapshot schedule and/or retention policy.
+func cmdFsSnapSchedulesPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.FsSnapScheduleRequest
+
+	names, err := fsPathVars(r, "name")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = ceph.ValidateFsSnapScheduleRequest(req)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.AddCephFSSnapSchedule(r.Context(), names[0], req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdFsSnapSchedulesDelete removes snapshot schedules and/or a retention policy.
+func cmdFsSnapSchedulesDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.FsSnapScheduleRequest
+
+	names, err := fsPathVars(r, "name")
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = ceph.ValidateFsSnapScheduleRemoveRequest(req)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.RemoveCephFSSnapSchedule(r.Context(), names[0], req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// fsPathVars unescapes and validates the requested CephFS names from the API path.
+func fsPathVars(r *http.Request, keys ...string) ([]string, error) {
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name, err := url.PathUnescape(mux.Vars(r)[key])
+		if err != nil {
+			return nil, err
+		}
+
+		err = validateFsName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// decodeFsSubvolumeRequest reads the API path names and the validated subvolume request body.
+func decodeFsSubvolumeRequest(r *http.Request, keys ...string) ([]string, types.FsSubvolumeRequest, error) {
+	var req types.FsSubvolumeRequest
+
+	names, err := fsPathVars(r, keys...)
+	if err != nil {
+		return nil, req, err
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, req, err
+	}
+
+	if len(req.Group) != 0 {
+		err = validateFsName(req.Group)
+		if err != nil {
+			return nil, req, err
+		}
+	}
+
+	err = ceph.ValidateFsSubvolumeRequest(req)
+	if err != nil {
+		return nil, req, err
+	}
+
+	return names, req, nil
+}
+
+// validateFsName checks that a CephFS volume, subvolume or group name is acceptable.
+func validateFsName(name string) error {
+	if !types.FsNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid name %q, expected to match '%s'", name, types.FsNameRegex.String())
+	}
+
+	return nil
+}
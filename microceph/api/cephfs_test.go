@@ -0,0 +1,17 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFsName(t *testing.T) {
+	for _, name := range []string{"cephfs", "vol_1", "a", "share-01.data"} {
+		assert.NoError(t, validateFsName(name), "expected %q to be valid", name)
+	}
+
+	for _, name := range []string{"", "../vol", "vol/sub", "-vol", "vol name"} {
+		assert.Error(t, validateFsName(name), "expected %q to be rejected", name)
+	}
+}
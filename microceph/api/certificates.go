@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
@@ -13,9 +14,20 @@ import (
 
 var certificatesRGWCmd = mcTypes.Endpoint{
 	Path: "certificates/rgw",
+	Get:  mcTypes.EndpointAction{Handler: cmdCertificatesRGWGet, ProxyTarget: true},
 	Put:  mcTypes.EndpointAction{Handler: cmdCertificatesRGWPut, ProxyTarget: true},
 }
 
+// cmdCertificatesRGWGet describes the RGW certificates installed on the node.
+func cmdCertificatesRGWGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	certs, err := ceph.ListRGWCertificates()
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, certs)
+}
+
 func cmdCertificatesRGWPut(s mcTypes.State, r *http.Request) mcTypes.Response {
 	var req types.CertificateSetRequest
 
@@ -24,7 +36,21 @@ func cmdCertificatesRGWPut(s mcTypes.State, r *http.Request) mcTypes.Response {
 		return mcTypes.InternalError(err)
 	}
 
-	err = ceph.UpdateRGWCertificates(interfaces.CephState{State: s}, req.SSLCertificate, req.SSLPrivateKey)
+	if len(req.GroupID) != 0 && !types.RGWGroupIDRegex.MatchString(req.GroupID) {
+		return mcTypes.BadRequest(fmt.Errorf("expected group_id to be valid (regex: '%s')", types.RGWGroupIDRegex.String()))
+	}
+
+	if req.SelfManaged {
+		if len(req.SSLCertificate) != 0 || len(req.SSLPrivateKey) != 0 {
+			return mcTypes.BadRequest(fmt.Errorf("a self-managed certificate cannot be combined with certificate material"))
+		}
+
+		err = ceph.SetRGWSelfManagedCertificate(r.Context(), interfaces.CephState{State: s}, req.GroupID)
+	} else if len(req.GroupID) != 0 {
+		err = ceph.UpdateRGWGroupCertificates(interfaces.CephState{State: s}, req.GroupID, req.SSLCertificate, req.SSLPrivateKey)
+	} else {
+		err = ceph.UpdateRGWCertificates(interfaces.CephState{State: s}, req.SSLCertificate, req.SSLPrivateKey)
+	}
 	if err != nil {
 		return mcTypes.SmartError(err)
 	}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/ceph"
+	"github.com/canonical/microceph/microceph/interfaces"
+)
+
+// client bundle API, fetched again by config management whenever monitors change.
+var clientBundleCmd = mcTypes.Endpoint{
+	Path: "client/bundles/{name}",
+	Get:  mcTypes.EndpointAction{Handler: cmdClientBundleGet, ProxyTarget: true},
+}
+
+// cmdClientBundleGet assembles the ceph.conf and keyring of a cephx client.
+func cmdClientBundleGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	name, err := authPathClientName(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	bundle, err := ceph.GetClientBundle(r.Context(), interfaces.CephState{State: s}, name)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, bundle)
+}
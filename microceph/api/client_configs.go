@@ -121,6 +121,11 @@ func clientConfigsKeyPut(s mcTypes.State, r *http.Request) mcTypes.Response {
 		return mcTypes.InternalError(err)
 	}
 
+	err = ceph.ValidateClientConfig(req.Key, req.Value)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
 	// If new config request is for global configuration.
 	err = database.ClientConfigQuery.AddNew(r.Context(), s, req.Key, req.Value, req.Host)
 	if err != nil {
@@ -3,9 +3,13 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/canonical/lxd/shared"
 	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/gorilla/mux"
 
 	"github.com/canonical/microceph/microceph/api/types"
 	"github.com/canonical/microceph/microceph/ceph"
@@ -23,6 +27,35 @@ var configsCmd = mcTypes.Endpoint{
 	Delete: mcTypes.EndpointAction{Handler: cmdConfigsDelete, ProxyTarget: true},
 }
 
+// /1.0/configs/apply endpoint.
+var configsApplyCmd = mcTypes.Endpoint{
+	Path: "configs/apply",
+
+	Post: mcTypes.EndpointAction{Handler: cmdConfigsApplyPost, ProxyTarget: true},
+}
+
+// /1.0/configs/check endpoint.
+var configsCheckCmd = mcTypes.Endpoint{
+	Path: "configs/check",
+
+	Get:  mcTypes.EndpointAction{Handler: cmdConfigsCheckGet, ProxyTarget: true},
+	Post: mcTypes.EndpointAction{Handler: cmdConfigsCheckPost, ProxyTarget: true},
+}
+
+// /1.0/configs/history endpoint.
+var configsHistoryCmd = mcTypes.Endpoint{
+	Path: "configs/history",
+
+	Get: mcTypes.EndpointAction{Handler: cmdConfigsHistoryGet, ProxyTarget: true},
+}
+
+// /1.0/configs/history/{id}/rollback endpoint.
+var configsRollbackCmd = mcTypes.Endpoint{
+	Path: "configs/history/{id}/rollback",
+
+	Post: mcTypes.EndpointAction{Handler: cmdConfigsRollbackPost, ProxyTarget: true},
+}
+
 func cmdConfigsGet(s mcTypes.State, r *http.Request) mcTypes.Response {
 	var err error
 	var req types.Config
@@ -34,11 +67,18 @@ func cmdConfigsGet(s mcTypes.State, r *http.Request) mcTypes.Response {
 	}
 
 	// If a valid key string is passed, fetch that key.
-	if len(req.Key) > 0 {
+	if len(req.Key) > 0 && req.Advanced {
+		configs, err = ceph.GetAdvancedConfigItem(req)
+	} else if len(req.Key) > 0 {
 		configs, err = ceph.GetConfigItem(req)
 	} else {
-		// Fetch all configs.
+		// Fetch all configs, along with the advanced options set through MicroCeph.
 		configs, err = ceph.ListConfigs()
+		if err == nil {
+			var advanced types.Configs
+			advanced, err = ceph.ListAdvancedConfigs(r.Context(), interfaces.CephState{State: s})
+			configs = append(configs, advanced...)
+		}
 	}
 	if err != nil {
 		return mcTypes.SmartError(err)
@@ -49,21 +89,21 @@ func cmdConfigsGet(s mcTypes.State, r *http.Request) mcTypes.Response {
 
 func cmdConfigsPut(s mcTypes.State, r *http.Request) mcTypes.Response {
 	var req types.Config
-	configTable := ceph.GetConstConfigTable()
 
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		return mcTypes.InternalError(err)
 	}
 
-	// Configure the key/value
-	err = ceph.SetConfigItem(req)
+	req.Requester = configRequester(s, r)
+
+	// Configure the key/value, recording the change.
+	services, err := ceph.SetClusterConfig(r.Context(), interfaces.CephState{State: s}, req)
 	if err != nil {
 		return mcTypes.SmartError(err)
 	}
 
 	if !req.SkipRestart {
-		services := configTable[req.Key].Daemons
 		err = configChangeRefresh(r.Context(), s, services, req.Wait)
 		if err != nil {
 			return mcTypes.InternalError(err)
@@ -75,21 +115,164 @@ func cmdConfigsPut(s mcTypes.State, r *http.Request) mcTypes.Response {
 
 func cmdConfigsDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
 	var req types.Config
-	configTable := ceph.GetConstConfigTable()
 
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		return mcTypes.InternalError(err)
 	}
 
-	// Clean the key/value
-	err = ceph.RemoveConfigItem(req)
+	req.Requester = configRequester(s, r)
+
+	// Clean the key/value, recording the change.
+	services, err := ceph.ResetClusterConfig(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	if !req.SkipRestart {
+		err = configChangeRefresh(r.Context(), s, services, req.Wait)
+		if err != nil {
+			return mcTypes.InternalError(err)
+		}
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdConfigsApplyPost brings the cluster configuration to a config document,
+// restarting each affected daemon once for all the changes.
+func cmdConfigsApplyPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.ConfigApply
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.BadRequest(fmt.Errorf("invalid config apply request: %w", err))
+	}
+
+	req.Requester = configRequester(s, r)
+
+	plan, err := ceph.PlanConfigApply(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	if req.DryRun || len(plan.Changes) == 0 {
+		return mcTypes.SyncResponse(true, plan)
+	}
+
+	applied, err := ceph.ApplyConfigPlan(r.Context(), interfaces.CephState{State: s}, plan, req.Requester)
+	if err != nil {
+		// Refresh the daemons for the changes left applied to take effect,
+		// the daemons of the whole plan cover them.
+		if len(applied) > 0 {
+			refreshErr := configApplyRefresh(r.Context(), s, req, types.ConfigPlan{Changes: applied, Services: plan.Services})
+			if refreshErr != nil {
+				logger.Errorf("failed to refresh ceph services for the partially applied config: %v", refreshErr)
+			}
+		}
+
+		return mcTypes.SmartError(err)
+	}
+	plan.Applied = true
+
+	err = configApplyRefresh(r.Context(), s, req, plan)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	return mcTypes.SyncResponse(true, plan)
+}
+
+// configApplyRefresh restarts the daemons of an applied plan, once for all its
+// changes, and renders ceph.conf again for client config changes.
+func configApplyRefresh(ctx context.Context, s mcTypes.State, req types.ConfigApply, plan types.ConfigPlan) error {
+	if !req.SkipRestart && len(plan.Services) > 0 {
+		err := configChangeRefresh(ctx, s, plan.Services, req.Wait)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Client configs only need ceph.conf to be rendered again, once for all.
+	clientChanged := false
+	for _, change := range plan.Changes {
+		if change.Scope == types.ConfigScopeClient {
+			clientChanged = true
+		}
+	}
+
+	if clientChanged {
+		return clientConfigUpdate(ctx, s, req.Wait)
+	}
+
+	return nil
+}
+
+// cmdConfigsCheckGet reports the drift between the options MicroCeph tracks and Ceph.
+func cmdConfigsCheckGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	drifts, err := ceph.CheckConfigDrift(r.Context(), interfaces.CephState{State: s})
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, drifts)
+}
+
+// cmdConfigsCheckPost reconciles the drift between the options MicroCeph tracks and Ceph.
+func cmdConfigsCheckPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.ConfigReconcile
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	req.Requester = configRequester(s, r)
+
+	if req.Direction != types.ConfigReconcileCeph && req.Direction != types.ConfigReconcileMicroCeph {
+		return mcTypes.BadRequest(fmt.Errorf("invalid reconcile direction %q", req.Direction))
+	}
+
+	drifts, err := ceph.ReconcileConfigDrift(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, drifts)
+}
+
+// cmdConfigsHistoryGet lists the recorded config changes, optionally of a single key.
+func cmdConfigsHistoryGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	changes, err := ceph.GetConfigHistory(r.Context(), interfaces.CephState{State: s}, r.URL.Query().Get("key"))
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, changes)
+}
+
+// cmdConfigsRollbackPost restores the value a recorded config change replaced.
+func cmdConfigsRollbackPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.ConfigRollback
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		return mcTypes.BadRequest(fmt.Errorf("expected change id to be a number: %w", err))
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	req.Requester = configRequester(s, r)
+
+	services, err := ceph.RollbackConfigChange(r.Context(), interfaces.CephState{State: s}, id, req)
 	if err != nil {
 		return mcTypes.SmartError(err)
 	}
 
 	if !req.SkipRestart {
-		services := configTable[req.Key].Daemons
 		err = configChangeRefresh(r.Context(), s, services, req.Wait)
 		if err != nil {
 			return mcTypes.InternalError(err)
@@ -99,6 +282,29 @@ func cmdConfigsDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
 	return mcTypes.EmptySyncResponse
 }
 
+// configRequester identifies who asked for a config change from the
+// authenticated request, for the config history. Requests over the local
+// socket come from an administrator of this member, the others from the
+// member, or the client, whose certificate they present.
+func configRequester(s mcTypes.State, r *http.Request) string {
+	if r.RemoteAddr == "@" {
+		return fmt.Sprintf("local@%s", s.Name())
+	}
+
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return "unknown"
+	}
+
+	cert := r.TLS.PeerCertificates[0]
+	for name, remote := range s.Truststore().RemoteCertificatesNative() {
+		if cert.Equal(&remote) {
+			return fmt.Sprintf("member@%s", name)
+		}
+	}
+
+	return fmt.Sprintf("cert@%s", shared.CertFingerprint(cert)[:12])
+}
+
 // Perform ordered (one after other) restart of provided Ceph services across the ceph cluster.
 func configChangeRefresh(ctx context.Context, s mcTypes.State, services []string, wait bool) error {
 	if wait {
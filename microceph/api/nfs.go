@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/ceph"
+	"github.com/canonical/microceph/microceph/interfaces"
+)
+
+// /1.0/nfs/{cluster}/exports endpoint.
+var nfsExportsCmd = mcTypes.Endpoint{
+	Path: "nfs/{cluster}/exports",
+	Get:  mcTypes.EndpointAction{Handler: cmdNFSExportsGet, ProxyTarget: true},
+	Post: mcTypes.EndpointAction{Handler: cmdNFSExportsPost, ProxyTarget: true},
+}
+
+// /1.0/nfs/{cluster}/exports/{id} endpoint.
+var nfsExportCmd = mcTypes.Endpoint{
+	Path:   "nfs/{cluster}/exports/{id}",
+	Put:    mcTypes.EndpointAction{Handler: cmdNFSExportPut, ProxyTarget: true},
+	Delete: mcTypes.EndpointAction{Handler: cmdNFSExportDelete, ProxyTarget: true},
+}
+
+// /1.0/nfs/{cluster}/status endpoint.
+var nfsStatusCmd = mcTypes.Endpoint{
+	Path: "nfs/{cluster}/status",
+	Get:  mcTypes.EndpointAction{Handler: cmdNFSStatusGet, ProxyTarget: true},
+}
+
+// /1.0/nfs/{cluster}/repair endpoint.
+var nfsRepairCmd = mcTypes.Endpoint{
+	Path: "nfs/{cluster}/repair",
+	Post: mcTypes.EndpointAction{Handler: cmdNFSRepairPost, ProxyTarget: true},
+}
+
+// cmdNFSStatusGet reports the gateways and grace database of an NFS cluster,
+// or with local=true the gateway of this member.
+func cmdNFSStatusGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	clusterID, err := nfsPathClusterID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	// Members asked for the gateway they run only report that one.
+	if r.URL.Query().Get("local") == "true" {
+		node, err := ceph.GetLocalNFSNodeStatus(r.Context(), interfaces.CephState{State: s}, clusterID)
+		if err != nil {
+			return mcTypes.SmartError(err)
+		}
+
+		return mcTypes.SyncResponse(true, node)
+	}
+
+	status, err := ceph.GetNFSClusterStatus(r.Context(), interfaces.CephState{State: s}, clusterID)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, status)
+}
+
+// cmdNFSRepairPost removes stale nodes from the grace database of an NFS cluster
+// and returns their names.
+func cmdNFSRepairPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	clusterID, err := nfsPathClusterID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	removed, err := ceph.RepairNFSCluster(r.Context(), interfaces.CephState{State: s}, clusterID)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, removed)
+}
+
+// cmdNFSExportsGet lists the exports of an NFS cluster.
+func cmdNFSExportsGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	clusterID, err := nfsPathClusterID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	exports, err := ceph.ListNFSExports(r.Context(), interfaces.CephState{State: s}, clusterID)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, exports)
+}
+
+// cmdNFSExportsPost creates an export of an NFS cluster and returns it with its ID.
+func cmdNFSExportsPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var export types.NFSExport
+
+	clusterID, err := nfsPathClusterID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&export)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	export, err = ceph.CreateNFSExport(r.Context(), interfaces.CephState{State: s}, clusterID, export)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, export)
+}
+
+// cmdNFSExportPut replaces an export of an NFS cluster.
+func cmdNFSExportPut(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var export types.NFSExport
+
+	clusterID, err := nfsPathClusterID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	exportID, err := nfsPathExportID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&export)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	export.ExportID = exportID
+	err = ceph.UpdateNFSExport(r.Context(), interfaces.CephState{State: s}, clusterID, export)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdNFSExportDelete removes an export of an NFS cluster.
+func cmdNFSExportDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
+	clusterID, err := nfsPathClusterID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	exportID, err := nfsPathExportID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.DeleteNFSExport(r.Context(), interfaces.CephState{State: s}, clusterID, exportID)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// nfsPathClusterID unescapes and validates the {cluster} path variable.
+func nfsPathClusterID(r *http.Request) (string, error) {
+	clusterID, err := url.PathUnescape(mux.Vars(r)["cluster"])
+	if err != nil {
+		return "", err
+	}
+
+	if !types.NFSClusterIDRegex.MatchString(clusterID) {
+		return "", fmt.Errorf("expected cluster_id to be valid (regex: '%s')", types.NFSClusterIDRegex.String())
+	}
+
+	return clusterID, nil
+}
+
+// nfsPathExportID parses the {id} path variable.
+func nfsPathExportID(r *http.Request) (int, error) {
+	exportID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || exportID < 1 {
+		return 0, fmt.Errorf("invalid export ID %q", mux.Vars(r)["id"])
+	}
+
+	return exportID, nil
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/ceph"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// /ops/backup endpoint.
+var opsBackupCmd = mcTypes.Endpoint{
+	Path: "ops/backup",
+	Post: mcTypes.EndpointAction{Handler: cmdOpsBackupPost, ProxyTarget: false},
+}
+
+// /ops/restore endpoint.
+var opsRestoreCmd = mcTypes.Endpoint{
+	Path: "ops/restore",
+	Post: mcTypes.EndpointAction{Handler: cmdOpsRestorePost, ProxyTarget: false},
+}
+
+// cmdOpsBackupPost writes a backup archive of the cluster on the local member.
+func cmdOpsBackupPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.ClusterBackup
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	result, err := ceph.BackupCluster(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		logger.Errorf("failed to back up cluster: %v", err)
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, result)
+}
+
+// cmdOpsRestorePost rebuilds the control plane on the local member from a
+// backup archive.
+func cmdOpsRestorePost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.ClusterRestore
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = ceph.ValidateBackupPath(req.Path)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	result, err := ceph.RestoreCluster(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		logger.Errorf("failed to restore cluster from %s: %v", req.Path, err)
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, result)
+}
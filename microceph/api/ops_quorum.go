@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/ceph"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// /ops/recover-quorum endpoint. It is served without a database, which may
+// have lost its quorum along with the monitors: AllowedBeforeInit lets
+// requests through while the daemon waits for it. Operators reach it with
+// 'microceph cluster recover-quorum' on a surviving monitor host, which talks
+// to the local daemon over its unix socket. Requests are never proxied to
+// other members, which may be unreachable.
+var opsRecoverQuorumCmd = mcTypes.Endpoint{
+	Path:              "ops/recover-quorum",
+	Get:               mcTypes.EndpointAction{Handler: cmdOpsRecoverQuorumGet, ProxyTarget: false},
+	Post:              mcTypes.EndpointAction{Handler: cmdOpsRecoverQuorumPost, ProxyTarget: false},
+	AllowedBeforeInit: true,
+}
+
+// cmdOpsRecoverQuorumGet reports the monitor quorum as seen by the local monitor.
+func cmdOpsRecoverQuorumGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	status, err := ceph.GetQuorumStatus(interfaces.CephState{State: s})
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, status)
+}
+
+// cmdOpsRecoverQuorumPost removes unreachable monitors from the monmap of the
+// local monitor.
+func cmdOpsRecoverQuorumPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.QuorumRecovery
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	if len(req.Remove) == 0 {
+		return mcTypes.BadRequest(fmt.Errorf("no monitors to remove"))
+	}
+
+	result, err := ceph.RecoverQuorum(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		logger.Errorf("failed to recover monitor quorum: %v", err)
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, result)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/ceph"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// /ops/upgrade endpoint.
+var opsUpgradeCmd = mcTypes.Endpoint{
+	Path: "ops/upgrade",
+	Get:  mcTypes.EndpointAction{Handler: cmdOpsUpgradeGet, ProxyTarget: false},
+	Post: mcTypes.EndpointAction{Handler: cmdOpsUpgradePost, ProxyTarget: false},
+}
+
+// /ops/upgrade/refresh endpoint.
+var opsUpgradeRefreshCmd = mcTypes.Endpoint{
+	Path: "ops/upgrade/refresh",
+	Post: mcTypes.EndpointAction{Handler: cmdOpsUpgradeRefreshPost, ProxyTarget: true},
+}
+
+// /ops/upgrade/status endpoint.
+var opsUpgradeStatusCmd = mcTypes.Endpoint{
+	Path: "ops/upgrade/status",
+	Get:  mcTypes.EndpointAction{Handler: cmdOpsUpgradeStatusGet, ProxyTarget: true},
+}
+
+// cmdOpsUpgradeGet returns the order members are upgraded in.
+func cmdOpsUpgradeGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	plan, err := ceph.GetUpgradePlan(r.Context(), interfaces.CephState{State: s})
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, plan)
+}
+
+// cmdOpsUpgradePost finalizes an upgrade once every member runs the new release.
+func cmdOpsUpgradePost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	release, err := ceph.FinalizeUpgrade()
+	if err != nil {
+		logger.Errorf("failed to finalize upgrade: %v", err)
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, release)
+}
+
+// cmdOpsUpgradeRefreshPost starts the snap refresh of the target member.
+func cmdOpsUpgradeRefreshPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.UpgradeRefresh
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	result, err := ceph.RefreshMember(r.Context(), interfaces.CephState{State: s}, req.Channel)
+	if err != nil {
+		logger.Errorf("failed to refresh %s to %s: %v", s.Name(), req.Channel, err)
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, result)
+}
+
+// cmdOpsUpgradeStatusGet reports the upgrade state of the target member.
+func cmdOpsUpgradeStatusGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	tolerated := []string{}
+	tolerate := r.URL.Query().Get("tolerate")
+	if len(tolerate) != 0 {
+		tolerated = strings.Split(tolerate, ",")
+	}
+
+	status, err := ceph.GetUpgradeStatus(interfaces.CephState{State: s}, tolerated)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, status)
+}
+
+// /ops/refresh endpoint.
+var opsRefreshCmd = mcTypes.Endpoint{
+	Path: "ops/refresh",
+	Get:  mcTypes.EndpointAction{Handler: cmdOpsRefreshGet, ProxyTarget: false},
+	Post: mcTypes.EndpointAction{Handler: cmdOpsRefreshPost, ProxyTarget: true},
+}
+
+// cmdOpsRefreshGet returns the latest refresh decision of every member.
+func cmdOpsRefreshGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	decisions, err := ceph.GetRefreshDecisions(r.Context(), interfaces.CephState{State: s})
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, decisions)
+}
+
+// cmdOpsRefreshPost decides whether the target member can be refreshed.
+func cmdOpsRefreshPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RefreshCheck
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	decision, err := ceph.CheckRefresh(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		logger.Errorf("failed to check refresh of %s: %v", s.Name(), err)
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, decision)
+}
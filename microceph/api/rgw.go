@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/ceph"
+	"github.com/canonical/microceph/microceph/interfaces"
+)
+
+// /1.0/rgw/realms endpoint.
+var rgwRealmsCmd = mcTypes.Endpoint{
+	Path: "rgw/realms",
+	Post: mcTypes.EndpointAction{Handler: cmdRgwRealmsPost, ProxyTarget: true},
+}
+
+// /1.0/rgw/realms/{name}/pull endpoint.
+var rgwRealmPullCmd = mcTypes.Endpoint{
+	Path: "rgw/realms/{name}/pull",
+	Post: mcTypes.EndpointAction{Handler: cmdRgwRealmPullPost, ProxyTarget: true},
+}
+
+// /1.0/rgw/realms/{name}/commit endpoint.
+var rgwRealmCommitCmd = mcTypes.Endpoint{
+	Path: "rgw/realms/{name}/commit",
+	Post: mcTypes.EndpointAction{Handler: cmdRgwRealmCommitPost, ProxyTarget: true},
+}
+
+// /1.0/rgw/zonegroups endpoint.
+var rgwZoneGroupsCmd = mcTypes.Endpoint{
+	Path: "rgw/zonegroups",
+	Post: mcTypes.EndpointAction{Handler: cmdRgwZoneGroupsPost, ProxyTarget: true},
+}
+
+// /1.0/rgw/zones endpoint.
+var rgwZonesCmd = mcTypes.Endpoint{
+	Path: "rgw/zones",
+	Post: mcTypes.EndpointAction{Handler: cmdRgwZonesPost, ProxyTarget: true},
+}
+
+// /1.0/rgw/zone endpoint.
+var rgwZoneCmd = mcTypes.Endpoint{
+	Path: "rgw/zone",
+	Put:  mcTypes.EndpointAction{Handler: cmdRgwZonePut, ProxyTarget: true},
+}
+
+// /1.0/rgw/sync/status endpoint.
+var rgwSyncStatusCmd = mcTypes.Endpoint{
+	Path: "rgw/sync/status",
+	Get:  mcTypes.EndpointAction{Handler: cmdRgwSyncStatusGet, ProxyTarget: true},
+}
+
+// cmdRgwRealmsPost creates a realm.
+func cmdRgwRealmsPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwRealmRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = validateRgwName(req.Name)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.CreateRgwRealm(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwRealmPullPost pulls a realm from the master zone of another site.
+func cmdRgwRealmPullPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwRealmRequest
+
+	realm, err := rgwPathName(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	req.Name = realm
+	err = ceph.PullRgwRealm(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwRealmCommitPost commits the pending period of a realm.
+func cmdRgwRealmCommitPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	realm, err := rgwPathName(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.CommitRgwPeriod(r.Context(), interfaces.CephState{State: s}, realm)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwZonePut applies the recorded zone to the RGW daemons of the member.
+func cmdRgwZonePut(s mcTypes.State, r *http.Request) mcTypes.Response {
+	err := ceph.ApplyRgwZone(r.Context(), interfaces.CephState{State: s})
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwZoneGroupsPost creates a zonegroup.
+func cmdRgwZoneGroupsPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwZoneGroupRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = validateRgwName(req.Name)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.CreateRgwZoneGroup(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwZonesPost creates a zone and points the RGW daemons at it.
+func cmdRgwZonesPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwZoneRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = validateRgwName(req.Name)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.CreateRgwZone(r.Context(), interfaces.CephState{State: s}, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwSyncStatusGet reports whether the local zone has caught up with its peers.
+func cmdRgwSyncStatusGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	status, err := ceph.GetRgwSyncStatus(r.Context(), s)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, status)
+}
+
+// rgwPathName unescapes and validates the {name} path variable.
+func rgwPathName(r *http.Request) (string, error) {
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return "", err
+	}
+
+	return name, validateRgwName(name)
+}
+
+// validateRgwName checks that an RGW realm, zonegroup or zone name is acceptable.
+func validateRgwName(name string) error {
+	if !types.RgwNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid name %q, expected to match '%s'", name, types.RgwNameRegex.String())
+	}
+
+	return nil
+}
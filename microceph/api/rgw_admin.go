@@ -0,0 +1,306 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/ceph"
+)
+
+// /1.0/rgw/users endpoint.
+var rgwUsersCmd = mcTypes.Endpoint{
+	Path: "rgw/users",
+	Get:  mcTypes.EndpointAction{Handler: cmdRgwUsersGet, ProxyTarget: true},
+	Post: mcTypes.EndpointAction{Handler: cmdRgwUsersPost, ProxyTarget: true},
+}
+
+// /1.0/rgw/users/{uid} endpoint.
+var rgwUserCmd = mcTypes.Endpoint{
+	Path:   "rgw/users/{uid}",
+	Get:    mcTypes.EndpointAction{Handler: cmdRgwUserGet, ProxyTarget: true},
+	Put:    mcTypes.EndpointAction{Handler: cmdRgwUserPut, ProxyTarget: true},
+	Delete: mcTypes.EndpointAction{Handler: cmdRgwUserDelete, ProxyTarget: true},
+}
+
+// /1.0/rgw/users/{uid}/keys endpoint.
+var rgwUserKeysCmd = mcTypes.Endpoint{
+	Path: "rgw/users/{uid}/keys",
+	Post: mcTypes.EndpointAction{Handler: cmdRgwUserKeysPost, ProxyTarget: true},
+}
+
+// /1.0/rgw/users/{uid}/keys/{key} endpoint.
+var rgwUserKeyCmd = mcTypes.Endpoint{
+	Path:   "rgw/users/{uid}/keys/{key}",
+	Delete: mcTypes.EndpointAction{Handler: cmdRgwUserKeyDelete, ProxyTarget: true},
+}
+
+// /1.0/rgw/users/{uid}/quota endpoint.
+var rgwUserQuotaCmd = mcTypes.Endpoint{
+	Path: "rgw/users/{uid}/quota",
+	Put:  mcTypes.EndpointAction{Handler: cmdRgwUserQuotaPut, ProxyTarget: true},
+}
+
+// /1.0/rgw/buckets endpoint.
+var rgwBucketsCmd = mcTypes.Endpoint{
+	Path: "rgw/buckets",
+	Get:  mcTypes.EndpointAction{Handler: cmdRgwBucketsGet, ProxyTarget: true},
+}
+
+// /1.0/rgw/buckets/{bucket} endpoint.
+var rgwBucketCmd = mcTypes.Endpoint{
+	Path: "rgw/buckets/{bucket}",
+	Put:  mcTypes.EndpointAction{Handler: cmdRgwBucketPut, ProxyTarget: true},
+}
+
+// cmdRgwUsersGet lists the RGW users.
+func cmdRgwUsersGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	users, err := ceph.ListRgwUsers()
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, users)
+}
+
+// cmdRgwUsersPost creates an RGW user and returns it with its first key.
+func cmdRgwUsersPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwUserRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = validateRgwUserID(req.UserID)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.CheckRgwUserChangeable(req.UserID)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	if len(req.DisplayName) == 0 {
+		req.DisplayName = req.UserID
+	}
+
+	user, err := ceph.CreateRgwUser(req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, user)
+}
+
+// cmdRgwUserGet fetches an RGW user with its keys and quotas.
+func cmdRgwUserGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	uid, err := rgwUserPathID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	user, err := ceph.GetRgwUser(uid)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, user)
+}
+
+// cmdRgwUserPut suspends or re-enables an RGW user and updates its bucket limit.
+func cmdRgwUserPut(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwUserSetRequest
+
+	uid, err := rgwChangeableUserPathID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = ceph.SetRgwUser(uid, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwUserDelete removes an RGW user.
+func cmdRgwUserDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwUserDeleteRequest
+
+	uid, err := rgwChangeableUserPathID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	if req.PurgeData && !req.IsForceOp {
+		return mcTypes.BadRequest(fmt.Errorf("purging user %s deletes all of its buckets and objects, force is required", uid))
+	}
+
+	err = ceph.DeleteRgwUser(uid, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwUserKeysPost generates or rotates an S3 key of an RGW user and returns the new key.
+func cmdRgwUserKeysPost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwKeyRequest
+
+	uid, err := rgwChangeableUserPathID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	key, err := ceph.CreateRgwUserKey(uid, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, key)
+}
+
+// cmdRgwUserKeyDelete removes an S3 key of an RGW user.
+func cmdRgwUserKeyDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
+	uid, err := rgwChangeableUserPathID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	key, err := url.PathUnescape(mux.Vars(r)["key"])
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.RemoveRgwUserKey(uid, key)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwUserQuotaPut sets the user or per-bucket quota of an RGW user.
+func cmdRgwUserQuotaPut(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwQuotaRequest
+
+	uid, err := rgwChangeableUserPathID(r)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = ceph.SetRgwUserQuota(uid, req)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// cmdRgwBucketsGet lists the RGW buckets with their usage, optionally filtered by owner.
+func cmdRgwBucketsGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	owner := r.URL.Query().Get("owner")
+	if len(owner) != 0 {
+		err := validateRgwUserID(owner)
+		if err != nil {
+			return mcTypes.BadRequest(err)
+		}
+	}
+
+	buckets, err := ceph.ListRgwBuckets(owner)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, buckets)
+}
+
+// cmdRgwBucketPut changes the owner of an RGW bucket.
+func cmdRgwBucketPut(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.RgwBucketSetRequest
+
+	bucket, err := url.PathUnescape(mux.Vars(r)["bucket"])
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	if !types.RgwBucketNameRegex.MatchString(bucket) {
+		return mcTypes.BadRequest(fmt.Errorf("invalid bucket name %q, expected to match '%s'", bucket, types.RgwBucketNameRegex.String()))
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return mcTypes.InternalError(err)
+	}
+
+	err = validateRgwUserID(req.Owner)
+	if err != nil {
+		return mcTypes.BadRequest(err)
+	}
+
+	err = ceph.SetRgwBucketOwner(bucket, req.Owner)
+	if err != nil {
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
+// rgwUserPathID unescapes and validates the {uid} path variable.
+func rgwUserPathID(r *http.Request) (string, error) {
+	uid, err := url.PathUnescape(mux.Vars(r)["uid"])
+	if err != nil {
+		return "", err
+	}
+
+	return uid, validateRgwUserID(uid)
+}
+
+// rgwChangeableUserPathID is rgwUserPathID for requests changing the user,
+// which MicroCeph refuses for its multisite system user.
+func rgwChangeableUserPathID(r *http.Request) (string, error) {
+	uid, err := rgwUserPathID(r)
+	if err != nil {
+		return "", err
+	}
+
+	return uid, ceph.CheckRgwUserChangeable(uid)
+}
+
+// validateRgwUserID checks that an RGW user ID is acceptable.
+func validateRgwUserID(uid string) error {
+	if !types.RgwUserIDRegex.MatchString(uid) {
+		return fmt.Errorf("invalid user ID %q, expected to match '%s'", uid, types.RgwUserIDRegex.String())
+	}
+
+	return nil
+}
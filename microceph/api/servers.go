@@ -20,7 +20,12 @@ var Servers = map[string]mcTypes.Server{
 					resourcesCmd,
 					servicesCmd,
 					configsCmd,
+					configsApplyCmd,
+					configsCheckCmd,
+					configsHistoryCmd,
+					configsRollbackCmd,
 					restartServiceCmd,
+					rollingRestartServiceCmd,
 					mdsServiceCmd,
 					mgrServiceCmd,
 					monServiceCmd,
@@ -33,9 +38,12 @@ var Servers = map[string]mcTypes.Server{
 					clientCmd,
 					clientConfigsCmd,
 					clientConfigsKeyCmd,
+					clientBundleCmd,
 					microcephCmd,
 					microcephConfigsCmd,
 					logLevelCmd,
+					versionsCmd,
+					versionsMemberCmd,
 					clusterCmd,
 					remoteCmd,
 					remoteNameCmd,
@@ -46,8 +54,49 @@ var Servers = map[string]mcTypes.Server{
 					opsReplicationResourceCmd,
 					// Maintenance APIs
 					opsMaintenanceNodeCmd,
+					// Upgrade APIs
+					opsUpgradeCmd,
+					opsUpgradeRefreshCmd,
+					opsUpgradeStatusCmd,
+					opsRefreshCmd,
+					// Backup APIs
+					opsBackupCmd,
+					opsRestoreCmd,
+					opsRecoverQuorumCmd,
 					// Certificate APIs
 					certificatesRGWCmd,
+					// CephFS APIs
+					fsVolumesCmd,
+					fsVolumeCmd,
+					fsSubvolumesCmd,
+					fsSubvolumeCmd,
+					fsSubvolumeGroupsCmd,
+					fsSubvolumeGroupCmd,
+					fsSnapSchedulesCmd,
+					// RGW multisite and administration APIs
+					rgwRealmsCmd,
+					rgwRealmPullCmd,
+					rgwRealmCommitCmd,
+					rgwZoneGroupsCmd,
+					rgwZonesCmd,
+					rgwZoneCmd,
+					rgwSyncStatusCmd,
+					rgwUsersCmd,
+					rgwUserCmd,
+					rgwUserKeysCmd,
+					rgwUserKeyCmd,
+					rgwUserQuotaCmd,
+					rgwBucketsCmd,
+					rgwBucketCmd,
+					// NFS export and status APIs
+					nfsExportsCmd,
+					nfsExportCmd,
+					nfsStatusCmd,
+					nfsRepairCmd,
+					// Cephx client APIs
+					authClientsCmd,
+					authClientCmd,
+					authClientRotateCmd,
 					// CE142 placement and Ceph-only bootstrap APIs
 					placementCmd,
 					cephBootstrapCmd,
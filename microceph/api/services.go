@@ -1,10 +1,14 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"path"
+	"time"
 
 	"github.com/canonical/microceph/microceph/database"
 	"github.com/canonical/microceph/microceph/interfaces"
@@ -132,9 +136,9 @@ func cmdRestartServicePost(s mcTypes.State, r *http.Request) mcTypes.Response {
 	}
 
 	// Check if provided services are valid and available in microceph
+	valid_services := ceph.GetConfigTableServiceSet()
 	for _, service := range services {
-		valid_services := ceph.GetConfigTableServiceSet()
-		if _, ok := valid_services[service.Service]; !ok {
+		if _, ok := valid_services[service.Service]; !ok && !ceph.IsOSDDaemon(service.Service) {
 			err := fmt.Errorf("%s is not a valid ceph service", service.Service)
 			logger.Errorf("%v", err)
 			return mcTypes.InternalError(err)
@@ -159,6 +163,50 @@ func cmdRestartServicePost(s mcTypes.State, r *http.Request) mcTypes.Response {
 	return mcTypes.EmptySyncResponse
 }
 
+// Rolling Service Restart Endpoint.
+var rollingRestartServiceCmd = mcTypes.Endpoint{
+	Path: "services/restart/rolling",
+	Post: mcTypes.EndpointAction{Handler: cmdRollingRestartServicePost, ProxyTarget: true},
+}
+
+// rollingRestartTimeout bounds the server-side execution of a rolling restart.
+// Every step may wait for the cluster health for the health timeout of the
+// request, so the restart must outlive typical client timeouts, and it carries
+// on if the client disconnects rather than stopping between two members.
+const rollingRestartTimeout = 2 * time.Hour
+
+func cmdRollingRestartServicePost(s mcTypes.State, r *http.Request) mcTypes.Response {
+	var req types.ServiceRestart
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		logger.Errorf("Failed decoding rolling restart: %v", err)
+		return mcTypes.BadRequest(fmt.Errorf("invalid rolling restart request: %w", err))
+	}
+
+	if req.MaxParallel < 0 || req.HealthTimeout < 0 {
+		return mcTypes.BadRequest(fmt.Errorf("max parallel and health timeout must not be negative"))
+	}
+
+	valid_services := ceph.GetConfigTableServiceSet()
+	for _, service := range req.Services {
+		if _, ok := valid_services[service]; !ok {
+			return mcTypes.BadRequest(fmt.Errorf("%s is not a valid ceph service", service))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), rollingRestartTimeout)
+	defer cancel()
+
+	err = ceph.RollingRestartCephServices(ctx, interfaces.CephState{State: s}, req)
+	if err != nil {
+		logger.Errorf("Failed rolling restart of %v: %v", req.Services, err)
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.EmptySyncResponse
+}
+
 // cmdDeleteService handles service deletion.
 func cmdDeleteService(s mcTypes.State, r *http.Request) mcTypes.Response {
 	which := path.Base(r.URL.Path)
@@ -201,8 +249,33 @@ func cmdNFSDeleteService(s mcTypes.State, r *http.Request) mcTypes.Response {
 	return mcTypes.EmptySyncResponse
 }
 
+// cmdRGWServiceDelete handles the RGW service deletion, of a grouped RGW service if a GroupID is given.
 func cmdRGWServiceDelete(s mcTypes.State, r *http.Request) mcTypes.Response {
-	err := ceph.DisableRGW(r.Context(), interfaces.CephState{State: s})
+	var svc types.RGWGroupService
+
+	// The body is optional, the default RGW service is removed without one.
+	err := json.NewDecoder(r.Body).Decode(&svc)
+	if err != nil && !errors.Is(err, io.EOF) {
+		logger.Errorf("failed decoding disable service request: %v", err)
+		return mcTypes.InternalError(err)
+	}
+
+	if len(svc.GroupID) != 0 {
+		if !types.RGWGroupIDRegex.MatchString(svc.GroupID) {
+			err := fmt.Errorf("expected group_id to be valid (regex: '%s')", types.RGWGroupIDRegex.String())
+			return mcTypes.SmartError(err)
+		}
+
+		err = ceph.DisableRGWGroup(r.Context(), interfaces.CephState{State: s}, svc.GroupID)
+		if err != nil {
+			logger.Errorf("Failed disabling RGW: %v", err)
+			return mcTypes.SmartError(err)
+		}
+
+		return mcTypes.EmptySyncResponse
+	}
+
+	err = ceph.DisableRGW(r.Context(), interfaces.CephState{State: s})
 	if err != nil {
 		logger.Errorf("Failed disabling RGW: %v", err)
 		return mcTypes.SmartError(err)
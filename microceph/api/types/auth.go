@@ -0,0 +1,48 @@
+package types
+
+import "regexp"
+
+// Cephx capability profiles, each granting access to a single resource.
+const (
+	AuthProfileRbd    = "rbd"
+	AuthProfileCephFS = "cephfs"
+	AuthProfileRgw    = "rgw"
+)
+
+// AuthClientProfile grants a cephx client access to an RBD pool, a CephFS
+// path or the RADOS Gateway.
+type AuthClientProfile struct {
+	// Type is one of rbd, cephfs or rgw.
+	Type string `json:"type" yaml:"type"`
+	// Pool is the RBD pool of an rbd profile.
+	Pool string `json:"pool,omitempty" yaml:"pool,omitempty"`
+	// FsName is the CephFS volume of a cephfs profile.
+	FsName string `json:"fs_name,omitempty" yaml:"fs_name,omitempty"`
+	// Path is the directory of a cephfs profile, the whole volume by default.
+	Path     string `json:"path,omitempty" yaml:"path,omitempty"`
+	ReadOnly bool   `json:"read_only" yaml:"read_only"`
+}
+
+// AuthClientRequest holds the request data for creating a cephx client.
+type AuthClientRequest struct {
+	// Name is the client name without the client. prefix.
+	Name     string              `json:"name" yaml:"name"`
+	Profiles []AuthClientProfile `json:"profiles" yaml:"profiles"`
+}
+
+// AuthClient is a cephx client along with its capabilities.
+type AuthClient struct {
+	Name string            `json:"name" yaml:"name"`
+	Caps map[string]string `json:"caps" yaml:"caps"`
+	// Managed is set for clients of the MicroCeph services, they cannot be changed.
+	Managed bool `json:"managed" yaml:"managed"`
+	// Key and Keyring are only set when fetching a single client.
+	Key     string `json:"key,omitempty" yaml:"key,omitempty"`
+	Keyring string `json:"keyring,omitempty" yaml:"keyring,omitempty"`
+}
+
+// AuthClients holds a slice of cephx clients.
+type AuthClients []AuthClient
+
+// AuthClientNameRegex is a regex for acceptable cephx client names.
+var AuthClientNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,63}$`)
@@ -0,0 +1,41 @@
+// Package types provides shared types and structs.
+package types
+
+import (
+	"time"
+)
+
+// ClusterBackup holds the parameters of a cluster backup. Force backs up even
+// when stopping the local monitor breaks the monitor quorum.
+type ClusterBackup struct {
+	Force bool `json:"force" yaml:"force"`
+}
+
+// ClusterBackupResult describes a cluster backup. Path is the archive written
+// on the member, Monitors the monitors in the monmap at backup time.
+type ClusterBackupResult struct {
+	Path     string    `json:"path" yaml:"path"`
+	FsID     string    `json:"fsid" yaml:"fsid"`
+	Member   string    `json:"member" yaml:"member"`
+	Monitors []string  `json:"monitors" yaml:"monitors"`
+	Created  time.Time `json:"created" yaml:"created"`
+}
+
+// ClusterRestore holds the path of the archive a cluster is restored from, on
+// the member restoring it.
+type ClusterRestore struct {
+	Path string `json:"path" yaml:"path"`
+}
+
+// ClusterRestoreResult describes a cluster restore. RemovedMonitors are the
+// monitors dropped from the monmap and Skipped the number of rows per table
+// left out as they belong to members missing from the cluster. NotStarted
+// lists the daemons of the member whose records are left out as the restore
+// does not start them.
+type ClusterRestoreResult struct {
+	FsID            string         `json:"fsid" yaml:"fsid"`
+	Member          string         `json:"member" yaml:"member"`
+	RemovedMonitors []string       `json:"removed_monitors" yaml:"removed_monitors"`
+	Skipped         map[string]int `json:"skipped" yaml:"skipped"`
+	NotStarted      []string       `json:"not_started" yaml:"not_started"`
+}
@@ -0,0 +1,102 @@
+package types
+
+import "regexp"
+
+// FsVolume describes a CephFS volume and the pools backing it.
+type FsVolume struct {
+	Name          string   `json:"name" yaml:"name"`
+	MetadataPool  string   `json:"metadata_pool" yaml:"metadata_pool"`
+	DataPools     []string `json:"data_pools" yaml:"data_pools"`
+	MaxMDS        int      `json:"max_mds" yaml:"max_mds"`
+	StandbyReplay bool     `json:"standby_replay" yaml:"standby_replay"`
+}
+
+// FsVolumes holds a slice of CephFS volumes.
+type FsVolumes []FsVolume
+
+// FsVolumeCreateRequest holds the request data for creating a CephFS volume.
+type FsVolumeCreateRequest struct {
+	Name string `json:"name" yaml:"name"`
+	// EnableMDS places an MDS on the serving node if none exists in the cluster.
+	EnableMDS bool `json:"enable_mds" yaml:"enable_mds"`
+}
+
+// FsVolumeSetRequest holds the volume properties to be updated, nil fields are left untouched.
+type FsVolumeSetRequest struct {
+	MaxMDS        *int  `json:"max_mds,omitempty" yaml:"max_mds,omitempty"`
+	StandbyReplay *bool `json:"standby_replay,omitempty" yaml:"standby_replay,omitempty"`
+}
+
+// FsVolumeDeleteRequest holds the request data for removing a CephFS volume.
+type FsVolumeDeleteRequest struct {
+	IsForceOp bool `json:"force" yaml:"force"`
+}
+
+// FsNameRegex is a regex for acceptable CephFS volume, subvolume and group names.
+var FsNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+// FsSubvolumeRequest holds the request data for managing a CephFS subvolume or subvolume group.
+type FsSubvolumeRequest struct {
+	Name string `json:"name" yaml:"name"`
+	// Group is the subvolume group of a subvolume, empty for the default group.
+	Group string `json:"group" yaml:"group"`
+	// Size is the quota in bytes, 0 means unlimited on create.
+	Size int64 `json:"size" yaml:"size"`
+	// Unlimited removes the quota on resize, which needs either a Size or Unlimited.
+	Unlimited  bool   `json:"unlimited" yaml:"unlimited"`
+	PoolLayout string `json:"pool_layout" yaml:"pool_layout"`
+	Mode       string `json:"mode" yaml:"mode"`
+	NoShrink   bool   `json:"no_shrink" yaml:"no_shrink"`
+	IsForceOp  bool   `json:"force" yaml:"force"`
+}
+
+// FsSubvolume describes a CephFS subvolume or subvolume group.
+type FsSubvolume struct {
+	Name  string `json:"name" yaml:"name"`
+	Group string `json:"group,omitempty" yaml:"group,omitempty"`
+	Path  string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// FsSubvolumes holds a slice of CephFS subvolumes or subvolume groups.
+type FsSubvolumes []FsSubvolume
+
+// FsModeRegex is a regex for acceptable octal subvolume modes.
+var FsModeRegex = regexp.MustCompile(`^[0-7]{3,4}$`)
+
+// FsSnapScheduleRequest holds the request data for managing CephFS snapshot schedules.
+// A schedule applies to a directory path, or to a subvolume when Subvolume is set.
+type FsSnapScheduleRequest struct {
+	Path      string `json:"path" yaml:"path"`
+	Subvolume string `json:"subvolume" yaml:"subvolume"`
+	Group     string `json:"group" yaml:"group"`
+	// Schedule is the snapshot interval, e.g. 1h, 1d or 1w.
+	Schedule string `json:"schedule" yaml:"schedule"`
+	// Start is an ISO 8601 timestamp the schedule is anchored to.
+	Start string `json:"start" yaml:"start"`
+	// Retention is a compact retention spec, e.g. 24h7d4w keeps 24 hourly, 7 daily and 4 weekly snapshots.
+	Retention string `json:"retention" yaml:"retention"`
+}
+
+// FsSnapSchedule describes a configured CephFS snapshot schedule and its progress.
+type FsSnapSchedule struct {
+	Volume    string         `json:"fs" yaml:"fs"`
+	Subvolume string         `json:"subvol" yaml:"subvol"`
+	Group     string         `json:"group" yaml:"group"`
+	Path      string         `json:"path" yaml:"path"`
+	Schedule  string         `json:"schedule" yaml:"schedule"`
+	Retention map[string]int `json:"retention" yaml:"retention"`
+	Start     string         `json:"start" yaml:"start"`
+	Last      string         `json:"last" yaml:"last"`
+	Created   int            `json:"created_count" yaml:"created_count"`
+	Pruned    int            `json:"pruned_count" yaml:"pruned_count"`
+	Active    bool           `json:"active" yaml:"active"`
+}
+
+// FsSnapSchedules holds a slice of CephFS snapshot schedules.
+type FsSnapSchedules []FsSnapSchedule
+
+// FsSnapScheduleRegex is a regex for acceptable snapshot schedule intervals.
+var FsSnapScheduleRegex = regexp.MustCompile(`^[1-9][0-9]*[mhdwMy]$`)
+
+// FsSnapRetentionRegex is a regex for acceptable compact snapshot retention specs.
+var FsSnapRetentionRegex = regexp.MustCompile(`^([1-9][0-9]*[nmhdwMy])+$`)
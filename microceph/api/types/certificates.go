@@ -1,8 +1,30 @@
 package types
 
+import "time"
+
 // CertificateSetRequest holds the request data for setting RGW SSL certificates.
 type CertificateSetRequest struct {
 	SSLCertificate string `json:"ssl_certificate" yaml:"ssl_certificate"`
 	SSLPrivateKey  string `json:"ssl_private_key" yaml:"ssl_private_key"`
 	Restart        bool   `json:"restart" yaml:"restart"`
+	// GroupID selects a grouped RGW daemon, the default daemon when empty.
+	GroupID string `json:"group_id" yaml:"group_id"`
+	// SelfManaged issues the certificate from the cluster-internal CA instead.
+	SelfManaged bool `json:"self_managed" yaml:"self_managed"`
+}
+
+// CertificateInfo describes an installed SSL certificate.
+type CertificateInfo struct {
+	Service     string    `json:"service" yaml:"service"`
+	GroupID     string    `json:"group_id" yaml:"group_id"`
+	Subject     string    `json:"subject" yaml:"subject"`
+	Issuer      string    `json:"issuer" yaml:"issuer"`
+	DNSNames    []string  `json:"dns_names" yaml:"dns_names"`
+	IPAddresses []string  `json:"ip_addresses" yaml:"ip_addresses"`
+	NotAfter    time.Time `json:"not_after" yaml:"not_after"`
+	// SelfManaged certificates are issued and renewed by MicroCeph.
+	SelfManaged bool `json:"self_managed" yaml:"self_managed"`
 }
+
+// CertificateInfos holds a slice of certificate descriptions.
+type CertificateInfos []CertificateInfo
@@ -10,3 +10,13 @@ type ClientConfig struct {
 
 // ClientConfigs is a slice of client configs
 type ClientConfigs []ClientConfig
+
+// ClientBundle is what an external consumer needs to reach the cluster as a cephx client.
+type ClientBundle struct {
+	User     string   `json:"user" yaml:"user"`
+	FsID     string   `json:"fsid" yaml:"fsid"`
+	Monitors []string `json:"monitors" yaml:"monitors"`
+	// CephConf is a minimal ceph.conf holding the monitors and the cluster wide client configs.
+	CephConf string `json:"ceph_conf" yaml:"ceph_conf"`
+	Keyring  string `json:"keyring" yaml:"keyring"`
+}
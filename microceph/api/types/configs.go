@@ -1,13 +1,134 @@
 // Package types provides shared types and structs.
 package types
 
+import "time"
+
 // Configs holds the key value pair
 type Config struct {
 	Key         string `json:"key" yaml:"key"`
 	Value       string `json:"value" yaml:"value"`
 	Wait        bool   `json:"wait" yaml:"wait"`
 	SkipRestart bool   `json:"skip_restart" yaml:"skip_restart"`
+	// Advanced allows any ceph option, validated against its schema, instead
+	// of the keys MicroCeph lists.
+	Advanced bool `json:"advanced" yaml:"advanced"`
+	// Who is the section an advanced option applies to, global by default.
+	Who string `json:"who,omitempty" yaml:"who,omitempty"`
+	// Requester identifies who asked for the change in the config history,
+	// the server derives it from the authenticated request.
+	Requester string `json:"-" yaml:"-"`
 }
 
 // Configs is a slice of configs
 type Configs []Config
+
+// ConfigChange is a recorded cluster config change. A nil value means the
+// key was unset before or after the change.
+type ConfigChange struct {
+	ID        int64     `json:"id" yaml:"id"`
+	Key       string    `json:"key" yaml:"key"`
+	Who       string    `json:"who" yaml:"who"`
+	Advanced  bool      `json:"advanced" yaml:"advanced"`
+	OldValue  *string   `json:"old_value" yaml:"old_value"`
+	NewValue  *string   `json:"new_value" yaml:"new_value"`
+	Member    string    `json:"member" yaml:"member"`
+	Requester string    `json:"requester" yaml:"requester"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+}
+
+// ConfigChanges is a slice of config changes
+type ConfigChanges []ConfigChange
+
+// ConfigRollback requests restoring the value a recorded change replaced.
+type ConfigRollback struct {
+	Wait        bool `json:"wait" yaml:"wait"`
+	SkipRestart bool `json:"skip_restart" yaml:"skip_restart"`
+	// Requester is derived by the server from the authenticated request.
+	Requester string `json:"-" yaml:"-"`
+}
+
+// Scopes of the keys of a config document.
+const (
+	ConfigScopeCluster  = "cluster"
+	ConfigScopeAdvanced = "advanced"
+	ConfigScopeClient   = "client"
+)
+
+// ConfigDocument is a desired cluster configuration as a whole.
+type ConfigDocument struct {
+	// Cluster holds the MicroCeph supported cluster config keys.
+	Cluster map[string]string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	// Advanced holds arbitrary ceph options by section, such as global or osd.
+	Advanced map[string]map[string]string `json:"advanced,omitempty" yaml:"advanced,omitempty"`
+	// Client holds the cluster wide client config keys.
+	Client map[string]string `json:"client,omitempty" yaml:"client,omitempty"`
+}
+
+// ConfigApply requests bringing the cluster configuration to a config document.
+type ConfigApply struct {
+	Document ConfigDocument `json:"document" yaml:"document"`
+	// Prune resets the keys set through MicroCeph that are not in the document.
+	Prune bool `json:"prune" yaml:"prune"`
+	// DryRun only works out the plan.
+	DryRun      bool `json:"dry_run" yaml:"dry_run"`
+	Wait        bool `json:"wait" yaml:"wait"`
+	SkipRestart bool `json:"skip_restart" yaml:"skip_restart"`
+	// Requester is derived by the server from the authenticated request.
+	Requester string `json:"-" yaml:"-"`
+}
+
+// ConfigPlanChange is a single change of a config apply plan. A nil new
+// value resets the key.
+type ConfigPlanChange struct {
+	Scope    string  `json:"scope" yaml:"scope"`
+	Who      string  `json:"who,omitempty" yaml:"who,omitempty"`
+	Key      string  `json:"key" yaml:"key"`
+	OldValue *string `json:"old_value" yaml:"old_value"`
+	NewValue *string `json:"new_value" yaml:"new_value"`
+}
+
+// ConfigPlan is the set of changes bringing the cluster to a config document.
+type ConfigPlan struct {
+	Changes []ConfigPlanChange `json:"changes" yaml:"changes"`
+	// Services are the daemons restarted once for all the changes.
+	Services []string `json:"services" yaml:"services"`
+	Applied  bool     `json:"applied" yaml:"applied"`
+}
+
+// Kinds of drift between MicroCeph and the monitors' config database.
+const (
+	// ConfigDriftChanged is an option changed outside of MicroCeph.
+	ConfigDriftChanged = "changed"
+	// ConfigDriftMissing is an option MicroCeph set that is no longer in Ceph.
+	ConfigDriftMissing = "missing"
+	// ConfigDriftCephOnly is an option set in Ceph without MicroCeph.
+	ConfigDriftCephOnly = "ceph-only"
+)
+
+// Directions config drift is reconciled in.
+const (
+	// ConfigReconcileCeph makes Ceph match MicroCeph.
+	ConfigReconcileCeph = "ceph"
+	// ConfigReconcileMicroCeph makes MicroCeph match Ceph.
+	ConfigReconcileMicroCeph = "microceph"
+)
+
+// ConfigDrift is an option whose value in Ceph differs from what MicroCeph
+// tracks. A nil value means the option is unset on that side.
+type ConfigDrift struct {
+	Kind     string  `json:"kind" yaml:"kind"`
+	Who      string  `json:"who" yaml:"who"`
+	Key      string  `json:"key" yaml:"key"`
+	Expected *string `json:"expected" yaml:"expected"`
+	Actual   *string `json:"actual" yaml:"actual"`
+}
+
+// ConfigDrifts is a slice of config drifts
+type ConfigDrifts []ConfigDrift
+
+// ConfigReconcile requests reconciling config drift in a direction.
+type ConfigReconcile struct {
+	Direction string `json:"direction" yaml:"direction"`
+	// Requester is derived by the server from the authenticated request.
+	Requester string `json:"-" yaml:"-"`
+}
@@ -0,0 +1,64 @@
+package types
+
+// NFSExportClient restricts or widens access to an NFS export for a set of client networks.
+type NFSExportClient struct {
+	// Addresses are client addresses or CIDRs.
+	Addresses  []string `json:"addresses" yaml:"addresses"`
+	AccessType string   `json:"access_type" yaml:"access_type"`
+	Squash     string   `json:"squash" yaml:"squash"`
+}
+
+// NFSExport describes a CephFS-backed export of an NFS cluster. RGW-backed
+// exports are not supported, the snap does not ship the Ganesha RGW FSAL.
+type NFSExport struct {
+	// ExportID is assigned on creation.
+	ExportID   int    `json:"export_id" yaml:"export_id"`
+	PseudoPath string `json:"pseudo_path" yaml:"pseudo_path"`
+	FsName     string `json:"fs_name" yaml:"fs_name"`
+	// Path is the exported directory within the CephFS volume.
+	Path string `json:"path" yaml:"path"`
+	// AccessType is one of RW, RO or NONE.
+	AccessType string `json:"access_type" yaml:"access_type"`
+	// Squash is one of none, root, root_id or all.
+	Squash  string            `json:"squash" yaml:"squash"`
+	Clients []NFSExportClient `json:"clients" yaml:"clients"`
+}
+
+// NFSExports holds a slice of NFS exports.
+type NFSExports []NFSExport
+
+// NFSGraceEntry is a node registered in the shared grace database of an NFS cluster.
+type NFSGraceEntry struct {
+	Node string `json:"node" yaml:"node"`
+	// NeedsGrace is set while the node has clients to reclaim state.
+	NeedsGrace bool `json:"needs_grace" yaml:"needs_grace"`
+	// Enforcing is set while the node enforces the grace period.
+	Enforcing bool `json:"enforcing" yaml:"enforcing"`
+	// Stale is set for nodes not backed by a gateway of the cluster. It is
+	// never set while the hostname of a gateway is unknown.
+	Stale bool `json:"stale" yaml:"stale"`
+}
+
+// NFSGraceStatus is the shared grace database of an NFS cluster.
+type NFSGraceStatus struct {
+	CurrentEpoch  uint64          `json:"current_epoch" yaml:"current_epoch"`
+	RecoveryEpoch uint64          `json:"recovery_epoch" yaml:"recovery_epoch"`
+	Entries       []NFSGraceEntry `json:"entries" yaml:"entries"`
+}
+
+// NFSNodeStatus is a Ganesha gateway of an NFS cluster.
+type NFSNodeStatus struct {
+	Member      string `json:"member" yaml:"member"`
+	Hostname    string `json:"hostname" yaml:"hostname"`
+	BindAddress string `json:"bind_address" yaml:"bind_address"`
+	BindPort    uint   `json:"bind_port" yaml:"bind_port"`
+	// State is the state of the gateway service, unreachable if its member could not be asked.
+	State string `json:"state" yaml:"state"`
+}
+
+// NFSClusterStatus describes the gateways and grace database of an NFS cluster.
+type NFSClusterStatus struct {
+	ClusterID string          `json:"cluster_id" yaml:"cluster_id"`
+	Nodes     []NFSNodeStatus `json:"nodes" yaml:"nodes"`
+	Grace     NFSGraceStatus  `json:"grace" yaml:"grace"`
+}
@@ -0,0 +1,34 @@
+// Package types provides shared types and structs.
+package types
+
+// QuorumMonitor is a monitor of the monmap of the local monitor. A monitor is
+// reachable when it is in the quorum or the local monitor is in contact with
+// it.
+type QuorumMonitor struct {
+	Name      string `json:"name" yaml:"name"`
+	Address   string `json:"address" yaml:"address"`
+	InQuorum  bool   `json:"in_quorum" yaml:"in_quorum"`
+	Reachable bool   `json:"reachable" yaml:"reachable"`
+}
+
+// QuorumStatus is the monitor quorum as seen by the monitor of a member.
+// State is the state of that monitor, e.g. probing or leader.
+type QuorumStatus struct {
+	Member   string          `json:"member" yaml:"member"`
+	State    string          `json:"state" yaml:"state"`
+	Monitors []QuorumMonitor `json:"monitors" yaml:"monitors"`
+}
+
+// QuorumRecovery holds the unreachable monitors to remove from the monmap.
+type QuorumRecovery struct {
+	Remove []string `json:"remove" yaml:"remove"`
+}
+
+// QuorumRecoveryResult describes a quorum recovery. Removed are the monitors
+// removed from the monmap and Monitors the monitors left in it. Unrefreshed
+// lists the members whose config could not be refreshed.
+type QuorumRecoveryResult struct {
+	Removed     []string `json:"removed" yaml:"removed"`
+	Monitors    []string `json:"monitors" yaml:"monitors"`
+	Unrefreshed []string `json:"unrefreshed" yaml:"unrefreshed"`
+}
@@ -38,6 +38,8 @@ type CephfsReplicationRequest struct {
 	Schedule        string `json:"schedule" yaml:"schedule"`
 	RetentionPolicy string `json:"retention_policy" yaml:"retention_policy"`
 	IsForceOp       bool   `json:"force" yaml:"force"`
+	// Reverse the mirroring direction on promotion, used for failback.
+	ReverseDirection bool `json:"reverse" yaml:"reverse"`
 }
 
 // GetWorkloadType provides the workload name for replication request
@@ -48,6 +50,11 @@ func (req CephfsReplicationRequest) GetWorkloadType() CephWorkloadType {
 
 // GetAPIObjectID provides the API object id i.e. /replication/cephfs/<volume-name>
 func (req CephfsReplicationRequest) GetAPIObjectID() string {
+	// Promote and demote are site-wide operations, the volume only narrows their scope.
+	if req.RequestType == PromoteReplicationRequest || req.RequestType == DemoteReplicationRequest {
+		return ""
+	}
+
 	// For filesystem workloads, the only resource is the volume name.
 	if len(req.Volume) != 0 {
 		logger.Debugf("REPAPI: Resource: %s", req.Volume)
@@ -117,8 +124,11 @@ type CephFsReplicationResponsePeerItem struct {
 
 type CephFsReplicationResponseStatus struct {
 	Volume              string                                       `json:"volume" yaml:"volume"`
+	Role                string                                       `json:"role" yaml:"role"`
 	MirrorResourceCount int                                          `json:"mirror_path_count" yaml:"mirror_path_count"`
 	Peers               map[string]CephFsReplicationResponsePeerItem `json:"peers" yaml:"peers"`
+	// Time of the latest scheduled snapshot, per mirrored resource path.
+	LastSnapshot map[string]string `json:"last_snapshot" yaml:"last_snapshot"`
 }
 
 // ############################ Helper Functions  ##################################
@@ -0,0 +1,126 @@
+package types
+
+import (
+	"net/url"
+
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// ################################## RGW Replication Request ##################################
+
+// RgwReplicationRequest implements ReplicationRequest for RGW multisite replication.
+type RgwReplicationRequest struct {
+	// Realm and zonegroup the zones are replicated within, defaults are used when empty.
+	Realm      string `json:"realm" yaml:"realm"`
+	ZoneGroup  string `json:"zonegroup" yaml:"zonegroup"`
+	RemoteName string `json:"remote" yaml:"remote"`
+	// Comma separated S3 endpoint URLs of the local and remote zones.
+	Endpoints       string                 `json:"endpoints" yaml:"endpoints"`
+	RemoteEndpoints string                 `json:"remote_endpoints" yaml:"remote_endpoints"`
+	RequestType     ReplicationRequestType `json:"request_type" yaml:"request_type"`
+	IsForceOp       bool                   `json:"force" yaml:"force"`
+}
+
+// GetWorkloadType provides the workload name for replication request
+func (req RgwReplicationRequest) GetWorkloadType() CephWorkloadType {
+	logger.Debugf("REPAPI: Workload: rgw")
+	return RgwWorkload
+}
+
+// GetAPIObjectID provides the API object id i.e. /replication/rgw/<realm-name>
+func (req RgwReplicationRequest) GetAPIObjectID() string {
+	// Promote and demote are site-wide operations.
+	if req.RequestType == PromoteReplicationRequest || req.RequestType == DemoteReplicationRequest {
+		return ""
+	}
+
+	if len(req.Realm) != 0 {
+		logger.Debugf("REPAPI: Resource: %s", req.Realm)
+		return req.Realm
+	}
+
+	return ""
+}
+
+// SetAPIObjectID provides the API object id i.e. /replication/rgw/<realm-name>
+func (req *RgwReplicationRequest) SetAPIObjectID(id string) error {
+	// unescape object string
+	realm, err := url.PathUnescape(id)
+	if err != nil {
+		return err
+	}
+
+	if len(realm) != 0 {
+		req.Realm = realm
+	}
+
+	return nil
+}
+
+// GetAPIRequestType provides the REST method for the request
+func (req RgwReplicationRequest) GetAPIRequestType() string {
+	return GetAPIRequestTypeGeneric(req.RequestType)
+}
+
+// GetWorkloadRequestType provides the event used as the FSM trigger.
+func (req RgwReplicationRequest) GetWorkloadRequestType() string {
+	return GetWorkloadRequestTypeGeneric(req.RequestType)
+}
+
+// OverwriteRequestType overwrites the request type of the replication request.
+func (req *RgwReplicationRequest) OverwriteRequestType(overwriteRequestType ReplicationRequestType) {
+	if len(overwriteRequestType) != 0 {
+		req.RequestType = overwriteRequestType
+	}
+}
+
+// ################################## RGW Replication Response ##################################
+
+// RgwReplicationZone describes a zone of the replicated zonegroup.
+type RgwReplicationZone struct {
+	Name      string   `json:"name" yaml:"name"`
+	ID        string   `json:"id" yaml:"id"`
+	Endpoints []string `json:"endpoints" yaml:"endpoints"`
+	IsMaster  bool     `json:"is_master" yaml:"is_master"`
+	ReadOnly  bool     `json:"read_only" yaml:"read_only"`
+}
+
+// RgwReplicationResponseList is the list of zones in the local zonegroup.
+type RgwReplicationResponseList []RgwReplicationZone
+
+// RgwSyncVerdict reports whether a zone has caught up with one of its peers.
+type RgwSyncVerdict struct {
+	CaughtUp           bool  `json:"caught_up" yaml:"caught_up"`
+	BehindShards       []int `json:"behind_shards" yaml:"behind_shards"`
+	FullSyncShards     int   `json:"full_sync_shards" yaml:"full_sync_shards"`
+	PeriodMismatch     bool  `json:"period_mismatch" yaml:"period_mismatch"`
+	PeerLogUnavailable bool  `json:"peer_log_unavailable" yaml:"peer_log_unavailable"`
+}
+
+// RgwReplicationResponseStatus is the multisite sync status of the local zone.
+type RgwReplicationResponseStatus struct {
+	Realm     string               `json:"realm" yaml:"realm"`
+	ZoneGroup string               `json:"zonegroup" yaml:"zonegroup"`
+	Zone      string               `json:"zone" yaml:"zone"`
+	IsMaster  bool                 `json:"is_master" yaml:"is_master"`
+	Zones     []RgwReplicationZone `json:"zones" yaml:"zones"`
+	// Metadata sync against the master zone, nil on the master itself.
+	MetadataSync *RgwSyncVerdict `json:"metadata_sync,omitempty" yaml:"metadata_sync,omitempty"`
+	// Data sync per source zone.
+	DataSync map[string]RgwSyncVerdict `json:"data_sync" yaml:"data_sync"`
+}
+
+// IsCaughtUp checks that metadata and data sync have caught up with every peer.
+func (status RgwReplicationResponseStatus) IsCaughtUp() bool {
+	if status.MetadataSync != nil && !status.MetadataSync.CaughtUp {
+		return false
+	}
+
+	for _, verdict := range status.DataSync {
+		if !verdict.CaughtUp {
+			return false
+		}
+	}
+
+	return true
+}
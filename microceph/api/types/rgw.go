@@ -0,0 +1,117 @@
+package types
+
+import "regexp"
+
+// RgwRealmRequest holds the request data for creating or pulling an RGW realm.
+type RgwRealmRequest struct {
+	Name string `json:"name" yaml:"name"`
+	// URL, AccessKey and SecretKey locate and authenticate against the master zone when pulling.
+	URL       string `json:"url" yaml:"url"`
+	AccessKey string `json:"access_key" yaml:"access_key"`
+	SecretKey string `json:"secret_key" yaml:"secret_key"`
+}
+
+// RgwZoneGroupRequest holds the request data for creating an RGW zonegroup.
+type RgwZoneGroupRequest struct {
+	Name string `json:"name" yaml:"name"`
+	// Realm defaults to the one recorded by realm create or pull.
+	Realm     string   `json:"realm" yaml:"realm"`
+	Endpoints []string `json:"endpoints" yaml:"endpoints"`
+	IsMaster  bool     `json:"master" yaml:"master"`
+}
+
+// RgwZoneRequest holds the request data for creating an RGW zone.
+type RgwZoneRequest struct {
+	Name string `json:"name" yaml:"name"`
+	// ZoneGroup defaults to the one recorded by zonegroup create.
+	ZoneGroup string   `json:"zonegroup" yaml:"zonegroup"`
+	Endpoints []string `json:"endpoints" yaml:"endpoints"`
+	IsMaster  bool     `json:"master" yaml:"master"`
+}
+
+// RgwNameRegex is a regex for acceptable RGW realm, zonegroup and zone names.
+var RgwNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+// RgwUserKey is an S3 key pair of an RGW user.
+type RgwUserKey struct {
+	User      string `json:"user" yaml:"user"`
+	AccessKey string `json:"access_key" yaml:"access_key"`
+	// SecretKey is only set when the key has just been created.
+	SecretKey string `json:"secret_key,omitempty" yaml:"secret_key,omitempty"`
+}
+
+// RgwQuota is a user or per-bucket quota, negative limits mean unlimited.
+type RgwQuota struct {
+	Enabled    bool  `json:"enabled" yaml:"enabled"`
+	MaxSize    int64 `json:"max_size" yaml:"max_size"`
+	MaxObjects int64 `json:"max_objects" yaml:"max_objects"`
+}
+
+// RgwUser describes an RGW user.
+type RgwUser struct {
+	UserID      string       `json:"user_id" yaml:"user_id"`
+	DisplayName string       `json:"display_name" yaml:"display_name"`
+	Email       string       `json:"email" yaml:"email"`
+	Suspended   bool         `json:"suspended" yaml:"suspended"`
+	MaxBuckets  int          `json:"max_buckets" yaml:"max_buckets"`
+	Keys        []RgwUserKey `json:"keys" yaml:"keys"`
+	UserQuota   RgwQuota     `json:"user_quota" yaml:"user_quota"`
+	BucketQuota RgwQuota     `json:"bucket_quota" yaml:"bucket_quota"`
+}
+
+// RgwUserRequest holds the request data for creating an RGW user.
+type RgwUserRequest struct {
+	UserID      string `json:"user_id" yaml:"user_id"`
+	DisplayName string `json:"display_name" yaml:"display_name"`
+	Email       string `json:"email" yaml:"email"`
+	// MaxBuckets is left at the RGW default when 0.
+	MaxBuckets int `json:"max_buckets" yaml:"max_buckets"`
+}
+
+// RgwUserSetRequest holds the user properties to be updated, nil fields are left untouched.
+type RgwUserSetRequest struct {
+	Suspended  *bool `json:"suspended,omitempty" yaml:"suspended,omitempty"`
+	MaxBuckets *int  `json:"max_buckets,omitempty" yaml:"max_buckets,omitempty"`
+}
+
+// RgwUserDeleteRequest holds the request data for removing an RGW user.
+type RgwUserDeleteRequest struct {
+	// PurgeData removes the buckets and objects of the user as well.
+	PurgeData bool `json:"purge_data" yaml:"purge_data"`
+	IsForceOp bool `json:"force" yaml:"force"`
+}
+
+// RgwKeyRequest holds the request data for generating an S3 key of an RGW user.
+type RgwKeyRequest struct {
+	// Rotate is the access key to remove once the new key is generated.
+	Rotate string `json:"rotate" yaml:"rotate"`
+}
+
+// RgwQuotaRequest holds the request data for setting a quota of an RGW user.
+type RgwQuotaRequest struct {
+	// Scope is either "user" or "bucket".
+	Scope string `json:"scope" yaml:"scope"`
+	RgwQuota
+}
+
+// RgwBucket describes an RGW bucket and its usage.
+type RgwBucket struct {
+	Name       string `json:"name" yaml:"name"`
+	Owner      string `json:"owner" yaml:"owner"`
+	NumObjects int64  `json:"num_objects" yaml:"num_objects"`
+	Size       int64  `json:"size" yaml:"size"`
+}
+
+// RgwBuckets holds a slice of RGW buckets.
+type RgwBuckets []RgwBucket
+
+// RgwBucketSetRequest holds the request data for changing the owner of an RGW bucket.
+type RgwBucketSetRequest struct {
+	Owner string `json:"owner" yaml:"owner"`
+}
+
+// RgwUserIDRegex is a regex for acceptable RGW user IDs, optionally prefixed by a tenant.
+var RgwUserIDRegex = regexp.MustCompile(`^([a-zA-Z0-9_-]+\$)?[a-zA-Z0-9_][a-zA-Z0-9_.@-]{0,127}$`)
+
+// RgwBucketNameRegex is a regex for acceptable RGW bucket names.
+var RgwBucketNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,254}$`)
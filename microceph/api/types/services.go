@@ -34,6 +34,14 @@ type NFSService struct {
 // NFSClusterIDRegex is a regex for acceptable ClusterIDs.
 var NFSClusterIDRegex = regexp.MustCompile(`^[\w][\w.-]{1,61}[\w]$`)
 
+// RGWGroupService holds the Group ID of a grouped RGW Service.
+type RGWGroupService struct {
+	GroupID string `json:"group_id" yaml:"group_id"`
+}
+
+// RGWGroupIDRegex is a regex for acceptable RGW GroupIDs, they name the daemon's ceph client.
+var RGWGroupIDRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,30}[a-zA-Z0-9])?$`)
+
 // RGWService holds a port number and enable/disable flag
 type RGWService struct {
 	Service
@@ -46,3 +54,16 @@ type RGWService struct {
 type MonitorStatus struct {
 	Addresses []string `json:"addresses" yaml:"addresses"`
 }
+
+// ServiceRestart holds a request to restart a set of services member by member
+// across the cluster, waiting for the cluster to be healthy before every step.
+// MaxParallel bounds the
+// members restarted at once within a failure domain and ToleratedWarnings
+// lists health check codes that do not block a step. HealthTimeout is the
+// time in seconds to wait for the cluster to be healthy before a step.
+type ServiceRestart struct {
+	Services          []string `json:"services" yaml:"services"`
+	MaxParallel       int      `json:"max_parallel" yaml:"max_parallel"`
+	ToleratedWarnings []string `json:"tolerated_warnings" yaml:"tolerated_warnings"`
+	HealthTimeout     int64    `json:"health_timeout" yaml:"health_timeout"`
+}
@@ -0,0 +1,68 @@
+// Package types provides shared types and structs.
+package types
+
+import (
+	"time"
+)
+
+// UpgradeStep is a cluster member refreshed during an upgrade, along with the
+// services it hosts.
+type UpgradeStep struct {
+	Member   string   `json:"member" yaml:"member"`
+	Services []string `json:"services" yaml:"services"`
+}
+
+// UpgradePlan holds the members of the cluster in the order they are upgraded.
+type UpgradePlan struct {
+	Steps []UpgradeStep `json:"steps" yaml:"steps"`
+}
+
+// UpgradeRefresh holds the snap channel a member is refreshed to.
+type UpgradeRefresh struct {
+	Channel string `json:"channel" yaml:"channel"`
+}
+
+// UpgradeRefreshResult tells whether a refresh was started, false when the
+// member already runs the latest revision of the channel.
+type UpgradeRefreshResult struct {
+	Refreshing bool `json:"refreshing" yaml:"refreshing"`
+}
+
+// UpgradeStatus holds the state of a member during an upgrade.
+// Revision is the snap revision of the member, Release the Ceph release it
+// ships and Versions the number of daemons running every Ceph version in the
+// cluster. Outdated lists the daemons of the member which do not report
+// Release yet. Health is empty when the cluster can go through an upgrade step.
+type UpgradeStatus struct {
+	Member   string           `json:"member" yaml:"member"`
+	Revision string           `json:"revision" yaml:"revision"`
+	Release  string           `json:"release" yaml:"release"`
+	Versions map[string]int32 `json:"versions" yaml:"versions"`
+	Outdated []string         `json:"outdated" yaml:"outdated"`
+	Health   string           `json:"health" yaml:"health"`
+}
+
+// RefreshCheck holds a request to the pre-refresh health gate of a member,
+// ToleratedWarnings lists health check codes that do not hold up a refresh.
+// A dry run neither records the decision nor claims the refresh.
+type RefreshCheck struct {
+	ToleratedWarnings []string `json:"tolerated_warnings" yaml:"tolerated_warnings"`
+	DryRun            bool     `json:"dry_run" yaml:"dry_run"`
+}
+
+// RefreshDecision is the latest decision of the pre-refresh health gate of a
+// member. Reason explains a refused refresh, or how an allowed one ended when
+// it did not end on a new revision. Completed is set once the member comes
+// back from an allowed refresh. Revision is the snap revision the member ran
+// when the decision was taken.
+type RefreshDecision struct {
+	Member    string    `json:"member" yaml:"member"`
+	Allowed   bool      `json:"allowed" yaml:"allowed"`
+	Reason    string    `json:"reason" yaml:"reason"`
+	Completed bool      `json:"completed" yaml:"completed"`
+	Revision  string    `json:"revision" yaml:"revision"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+}
+
+// RefreshDecisions is a slice of refresh decisions.
+type RefreshDecisions []RefreshDecision
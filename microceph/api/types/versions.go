@@ -0,0 +1,35 @@
+// Package types provides shared types and structs.
+package types
+
+// DaemonVersion holds the Ceph version a daemon reports running, or the error
+// met asking it.
+type DaemonVersion struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+	Release string `json:"release" yaml:"release"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// MemberVersions holds the snap revision and microcephd version of a cluster
+// member along with the versions of the Ceph daemons it hosts.
+type MemberVersions struct {
+	Member           string          `json:"member" yaml:"member"`
+	Revision         string          `json:"revision" yaml:"revision"`
+	MicroCephVersion string          `json:"microceph_version" yaml:"microceph_version"`
+	Daemons          []DaemonVersion `json:"daemons" yaml:"daemons"`
+	Error            string          `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ClusterVersions reports the versions running across the cluster.
+// MixedCeph is set when daemons run different Ceph versions and MixedSnap when
+// members run different snap revisions. OSDReleasePending is set when every
+// OSD runs a release newer than RequireOSDRelease.
+type ClusterVersions struct {
+	Members           []MemberVersions `json:"members" yaml:"members"`
+	Versions          map[string]int32 `json:"versions" yaml:"versions"`
+	MixedCeph         bool             `json:"mixed_ceph" yaml:"mixed_ceph"`
+	MixedSnap         bool             `json:"mixed_snap" yaml:"mixed_snap"`
+	RequireOSDRelease string           `json:"require_osd_release" yaml:"require_osd_release"`
+	OSDRelease        string           `json:"osd_release" yaml:"osd_release"`
+	OSDReleasePending bool             `json:"osd_release_pending" yaml:"osd_release_pending"`
+}
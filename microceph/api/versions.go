@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/ceph"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// /1.0/versions endpoint.
+var versionsCmd = mcTypes.Endpoint{
+	Path: "versions",
+	Get:  mcTypes.EndpointAction{Handler: cmdVersionsGet, ProxyTarget: false},
+}
+
+// /1.0/versions/member endpoint.
+var versionsMemberCmd = mcTypes.Endpoint{
+	Path: "versions/member",
+	Get:  mcTypes.EndpointAction{Handler: cmdVersionsMemberGet, ProxyTarget: true},
+}
+
+// cmdVersionsGet reports the versions running across the cluster.
+func cmdVersionsGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	report, err := ceph.GetClusterVersions(r.Context(), interfaces.CephState{State: s})
+	if err != nil {
+		logger.Errorf("failed to fetch cluster versions: %v", err)
+		return mcTypes.SmartError(err)
+	}
+
+	return mcTypes.SyncResponse(true, report)
+}
+
+// cmdVersionsMemberGet reports the snap revision and microcephd version of the
+// target member.
+func cmdVersionsMemberGet(s mcTypes.State, r *http.Request) mcTypes.Response {
+	return mcTypes.SyncResponse(true, ceph.GetMemberVersions(interfaces.CephState{State: s}))
+}
@@ -0,0 +1,384 @@
+package ceph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// authCapOrder is the order capabilities are granted and rendered in.
+var authCapOrder = []string{"mon", "mgr", "mds", "osd"}
+
+// Name prefixes of the clients MicroCeph creates for its own services.
+var authManagedPrefixes = []string{"bootstrap-", "radosgw.", "nfs.", "nfs-export.", "rbd-mirror.", "cephfs-mirror.", "fsmir-"}
+
+// authEntry is an entry of `auth ls` and `auth get` output.
+type authEntry struct {
+	Entity string            `json:"entity"`
+	Key    string            `json:"key"`
+	Caps   map[string]string `json:"caps"`
+}
+
+// isManagedAuthClient tells whether a client belongs to the admin, a MicroCeph
+// service or one of the remote clusters, which access this one as client.<remote>.
+func isManagedAuthClient(name string, remotes []string) bool {
+	if name == "admin" || slices.Contains(remotes, name) {
+		return true
+	}
+
+	for _, prefix := range authManagedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authRemoteNames fetches the names of the remote clusters.
+func authRemoteNames(ctx context.Context, s interfaces.StateInterface) ([]string, error) {
+	remotes, err := database.GetRemoteDb(ctx, s.ClusterState(), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote clusters: %w", err)
+	}
+
+	names := []string{}
+	for _, remote := range remotes {
+		names = append(names, remote.Name)
+	}
+
+	return names, nil
+}
+
+// authClientCaps composes the capabilities of the rbd and rgw profiles of a
+// set. Grants on the same daemon type are joined so that profiles can be
+// combined. The cephfs profiles are checked and returned, their capabilities
+// are handed out by `ceph fs authorize`.
+func authClientCaps(profiles []types.AuthClientProfile) ([][]string, []types.AuthClientProfile, error) {
+	if len(profiles) == 0 {
+		return nil, nil, fmt.Errorf("at least one capability profile is required")
+	}
+
+	grants := map[string][]string{}
+	grant := func(daemon, capability string) {
+		if !slices.Contains(grants[daemon], capability) {
+			grants[daemon] = append(grants[daemon], capability)
+		}
+	}
+
+	fsProfiles := []types.AuthClientProfile{}
+	for _, profile := range profiles {
+		switch profile.Type {
+		case types.AuthProfileRbd:
+			if len(profile.Pool) == 0 {
+				return nil, nil, fmt.Errorf("the rbd profile requires a pool")
+			}
+
+			grant("mon", "profile rbd")
+			grant("mgr", fmt.Sprintf("profile rbd pool=%s", profile.Pool))
+			if profile.ReadOnly {
+				grant("osd", fmt.Sprintf("profile rbd-read-only pool=%s", profile.Pool))
+			} else {
+				grant("osd", fmt.Sprintf("profile rbd pool=%s", profile.Pool))
+			}
+		case types.AuthProfileCephFS:
+			if !types.FsNameRegex.MatchString(profile.FsName) {
+				return nil, nil, fmt.Errorf("the cephfs profile requires a valid volume name, got %q", profile.FsName)
+			}
+
+			if len(profile.Path) == 0 {
+				profile.Path = "/"
+			}
+			if !strings.HasPrefix(profile.Path, "/") {
+				return nil, nil, fmt.Errorf("cephfs path %q must be absolute", profile.Path)
+			}
+			profile.Path = path.Clean(profile.Path)
+
+			fsProfiles = append(fsProfiles, profile)
+		case types.AuthProfileRgw:
+			if profile.ReadOnly {
+				return nil, nil, fmt.Errorf("the rgw profile cannot be read-only")
+			}
+
+			// Limited to the pools of the rgw application. Unlike the
+			// gateways, clients only need to read the cluster maps.
+			grant("mon", "allow r")
+			grant("osd", "allow rwx tag rgw *=*")
+		default:
+			return nil, nil, fmt.Errorf("unknown capability profile %q, expected one of rbd, cephfs or rgw", profile.Type)
+		}
+	}
+
+	return joinAuthCaps(map[string]string{}, grants), fsProfiles, nil
+}
+
+// joinAuthCaps appends grants to the capabilities a client already has, in the
+// form `ceph auth add` and `ceph auth caps` take them.
+func joinAuthCaps(existing map[string]string, grants map[string][]string) [][]string {
+	caps := [][]string{}
+	for _, daemon := range authCapOrder {
+		all := []string{}
+		if len(existing[daemon]) != 0 {
+			all = append(all, existing[daemon])
+		}
+		all = append(all, grants[daemon]...)
+
+		if len(all) != 0 {
+			caps = append(caps, []string{daemon, strings.Join(all, ", ")})
+		}
+	}
+
+	return caps
+}
+
+// renderAuthKeyring renders an entry the way `ceph auth get` exports it.
+func renderAuthKeyring(entry authEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]\n", entry.Entity)
+	fmt.Fprintf(&b, "\tkey = %s\n", entry.Key)
+
+	daemons := []string{}
+	for daemon := range entry.Caps {
+		daemons = append(daemons, daemon)
+	}
+	sort.Strings(daemons)
+
+	for _, daemon := range daemons {
+		fmt.Fprintf(&b, "\tcaps %s = %q\n", daemon, entry.Caps[daemon])
+	}
+
+	return b.String()
+}
+
+// ListAuthClients fetches the cephx clients and their capabilities, without their keys.
+func ListAuthClients(ctx context.Context, s interfaces.StateInterface) (types.AuthClients, error) {
+	remotes, err := authRemoteNames(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := cephRun("auth", "ls", "-f", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cephx clients: %w", err)
+	}
+
+	dump := struct {
+		AuthDump []authEntry `json:"auth_dump"`
+	}{}
+	err = json.Unmarshal([]byte(output), &dump)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal auth ls output: %w", err)
+	}
+
+	clients := types.AuthClients{}
+	for _, entry := range dump.AuthDump {
+		name, found := strings.CutPrefix(entry.Entity, "client.")
+		if !found {
+			continue
+		}
+
+		clients = append(clients, types.AuthClient{Name: name, Caps: entry.Caps, Managed: isManagedAuthClient(name, remotes)})
+	}
+
+	return clients, nil
+}
+
+// getAuthEntry fetches the entry of a cephx client.
+func getAuthEntry(name string) (authEntry, error) {
+	output, err := cephRun("auth", "get", fmt.Sprintf("client.%s", name), "-f", "json")
+	if err != nil {
+		if strings.Contains(err.Error(), "ENOENT") {
+			return authEntry{}, api.StatusErrorf(http.StatusNotFound, "cephx client %s not found", name)
+		}
+
+		return authEntry{}, fmt.Errorf("failed to fetch cephx client %s: %w", name, err)
+	}
+
+	entries := []authEntry{}
+	err = json.Unmarshal([]byte(output), &entries)
+	if err != nil || len(entries) != 1 {
+		return authEntry{}, fmt.Errorf("cannot unmarshal auth get output of client %s: %v", name, err)
+	}
+
+	return entries[0], nil
+}
+
+// GetAuthClient fetches a cephx client along with its key and keyring.
+func GetAuthClient(ctx context.Context, s interfaces.StateInterface, name string) (types.AuthClient, error) {
+	remotes, err := authRemoteNames(ctx, s)
+	if err != nil {
+		return types.AuthClient{}, err
+	}
+
+	entry, err := getAuthEntry(name)
+	if err != nil {
+		return types.AuthClient{}, err
+	}
+
+	return types.AuthClient{
+		Name:    name,
+		Caps:    entry.Caps,
+		Managed: isManagedAuthClient(name, remotes),
+		Key:     entry.Key,
+		Keyring: renderAuthKeyring(entry),
+	}, nil
+}
+
+// checkUnmanagedAuthClient refuses changes to the clients MicroCeph manages.
+func checkUnmanagedAuthClient(ctx context.Context, s interfaces.StateInterface, name string) error {
+	remotes, err := authRemoteNames(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if isManagedAuthClient(name, remotes) {
+		return fmt.Errorf("client %s is managed by MicroCeph", name)
+	}
+
+	return nil
+}
+
+// CreateAuthClient creates a cephx client with the capabilities of the requested profiles.
+func CreateAuthClient(ctx context.Context, s interfaces.StateInterface, req types.AuthClientRequest) (types.AuthClient, error) {
+	remotes, err := authRemoteNames(ctx, s)
+	if err != nil {
+		return types.AuthClient{}, err
+	}
+
+	if isManagedAuthClient(req.Name, remotes) {
+		return types.AuthClient{}, fmt.Errorf("client name %s is reserved for MicroCeph services", req.Name)
+	}
+
+	caps, fsProfiles, err := authClientCaps(req.Profiles)
+	if err != nil {
+		return types.AuthClient{}, err
+	}
+
+	_, err = getAuthEntry(req.Name)
+	if err == nil {
+		return types.AuthClient{}, api.StatusErrorf(http.StatusConflict, "cephx client %s already exists", req.Name)
+	} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return types.AuthClient{}, err
+	}
+
+	err = createAuthClient(req.Name, caps, fsProfiles)
+	if err != nil {
+		// Do not leave a client with part of the capabilities behind.
+		_ = DeleteClientKey(req.Name)
+		return types.AuthClient{}, err
+	}
+
+	logger.Infof("Auth: created cephx client %s", req.Name)
+	return GetAuthClient(ctx, s, req.Name)
+}
+
+// createAuthClient creates a client through `ceph fs authorize` for its cephfs
+// profiles, and grants it the capabilities of the others on top.
+func createAuthClient(name string, caps [][]string, fsProfiles []types.AuthClientProfile) error {
+	entity := fmt.Sprintf("client.%s", name)
+	if len(fsProfiles) == 0 {
+		args := []string{"auth", "add", entity}
+		for _, capability := range caps {
+			args = append(args, capability...)
+		}
+
+		_, err := cephRun(args...)
+		if err != nil {
+			return fmt.Errorf("failed to create cephx client %s: %w", name, err)
+		}
+
+		return nil
+	}
+
+	for _, profile := range fsProfiles {
+		perm := "rw"
+		if profile.ReadOnly {
+			perm = "r"
+		}
+
+		_, err := cephRun("fs", "authorize", profile.FsName, entity, profile.Path, perm)
+		if err != nil {
+			return fmt.Errorf("failed to authorize cephx client %s on %s:%s: %w", name, profile.FsName, profile.Path, err)
+		}
+	}
+
+	if len(caps) == 0 {
+		return nil
+	}
+
+	entry, err := getAuthEntry(name)
+	if err != nil {
+		return err
+	}
+
+	grants := map[string][]string{}
+	for _, capability := range caps {
+		grants[capability[0]] = []string{capability[1]}
+	}
+
+	args := []string{"auth", "caps", entity}
+	for _, capability := range joinAuthCaps(entry.Caps, grants) {
+		args = append(args, capability...)
+	}
+
+	_, err = cephRun(args...)
+	if err != nil {
+		return fmt.Errorf("failed to grant capabilities to cephx client %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// RotateAuthClientKey replaces the key of a cephx client, keeping its capabilities.
+func RotateAuthClientKey(ctx context.Context, s interfaces.StateInterface, name string) (types.AuthClient, error) {
+	err := checkUnmanagedAuthClient(ctx, s, name)
+	if err != nil {
+		return types.AuthClient{}, err
+	}
+
+	_, err = getAuthEntry(name)
+	if err != nil {
+		return types.AuthClient{}, err
+	}
+
+	_, err = cephRun("auth", "rotate", fmt.Sprintf("client.%s", name))
+	if err != nil {
+		return types.AuthClient{}, fmt.Errorf("failed to rotate key of cephx client %s: %w", name, err)
+	}
+
+	logger.Infof("Auth: rotated key of cephx client %s", name)
+	return GetAuthClient(ctx, s, name)
+}
+
+// RemoveAuthClient deletes a cephx client.
+func RemoveAuthClient(ctx context.Context, s interfaces.StateInterface, name string) error {
+	err := checkUnmanagedAuthClient(ctx, s, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = getAuthEntry(name)
+	if err != nil {
+		return err
+	}
+
+	err = DeleteClientKey(name)
+	if err != nil {
+		return fmt.Errorf("failed to remove cephx client %s: %w", name, err)
+	}
+
+	logger.Infof("Auth: removed cephx client %s", name)
+	return nil
+}
@@ -0,0 +1,119 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type authClientSuite struct {
+	tests.BaseSuite
+	getRemoteDb func(context.Context, mcTypes.State, string) (types.RemoteRecords, error)
+}
+
+func TestAuthClient(t *testing.T) {
+	suite.Run(t, new(authClientSuite))
+}
+
+func (s *authClientSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+
+	// The remote cluster siteb accesses this one as client.siteb.
+	s.getRemoteDb = database.GetRemoteDb
+	database.GetRemoteDb = func(ctx context.Context, st mcTypes.State, name string) (types.RemoteRecords, error) {
+		return types.RemoteRecords{{Name: "siteb", LocalName: "sitea"}}, nil
+	}
+}
+
+func (s *authClientSuite) TearDownTest() {
+	database.GetRemoteDb = s.getRemoteDb
+	s.BaseSuite.TearDownTest()
+}
+
+func (s *authClientSuite) TestAuthClientCapsCombined() {
+	caps, fsProfiles, err := authClientCaps([]types.AuthClientProfile{
+		{Type: types.AuthProfileRbd, Pool: "vms"},
+		{Type: types.AuthProfileRbd, Pool: "images", ReadOnly: true},
+		{Type: types.AuthProfileCephFS, FsName: "shared", Path: "/web/"},
+		{Type: types.AuthProfileRgw},
+	})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), [][]string{
+		{"mon", "profile rbd, allow r"},
+		{"mgr", "profile rbd pool=vms, profile rbd pool=images"},
+		{"osd", "profile rbd pool=vms, profile rbd-read-only pool=images, allow rwx tag rgw *=*"},
+	}, caps)
+	assert.Equal(s.T(), []types.AuthClientProfile{{Type: types.AuthProfileCephFS, FsName: "shared", Path: "/web"}}, fsProfiles)
+}
+
+func (s *authClientSuite) TestAuthClientCapsRgw() {
+	caps, fsProfiles, err := authClientCaps([]types.AuthClientProfile{{Type: types.AuthProfileRgw}})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), [][]string{
+		{"mon", "allow r"},
+		{"osd", "allow rwx tag rgw *=*"},
+	}, caps)
+	assert.Empty(s.T(), fsProfiles)
+}
+
+func (s *authClientSuite) TestAuthClientCapsErrors() {
+	for name, profiles := range map[string][]types.AuthClientProfile{
+		"no profiles":       {},
+		"rbd without pool":  {{Type: types.AuthProfileRbd}},
+		"relative path":     {{Type: types.AuthProfileCephFS, FsName: "shared", Path: "web"}},
+		"read-only rgw":     {{Type: types.AuthProfileRgw, ReadOnly: true}},
+		"unknown profile":   {{Type: "s3"}},
+		"cephfs without fs": {{Type: types.AuthProfileCephFS}},
+	} {
+		_, _, err := authClientCaps(profiles)
+		assert.Error(s.T(), err, name)
+	}
+}
+
+func (s *authClientSuite) TestCreateAuthClient() {
+	state := interfaces.CephState{State: &mocks.MockState{ClusterName: "node0"}}
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "auth", "get", "client.web", "-f", "json").Return("", fmt.Errorf("Error ENOENT: failed to find client.web in keyring")).Once()
+	r.On("RunCommand", "ceph", "fs", "authorize", "shared", "client.web", "/", "r").Return("", nil).Once()
+	r.On("RunCommand", "ceph", "auth", "get", "client.web", "-f", "json").Return(
+		`[{"entity":"client.web","key":"QUJD","caps":{"mds":"allow r fsname=shared","mon":"allow r fsname=shared","osd":"allow r tag cephfs data=shared"}}]`, nil).Once()
+	r.On("RunCommand", "ceph", "auth", "caps", "client.web", "mon", "allow r fsname=shared, profile rbd", "mgr", "profile rbd pool=vms",
+		"mds", "allow r fsname=shared", "osd", "allow r tag cephfs data=shared, profile rbd pool=vms").Return("", nil).Once()
+	r.On("RunCommand", "ceph", "auth", "get", "client.web", "-f", "json").Return(
+		`[{"entity":"client.web","key":"QUJD","caps":{"mds":"allow r fsname=shared","mon":"allow r fsname=shared, profile rbd","osd":"allow r tag cephfs data=shared, profile rbd pool=vms","mgr":"profile rbd pool=vms"}}]`, nil).Once()
+	common.ProcessExec = r
+
+	client, err := CreateAuthClient(context.Background(), state, types.AuthClientRequest{
+		Name: "web",
+		Profiles: []types.AuthClientProfile{
+			{Type: types.AuthProfileCephFS, FsName: "shared", ReadOnly: true},
+			{Type: types.AuthProfileRbd, Pool: "vms"},
+		},
+	})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "QUJD", client.Key)
+	assert.Equal(s.T(), "[client.web]\n\tkey = QUJD\n\tcaps mds = \"allow r fsname=shared\"\n\tcaps mgr = \"profile rbd pool=vms\"\n\tcaps mon = \"allow r fsname=shared, profile rbd\"\n\tcaps osd = \"allow r tag cephfs data=shared, profile rbd pool=vms\"\n", client.Keyring)
+}
+
+func (s *authClientSuite) TestManagedClientsAreProtected() {
+	state := interfaces.CephState{State: &mocks.MockState{ClusterName: "node0"}}
+
+	for _, name := range []string{"admin", "radosgw.gateway", "nfs.foo.node0", "rbd-mirror.node0", "siteb"} {
+		assert.Error(s.T(), RemoveAuthClient(context.Background(), state, name), name)
+
+		_, err := CreateAuthClient(context.Background(), state, types.AuthClientRequest{Name: name, Profiles: []types.AuthClientProfile{{Type: types.AuthProfileRgw}}})
+		assert.Error(s.T(), err, name)
+	}
+}
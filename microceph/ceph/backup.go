@@ -0,0 +1,863 @@
+package ceph
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+	"github.com/canonical/microceph/microceph/version"
+)
+
+// Layout of a backup archive.
+const (
+	backupManifestFile = "manifest.json"
+	backupDatabaseFile = "database.json"
+	backupAuthFile     = "auth.keyring"
+	backupMonDir       = "mon"
+	backupConfKeyrings = "keyrings/conf"
+	backupDataKeyrings = "keyrings/data"
+)
+
+// backupFormatVersion is bumped on incompatible changes of the archive layout.
+const backupFormatVersion = 1
+
+// backupMonTimeout bounds the wait on the local monitor to join the quorum
+// after a backup or a restore.
+var backupMonTimeout = 5 * time.Minute
+
+// backupManifest describes a backup archive. Monitors are the monitors in the
+// monmap at backup time.
+type backupManifest struct {
+	Version          int       `json:"version"`
+	FsID             string    `json:"fsid"`
+	Member           string    `json:"member"`
+	Monitors         []string  `json:"monitors"`
+	MicroCephVersion string    `json:"microceph_version"`
+	Created          time.Time `json:"created"`
+}
+
+// backupFile is a file of a backup archive held in memory.
+type backupFile struct {
+	name    string
+	mode    int64
+	content []byte
+}
+
+// configValue fetches the value of a key from the dumped rows of the config table.
+func configValue(rows database.TableRows, key string) string {
+	for _, row := range rows {
+		if row["key"] == key {
+			value, _ := row["value"].(string)
+			return value
+		}
+	}
+
+	return ""
+}
+
+// collectKeyrings reads the keyrings of the member, the monitor keyring being
+// part of the monitor store.
+func collectKeyrings(paths constants.PathConst) ([]backupFile, error) {
+	files := []backupFile{}
+
+	confKeyrings, err := filepath.Glob(filepath.Join(paths.ConfPath, "*.keyring"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range confKeyrings {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keyring: %w", err)
+		}
+
+		files = append(files, backupFile{name: backupConfKeyrings + "/" + filepath.Base(path), mode: 0600, content: content})
+	}
+
+	dataKeyrings, err := filepath.Glob(filepath.Join(paths.DataPath, "*", "*", "keyring"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range dataKeyrings {
+		rel, err := filepath.Rel(paths.DataPath, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(rel, backupMonDir+string(filepath.Separator)) {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keyring: %w", err)
+		}
+
+		files = append(files, backupFile{name: backupDataKeyrings + "/" + filepath.ToSlash(rel), mode: 0600, content: content})
+	}
+
+	return files, nil
+}
+
+// writeBackupArchive writes a gzipped tar archive of the files and of the
+// monitor store.
+func writeBackupArchive(w io.Writer, files []backupFile, monDataPath string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, file := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    file.mode,
+			Size:    int64(len(file.content)),
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tw.Write(file.content)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := filepath.WalkDir(monDataPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(monDataPath, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		header.Name = filepath.ToSlash(filepath.Join(backupMonDir, rel))
+		err = tw.WriteHeader(header)
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive monitor store: %w", err)
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// readBackupArchive reads the manifest and database of a backup archive and
+// extracts the other files into a directory.
+func readBackupArchive(r io.Reader, dir string) (backupManifest, map[string]database.TableRows, error) {
+	manifest := backupManifest{}
+	var tables map[string]database.TableRows
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return manifest, nil, fmt.Errorf("not a backup archive: %w", err)
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return manifest, nil, fmt.Errorf("failed to read backup archive: %w", err)
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return manifest, nil, fmt.Errorf("invalid path %q in backup archive", header.Name)
+		}
+
+		switch {
+		case name == backupManifestFile:
+			err = json.NewDecoder(tr).Decode(&manifest)
+		case name == backupDatabaseFile:
+			decoder := json.NewDecoder(tr)
+			decoder.UseNumber()
+			err = decoder.Decode(&tables)
+		case header.Typeflag == tar.TypeDir:
+			err = os.MkdirAll(filepath.Join(dir, name), 0700)
+		case header.Typeflag == tar.TypeReg:
+			err = extractFile(filepath.Join(dir, name), header.FileInfo().Mode().Perm(), tr)
+		}
+		if err != nil {
+			return manifest, nil, fmt.Errorf("failed to read %s from backup archive: %w", header.Name, err)
+		}
+	}
+
+	if manifest.Version == 0 {
+		return manifest, nil, fmt.Errorf("backup archive has no manifest")
+	}
+
+	if manifest.Version != backupFormatVersion {
+		return manifest, nil, fmt.Errorf("unsupported backup format version %d", manifest.Version)
+	}
+
+	if tables == nil {
+		return manifest, nil, fmt.Errorf("backup archive has no database")
+	}
+
+	return manifest, tables, nil
+}
+
+// extractFile writes a file extracted from an archive.
+func extractFile(path string, mode os.FileMode, r io.Reader) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+	return errors.Join(err, f.Close())
+}
+
+// copyTree copies the files of a directory into another one, nothing is done
+// when the source does not exist.
+func copyTree(src string, dst string) error {
+	_, err := os.Stat(src)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(target, content, 0600)
+	})
+}
+
+// backupFiles dumps the MicroCeph database and lays out the files of a backup
+// archive, the fsid of the cluster is set on the result.
+func backupFiles(ctx context.Context, s interfaces.StateInterface, result *types.ClusterBackupResult, auth string, keyrings []backupFile) ([]backupFile, error) {
+	var tables map[string]database.TableRows
+	err := s.ClusterState().Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		tables, err = database.DumpTables(ctx, tx, database.BackupTables)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump database: %w", err)
+	}
+
+	result.FsID = configValue(tables["config"], "fsid")
+	manifest, err := json.Marshal(backupManifest{
+		Version:          backupFormatVersion,
+		FsID:             result.FsID,
+		Member:           result.Member,
+		Monitors:         result.Monitors,
+		MicroCephVersion: version.Version(),
+		Created:          result.Created,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dump, err := json.Marshal(tables)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]backupFile{
+		{name: backupManifestFile, mode: 0600, content: manifest},
+		{name: backupDatabaseFile, mode: 0600, content: dump},
+		{name: backupAuthFile, mode: 0600, content: []byte(auth)},
+	}, keyrings...), nil
+}
+
+// BackupCluster writes an archive of the MicroCeph database, the auth
+// database, the keyrings of the local member and its monitor store. The local
+// monitor is always stopped while its store is archived for the copy to be
+// consistent. Unless the backup is forced, it is refused when mon ok-to-stop
+// reports the other monitors cannot keep the quorum meanwhile. The database
+// is dumped while the monitor is stopped.
+func BackupCluster(ctx context.Context, s interfaces.StateInterface, req types.ClusterBackup) (types.ClusterBackupResult, error) {
+	member := s.ClusterState().Name()
+	result := types.ClusterBackupResult{Member: member, Created: time.Now().UTC()}
+
+	services, err := database.ServiceQuery.List(ctx, s.ClusterState())
+	if err != nil {
+		return result, fmt.Errorf("failed fetching services from db: %w", err)
+	}
+
+	if !isServicePlacementOnHost(services, "mon", member) {
+		return result, fmt.Errorf("%s hosts no monitor, back up from a monitor host", member)
+	}
+
+	result.Monitors, err = getMonmapNames(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	auth, err := cephRun("auth", "export")
+	if err != nil {
+		return result, fmt.Errorf("failed to export auth database: %w", err)
+	}
+
+	if !req.Force {
+		_, err = cephRun("mon", "ok-to-stop", member)
+		if err != nil {
+			return result, fmt.Errorf("stopping mon.%s breaks the monitor quorum, force the backup to accept the outage", member)
+		}
+	}
+
+	paths := constants.GetPathConst()
+	keyrings, err := collectKeyrings(paths)
+	if err != nil {
+		return result, err
+	}
+
+	err = os.MkdirAll(paths.BackupPath, constants.PermissionOnlyUserAccess)
+	if err != nil {
+		return result, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	result.Path = filepath.Join(paths.BackupPath, fmt.Sprintf("microceph-backup-%s.tar.gz", result.Created.Format("20060102-150405")))
+	f, err := os.OpenFile(result.Path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return result, fmt.Errorf("failed to create backup archive: %w", err)
+	}
+
+	err = snapStop("mon", false)
+	if err != nil {
+		f.Close()
+		os.Remove(result.Path)
+		return result, fmt.Errorf("failed to stop mon.%s: %w", member, err)
+	}
+
+	// The database is dumped while the monitor is stopped for it to match the
+	// monitor store.
+	files, err := backupFiles(ctx, s, &result, auth, keyrings)
+	if err == nil {
+		err = writeBackupArchive(f, files, filepath.Join(paths.DataPath, "mon", "ceph-"+member))
+	}
+
+	err = errors.Join(err, f.Close())
+	if err != nil {
+		os.Remove(result.Path)
+		err = fmt.Errorf("failed to write backup archive: %w", err)
+	}
+
+	startErr := snapStart("mon", false)
+	if startErr != nil {
+		return result, errors.Join(err, fmt.Errorf("failed to start mon.%s: %w", member, startErr))
+	}
+
+	if err != nil {
+		return result, err
+	}
+
+	if !waitForControlServiceReady(ctx, "mon", member, time.Now().Add(backupMonTimeout)) {
+		return result, fmt.Errorf("mon.%s did not rejoin the quorum after the backup", member)
+	}
+
+	logger.Infof("Backup: wrote backup of cluster %s to %s", result.FsID, result.Path)
+	return result, nil
+}
+
+// ValidateBackupPath checks a backup archive to restore lies in the backup
+// directory of the member.
+func ValidateBackupPath(path string) error {
+	dir := constants.GetPathConst().BackupPath
+	if filepath.Dir(filepath.Clean(path)) != dir {
+		return fmt.Errorf("backup archive must be in %s", dir)
+	}
+
+	return nil
+}
+
+// backupV2Only tells whether the monitors of a backed up cluster only use the
+// v2 messenger.
+func backupV2Only(config database.TableRows) bool {
+	monitors := 0
+	for _, row := range config {
+		key, _ := row["key"].(string)
+		if !strings.HasPrefix(key, "mon.host.") {
+			continue
+		}
+
+		value, _ := row["value"].(string)
+		if !strings.HasPrefix(value, "v2:") {
+			return false
+		}
+
+		monitors++
+	}
+
+	return monitors != 0
+}
+
+// restoreControlPlaneRows rewrites the dumped rows for the local member to be
+// the only monitor and manager host. The rows of the other daemons of the
+// local member are left out as the restore does not start them, they are
+// returned by name.
+func restoreControlPlaneRows(tables map[string]database.TableRows, member string, monHost string) (map[string]database.TableRows, []string) {
+	restored := map[string]database.TableRows{}
+	for table, rows := range tables {
+		restored[table] = rows
+	}
+
+	config := database.TableRows{}
+	for _, row := range tables["config"] {
+		key, _ := row["key"].(string)
+		if !strings.HasPrefix(key, "mon.host.") {
+			config = append(config, row)
+		}
+	}
+
+	restored["config"] = append(config, map[string]any{"key": "mon.host." + member, "value": monHost})
+
+	notStarted := []string{}
+	services := database.TableRows{}
+	for _, row := range tables["services"] {
+		if row["member"] == member {
+			if row["service"] != "mon" && row["service"] != "mgr" {
+				notStarted = append(notStarted, fmt.Sprint(row["service"]))
+			}
+
+			continue
+		}
+
+		services = append(services, row)
+	}
+
+	restored["services"] = append(services,
+		map[string]any{"member": member, "service": "mon"},
+		map[string]any{"member": member, "service": "mgr"},
+	)
+
+	disks := database.TableRows{}
+	for _, row := range tables["disks"] {
+		if row["member"] == member {
+			notStarted = append(notStarted, fmt.Sprintf("osd.%v", row["id"]))
+			continue
+		}
+
+		disks = append(disks, row)
+	}
+
+	if tables["disks"] != nil {
+		restored["disks"] = disks
+	}
+
+	groups := map[string]string{}
+	for _, row := range tables["service_groups"] {
+		groups[fmt.Sprint(row["id"])] = fmt.Sprintf("%v.%v", row["service"], row["group_id"])
+	}
+
+	grouped := database.TableRows{}
+	for _, row := range tables["grouped_services"] {
+		if row["member"] == member {
+			notStarted = append(notStarted, groups[fmt.Sprint(row["service_group_id"])])
+			continue
+		}
+
+		grouped = append(grouped, row)
+	}
+
+	if tables["grouped_services"] != nil {
+		restored["grouped_services"] = grouped
+	}
+
+	return restored, notStarted
+}
+
+// restoreMonStore puts a monitor store in place of the one of the local
+// monitor, which is kept aside, and rewrites its monmap to hold the local
+// monitor only. It returns the monitors removed from the monmap and the path
+// the previous store was moved to, empty if there was none. The previous
+// store is put back when the restore fails.
+func restoreMonStore(store string, monDataPath string, member string, monIP string) ([]string, string, error) {
+	_, err := os.Stat(store)
+	if err != nil {
+		return nil, "", fmt.Errorf("backup archive has no monitor store: %w", err)
+	}
+
+	// The monitor does not run on a member with Ceph bootstrap deferred.
+	err = snapStop("mon", false)
+	if err != nil {
+		logger.Warnf("Restore: failed to stop mon.%s: %v", member, err)
+	}
+
+	aside := ""
+	_, err = os.Stat(monDataPath)
+	if err == nil {
+		aside = fmt.Sprintf("%s.pre-restore-%d", monDataPath, time.Now().Unix())
+		err = os.Rename(monDataPath, aside)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to move aside the store of mon.%s: %w", member, err)
+		}
+
+		logger.Infof("Restore: moved the store of mon.%s to %s", member, aside)
+	}
+
+	removed, err := injectRestoredStore(store, monDataPath, member, monIP)
+	if err != nil {
+		return nil, "", errors.Join(err, rollbackMonStore(monDataPath, aside))
+	}
+
+	return removed, aside, nil
+}
+
+// rollbackMonStore puts back the store of the local monitor kept aside by
+// restoreMonStore.
+func rollbackMonStore(monDataPath string, aside string) error {
+	err := os.RemoveAll(monDataPath)
+	if err != nil {
+		return fmt.Errorf("failed to remove the restored monitor store: %w", err)
+	}
+
+	if len(aside) == 0 {
+		return nil
+	}
+
+	err = os.Rename(aside, monDataPath)
+	if err != nil {
+		return fmt.Errorf("failed to put back the monitor store kept at %s: %w", aside, err)
+	}
+
+	logger.Infof("Restore: put back the monitor store kept at %s", aside)
+	return nil
+}
+
+// injectRestoredStore moves a restored monitor store in place and injects a
+// monmap holding the local monitor only. It returns the monitors removed from
+// the monmap.
+func injectRestoredStore(store string, monDataPath string, member string, monIP string) ([]string, error) {
+	err := os.MkdirAll(filepath.Dir(monDataPath), constants.PermissionOnlyUserAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Rename(store, monDataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore the store of mon.%s: %w", member, err)
+	}
+
+	monmap := filepath.Join(filepath.Dir(store), "monmap")
+	names, err := extractMonmap(member, monDataPath, monmap)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []string{}
+	for _, name := range names {
+		err = rmMonmap(monmap, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if name != member {
+			removed = append(removed, name)
+		}
+	}
+
+	err = addMonmap(monmap, member, monIP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add mon.%s to monmap: %w", member, err)
+	}
+
+	err = injectMonmap(member, monDataPath, monmap)
+	if err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+// restoreKeyrings puts back the keyrings of a backup. The keyrings of the
+// daemons are only restored onto the member they were backed up from, the
+// auth database of the restored monitor store holding every key anyway.
+func restoreKeyrings(staging string, paths constants.PathConst, sameMember bool) error {
+	err := copyTree(filepath.Join(staging, filepath.FromSlash(backupConfKeyrings)), paths.ConfPath)
+	if err != nil {
+		return fmt.Errorf("failed to restore keyrings: %w", err)
+	}
+
+	if !sameMember {
+		return nil
+	}
+
+	err = copyTree(filepath.Join(staging, filepath.FromSlash(backupDataKeyrings)), paths.DataPath)
+	if err != nil {
+		return fmt.Errorf("failed to restore keyrings: %w", err)
+	}
+
+	return nil
+}
+
+// keyringPath maps the archive name of a keyring collected by collectKeyrings
+// back to its path on the member.
+func keyringPath(paths constants.PathConst, name string) string {
+	rel, ok := strings.CutPrefix(name, backupConfKeyrings+"/")
+	if ok {
+		return filepath.Join(paths.ConfPath, rel)
+	}
+
+	rel = strings.TrimPrefix(name, backupDataKeyrings+"/")
+	return filepath.Join(paths.DataPath, filepath.FromSlash(rel))
+}
+
+// putBackKeyrings puts back the keyrings collected before a restore, removing
+// the keyrings the restore added.
+func putBackKeyrings(paths constants.PathConst, keyrings []backupFile) error {
+	current, err := collectKeyrings(paths)
+	if err != nil {
+		return err
+	}
+
+	kept := map[string]bool{}
+	for _, file := range keyrings {
+		kept[file.name] = true
+	}
+
+	for _, file := range current {
+		if kept[file.name] {
+			continue
+		}
+
+		err = os.Remove(keyringPath(paths, file.name))
+		if err != nil {
+			return fmt.Errorf("failed to remove restored keyring: %w", err)
+		}
+	}
+
+	for _, file := range keyrings {
+		err = os.WriteFile(keyringPath(paths, file.name), file.content, os.FileMode(file.mode))
+		if err != nil {
+			return fmt.Errorf("failed to put back keyring: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rollbackRestore stops the restored monitor and manager and puts back the
+// monitor store and keyrings the member had before the restore.
+func rollbackRestore(member string, paths constants.PathConst, monDataPath string, aside string, keyrings []backupFile) error {
+	for _, service := range []string{"mgr", "mon"} {
+		err := snapStop(service, true)
+		if err != nil {
+			logger.Warnf("Restore: failed to stop %s.%s: %v", service, member, err)
+		}
+	}
+
+	return errors.Join(rollbackMonStore(monDataPath, aside), putBackKeyrings(paths, keyrings))
+}
+
+// RestoreCluster rebuilds a single-node control plane from a backup archive:
+// the monitor store is restored with a monmap holding only the local monitor,
+// the MicroCeph database is restored and the monitor and manager started. The
+// member must be the only member of a cluster bootstrapped with Ceph bootstrap
+// deferred.
+func RestoreCluster(ctx context.Context, s interfaces.StateInterface, req types.ClusterRestore) (types.ClusterRestoreResult, error) {
+	member := s.ClusterState().Name()
+	result := types.ClusterRestoreResult{Member: member}
+
+	err := ValidateBackupPath(req.Path)
+	if err != nil {
+		return result, err
+	}
+
+	bootstrapped, err := cephIsBootstrappedFunc(ctx, s)
+	if err != nil {
+		return result, err
+	}
+
+	if bootstrapped {
+		return result, fmt.Errorf("Ceph is already bootstrapped, restore onto a member bootstrapped with Ceph bootstrap deferred")
+	}
+
+	members := len(s.ClusterState().Truststore().RemotesByName())
+	if members > 1 {
+		return result, fmt.Errorf("restore rebuilds a single-node control plane, the cluster has %d members", members)
+	}
+
+	paths := constants.GetPathConst()
+
+	// Staged on the filesystem of the monitor stores so that the restored
+	// store can be moved in place.
+	staging, err := os.MkdirTemp(paths.DataPath, "restore-")
+	if err != nil {
+		return result, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	f, err := os.Open(req.Path)
+	if err != nil {
+		return result, fmt.Errorf("failed to open backup archive: %w", err)
+	}
+
+	manifest, tables, err := readBackupArchive(f, staging)
+	f.Close()
+	if err != nil {
+		return result, err
+	}
+
+	result.FsID = manifest.FsID
+
+	pubNet := configValue(tables["config"], "public_network")
+	monIP, err := common.Network.FindIpOnSubnet(pubNet)
+	if err != nil {
+		return result, fmt.Errorf("failed to locate IP on public network %s: %w", pubNet, err)
+	}
+
+	monHost := monIP
+	if backupV2Only(tables["config"]) {
+		monHost = "v2:" + monIP + ":3300"
+	}
+
+	tables, result.NotStarted = restoreControlPlaneRows(tables, member, monHost)
+
+	// The keyrings of the member are put back along with its monitor store
+	// when the restore fails once they are overwritten.
+	savedKeyrings, err := collectKeyrings(paths)
+	if err != nil {
+		return result, err
+	}
+
+	monDataPath := filepath.Join(paths.DataPath, "mon", "ceph-"+member)
+	removed, aside, err := restoreMonStore(filepath.Join(staging, backupMonDir), monDataPath, member, monIP)
+	if err != nil {
+		return result, err
+	}
+
+	// The database is restored in a single transaction, the monitor store is
+	// put back when it fails to leave the member as it was.
+	err = s.ClusterState().Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		result.Skipped, err = database.RestoreTables(ctx, tx, tables)
+		return err
+	})
+	if err != nil {
+		return result, errors.Join(fmt.Errorf("failed to restore database: %w", err), rollbackMonStore(monDataPath, aside))
+	}
+
+	result.RemovedMonitors = removed
+
+	// From here on a failure stops the restored daemons and puts back the
+	// monitor store and keyrings the member had.
+	rollback := func(err error) error {
+		return errors.Join(err, rollbackRestore(member, paths, monDataPath, aside, savedKeyrings))
+	}
+
+	err = restoreKeyrings(staging, paths, manifest.Member == member)
+	if err != nil {
+		return result, rollback(err)
+	}
+
+	err = UpdateConfig(ctx, s)
+	if err != nil {
+		return result, rollback(err)
+	}
+
+	err = snapStart("mon", true)
+	if err != nil {
+		return result, rollback(fmt.Errorf("failed to start mon.%s: %w", member, err))
+	}
+
+	if !waitForControlServiceReady(ctx, "mon", member, time.Now().Add(backupMonTimeout)) {
+		return result, rollback(fmt.Errorf("mon.%s did not form a quorum after the restore", member))
+	}
+
+	err = enableMsgr2()
+	if err != nil {
+		return result, rollback(err)
+	}
+
+	mgrDataPath := filepath.Join(paths.DataPath, "mgr", "ceph-"+member)
+	_, err = os.Stat(filepath.Join(mgrDataPath, "keyring"))
+	if err != nil {
+		err = os.MkdirAll(mgrDataPath, constants.PermissionOnlyUserAccess)
+		if err != nil {
+			return result, rollback(fmt.Errorf("failed to restore manager: %w", err))
+		}
+
+		err = bootstrapMgr(member, mgrDataPath)
+		if err != nil {
+			return result, rollback(fmt.Errorf("failed to restore manager: %w", err))
+		}
+	}
+
+	err = snapStart("mgr", true)
+	if err != nil {
+		return result, rollback(fmt.Errorf("failed to start mgr.%s: %w", member, err))
+	}
+
+	err = MarkCephBootstrappedFunc(ctx, s)
+	if err != nil {
+		return result, rollback(err)
+	}
+
+	logger.Infof("Restore: restored cluster %s on %s from %s", result.FsID, member, req.Path)
+	return result, nil
+}
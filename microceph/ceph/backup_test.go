@@ -0,0 +1,236 @@
+package ceph
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type backupSuite struct {
+	tests.BaseSuite
+}
+
+func TestBackup(t *testing.T) {
+	suite.Run(t, new(backupSuite))
+}
+
+func (s *backupSuite) TestBackupArchiveRoundTrip() {
+	monDataPath := filepath.Join(s.T().TempDir(), "ceph-node1")
+	assert.NoError(s.T(), os.MkdirAll(filepath.Join(monDataPath, "store.db"), 0700))
+	assert.NoError(s.T(), os.WriteFile(filepath.Join(monDataPath, "keyring"), []byte("[mon.]\n"), 0600))
+	assert.NoError(s.T(), os.WriteFile(filepath.Join(monDataPath, "store.db", "000001.sst"), []byte("sst"), 0600))
+
+	manifest, err := json.Marshal(backupManifest{Version: backupFormatVersion, FsID: "abc", Member: "node1", Monitors: []string{"node1", "node2"}})
+	assert.NoError(s.T(), err)
+
+	files := []backupFile{
+		{name: backupManifestFile, mode: 0600, content: manifest},
+		{name: backupDatabaseFile, mode: 0600, content: []byte(`{"config":[{"id":1,"key":"fsid","value":"abc"}],"services":[{"id":1,"member":"node1","member_id":1,"service":"mon"}]}`)},
+		{name: backupConfKeyrings + "/ceph.client.admin.keyring", mode: 0600, content: []byte("[client.admin]\n")},
+		{name: backupDataKeyrings + "/mgr/ceph-node1/keyring", mode: 0600, content: []byte("[mgr.node1]\n")},
+	}
+
+	var archive bytes.Buffer
+	assert.NoError(s.T(), writeBackupArchive(&archive, files, monDataPath))
+
+	dir := s.T().TempDir()
+	readManifest, tables, err := readBackupArchive(&archive, dir)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "abc", readManifest.FsID)
+	assert.Equal(s.T(), []string{"node1", "node2"}, readManifest.Monitors)
+	assert.Equal(s.T(), "abc", configValue(tables["config"], "fsid"))
+	assert.Equal(s.T(), json.Number("1"), tables["services"][0]["member_id"])
+
+	content, err := os.ReadFile(filepath.Join(dir, backupMonDir, "store.db", "000001.sst"))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "sst", string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, backupDataKeyrings, "mgr", "ceph-node1", "keyring"))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "[mgr.node1]\n", string(content))
+}
+
+func (s *backupSuite) TestReadBackupArchiveRejectsEscapingPaths() {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	assert.NoError(s.T(), tw.WriteHeader(&tar.Header{Name: "../../etc/passwd", Mode: 0600, Size: 1, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("x"))
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), tw.Close())
+	assert.NoError(s.T(), gz.Close())
+
+	_, _, err = readBackupArchive(&archive, s.T().TempDir())
+	assert.ErrorContains(s.T(), err, "invalid path")
+}
+
+func (s *backupSuite) TestRestoreControlPlaneRows() {
+	tables := map[string]database.TableRows{
+		"config": {
+			{"key": "fsid", "value": "abc"},
+			{"key": "mon.host.node1", "value": "v2:10.0.0.1:3300"},
+			{"key": "mon.host.node2", "value": "v2:10.0.0.2:3300"},
+		},
+		"services": {
+			{"member": "node1", "service": "mon"},
+			{"member": "node1", "service": "rgw"},
+			{"member": "node2", "service": "mon"},
+		},
+		"disks": {
+			{"id": int64(1), "member": "node1", "path": "/dev/sdb"},
+			{"id": int64(2), "member": "node2", "path": "/dev/sdb"},
+		},
+		"service_groups": {
+			{"id": int64(1), "service": "nfs", "group_id": "fs1"},
+		},
+		"grouped_services": {
+			{"id": int64(1), "service_group_id": int64(1), "member": "node1"},
+		},
+	}
+
+	assert.True(s.T(), backupV2Only(tables["config"]))
+	assert.False(s.T(), backupV2Only(database.TableRows{{"key": "mon.host.node1", "value": "10.0.0.1"}}))
+
+	restored, notStarted := restoreControlPlaneRows(tables, "node1", "v2:10.0.0.9:3300")
+	assert.Equal(s.T(), []string{"rgw", "osd.1", "nfs.fs1"}, notStarted)
+	assert.Equal(s.T(), database.TableRows{
+		{"key": "fsid", "value": "abc"},
+		{"key": "mon.host.node1", "value": "v2:10.0.0.9:3300"},
+	}, restored["config"])
+	assert.Equal(s.T(), database.TableRows{
+		{"member": "node2", "service": "mon"},
+		{"member": "node1", "service": "mon"},
+		{"member": "node1", "service": "mgr"},
+	}, restored["services"])
+	assert.Equal(s.T(), database.TableRows{{"id": int64(2), "member": "node2", "path": "/dev/sdb"}}, restored["disks"])
+	assert.Empty(s.T(), restored["grouped_services"])
+
+	// The dump itself is left alone.
+	assert.Len(s.T(), tables["config"], 3)
+}
+
+func (s *backupSuite) TestRestoreMonStore() {
+	dataPath := s.T().TempDir()
+	staging := s.T().TempDir()
+	store := filepath.Join(staging, backupMonDir)
+	assert.NoError(s.T(), os.MkdirAll(store, 0700))
+	assert.NoError(s.T(), os.WriteFile(filepath.Join(store, "kv_backend"), []byte("rocksdb\n"), 0600))
+
+	monDataPath := filepath.Join(dataPath, "mon", "ceph-node3")
+	assert.NoError(s.T(), os.MkdirAll(monDataPath, 0700))
+
+	monmap := filepath.Join(staging, "monmap")
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "snapctl", "stop", "microceph.mon").Return("", nil).Once()
+	r.On("RunCommand", "ceph-mon", "-i", "node3", "--mon-data", monDataPath, "--extract-monmap", monmap).Return("", nil).Once()
+	r.On("RunCommand", "monmaptool", "--print", monmap).Return(`monmaptool: monmap file /tmp/monmap
+epoch 3
+fsid abc
+last_changed 2026-10-01T10:00:00.000000+0000
+created 2026-09-01T10:00:00.000000+0000
+min_mon_release 19 (squid)
+election_strategy: 1
+0: [v2:10.0.0.1:3300/0,v1:10.0.0.1:6789/0] mon.node1
+1: [v2:10.0.0.2:3300/0,v1:10.0.0.2:6789/0] mon.node2
+`, nil).Once()
+	r.On("RunCommand", "monmaptool", "--rm", "node1", monmap).Return("", nil).Once()
+	r.On("RunCommand", "monmaptool", "--rm", "node2", monmap).Return("", nil).Once()
+	r.On("RunCommand", "monmaptool", "--add", "node3", "10.0.0.9", monmap).Return("", nil).Once()
+	r.On("RunCommand", "ceph-mon", "-i", "node3", "--mon-data", monDataPath, "--inject-monmap", monmap).Return("", nil).Once()
+	common.ProcessExec = r
+
+	removed, aside, err := restoreMonStore(store, monDataPath, "node3", "10.0.0.9")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"node1", "node2"}, removed)
+
+	content, err := os.ReadFile(filepath.Join(monDataPath, "kv_backend"))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "rocksdb\n", string(content))
+
+	// The previous store is kept aside.
+	kept, err := filepath.Glob(monDataPath + ".pre-restore-*")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{aside}, kept)
+}
+
+func (s *backupSuite) TestRestoreMonStoreRollsBack() {
+	dataPath := s.T().TempDir()
+	staging := s.T().TempDir()
+	store := filepath.Join(staging, backupMonDir)
+	assert.NoError(s.T(), os.MkdirAll(store, 0700))
+
+	monDataPath := filepath.Join(dataPath, "mon", "ceph-node3")
+	assert.NoError(s.T(), os.MkdirAll(monDataPath, 0700))
+	assert.NoError(s.T(), os.WriteFile(filepath.Join(monDataPath, "keyring"), []byte("previous\n"), 0600))
+
+	monmap := filepath.Join(staging, "monmap")
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "snapctl", "stop", "microceph.mon").Return("", nil).Once()
+	r.On("RunCommand", "ceph-mon", "-i", "node3", "--mon-data", monDataPath, "--extract-monmap", monmap).Return("", fmt.Errorf("corrupt store")).Once()
+	common.ProcessExec = r
+
+	_, _, err := restoreMonStore(store, monDataPath, "node3", "10.0.0.9")
+	assert.Error(s.T(), err)
+
+	// The previous store is back in place.
+	content, err := os.ReadFile(filepath.Join(monDataPath, "keyring"))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "previous\n", string(content))
+
+	aside, err := filepath.Glob(monDataPath + ".pre-restore-*")
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), aside)
+}
+
+func (s *backupSuite) TestRollbackRestore() {
+	paths := constants.PathConst{ConfPath: s.T().TempDir(), DataPath: s.T().TempDir()}
+	adminKeyring := filepath.Join(paths.ConfPath, "ceph.client.admin.keyring")
+	assert.NoError(s.T(), os.WriteFile(adminKeyring, []byte("previous\n"), 0600))
+
+	saved, err := collectKeyrings(paths)
+	assert.NoError(s.T(), err)
+
+	// The restore overwrote the admin keyring, added a manager keyring and
+	// moved the previous monitor store aside.
+	assert.NoError(s.T(), os.WriteFile(adminKeyring, []byte("restored\n"), 0600))
+	mgrKeyring := filepath.Join(paths.DataPath, "mgr", "ceph-node3", "keyring")
+	assert.NoError(s.T(), os.MkdirAll(filepath.Dir(mgrKeyring), 0700))
+	assert.NoError(s.T(), os.WriteFile(mgrKeyring, []byte("[mgr.node3]\n"), 0600))
+
+	monDataPath := filepath.Join(paths.DataPath, "mon", "ceph-node3")
+	aside := monDataPath + ".pre-restore-1"
+	assert.NoError(s.T(), os.MkdirAll(monDataPath, 0700))
+	assert.NoError(s.T(), os.MkdirAll(aside, 0700))
+	assert.NoError(s.T(), os.WriteFile(filepath.Join(aside, "keyring"), []byte("previous\n"), 0600))
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "snapctl", "stop", "microceph.mgr", "--disable").Return("", nil).Once()
+	r.On("RunCommand", "snapctl", "stop", "microceph.mon", "--disable").Return("", nil).Once()
+	common.ProcessExec = r
+
+	assert.NoError(s.T(), rollbackRestore("node3", paths, monDataPath, aside, saved))
+
+	content, err := os.ReadFile(adminKeyring)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "previous\n", string(content))
+	assert.NoFileExists(s.T(), mgrKeyring)
+
+	content, err = os.ReadFile(filepath.Join(monDataPath, "keyring"))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "previous\n", string(content))
+	assert.NoDirExists(s.T(), aside)
+}
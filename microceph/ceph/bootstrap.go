@@ -31,9 +31,9 @@ func CreateSnapPaths() error {
 }
 
 // BootstrapCephConfigs configures the cluster network on mon KV store.
-func BootstrapCephConfigs(cn string, pn string) error {
+func BootstrapCephConfigs(ctx context.Context, s interfaces.StateInterface, cn string, pn string) error {
 	// Cluster Network
-	err := SetConfigItem(apiTypes.Config{
+	err := SetInternalConfigItem(ctx, s, apiTypes.Config{
 		Key:   "cluster_network",
 		Value: cn,
 	})
@@ -54,7 +54,7 @@ func BootstrapCephConfigs(cn string, pn string) error {
 	// The values are documented here and have been stable for the lifetime of Ceph
 	// https://docs.ceph.com/en/latest/rbd/rbd-config-ref/#image-features
 	// 63 = layering + exclusive-lock + object-map + fast-diff + deep-flatten + stripingv2
-	err = SetConfigItem(apiTypes.Config{
+	err = SetInternalConfigItem(ctx, s, apiTypes.Config{
 		Key:   "rbd_default_features",
 		Value: "63",
 	})
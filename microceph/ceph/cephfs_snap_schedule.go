@@ -0,0 +1,215 @@
+package ceph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// Contains methods for interacting with the snap_schedule mgr module.
+
+// AddCephFSSnapSchedule adds a snapshot schedule and/or a retention policy to a path or subvolume.
+func AddCephFSSnapSchedule(ctx context.Context, volume string, req types.FsSnapScheduleRequest) error {
+	err := ValidateFsSnapScheduleRequest(req)
+	if err != nil {
+		return err
+	}
+
+	err = EnableMgrModule(ctx, constants.MgrModuleSnapSchedule, "", "")
+	if err != nil {
+		return fmt.Errorf("failed to enable mgr module %s: %w", constants.MgrModuleSnapSchedule, err)
+	}
+
+	if len(req.Schedule) != 0 {
+		args := []string{"add", fsSnapSchedulePath(req), req.Schedule}
+		if len(req.Start) != 0 {
+			args = append(args, req.Start)
+		}
+
+		_, err = cephRunContext(ctx, cephFSSnapScheduleCmd(volume, req, args)...)
+		if err != nil {
+			return fmt.Errorf("failed to add snapshot schedule %s on %s: %w", req.Schedule, fsSnapScheduleTarget(volume, req), err)
+		}
+
+		logger.Infof("FSSNAP: added snapshot schedule %s on %s", req.Schedule, fsSnapScheduleTarget(volume, req))
+	}
+
+	if len(req.Retention) != 0 {
+		args := []string{"retention", "add", fsSnapSchedulePath(req), req.Retention}
+		_, err = cephRunContext(ctx, cephFSSnapScheduleCmd(volume, req, args)...)
+		if err != nil {
+			return fmt.Errorf("failed to add snapshot retention %s on %s: %w", req.Retention, fsSnapScheduleTarget(volume, req), err)
+		}
+
+		logger.Infof("FSSNAP: added snapshot retention %s on %s", req.Retention, fsSnapScheduleTarget(volume, req))
+	}
+
+	return nil
+}
+
+// RemoveCephFSSnapSchedule removes snapshot schedules and/or a retention policy from a path or subvolume.
+// Without a schedule or retention, all schedules on the path are removed.
+func RemoveCephFSSnapSchedule(ctx context.Context, volume string, req types.FsSnapScheduleRequest) error {
+	if len(req.Retention) != 0 {
+		args := []string{"retention", "remove", fsSnapSchedulePath(req), req.Retention}
+		_, err := cephRunContext(ctx, cephFSSnapScheduleCmd(volume, req, args)...)
+		if err != nil {
+			return fmt.Errorf("failed to remove snapshot retention %s from %s: %w", req.Retention, fsSnapScheduleTarget(volume, req), err)
+		}
+
+		// Only the retention policy was asked to be removed.
+		if len(req.Schedule) == 0 {
+			return nil
+		}
+	}
+
+	args := []string{"remove", fsSnapSchedulePath(req)}
+	if len(req.Schedule) != 0 {
+		args = append(args, req.Schedule)
+		if len(req.Start) != 0 {
+			args = append(args, req.Start)
+		}
+	}
+
+	_, err := cephRunContext(ctx, cephFSSnapScheduleCmd(volume, req, args)...)
+	if err != nil {
+		return fmt.Errorf("failed to remove snapshot schedule from %s: %w", fsSnapScheduleTarget(volume, req), err)
+	}
+
+	logger.Infof("FSSNAP: removed snapshot schedule %s from %s", req.Schedule, fsSnapScheduleTarget(volume, req))
+	return nil
+}
+
+// ListCephFSSnapSchedules fetches the snapshot schedules of a path or subvolume along with their last snapshot time.
+// Nothing is scheduled while the snap_schedule mgr module is disabled, which only adding a schedule enables.
+func ListCephFSSnapSchedules(ctx context.Context, volume string, req types.FsSnapScheduleRequest) (types.FsSnapSchedules, error) {
+	enabled, err := IsMgrModuleEnabled(ctx, constants.MgrModuleSnapSchedule)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return types.FsSnapSchedules{}, nil
+	}
+
+	return listCephFSSnapSchedules(ctx, volume, req)
+}
+
+// GetCephFSLastSnapshot fetches the most recent scheduled snapshot time of a path or subvolume.
+// An empty string is returned if no schedule has produced a snapshot yet. The snap_schedule
+// mgr module is expected to be enabled, see IsMgrModuleEnabled.
+func GetCephFSLastSnapshot(ctx context.Context, volume string, req types.FsSnapScheduleRequest) (string, error) {
+	schedules, err := listCephFSSnapSchedules(ctx, volume, req)
+	if err != nil {
+		return "", err
+	}
+
+	last := ""
+	for _, schedule := range schedules {
+		// ISO 8601 timestamps sort lexically.
+		if schedule.Last > last {
+			last = schedule.Last
+		}
+	}
+
+	return last, nil
+}
+
+// ValidateFsSnapScheduleRequest checks the schedule and retention specs of a request.
+func ValidateFsSnapScheduleRequest(req types.FsSnapScheduleRequest) error {
+	if len(req.Schedule) == 0 && len(req.Retention) == 0 {
+		return fmt.Errorf("either a snapshot schedule or a retention policy is required")
+	}
+
+	return ValidateFsSnapScheduleRemoveRequest(req)
+}
+
+// ValidateFsSnapScheduleRemoveRequest checks the schedule and retention specs of a removal,
+// which may set neither to remove every schedule of the path.
+func ValidateFsSnapScheduleRemoveRequest(req types.FsSnapScheduleRequest) error {
+	if len(req.Schedule) != 0 && !types.FsSnapScheduleRegex.MatchString(req.Schedule) {
+		return fmt.Errorf("invalid snapshot schedule %q, expected a number followed by one of m, h, d, w, M, y", req.Schedule)
+	}
+
+	if len(req.Retention) != 0 && !types.FsSnapRetentionRegex.MatchString(req.Retention) {
+		return fmt.Errorf("invalid retention policy %q, expected counts followed by one of n, m, h, d, w, M, y (e.g. 24h7d)", req.Retention)
+	}
+
+	if len(req.Group) != 0 && len(req.Subvolume) == 0 {
+		return fmt.Errorf("a subvolume group requires a subvolume")
+	}
+
+	if len(req.Start) != 0 && len(req.Schedule) == 0 {
+		return fmt.Errorf("a start time requires a snapshot schedule")
+	}
+
+	return nil
+}
+
+// ##### Helper Functions #####
+
+// listCephFSSnapSchedules fetches the snapshot schedules of a path or subvolume.
+func listCephFSSnapSchedules(ctx context.Context, volume string, req types.FsSnapScheduleRequest) (types.FsSnapSchedules, error) {
+	args := []string{"status", fsSnapSchedulePath(req), "--format=json"}
+	output, err := cephRunContext(ctx, cephFSSnapScheduleCmd(volume, req, args)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch snapshot schedules of %s: %w", fsSnapScheduleTarget(volume, req), err)
+	}
+
+	return parseCephFSSnapScheduleStatus(output)
+}
+
+// cephFSSnapScheduleCmd prefixes the snap-schedule command and scopes it to a volume and subvolume.
+func cephFSSnapScheduleCmd(volume string, req types.FsSnapScheduleRequest, args []string) []string {
+	cmd := append([]string{"fs", "snap-schedule"}, args...)
+	cmd = append(cmd, "--fs", volume)
+
+	if len(req.Subvolume) != 0 {
+		cmd = append(cmd, "--subvol", req.Subvolume)
+		if len(req.Group) != 0 {
+			cmd = append(cmd, "--group", req.Group)
+		}
+	}
+
+	return cmd
+}
+
+// fsSnapSchedulePath provides the schedule path, relative to the subvolume if one is requested.
+func fsSnapSchedulePath(req types.FsSnapScheduleRequest) string {
+	if len(req.Path) == 0 {
+		return "/"
+	}
+
+	return req.Path
+}
+
+// fsSnapScheduleTarget describes the scheduled resource for logs and errors.
+func fsSnapScheduleTarget(volume string, req types.FsSnapScheduleRequest) string {
+	if len(req.Subvolume) != 0 {
+		return fmt.Sprintf("%s:%s/%s%s", volume, req.Group, req.Subvolume, fsSnapSchedulePath(req))
+	}
+
+	return fmt.Sprintf("%s:%s", volume, fsSnapSchedulePath(req))
+}
+
+func parseCephFSSnapScheduleStatus(output string) (types.FsSnapSchedules, error) {
+	schedules := types.FsSnapSchedules{}
+
+	// The mgr module reports a plain message rather than json when nothing is scheduled.
+	output = strings.TrimSpace(output)
+	if len(output) == 0 || !strings.HasPrefix(output, "[") {
+		return schedules, nil
+	}
+
+	err := json.Unmarshal([]byte(output), &schedules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot schedule status: %w", err)
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,104 @@
+package ceph
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type CephFSSnapScheduleSuite struct {
+	tests.BaseSuite
+}
+
+func TestCephFSSnapSchedule(t *testing.T) {
+	suite.Run(t, new(CephFSSnapScheduleSuite))
+}
+
+func (s *CephFSSnapScheduleSuite) TestAddCephFSSnapScheduleSubvolume() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", "ceph", "mgr", "module", "enable", "snap_schedule").Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snap-schedule", "add", "/", "1h",
+		"--fs", "vol1", "--subvol", "share1", "--group", "tenants").Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snap-schedule", "retention", "add", "/", "24h7d",
+		"--fs", "vol1", "--subvol", "share1", "--group", "tenants").Return("", nil).Once()
+	common.ProcessExec = r
+
+	err := AddCephFSSnapSchedule(context.Background(), "vol1", types.FsSnapScheduleRequest{
+		Subvolume: "share1",
+		Group:     "tenants",
+		Schedule:  "1h",
+		Retention: "24h7d",
+	})
+	assert.NoError(s.T(), err)
+}
+
+func (s *CephFSSnapScheduleSuite) TestAddCephFSSnapScheduleInvalid() {
+	err := AddCephFSSnapSchedule(context.Background(), "vol1", types.FsSnapScheduleRequest{Path: "/data"})
+	assert.Error(s.T(), err)
+
+	err = AddCephFSSnapSchedule(context.Background(), "vol1", types.FsSnapScheduleRequest{Path: "/data", Schedule: "hourly"})
+	assert.Error(s.T(), err)
+
+	err = AddCephFSSnapSchedule(context.Background(), "vol1", types.FsSnapScheduleRequest{Path: "/data", Retention: "7"})
+	assert.Error(s.T(), err)
+}
+
+func (s *CephFSSnapScheduleSuite) TestRemoveCephFSSnapRetentionOnly() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snap-schedule", "retention", "remove", "/data", "7d",
+		"--fs", "vol1").Return("", nil).Once()
+	common.ProcessExec = r
+
+	err := RemoveCephFSSnapSchedule(context.Background(), "vol1", types.FsSnapScheduleRequest{Path: "/data", Retention: "7d"})
+	assert.NoError(s.T(), err)
+}
+
+func (s *CephFSSnapScheduleSuite) TestGetCephFSLastSnapshot() {
+	r := mocks.NewRunner(s.T())
+
+	output, _ := os.ReadFile("./test_assets/cephfs_snap_schedule_status.json")
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snap-schedule", "status", "/", "--format=json",
+		"--fs", "vol1", "--subvol", "share1", "--group", "tenants").Return(string(output), nil).Once()
+	common.ProcessExec = r
+
+	last, err := GetCephFSLastSnapshot(context.Background(), "vol1", types.FsSnapScheduleRequest{Subvolume: "share1", Group: "tenants"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "2026-10-18T09:00:00", last)
+}
+
+func (s *CephFSSnapScheduleSuite) TestListCephFSSnapSchedulesModuleDisabled() {
+	r := mocks.NewRunner(s.T())
+
+	// Listing must not enable the mgr module.
+	r.On("RunCommandContext", mock.Anything, "ceph", "mgr", "module", "ls", "--format", "json").
+		Return(`{"always_on_modules":["balancer","crash"],"enabled_modules":["iostat"]}`, nil).Once()
+	common.ProcessExec = r
+
+	schedules, err := ListCephFSSnapSchedules(context.Background(), "vol1", types.FsSnapScheduleRequest{Path: "/data"})
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), schedules)
+}
+
+func (s *CephFSSnapScheduleSuite) TestValidateFsSnapScheduleRemoveRequest() {
+	assert.NoError(s.T(), ValidateFsSnapScheduleRemoveRequest(types.FsSnapScheduleRequest{Path: "/data"}))
+	assert.Error(s.T(), ValidateFsSnapScheduleRemoveRequest(types.FsSnapScheduleRequest{Path: "/data", Schedule: "hourly"}))
+	assert.Error(s.T(), ValidateFsSnapScheduleRemoveRequest(types.FsSnapScheduleRequest{Group: "tenants"}))
+	assert.Error(s.T(), ValidateFsSnapScheduleRemoveRequest(types.FsSnapScheduleRequest{Start: "2026-10-18T00:00:00"}))
+}
+
+func (s *CephFSSnapScheduleSuite) TestParseCephFSSnapScheduleStatusEmpty() {
+	schedules, err := parseCephFSSnapScheduleStatus("No snapshot schedule for path /data")
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), schedules)
+}
@@ -79,13 +79,31 @@ func cephFSSnapshotMirrorPeerImport(volume string, token string) error {
 
 // cephFSSnapshotMirrorPeerExists checks if a mirroring peer with the given remote name exists for the specified volume
 func cephFSSnapshotMirrorPeerExists(ctx context.Context, volume string, remoteName string) (bool, error) {
-	output, err := cephRunContext(ctx, cephFSSnapshotMirrorCmd([]string{
+	peers, err := cephFSSnapshotMirrorPeerList(ctx, volume, "", "")
+	if err != nil {
+		return false, err
+	}
+
+	for _, peer := range peers {
+		if peer.SiteName == remoteName {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// cephFSSnapshotMirrorPeerList fetches the mirroring peers of a volume keyed by peer UUID, optionally from a remote cluster.
+func cephFSSnapshotMirrorPeerList(ctx context.Context, volume string, remoteName string, localName string) (map[string]CephFSSnapshotMirrorPeer, error) {
+	args := cephFSSnapshotMirrorCmd([]string{
 		"peer_list", volume, "--format=json",
-	})...)
+	})
+
+	output, err := cephRunContext(ctx, appendRemoteClusterArgs(args, remoteName, localName)...)
 	if err != nil {
 		err = fmt.Errorf("failed to get CephFS snapshot mirror list: %w", err)
 		logger.Error(err.Error())
-		return false, err
+		return nil, err
 	}
 
 	peers := map[string]CephFSSnapshotMirrorPeer{}
@@ -93,18 +111,22 @@ func cephFSSnapshotMirrorPeerExists(ctx context.Context, volume string, remoteNa
 	if err != nil {
 		err = fmt.Errorf("failed to parse CephFS snapshot mirror list: %w", err)
 		logger.Error(err.Error())
-		return false, err
+		return nil, err
 	}
 
 	logger.Debugf("CephFS snapshot peers found: %+v", peers)
 
-	for _, peer := range peers {
-		if peer.SiteName == remoteName {
-			return true, nil
-		}
-	}
+	return peers, nil
+}
 
-	return false, nil
+// cephFSSnapshotMirrorPeerRemove removes a mirroring peer from a volume, optionally on a remote cluster.
+func cephFSSnapshotMirrorPeerRemove(ctx context.Context, volume string, peerUUID string, remoteName string, localName string) error {
+	args := cephFSSnapshotMirrorCmd([]string{
+		"peer_remove", volume, peerUUID,
+	})
+
+	_, err := cephRunContext(ctx, appendRemoteClusterArgs(args, remoteName, localName)...)
+	return err
 }
 
 // cephFSSnapshotMirrorList fetches the list of paths enabled for mirroring in a volume
@@ -114,6 +136,15 @@ func cephFSSnapshotMirrorList(ctx context.Context, volume string) (string, error
 	})...)
 }
 
+// cephFSSnapshotMirrorRemoteList fetches the list of paths enabled for mirroring in a volume on a remote cluster
+func cephFSSnapshotMirrorRemoteList(ctx context.Context, volume string, remoteName string, localName string) (string, error) {
+	args := cephFSSnapshotMirrorCmd([]string{
+		"ls", volume, "--format=json",
+	})
+
+	return cephRunContext(ctx, appendRemoteClusterArgs(args, remoteName, localName)...)
+}
+
 // cephFSSnapshotMirrorDaemonStatus fetches the cephfs mirroring daemon status
 func cephFSSnapshotMirrorDaemonStatus(ctx context.Context) (string, error) {
 	return cephRunContext(ctx, cephFSSnapshotMirrorCmd([]string{
@@ -0,0 +1,223 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// Subvolume kinds as named by the ceph fs CLI.
+const (
+	fsKindSubvolume      = "subvolume"
+	fsKindSubvolumeGroup = "subvolumegroup"
+)
+
+// CreateCephFSSubvolume creates a subvolume with the requested quota, pool layout and mode.
+func CreateCephFSSubvolume(ctx context.Context, volume string, req types.FsSubvolumeRequest) error {
+	args := []string{"fs", fsKindSubvolume, "create", volume, req.Name}
+	args = append(args, fsCreateOptArgs(req)...)
+	args = appendFsGroupArgs(args, req.Group)
+
+	_, err := cephRunContext(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to create subvolume %s in CephFS volume %s: %w", req.Name, volume, err)
+	}
+
+	logger.Infof("FSVOL: created subvolume %s/%s in volume %s", req.Group, req.Name, volume)
+	return nil
+}
+
+// ResizeCephFSSubvolume updates the quota of a subvolume, or removes it if unlimited.
+func ResizeCephFSSubvolume(ctx context.Context, volume string, req types.FsSubvolumeRequest) error {
+	err := ValidateFsResizeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"fs", fsKindSubvolume, "resize", volume, req.Name, fsQuotaArg(req)}
+	args = appendFsGroupArgs(args, req.Group)
+	if req.NoShrink {
+		args = append(args, "--no_shrink")
+	}
+
+	_, err = cephRunContext(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to resize subvolume %s in CephFS volume %s: %w", req.Name, volume, err)
+	}
+
+	return nil
+}
+
+// RemoveCephFSSubvolume removes a subvolume and its data.
+func RemoveCephFSSubvolume(ctx context.Context, volume string, req types.FsSubvolumeRequest) error {
+	args := []string{"fs", fsKindSubvolume, "rm", volume, req.Name}
+	args = appendFsGroupArgs(args, req.Group)
+	if req.IsForceOp {
+		args = append(args, "--force")
+	}
+
+	_, err := cephRunContext(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to remove subvolume %s from CephFS volume %s: %w", req.Name, volume, err)
+	}
+
+	logger.Infof("FSVOL: removed subvolume %s/%s from volume %s", req.Group, req.Name, volume)
+	return nil
+}
+
+// GetCephFSSubvolumeAbsPath fetches the absolute path of a subvolume within its volume.
+func GetCephFSSubvolumeAbsPath(ctx context.Context, volume string, subvolume string, group string) (string, error) {
+	args := appendFsGroupArgs([]string{"fs", fsKindSubvolume, "getpath", volume, subvolume}, group)
+
+	output, err := cephRunContext(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get path of subvolume %s in CephFS volume %s: %w", subvolume, volume, err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// CreateCephFSSubvolumeGroup creates a subvolume group with the requested quota, pool layout and mode.
+func CreateCephFSSubvolumeGroup(ctx context.Context, volume string, req types.FsSubvolumeRequest) error {
+	args := []string{"fs", fsKindSubvolumeGroup, "create", volume, req.Name}
+	args = append(args, fsCreateOptArgs(req)...)
+
+	_, err := cephRunContext(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to create subvolume group %s in CephFS volume %s: %w", req.Name, volume, err)
+	}
+
+	logger.Infof("FSVOL: created subvolume group %s in volume %s", req.Name, volume)
+	return nil
+}
+
+// ResizeCephFSSubvolumeGroup updates the quota of a subvolume group, or removes it if unlimited.
+func ResizeCephFSSubvolumeGroup(ctx context.Context, volume string, req types.FsSubvolumeRequest) error {
+	err := ValidateFsResizeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"fs", fsKindSubvolumeGroup, "resize", volume, req.Name, fsQuotaArg(req)}
+	if req.NoShrink {
+		args = append(args, "--no_shrink")
+	}
+
+	_, err = cephRunContext(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to resize subvolume group %s in CephFS volume %s: %w", req.Name, volume, err)
+	}
+
+	return nil
+}
+
+// RemoveCephFSSubvolumeGroup removes an empty subvolume group.
+func RemoveCephFSSubvolumeGroup(ctx context.Context, volume string, req types.FsSubvolumeRequest) error {
+	args := []string{"fs", fsKindSubvolumeGroup, "rm", volume, req.Name}
+	if req.IsForceOp {
+		args = append(args, "--force")
+	}
+
+	_, err := cephRunContext(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to remove subvolume group %s from CephFS volume %s: %w", req.Name, volume, err)
+	}
+
+	logger.Infof("FSVOL: removed subvolume group %s from volume %s", req.Name, volume)
+	return nil
+}
+
+// ListCephFSSubvolumeGroups lists the names of the subvolume groups of a volume.
+func ListCephFSSubvolumeGroups(ctx context.Context, volume string) ([]string, error) {
+	output, err := cephRunContext(ctx, "fs", fsKindSubvolumeGroup, "ls", volume, "--format=json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subvolume groups of CephFS volume %s: %w", volume, err)
+	}
+
+	groups := []string{}
+	for _, group := range gjson.Get(output, "#.name").Array() {
+		groups = append(groups, group.String())
+	}
+
+	return groups, nil
+}
+
+// GetCephFSSubvolumeGroupAbsPath fetches the absolute path of a subvolume group within its volume.
+func GetCephFSSubvolumeGroupAbsPath(ctx context.Context, volume string, group string) (string, error) {
+	output, err := cephRunContext(ctx, "fs", fsKindSubvolumeGroup, "getpath", volume, group)
+	if err != nil {
+		return "", fmt.Errorf("failed to get path of subvolume group %s in CephFS volume %s: %w", group, volume, err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// ValidateFsSubvolumeRequest checks the quota and mode of a subvolume request.
+func ValidateFsSubvolumeRequest(req types.FsSubvolumeRequest) error {
+	if req.Size < 0 {
+		return fmt.Errorf("invalid size %d, expected a positive number of bytes", req.Size)
+	}
+
+	if len(req.Mode) != 0 && !types.FsModeRegex.MatchString(req.Mode) {
+		return fmt.Errorf("invalid mode %q, expected an octal mode such as 755", req.Mode)
+	}
+
+	return nil
+}
+
+// ValidateFsResizeRequest checks a resize request sets either a quota or no quota at all.
+func ValidateFsResizeRequest(req types.FsSubvolumeRequest) error {
+	if req.Unlimited && req.Size != 0 {
+		return fmt.Errorf("a size of %d cannot be unlimited", req.Size)
+	}
+
+	if !req.Unlimited && req.Size <= 0 {
+		return fmt.Errorf("no size given, set a size or unlimited to remove the quota")
+	}
+
+	return nil
+}
+
+// ##### Helper Functions #####
+
+// fsCreateOptArgs prepares the optional quota, layout and mode arguments for create commands.
+func fsCreateOptArgs(req types.FsSubvolumeRequest) []string {
+	args := []string{}
+	if req.Size > 0 {
+		args = append(args, "--size", strconv.FormatInt(req.Size, 10))
+	}
+
+	if len(req.PoolLayout) != 0 {
+		args = append(args, "--pool_layout", req.PoolLayout)
+	}
+
+	if len(req.Mode) != 0 {
+		args = append(args, "--mode", req.Mode)
+	}
+
+	return args
+}
+
+// fsQuotaArg renders the quota of a resize request in bytes, or inf for no quota.
+func fsQuotaArg(req types.FsSubvolumeRequest) string {
+	if req.Unlimited {
+		return "inf"
+	}
+
+	return strconv.FormatInt(req.Size, 10)
+}
+
+// appendFsGroupArgs scopes a subvolume command to a subvolume group, if any.
+func appendFsGroupArgs(args []string, group string) []string {
+	if len(group) == 0 {
+		return args
+	}
+
+	return append(args, "--group_name", group)
+}
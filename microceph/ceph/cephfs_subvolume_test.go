@@ -0,0 +1,88 @@
+package ceph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type CephFSSubvolumeSuite struct {
+	tests.BaseSuite
+}
+
+func TestCephFSSubvolume(t *testing.T) {
+	suite.Run(t, new(CephFSSubvolumeSuite))
+}
+
+func (s *CephFSSubvolumeSuite) TestCreateCephFSSubvolumeWithOptions() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "subvolume", "create", "vol1", "share1",
+		"--size", "1073741824", "--pool_layout", "cephfs.vol1.data", "--mode", "0775",
+		"--group_name", "tenants").Return("", nil).Once()
+	common.ProcessExec = r
+
+	err := CreateCephFSSubvolume(context.Background(), "vol1", types.FsSubvolumeRequest{
+		Name:       "share1",
+		Group:      "tenants",
+		Size:       1073741824,
+		PoolLayout: "cephfs.vol1.data",
+		Mode:       "0775",
+	})
+	assert.NoError(s.T(), err)
+}
+
+func (s *CephFSSubvolumeSuite) TestResizeCephFSSubvolumeUnlimited() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "subvolume", "resize", "vol1", "share1", "inf").Return("", nil).Once()
+	common.ProcessExec = r
+
+	err := ResizeCephFSSubvolume(context.Background(), "vol1", types.FsSubvolumeRequest{Name: "share1", Unlimited: true})
+	assert.NoError(s.T(), err)
+}
+
+func (s *CephFSSubvolumeSuite) TestResizeCephFSSubvolumeNoSize() {
+	// An empty request must not remove the quota.
+	err := ResizeCephFSSubvolume(context.Background(), "vol1", types.FsSubvolumeRequest{Name: "share1"})
+	assert.ErrorContains(s.T(), err, "no size given")
+
+	err = ResizeCephFSSubvolume(context.Background(), "vol1", types.FsSubvolumeRequest{Name: "share1", Size: 1024, Unlimited: true})
+	assert.Error(s.T(), err)
+}
+
+func (s *CephFSSubvolumeSuite) TestResizeCephFSSubvolumeGroupNoShrink() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "subvolumegroup", "resize", "vol1", "tenants", "2048", "--no_shrink").Return("", nil).Once()
+	common.ProcessExec = r
+
+	err := ResizeCephFSSubvolumeGroup(context.Background(), "vol1", types.FsSubvolumeRequest{Name: "tenants", Size: 2048, NoShrink: true})
+	assert.NoError(s.T(), err)
+}
+
+func (s *CephFSSubvolumeSuite) TestGetCephFSSubvolumeAbsPath() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "subvolume", "getpath", "vol1", "share1", "--group_name", "tenants").
+		Return("/volumes/tenants/share1/8f2f6b3e-6d5a-4c38-9a0d-0f7c44e1c2a1\n", nil).Once()
+	common.ProcessExec = r
+
+	path, err := GetCephFSSubvolumeAbsPath(context.Background(), "vol1", "share1", "tenants")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "/volumes/tenants/share1/8f2f6b3e-6d5a-4c38-9a0d-0f7c44e1c2a1", path)
+}
+
+func (s *CephFSSubvolumeSuite) TestValidateFsSubvolumeRequest() {
+	assert.NoError(s.T(), ValidateFsSubvolumeRequest(types.FsSubvolumeRequest{Name: "share1", Mode: "755"}))
+	assert.Error(s.T(), ValidateFsSubvolumeRequest(types.FsSubvolumeRequest{Name: "share1", Mode: "rwx"}))
+	assert.Error(s.T(), ValidateFsSubvolumeRequest(types.FsSubvolumeRequest{Name: "share1", Size: -1}))
+}
@@ -1,10 +1,17 @@
 package ceph
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/canonical/microceph/microceph/api/types"
 	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
 	"github.com/canonical/microceph/microceph/logger"
 	"github.com/tidwall/gjson"
 )
@@ -203,6 +210,187 @@ func GetCephFSSubvolumePath(subvolumegroup string, subvolume string) string {
 	return fmt.Sprintf(constants.CephFSSubvolumePathTemplate, subvolumegroup, subvolume)
 }
 
+// ListCephFSVolumeInfo lists all CephFS volumes with their pools and MDS settings.
+func ListCephFSVolumeInfo(ctx context.Context) (types.FsVolumes, error) {
+	output, err := cephRunContext(ctx, "fs", "ls", "--format=json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CephFS volumes: %w", err)
+	}
+
+	var fsList []struct {
+		Name         string   `json:"name"`
+		MetadataPool string   `json:"metadata_pool"`
+		DataPools    []string `json:"data_pools"`
+	}
+	err = json.Unmarshal([]byte(output), &fsList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CephFS volume list: %w", err)
+	}
+
+	dump, err := cephRunContext(ctx, "fs", "dump", "--format=json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump CephFS map: %w", err)
+	}
+
+	response := make(types.FsVolumes, 0, len(fsList))
+	for _, fs := range fsList {
+		mdsmap := gjson.Get(dump, fmt.Sprintf("filesystems.#(mdsmap.fs_name==%q).mdsmap", fs.Name))
+		response = append(response, types.FsVolume{
+			Name:          fs.Name,
+			MetadataPool:  fs.MetadataPool,
+			DataPools:     fs.DataPools,
+			MaxMDS:        int(mdsmap.Get("max_mds").Int()),
+			StandbyReplay: mdsmap.Get("flags_state.allow_standby_replay").Bool(),
+		})
+	}
+
+	return response, nil
+}
+
+// CreateCephFSVolume creates a CephFS volume along with its data and metadata pools.
+func CreateCephFSVolume(ctx context.Context, volume string) error {
+	_, err := cephRunContext(ctx, "fs", "volume", "create", volume)
+	if err != nil {
+		return fmt.Errorf("failed to create CephFS volume %s: %w", volume, err)
+	}
+
+	logger.Infof("FSVOL: created cephfs volume %s", volume)
+	return nil
+}
+
+// poolDeleteMu serializes the removals allowing pool deletion meanwhile.
+var poolDeleteMu sync.Mutex
+
+// restorePoolDelete puts mon_allow_pool_delete back to its value before a
+// removal, unsetting it when it was not set.
+func restorePoolDelete(allowed *string) {
+	var err error
+	if allowed == nil {
+		_, err = cephRunContext(context.Background(), "config", "rm", "mon", "mon_allow_pool_delete")
+	} else {
+		_, err = cephRunContext(context.Background(), "config", "set", "mon", "mon_allow_pool_delete", *allowed)
+	}
+
+	if err != nil {
+		logger.Errorf("FSVOL: failed to restore mon_allow_pool_delete: %v", err)
+	}
+}
+
+// RemoveCephFSVolume removes a CephFS volume and deletes its pools.
+func RemoveCephFSVolume(ctx context.Context, volume string) error {
+	// Volume removal deletes the backing pools, which the monitors refuse
+	// unless pool deletion is allowed. Allow it for the duration of the call,
+	// one removal at a time for a removal not to restore the option under
+	// another.
+	poolDeleteMu.Lock()
+	defer poolDeleteMu.Unlock()
+
+	allowed, err := getConfigDumpValue("mon", "mon_allow_pool_delete")
+	if err != nil {
+		return fmt.Errorf("failed to query mon_allow_pool_delete: %w", err)
+	}
+
+	if allowed == nil || *allowed != "true" {
+		_, err = cephRunContext(ctx, "config", "set", "mon", "mon_allow_pool_delete", "true")
+		if err != nil {
+			return fmt.Errorf("failed to allow pool deletion: %w", err)
+		}
+
+		defer restorePoolDelete(allowed)
+	}
+
+	_, err = cephRunContext(ctx, "fs", "volume", "rm", volume, "--yes-i-really-mean-it")
+	if err != nil {
+		return fmt.Errorf("failed to remove CephFS volume %s: %w", volume, err)
+	}
+
+	logger.Infof("FSVOL: removed cephfs volume %s", volume)
+	return nil
+}
+
+// SetCephFSMaxMDS sets the number of active MDS daemons for a CephFS volume.
+func SetCephFSMaxMDS(ctx context.Context, volume string, maxMDS int) error {
+	if maxMDS < 1 {
+		return fmt.Errorf("max_mds must be at least 1, got %d", maxMDS)
+	}
+
+	_, err := cephRunContext(ctx, "fs", "set", volume, "max_mds", strconv.Itoa(maxMDS))
+	if err != nil {
+		return fmt.Errorf("failed to set max_mds for CephFS volume %s: %w", volume, err)
+	}
+
+	return nil
+}
+
+// SetCephFSStandbyReplay toggles standby-replay MDS daemons for a CephFS volume.
+func SetCephFSStandbyReplay(ctx context.Context, volume string, enable bool) error {
+	_, err := cephRunContext(ctx, "fs", "set", volume, "allow_standby_replay", strconv.FormatBool(enable))
+	if err != nil {
+		return fmt.Errorf("failed to set allow_standby_replay for CephFS volume %s: %w", volume, err)
+	}
+
+	return nil
+}
+
+// SetCephFSRefuseClientSession toggles whether a CephFS volume, optionally on a remote cluster, accepts new client sessions.
+func SetCephFSRefuseClientSession(ctx context.Context, volume string, refuse bool, remote string, local string) error {
+	args := []string{"fs", "set", volume, "refuse_client_session", strconv.FormatBool(refuse)}
+
+	_, err := cephRunContext(ctx, appendRemoteClusterArgs(args, remote, local)...)
+	if err != nil {
+		return fmt.Errorf("failed to set refuse_client_session for CephFS volume %s: %w", volume, err)
+	}
+
+	return nil
+}
+
+// EvictCephFSClients evicts the client sessions of every active MDS rank of a CephFS volume.
+// Evicted clients are blocklisted and have to remount once the volume accepts sessions again.
+func EvictCephFSClients(ctx context.Context, volume string) error {
+	output, err := cephRunContext(ctx, "fs", "get", volume, "--format=json")
+	if err != nil {
+		return fmt.Errorf("failed to fetch CephFS volume %s: %w", volume, err)
+	}
+
+	for _, rank := range gjson.Get(output, "mdsmap.in").Array() {
+		_, err = cephRunContext(ctx, "tell", fmt.Sprintf("mds.%s:%d", volume, rank.Int()), "client", "evict")
+		if err != nil {
+			return fmt.Errorf("failed to evict clients of CephFS volume %s rank %d: %w", volume, rank.Int(), err)
+		}
+	}
+
+	logger.Infof("FSVOL: evicted clients of volume %s", volume)
+	return nil
+}
+
+// GetCephFSRefuseClientSession checks whether a CephFS volume, optionally on a remote cluster, refuses new client sessions.
+func GetCephFSRefuseClientSession(ctx context.Context, volume string, remote string, local string) (bool, error) {
+	args := []string{"fs", "get", volume, "--format=json"}
+
+	output, err := cephRunContext(ctx, appendRemoteClusterArgs(args, remote, local)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch CephFS volume %s: %w", volume, err)
+	}
+
+	return gjson.Get(output, "mdsmap.flags_state.refuse_client_session").Bool(), nil
+}
+
+// IsServicePlaced checks if the named service is placed on any cluster member.
+func IsServicePlaced(ctx context.Context, s interfaces.StateInterface, service string) (bool, error) {
+	services, err := database.ServiceQuery.List(ctx, s.ClusterState())
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch services from db: %w", err)
+	}
+
+	for _, svc := range services {
+		if svc.Service == service {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // ##### Helper Functions #####
 
 func parseListVolOutputJson(output string) ([]Volume, error) {
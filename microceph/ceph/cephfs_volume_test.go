@@ -0,0 +1,124 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type CephFSVolumeSuite struct {
+	tests.BaseSuite
+}
+
+func TestCephFSVolume(t *testing.T) {
+	suite.Run(t, new(CephFSVolumeSuite))
+}
+
+func (s *CephFSVolumeSuite) TestListCephFSVolumeInfo() {
+	r := mocks.NewRunner(s.T())
+
+	fsLs, _ := os.ReadFile("./test_assets/cephfs_fs_ls.json")
+	fsDump, _ := os.ReadFile("./test_assets/cephfs_fs_dump.json")
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "ls", "--format=json").Return(string(fsLs), nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "dump", "--format=json").Return(string(fsDump), nil).Once()
+	common.ProcessExec = r
+
+	volumes, err := ListCephFSVolumeInfo(context.Background())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), types.FsVolumes{
+		{
+			Name:          "vol1",
+			MetadataPool:  "cephfs.vol1.meta",
+			DataPools:     []string{"cephfs.vol1.data"},
+			MaxMDS:        1,
+			StandbyReplay: false,
+		},
+		{
+			Name:          "vol2",
+			MetadataPool:  "cephfs.vol2.meta",
+			DataPools:     []string{"cephfs.vol2.data", "cephfs.vol2.ec"},
+			MaxMDS:        2,
+			StandbyReplay: true,
+		},
+	}, volumes)
+}
+
+func (s *CephFSVolumeSuite) TestRemoveCephFSVolumeRestoresPoolDelete() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(`[{"section": "mon", "name": "mon_allow_pool_delete", "value": "false"}]`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "config", "set", "mon", "mon_allow_pool_delete", "true").Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "volume", "rm", "vol1", "--yes-i-really-mean-it").Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "config", "set", "mon", "mon_allow_pool_delete", "false").Return("", nil).Once()
+	common.ProcessExec = r
+
+	err := RemoveCephFSVolume(context.Background(), "vol1")
+	assert.NoError(s.T(), err)
+}
+
+func (s *CephFSVolumeSuite) TestRemoveCephFSVolumeUnsetsPoolDelete() {
+	r := mocks.NewRunner(s.T())
+
+	// An unset option is removed again rather than set to its default.
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(`[]`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "config", "set", "mon", "mon_allow_pool_delete", "true").Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "volume", "rm", "vol1", "--yes-i-really-mean-it").Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "config", "rm", "mon", "mon_allow_pool_delete").Return("", nil).Once()
+	common.ProcessExec = r
+
+	err := RemoveCephFSVolume(context.Background(), "vol1")
+	assert.NoError(s.T(), err)
+}
+
+func (s *CephFSVolumeSuite) TestRemoveCephFSVolumePoolDeleteAllowed() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(`[{"section": "mon", "name": "mon_allow_pool_delete", "value": "true"}]`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "volume", "rm", "vol1", "--yes-i-really-mean-it").Return("", fmt.Errorf("volume busy")).Once()
+	common.ProcessExec = r
+
+	err := RemoveCephFSVolume(context.Background(), "vol1")
+	assert.ErrorContains(s.T(), err, "volume busy")
+}
+
+func (s *CephFSVolumeSuite) TestSetCephFSMaxMDS() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "set", "vol1", "max_mds", "2").Return("", nil).Once()
+	common.ProcessExec = r
+
+	assert.NoError(s.T(), SetCephFSMaxMDS(context.Background(), "vol1", 2))
+	assert.Error(s.T(), SetCephFSMaxMDS(context.Background(), "vol1", 0))
+}
+
+func (s *CephFSVolumeSuite) TestIsServicePlaced() {
+	sq := mocks.NewServiceQueryInterface(s.T())
+	sq.On("List", mock.Anything, mock.Anything).Return(
+		types.Services{
+			{Service: "mon", Location: "n0"},
+			{Service: "mds", Location: "n1"},
+		}, nil).Twice()
+	database.ServiceQuery = sq
+
+	state := mocks.NewStateInterface(s.T())
+	state.On("ClusterState").Return(&mocks.MockState{}).Twice()
+
+	placed, err := IsServicePlaced(context.Background(), state, "mds")
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), placed)
+
+	placed, err = IsServicePlaced(context.Background(), state, "rgw")
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), placed)
+}
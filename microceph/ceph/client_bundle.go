@@ -0,0 +1,79 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+)
+
+// renderClientBundleConf renders the ceph.conf of a client bundle. Only the
+// cluster wide configs of the [client] section and of the user's own section
+// are included as the consumer is not a member.
+func renderClientBundleConf(user, fsid string, monitors []string, configs database.ClientConfigItems) string {
+	globalConfigs := database.ClientConfigItems{}
+	for _, config := range configs {
+		if config.Host == constants.ClientConfigGlobalHostConst {
+			globalConfigs = append(globalConfigs, config)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by MicroCeph for client.%s.\n", user)
+	fmt.Fprintf(&b, "[global]\n")
+	fmt.Fprintf(&b, "fsid = %s\n", fsid)
+	fmt.Fprintf(&b, "mon host = %s\n", strings.Join(monitors, ","))
+	for _, section := range newClientConfig(globalConfigs).sections() {
+		if len(section.Options) == 0 || (section.Name != "client" && section.Name != "client."+user) {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n[%s]\n", section.Name)
+		for _, option := range section.Options {
+			fmt.Fprintf(&b, "%s = %s\n", option.Key, option.Value)
+		}
+	}
+
+	return b.String()
+}
+
+// GetClientBundle assembles the ceph.conf and keyring an external consumer
+// needs to reach the cluster as the given cephx client. The monitors are read
+// at call time, so fetching the bundle again picks up monitor changes.
+func GetClientBundle(ctx context.Context, s interfaces.StateInterface, user string) (types.ClientBundle, error) {
+	authClient, err := GetAuthClient(ctx, s, user)
+	if err != nil {
+		return types.ClientBundle{}, err
+	}
+
+	if authClient.Managed {
+		return types.ClientBundle{}, fmt.Errorf("client %s is managed by MicroCeph and cannot be bundled", user)
+	}
+
+	config, err := GetConfigDb(ctx, s)
+	if err != nil {
+		return types.ClientBundle{}, fmt.Errorf("failed to get config db: %w", err)
+	}
+
+	monitors, err := GetMonitorAddresses(ctx, s)
+	if err != nil {
+		return types.ClientBundle{}, err
+	}
+
+	configs, err := database.ClientConfigQuery.GetAll(ctx, s.ClusterState())
+	if err != nil {
+		return types.ClientBundle{}, fmt.Errorf("could not query database for client configs: %w", err)
+	}
+
+	return types.ClientBundle{
+		User:     user,
+		FsID:     config["fsid"],
+		Monitors: monitors,
+		CephConf: renderClientBundleConf(user, config["fsid"], monitors, configs),
+		Keyring:  authClient.Keyring,
+	}, nil
+}
@@ -3,66 +3,175 @@ package ceph
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
 
-	"github.com/canonical/microceph/microceph/common"
 	"github.com/canonical/microceph/microceph/database"
 )
 
+// clientSectionRegex matches the ceph.conf sections client configs can be placed in.
+var clientSectionRegex = regexp.MustCompile(`^client(\.[a-zA-Z0-9_-][a-zA-Z0-9_.-]*)?$`)
+
+// Prefixes of the librbd, CephFS client and ceph-fuse options, which clients
+// may be configured with.
+var clientConfigAllowPrefixes = []string{"rbd_", "client_", "fuse_"}
+
+// Further client-side options clients may be configured with: the admin
+// socket, logging and RADOS timeouts and throttles.
+var clientConfigAllowList = []string{
+	"admin_socket", "log_file", "log_to_file", "log_to_stderr",
+	"debug_rbd", "debug_client", "debug_rados", "debug_objecter", "debug_ms",
+	"rados_mon_op_timeout", "rados_osd_op_timeout", "objecter_inflight_ops", "objecter_inflight_op_bytes",
+}
+
 // ClientConfigT holds all the client configuration values *applicable* for
-// the host machine. These values are consumed by configwriter for ceph.conf
-// updation. This approach keeps the client config updation logic tied together
-// and easily extendable for more keys.
-type ClientConfigT struct {
-	IsCache             string
-	CacheSize           string
-	IsCacheWritethrough string
-	CacheMaxDirty       string
-	CacheTargetDirty    string
+// the host machine, by ceph.conf section and option. These values are
+// consumed by configwriter for ceph.conf updation.
+type ClientConfigT map[string]map[string]string
+
+// clientConfigSection is a rendered ceph.conf client section.
+type clientConfigSection struct {
+	Name    string
+	Options []clientConfigOption
+}
+
+// clientConfigOption is a rendered ceph.conf client option.
+type clientConfigOption struct {
+	Key   string
+	Value string
+}
+
+// SplitClientConfigKey splits a client config key into its ceph.conf section
+// and option. Plain options belong to the [client] section, options of named
+// sections are prefixed by the section, as in client.libvirt.admin_socket.
+func SplitClientConfigKey(key string) (string, string) {
+	idx := strings.LastIndex(key, ".")
+	if idx == -1 {
+		return "client", key
+	}
+
+	return key[:idx], key[idx+1:]
+}
+
+// ValidateClientConfigKey checks that a client config key names an allowed
+// client option in a client section.
+func ValidateClientConfigKey(key string) error {
+	section, option := SplitClientConfigKey(key)
+	if !clientSectionRegex.MatchString(section) {
+		return fmt.Errorf("invalid section %q, expected client or client.<name>", section)
+	}
+
+	if slices.Contains(clientConfigAllowList, option) {
+		return nil
+	}
+
+	for _, prefix := range clientConfigAllowPrefixes {
+		if strings.HasPrefix(option, prefix) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("option %s is not a supported client option, expected an option starting with %s or one of %s",
+		option, strings.Join(clientConfigAllowPrefixes, ", "), strings.Join(clientConfigAllowList, ", "))
+}
+
+// validateClientConfigValue checks that a client config value cannot break out
+// of its line in ceph.conf, into another option or section.
+func validateClientConfigValue(value string) error {
+	if strings.ContainsAny(value, "\r\n[") {
+		return fmt.Errorf("invalid value %q, line breaks and '[' are not allowed", value)
+	}
+
+	return nil
+}
+
+// ValidateClientConfig checks that a client config key names an allowed client
+// option in a client section, and that the value fits the option's schema.
+func ValidateClientConfig(key string, value string) error {
+	err := ValidateClientConfigKey(key)
+	if err != nil {
+		return err
+	}
+
+	err = validateClientConfigValue(value)
+	if err != nil {
+		return err
+	}
+
+	_, option := SplitClientConfigKey(key)
+	help, err := getCephOptionHelp(option)
+	if err != nil {
+		return err
+	}
+
+	return help.validate(value)
 }
 
 // GetClientConfigForHost fetches all the applicable client configurations for the provided host.
 func GetClientConfigForHost(ctx context.Context, s interfaces.StateInterface, hostname string) (ClientConfigT, error) {
-	retval := ClientConfigT{}
-
-	// Get all client configs for the current host.
+	// Get all client configs for the current host, host values override global ones.
 	configs, err := database.ClientConfigQuery.GetAllForHost(ctx, s.ClusterState(), hostname)
 	if err != nil {
 		return ClientConfigT{}, fmt.Errorf("could not query database for client configs: %v", err)
 	}
 
-	setterTable := GetClientConfigSet()
+	return newClientConfig(configs), nil
+}
+
+// newClientConfig sorts client config items into their sections. Items which
+// would not render safely into ceph.conf are left out.
+func newClientConfig(configs database.ClientConfigItems) ClientConfigT {
+	retval := ClientConfigT{}
 	for _, config := range configs {
-		// Populate client config table using the database values.
-		err = setFieldValue(&retval, fmt.Sprint(setterTable[config.Key]), config.Value)
+		err := validateClientConfigValue(config.Value)
 		if err != nil {
-			return ClientConfigT{}, fmt.Errorf("failed object population: %v", err)
+			logger.Warnf("skipping client config %s: %v", config.Key, err)
+			continue
+		}
+
+		section, option := SplitClientConfigKey(config.Key)
+		if retval[section] == nil {
+			retval[section] = map[string]string{}
 		}
+
+		retval[section][option] = config.Value
 	}
 
-	return retval, nil
+	return retval
 }
 
-// setFieldValue populates the individual client configuration values into ClientConfigT object fields.
-func setFieldValue(ogp *ClientConfigT, field string, value string) error {
-	r := reflect.ValueOf(ogp)
-	f := reflect.Indirect(r).FieldByName(field)
-	if f.Kind() != reflect.Invalid {
-		f.SetString(value)
-		return nil
+// sections orders the client config for rendering, the [client] section
+// always comes first so that named sections can override it.
+func (c ClientConfigT) sections() []clientConfigSection {
+	names := []string{}
+	for name := range c {
+		if name != "client" {
+			names = append(names, name)
+		}
 	}
-	return fmt.Errorf("cannot set field %s", field)
-}
+	sort.Strings(names)
+	names = append([]string{"client"}, names...)
+
+	sections := []clientConfigSection{}
+	for _, name := range names {
+		keys := []string{}
+		for key := range c[name] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
 
-// GetClientConfigSet provides the mapping between client config key and fieldname for population through reflection.
-func GetClientConfigSet() common.Set {
-	return common.Set{
-		"rbd_cache":                          "IsCache",
-		"rbd_cache_size":                     "CacheSize",
-		"rbd_cache_writethrough_until_flush": "IsCacheWritethrough",
-		"rbd_cache_max_dirty":                "CacheMaxDirty",
-		"rbd_cache_target_dirty":             "CacheTargetDirty",
+		section := clientConfigSection{Name: name, Options: []clientConfigOption{}}
+		for _, key := range keys {
+			section.Options = append(section.Options, clientConfigOption{Key: key, Value: c[name][key]})
+		}
+
+		sections = append(sections, section)
 	}
+
+	return sections
 }
@@ -2,14 +2,13 @@ package ceph
 
 import (
 	"context"
-	"fmt"
-	"reflect"
 	"testing"
 
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/microceph/microceph/tests"
 	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
 
+	"github.com/canonical/microceph/microceph/common"
 	"github.com/canonical/microceph/microceph/database"
 	"github.com/canonical/microceph/microceph/mocks"
 	"github.com/stretchr/testify/assert"
@@ -39,16 +38,10 @@ func (ccs *ClientConfigSuite) SetupTest() {
 }
 
 func addGetHostConfigsExpectation(mci *mocks.ClientConfigQueryIntf, cs mcTypes.State, hostname string) {
-	output := database.ClientConfigItems{}
-	count := 0
-	for configKey, field := range GetClientConfigSet() {
-		count++
-		output = append(output, database.ClientConfigItem{
-			ID:    count,
-			Host:  hostname,
-			Key:   configKey,
-			Value: fmt.Sprintf("%v", field),
-		})
+	output := database.ClientConfigItems{
+		{ID: 1, Host: hostname, Key: "rbd_cache", Value: "true"},
+		{ID: 2, Host: hostname, Key: "rbd_concurrent_management_ops", Value: "20"},
+		{ID: 3, Host: hostname, Key: "client.libvirt.admin_socket", Value: "/var/run/ceph/libvirt.asok"},
 	}
 
 	mci.On("GetAllForHost", cs, hostname).Return(output, nil)
@@ -64,10 +57,62 @@ func (ccs *ClientConfigSuite) TestFetchHostConfig() {
 
 	configs, err := GetClientConfigForHost(context.Background(), ccs.TestStateInterface, hostname)
 	assert.NoError(ccs.T(), err)
+	assert.Equal(ccs.T(), ClientConfigT{
+		"client":         {"rbd_cache": "true", "rbd_concurrent_management_ops": "20"},
+		"client.libvirt": {"admin_socket": "/var/run/ceph/libvirt.asok"},
+	}, configs)
 
-	// check fields
-	metaConfigs := reflect.ValueOf(configs)
-	for i := 0; i < metaConfigs.NumField(); i++ {
-		assert.Equal(ccs.T(), metaConfigs.Field(i).Interface(), metaConfigs.Type().Field(i).Name)
+	// The [client] section comes first, followed by the named sections.
+	sections := configs.sections()
+	assert.Equal(ccs.T(), []clientConfigSection{
+		{Name: "client", Options: []clientConfigOption{{Key: "rbd_cache", Value: "true"}, {Key: "rbd_concurrent_management_ops", Value: "20"}}},
+		{Name: "client.libvirt", Options: []clientConfigOption{{Key: "admin_socket", Value: "/var/run/ceph/libvirt.asok"}}},
+	}, sections)
+}
+
+func (ccs *ClientConfigSuite) TestValidateClientConfig() {
+	r := mocks.NewRunner(ccs.T())
+	r.On("RunCommand", "ceph", "config", "help", "rbd_concurrent_management_ops", "-f", "json").Return(
+		`{"name":"rbd_concurrent_management_ops","type":"uint","services":["rbd"],"enum_values":[],"min":"1","max":""}`, nil).Times(3)
+	r.On("RunCommand", "ceph", "config", "help", "admin_socket", "-f", "json").Return(
+		`{"name":"admin_socket","type":"str","services":[],"enum_values":[],"min":"","max":""}`, nil).Once()
+	common.ProcessExec = r
+
+	assert.NoError(ccs.T(), ValidateClientConfig("rbd_concurrent_management_ops", "20"))
+	assert.Error(ccs.T(), ValidateClientConfig("rbd_concurrent_management_ops", "many"))
+	assert.Error(ccs.T(), ValidateClientConfig("rbd_concurrent_management_ops", "0"))
+	assert.NoError(ccs.T(), ValidateClientConfig("client.libvirt.admin_socket", "/var/run/ceph/libvirt.asok"))
+
+	// Rejected before consulting ceph.
+	assert.Error(ccs.T(), ValidateClientConfig("osd_max_backfills", "4"))
+	assert.Error(ccs.T(), ValidateClientConfig("osd.rbd_cache", "true"))
+	assert.Error(ccs.T(), ValidateClientConfig("client.libvirt.keyring", "/etc/ceph/keyring"))
+	assert.Error(ccs.T(), ValidateClientConfig("mon_host", "10.0.0.1"))
+	assert.Error(ccs.T(), ValidateClientConfig("client.libvirt.admin_socket", "/run/a.asok\n[global]\nauth_client_required = none"))
+	assert.Error(ccs.T(), ValidateClientConfig("rbd_concurrent_management_ops", "20\r"))
+	assert.Error(ccs.T(), ValidateClientConfig("admin_socket", "[global]"))
+}
+
+func (ccs *ClientConfigSuite) TestValidateClientConfigKey() {
+	for _, key := range []string{"rbd_cache", "client.libvirt.rbd_cache_size", "client_oc_size", "fuse_default_permissions", "debug_rbd"} {
+		assert.NoError(ccs.T(), ValidateClientConfigKey(key), key)
 	}
+
+	for _, key := range []string{"public_network", "fsid", "keyring", "ms_bind_ipv6", "mgr.rbd_cache", "osd_max_backfills"} {
+		assert.Error(ccs.T(), ValidateClientConfigKey(key), key)
+	}
+}
+
+func (ccs *ClientConfigSuite) TestRenderClientBundleConf() {
+	configs := database.ClientConfigItems{
+		{Key: "rbd_cache_size", Value: "1024", Host: "*"},
+		{Key: "rbd_cache", Value: "true", Host: "*"},
+		// Host specific configs do not apply to external clients.
+		{Key: "rbd_cache_max_dirty", Value: "512", Host: "foohost"},
+		// Values which would inject lines are left out.
+		{Key: "admin_socket", Value: "/run/a.asok\n[global]\nauth_client_required = none", Host: "*"},
+	}
+
+	conf := renderClientBundleConf("web", "abcd", []string{"10.0.0.1", "10.0.0.2"}, configs)
+	assert.Equal(ccs.T(), "# Generated by MicroCeph for client.web.\n[global]\nfsid = abcd\nmon host = 10.0.0.1,10.0.0.2\n\n[client]\nrbd_cache = true\nrbd_cache_size = 1024\n", conf)
 }
@@ -6,12 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 
 	"github.com/canonical/microceph/microceph/constants"
 	"github.com/canonical/microceph/microceph/interfaces"
 
 	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
 	"github.com/canonical/microceph/microceph/common"
 	"github.com/canonical/microceph/microceph/database"
 	"github.com/canonical/microceph/microceph/logger"
@@ -99,6 +101,7 @@ func GetConfigTableServiceSet() common.Set {
 // Struct to get Config Items from config dump json output.
 type ConfigDumpItem struct {
 	Section string
+	Mask    string
 	Name    string
 	Value   string
 }
@@ -340,6 +343,48 @@ func backwardCompatPubnet(ctx context.Context, s interfaces.StateInterface) erro
 	return insertPubnetRecord(ctx, s, pubNet)
 }
 
+// bootstrapConfigKeys are the MicroCeph supported keys set at bootstrap.
+var bootstrapConfigKeys = []string{"cluster_network", "rbd_default_features", "osd_pool_default_crush_rule"}
+
+// backwardCompatDefaultConfigs tracks the keys set at bootstrap by older
+// versions of microceph, which did not record them, with their current value.
+func backwardCompatDefaultConfigs(ctx context.Context, s interfaces.StateInterface) error {
+	config, err := fetchConfigDb(ctx, s)
+	if err != nil {
+		return fmt.Errorf("failed to get config from db: %w", err)
+	}
+
+	var dump map[string]string
+	for _, key := range bootstrapConfigKeys {
+		who := GetConstConfigTable()[key].Who
+		_, userSet := config[trackedConfigKey(who, key)]
+		_, defaultSet := config[defaultConfigKey(who, key)]
+		if userSet || defaultSet {
+			continue
+		}
+
+		if dump == nil {
+			dump, err = getConfigDump()
+			if err != nil {
+				return fmt.Errorf("failed to fetch cluster config: %w", err)
+			}
+		}
+
+		value, ok := dump[who+"/"+key]
+		if !ok {
+			continue
+		}
+
+		err = database.SetConfigItemDb(ctx, s.ClusterState(), defaultConfigKey(who, key), value)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Config: tracking %s set at bootstrap as %s", key, value)
+	}
+
+	return nil
+}
+
 // backwardCompatMonitors retrieves monitor addresses from the node list and returns that
 // this a backward-compat shim to accomodate older versions of microceph
 func backwardCompatMonitors(ctx context.Context, s interfaces.StateInterface) ([]string, error) {
@@ -431,17 +476,13 @@ func UpdateConfig(ctx context.Context, s interfaces.StateInterface) error {
 	// Populate Template
 	err = conf.WriteConfig(
 		map[string]any{
-			"fsid":                config["fsid"],
-			"runDir":              runPath,
-			"monitors":            strings.Join(monitorAddresses, ","),
-			"pubNet":              config["public_network"],
-			"ipv4":                strings.Contains(config["public_network"], "."),
-			"ipv6":                strings.Contains(config["public_network"], ":"),
-			"isCache":             clientConfig.IsCache,
-			"cacheSize":           clientConfig.CacheSize,
-			"isCacheWritethrough": clientConfig.IsCacheWritethrough,
-			"cacheMaxDirty":       clientConfig.CacheMaxDirty,
-			"cacheTargetDirty":    clientConfig.CacheTargetDirty,
+			"fsid":           config["fsid"],
+			"runDir":         runPath,
+			"monitors":       strings.Join(monitorAddresses, ","),
+			"pubNet":         config["public_network"],
+			"ipv4":           strings.Contains(config["public_network"], "."),
+			"ipv6":           strings.Contains(config["public_network"], ":"),
+			"clientSections": clientConfig.sections(),
 		},
 		0644,
 	)
@@ -466,6 +507,28 @@ func UpdateConfig(ctx context.Context, s interfaces.StateInterface) error {
 	return nil
 }
 
+// refreshMemberConfigs renders the config files of every other cluster member
+// from the database, as UpdateConfig does on this one. Members which cannot be
+// refreshed are returned rather than failing the refresh of the others.
+var refreshMemberConfigs = func(ctx context.Context, s interfaces.StateInterface) ([]string, error) {
+	clients, err := getMemberClients(s)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := []string{}
+	for member, remoteClient := range clients {
+		err = client.UpdateClientConf(ctx, remoteClient)
+		if err != nil {
+			logger.Warnf("failed to refresh the config of %s: %v", member, err)
+			failed = append(failed, member)
+		}
+	}
+
+	sort.Strings(failed)
+	return failed, nil
+}
+
 // GetConfigDb retrieves the configuration from the database.
 func GetConfigDb(ctx context.Context, s interfaces.StateInterface) (map[string]string, error) {
 	var err error
@@ -0,0 +1,259 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// advancedWhoRegex matches the sections advanced options can be set for.
+var advancedWhoRegex = regexp.MustCompile(`^(global|mon|mgr|osd|mds|client)(\.[a-zA-Z0-9_-][a-zA-Z0-9_.-]*)?$`)
+
+// advancedDeniedOptions match the options that must not be set as advanced
+// options: they weaken authentication or encryption, point daemons at other
+// keys, sockets or addresses, and would cut members off the cluster.
+var advancedDeniedOptions = []*regexp.Regexp{
+	regexp.MustCompile(`^auth_`),
+	regexp.MustCompile(`^ms_.*_mode$`),
+	regexp.MustCompile(`^ms_bind_`),
+	regexp.MustCompile(`^(keyring|key|keyfile|admin_socket|fsid|run_dir|mon_host|mon_dns_srv_name|mon_initial_members)$`),
+	regexp.MustCompile(`^(public|cluster)_(addr|addrv|bind_addr)$`),
+}
+
+// checkAdvancedConfigAllowed refuses the security and addressing sensitive
+// options MicroCeph does not let operators change.
+func checkAdvancedConfigAllowed(key string) error {
+	for _, denied := range advancedDeniedOptions {
+		if denied.MatchString(key) {
+			return fmt.Errorf("option %s affects cluster security or addressing and cannot be set through MicroCeph", key)
+		}
+	}
+
+	return nil
+}
+
+// trackedConfigKey is the config table key a ceph option set through MicroCeph
+// is recorded under. The public network is recorded on its own at bootstrap.
+func trackedConfigKey(who string, key string) string {
+	if who == "global" && key == "public_network" {
+		return key
+	}
+
+	return fmt.Sprintf("%s%s/%s", constants.CephOptionKeyPrefix, who, key)
+}
+
+// defaultConfigKey is the config table key a ceph option MicroCeph sets on its
+// own is recorded under.
+func defaultConfigKey(who string, key string) string {
+	return fmt.Sprintf("%s%s/%s", constants.CephDefaultKeyPrefix, who, key)
+}
+
+// advancedConfigWho defaults the section of an advanced option to global and checks it.
+func advancedConfigWho(c types.Config) (string, error) {
+	if len(c.Who) == 0 {
+		return "global", nil
+	}
+
+	if !advancedWhoRegex.MatchString(c.Who) {
+		return "", fmt.Errorf("invalid section %q, expected global or a daemon type optionally followed by a daemon name", c.Who)
+	}
+
+	return c.Who, nil
+}
+
+// getAdvancedConfigHelp fetches the schema of an option that can be set through
+// the monitors' config database.
+func getAdvancedConfigHelp(key string) (cephOptionHelp, error) {
+	if GetConstConfigTable().isKeyPresent(key) {
+		return cephOptionHelp{}, fmt.Errorf("key %s is a MicroCeph supported cluster config, set it without --advanced", key)
+	}
+
+	help, err := getCephOptionHelp(key)
+	if err != nil {
+		return help, err
+	}
+
+	if slices.Contains(help.Flags, "no_mon_update") {
+		return help, fmt.Errorf("option %s cannot be set through the config database", key)
+	}
+
+	return help, nil
+}
+
+// advancedConfigDaemons works out the daemons to restart for an option change to
+// take effect. Options changeable at runtime need none, others need the daemons
+// of the option's services, limited to the daemon type of the section. Client
+// sections are read by the RGW daemons, and a section naming a single OSD only
+// restarts that OSD.
+func advancedConfigDaemons(who string, help cephOptionHelp) []string {
+	if help.CanUpdateAtRuntime {
+		return []string{}
+	}
+
+	serviceSet := GetConfigTableServiceSet()
+	candidates := help.Services
+	if len(candidates) == 0 {
+		// Common options are read by every daemon.
+		candidates = serviceSet.Keys()
+	}
+
+	whoType, _, _ := strings.Cut(who, ".")
+	daemons := []string{}
+	for _, service := range candidates {
+		_, ok := serviceSet[service]
+		if !ok {
+			continue
+		}
+
+		if whoType != "global" && whoType != service && !(whoType == "client" && service == "rgw") {
+			continue
+		}
+
+		daemon := service
+		if service == "osd" && IsOSDDaemon(who) {
+			daemon = who
+		}
+
+		if !slices.Contains(daemons, daemon) {
+			daemons = append(daemons, daemon)
+		}
+	}
+	sort.Strings(daemons)
+
+	return daemons
+}
+
+// SetAdvancedConfigItem validates an arbitrary ceph option against its schema,
+// applies it and records it in the config table. It returns the daemons to
+// restart for the change to take effect.
+func SetAdvancedConfigItem(ctx context.Context, s interfaces.StateInterface, c types.Config) ([]string, error) {
+	who, err := advancedConfigWho(c)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkAdvancedConfigAllowed(c.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	help, err := getAdvancedConfigHelp(c.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = help.validate(c.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = cephRun("config", "set", who, c.Key, c.Value)
+	if err != nil {
+		return nil, fmt.Errorf("config set(%s) failed: %w", c.Key, err)
+	}
+
+	err = database.SetConfigItemDb(ctx, s.ClusterState(), trackedConfigKey(who, c.Key), c.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Config: set advanced option %s/%s to %s", who, c.Key, c.Value)
+	return advancedConfigDaemons(who, help), nil
+}
+
+// RemoveAdvancedConfigItem clears an arbitrary ceph option and its record. It
+// returns the daemons to restart for the change to take effect.
+func RemoveAdvancedConfigItem(ctx context.Context, s interfaces.StateInterface, c types.Config) ([]string, error) {
+	who, err := advancedConfigWho(c)
+	if err != nil {
+		return nil, err
+	}
+
+	help, err := getAdvancedConfigHelp(c.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = cephRun("config", "rm", who, c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("config rm(%s) failed: %w", c.Key, err)
+	}
+
+	err = database.DeleteConfigItemDb(ctx, s.ClusterState(), trackedConfigKey(who, c.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Config: removed advanced option %s/%s", who, c.Key)
+	return advancedConfigDaemons(who, help), nil
+}
+
+// GetAdvancedConfigItem fetches the value of an arbitrary ceph option.
+func GetAdvancedConfigItem(c types.Config) (types.Configs, error) {
+	who, err := advancedConfigWho(c)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = getAdvancedConfigHelp(c.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	// workaround to query global configs from mon entity.
+	target := who
+	if who == "global" {
+		target = "mon"
+	}
+
+	value, err := cephRun("config", "get", target, c.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.Configs{{Key: c.Key, Value: strings.TrimSpace(value), Who: who, Advanced: true}}, nil
+}
+
+// ListAdvancedConfigs lists the arbitrary ceph options set through MicroCeph,
+// the MicroCeph supported keys recorded alongside them are left out.
+func ListAdvancedConfigs(ctx context.Context, s interfaces.StateInterface) (types.Configs, error) {
+	config, err := fetchConfigDb(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config db: %w", err)
+	}
+
+	configTable := GetConstConfigTable()
+	configs := types.Configs{}
+	for key, value := range config {
+		record, found := strings.CutPrefix(key, constants.CephOptionKeyPrefix)
+		if !found {
+			continue
+		}
+
+		who, option, found := strings.Cut(record, "/")
+		if !found || configTable.isKeyPresent(option) {
+			continue
+		}
+
+		configs = append(configs, types.Config{Key: option, Value: value, Who: who, Advanced: true})
+	}
+
+	sort.Slice(configs, func(i, j int) bool {
+		if configs[i].Who != configs[j].Who {
+			return configs[i].Who < configs[j].Who
+		}
+
+		return configs[i].Key < configs[j].Key
+	})
+
+	return configs, nil
+}
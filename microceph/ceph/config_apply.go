@@ -0,0 +1,325 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// sortedKeys returns the keys of a map in order, for the plan to be stable.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// optionalValue returns a reference to the value of a key if it is present.
+func optionalValue(m map[string]string, key string) *string {
+	value, ok := m[key]
+	if !ok {
+		return nil
+	}
+
+	return &value
+}
+
+// userClusterConfigs lists the MicroCeph supported keys set through MicroCeph,
+// keyed by section and option like the config dump. Keys MicroCeph sets on its
+// own, as at bootstrap, are not part of it.
+func userClusterConfigs(ctx context.Context, s interfaces.StateInterface) (common.Set, error) {
+	config, err := fetchConfigDb(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config db: %w", err)
+	}
+
+	configTable := GetConstConfigTable()
+	userSet := common.Set{}
+	for key := range config {
+		record, found := strings.CutPrefix(key, constants.CephOptionKeyPrefix)
+		if !found {
+			continue
+		}
+
+		_, option, found := strings.Cut(record, "/")
+		if found && configTable.isKeyPresent(option) {
+			userSet.Insert(record)
+		}
+	}
+
+	return userSet, nil
+}
+
+// planClusterConfigs diffs the MicroCeph supported cluster keys of a document
+// against the monitors' config database. Only the keys set through MicroCeph
+// are pruned, the ones set at bootstrap or with the ceph CLI are left alone.
+func planClusterConfigs(desired map[string]string, dump map[string]string, userSet common.Set, prune bool, services common.Set) ([]types.ConfigPlanChange, error) {
+	configTable := GetConstConfigTable()
+	changes := []types.ConfigPlanChange{}
+
+	for _, key := range sortedKeys(desired) {
+		def, ok := configTable[key]
+		if !ok {
+			return nil, fmt.Errorf("key %s is not a MicroCeph supported cluster config, list it under advanced", key)
+		}
+
+		value := desired[key]
+		current := optionalValue(dump, def.Who+"/"+key)
+		if current != nil && *current == value {
+			continue
+		}
+
+		if def.Permission != ClusterConfigRW {
+			return nil, fmt.Errorf("key %s is read only", key)
+		}
+
+		changes = append(changes, types.ConfigPlanChange{Scope: types.ConfigScopeCluster, Key: key, OldValue: current, NewValue: &value})
+		services.Add(def.Daemons)
+	}
+
+	if !prune {
+		return changes, nil
+	}
+
+	for _, key := range sortedKeys(configTable) {
+		def := configTable[key]
+		_, ok := desired[key]
+		_, userSetKey := userSet[def.Who+"/"+key]
+		current := optionalValue(dump, def.Who+"/"+key)
+		if ok || !userSetKey || current == nil || def.Permission != ClusterConfigRW {
+			continue
+		}
+
+		changes = append(changes, types.ConfigPlanChange{Scope: types.ConfigScopeCluster, Key: key, OldValue: current})
+		services.Add(def.Daemons)
+	}
+
+	return changes, nil
+}
+
+// planAdvancedConfigs diffs the advanced options of a document against the
+// monitors' config database. Only the options set through MicroCeph are
+// pruned, options set with the ceph CLI are left alone.
+func planAdvancedConfigs(desired map[string]map[string]string, dump map[string]string, recorded types.Configs, prune bool, services common.Set) ([]types.ConfigPlanChange, error) {
+	changes := []types.ConfigPlanChange{}
+
+	for _, who := range sortedKeys(desired) {
+		_, err := advancedConfigWho(types.Config{Who: who})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range sortedKeys(desired[who]) {
+			value := desired[who][key]
+			current := optionalValue(dump, who+"/"+key)
+			if current != nil && *current == value {
+				continue
+			}
+
+			help, err := getAdvancedConfigHelp(key)
+			if err != nil {
+				return nil, err
+			}
+
+			err = help.validate(value)
+			if err != nil {
+				return nil, err
+			}
+
+			changes = append(changes, types.ConfigPlanChange{Scope: types.ConfigScopeAdvanced, Who: who, Key: key, OldValue: current, NewValue: &value})
+			services.Add(advancedConfigDaemons(who, help))
+		}
+	}
+
+	if !prune {
+		return changes, nil
+	}
+
+	for _, config := range recorded {
+		_, ok := desired[config.Who][config.Key]
+		if ok {
+			continue
+		}
+
+		help, err := getAdvancedConfigHelp(config.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, types.ConfigPlanChange{Scope: types.ConfigScopeAdvanced, Who: config.Who, Key: config.Key, OldValue: optionalValue(dump, config.Who+"/"+config.Key)})
+		services.Add(advancedConfigDaemons(config.Who, help))
+	}
+
+	return changes, nil
+}
+
+// planClientConfigs diffs the client keys of a document against the cluster
+// wide client configs. Host specific client configs are not managed.
+func planClientConfigs(desired map[string]string, configs database.ClientConfigItems, prune bool) ([]types.ConfigPlanChange, error) {
+	current := map[string]string{}
+	for _, config := range configs {
+		if config.Host == constants.ClientConfigGlobalHostConst {
+			current[config.Key] = config.Value
+		}
+	}
+
+	changes := []types.ConfigPlanChange{}
+	for _, key := range sortedKeys(desired) {
+		value := desired[key]
+		old := optionalValue(current, key)
+		if old != nil && *old == value {
+			continue
+		}
+
+		err := ValidateClientConfig(key, value)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, types.ConfigPlanChange{Scope: types.ConfigScopeClient, Key: key, OldValue: old, NewValue: &value})
+	}
+
+	if !prune {
+		return changes, nil
+	}
+
+	for _, key := range sortedKeys(current) {
+		_, ok := desired[key]
+		if ok {
+			continue
+		}
+
+		changes = append(changes, types.ConfigPlanChange{Scope: types.ConfigScopeClient, Key: key, OldValue: optionalValue(current, key)})
+	}
+
+	return changes, nil
+}
+
+// PlanConfigApply works out the changes bringing the cluster configuration to
+// a config document, and the daemons to restart once for all of them. Every
+// value is validated before anything is changed.
+func PlanConfigApply(ctx context.Context, s interfaces.StateInterface, req types.ConfigApply) (types.ConfigPlan, error) {
+	plan := types.ConfigPlan{Changes: []types.ConfigPlanChange{}, Services: []string{}}
+	services := common.Set{}
+
+	dump, err := getConfigDump()
+	if err != nil {
+		return plan, fmt.Errorf("failed to fetch cluster config: %w", err)
+	}
+
+	userSet, err := userClusterConfigs(ctx, s)
+	if err != nil {
+		return plan, err
+	}
+
+	changes, err := planClusterConfigs(req.Document.Cluster, dump, userSet, req.Prune, services)
+	if err != nil {
+		return plan, err
+	}
+	plan.Changes = append(plan.Changes, changes...)
+
+	recorded, err := ListAdvancedConfigs(ctx, s)
+	if err != nil {
+		return plan, err
+	}
+
+	changes, err = planAdvancedConfigs(req.Document.Advanced, dump, recorded, req.Prune, services)
+	if err != nil {
+		return plan, err
+	}
+	plan.Changes = append(plan.Changes, changes...)
+
+	clientConfigs, err := database.ClientConfigQuery.GetAll(ctx, s.ClusterState())
+	if err != nil {
+		return plan, fmt.Errorf("could not query database for client configs: %w", err)
+	}
+
+	changes, err = planClientConfigs(req.Document.Client, clientConfigs, req.Prune)
+	if err != nil {
+		return plan, err
+	}
+	plan.Changes = append(plan.Changes, changes...)
+
+	plan.Services = services.Keys()
+	sort.Strings(plan.Services)
+
+	return plan, nil
+}
+
+// applyConfigChange sets the key of a plan change to a value, resetting it on
+// a nil value. Cluster and advanced changes are recorded in the config history.
+func applyConfigChange(ctx context.Context, s interfaces.StateInterface, change types.ConfigPlanChange, value *string, requester string) error {
+	var err error
+	switch change.Scope {
+	case types.ConfigScopeClient:
+		if value == nil {
+			err = database.RemoveGlobalClientConfigItemDb(ctx, s.ClusterState(), change.Key)
+		} else {
+			err = database.ClientConfigQuery.AddNew(ctx, s.ClusterState(), change.Key, *value, constants.ClientConfigGlobalHostConst)
+		}
+	default:
+		c := types.Config{
+			Key:       change.Key,
+			Who:       change.Who,
+			Advanced:  change.Scope == types.ConfigScopeAdvanced,
+			Requester: requester,
+		}
+
+		if value == nil {
+			_, err = ResetClusterConfig(ctx, s, c)
+		} else {
+			c.Value = *value
+			_, err = SetClusterConfig(ctx, s, c)
+		}
+	}
+
+	return err
+}
+
+// ApplyConfigPlan carries out the changes of a plan in order, without
+// restarting any daemon. Cluster and advanced changes are recorded in the
+// config history. When a change fails the ones applied before it are rolled
+// back, the changes that could not be rolled back are returned along with the
+// error for their daemons to be restarted.
+func ApplyConfigPlan(ctx context.Context, s interfaces.StateInterface, plan types.ConfigPlan, requester string) ([]types.ConfigPlanChange, error) {
+	for i, change := range plan.Changes {
+		err := applyConfigChange(ctx, s, change, change.NewValue, requester)
+		if err == nil {
+			continue
+		}
+
+		logger.Errorf("Config: failed applying %s, rolling back %d applied changes: %v", change.Key, i, err)
+		applied := []types.ConfigPlanChange{}
+		for j := i - 1; j >= 0; j-- {
+			rollbackErr := applyConfigChange(ctx, s, plan.Changes[j], plan.Changes[j].OldValue, requester)
+			if rollbackErr != nil {
+				logger.Errorf("Config: failed rolling back %s: %v", plan.Changes[j].Key, rollbackErr)
+				applied = append(applied, plan.Changes[j])
+			}
+		}
+
+		if len(applied) == 0 {
+			return nil, fmt.Errorf("failed on %s, the %d config changes applied before it were rolled back: %w", change.Key, i, err)
+		}
+
+		keys := []string{}
+		for _, c := range applied {
+			keys = append(keys, c.Key)
+		}
+		return applied, fmt.Errorf("failed on %s and could not roll back %s, these changes remain applied: %w", change.Key, strings.Join(keys, ", "), err)
+	}
+
+	logger.Infof("Config: applied %d config changes", len(plan.Changes))
+	return nil, nil
+}
@@ -0,0 +1,206 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type configApplySuite struct {
+	tests.BaseSuite
+	TestStateInterface *mocks.StateInterface
+}
+
+func TestConfigApply(t *testing.T) {
+	suite.Run(t, new(configApplySuite))
+}
+
+func (s *configApplySuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+
+	s.TestStateInterface = mocks.NewStateInterface(s.T())
+	state := &mocks.MockState{URL: api.NewURL(), ClusterName: "foohost"}
+	s.TestStateInterface.On("ClusterState").Return(state).Maybe()
+
+	origFetch := fetchConfigDb
+	s.T().Cleanup(func() { fetchConfigDb = origFetch })
+	fetchConfigDb = func(_ context.Context, _ interfaces.StateInterface) (map[string]string, error) {
+		return map[string]string{
+			constants.CephOptionKeyPrefix + "osd/osd_max_backfills":           "4",
+			constants.CephOptionKeyPrefix + "osd/osd_recovery_sleep":          "0.1",
+			constants.CephOptionKeyPrefix + "global/rgw_keystone_api_version": "3",
+		}, nil
+	}
+
+	ccq := mocks.NewClientConfigQueryIntf(s.T())
+	ccq.On("GetAll", s.TestStateInterface.ClusterState()).Return(database.ClientConfigItems{
+		{Key: "rbd_cache", Value: "true", Host: constants.ClientConfigGlobalHostConst},
+		{Key: "rbd_cache_size", Value: "1024", Host: constants.ClientConfigGlobalHostConst},
+		// Host specific configs are not managed by documents.
+		{Key: "rbd_cache_max_dirty", Value: "512", Host: "foohost"},
+	}, nil).Maybe()
+	database.ClientConfigQuery = ccq
+}
+
+func (s *configApplySuite) addDumpExpectation(r *mocks.Runner) {
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(`[
+{"section":"global","name":"public_network","value":"10.0.0.0/24"},
+{"section":"global","name":"cluster_network","value":"10.0.0.0/24"},
+{"section":"global","name":"rbd_default_features","value":"63"},
+{"section":"global","name":"rgw_keystone_url","value":"http://old:5000"},
+{"section":"global","name":"rgw_keystone_api_version","value":"3"},
+{"section":"osd","name":"osd_max_backfills","value":"4"},
+{"section":"osd","name":"osd_recovery_sleep","value":"0.1"}
+]`, nil).Once()
+}
+
+func (s *configApplySuite) TestPlanIsIdempotent() {
+	r := mocks.NewRunner(s.T())
+	s.addDumpExpectation(r)
+	common.ProcessExec = r
+
+	req := types.ConfigApply{Document: types.ConfigDocument{
+		Cluster:  map[string]string{"public_network": "10.0.0.0/24", "rgw_keystone_url": "http://old:5000", "rgw_keystone_api_version": "3"},
+		Advanced: map[string]map[string]string{"osd": {"osd_max_backfills": "4", "osd_recovery_sleep": "0.1"}},
+		Client:   map[string]string{"rbd_cache": "true", "rbd_cache_size": "1024"},
+	}, Prune: true}
+
+	plan, err := PlanConfigApply(context.Background(), s.TestStateInterface, req)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), plan.Changes)
+	assert.Empty(s.T(), plan.Services)
+}
+
+func (s *configApplySuite) TestPlanWithPrune() {
+	r := mocks.NewRunner(s.T())
+	s.addDumpExpectation(r)
+	r.On("RunCommand", "ceph", "config", "help", "osd_max_backfills", "-f", "json").Return(
+		`{"name":"osd_max_backfills","type":"uint","services":["osd"],"min":"","max":"","can_update_at_runtime":true}`, nil).Once()
+	r.On("RunCommand", "ceph", "config", "help", "osd_recovery_sleep", "-f", "json").Return(
+		`{"name":"osd_recovery_sleep","type":"float","services":["osd"],"min":"","max":"","can_update_at_runtime":true}`, nil).Once()
+	r.On("RunCommand", "ceph", "config", "help", "rbd_cache", "-f", "json").Return(
+		`{"name":"rbd_cache","type":"bool","services":["rbd"],"min":"","max":""}`, nil).Once()
+	common.ProcessExec = r
+
+	req := types.ConfigApply{Document: types.ConfigDocument{
+		Cluster:  map[string]string{"rgw_keystone_url": "http://new:5000"},
+		Advanced: map[string]map[string]string{"osd": {"osd_max_backfills": "2"}},
+		Client:   map[string]string{"rbd_cache": "false"},
+	}, Prune: true}
+
+	plan, err := PlanConfigApply(context.Background(), s.TestStateInterface, req)
+	assert.NoError(s.T(), err)
+
+	actions := []string{}
+	for _, change := range plan.Changes {
+		action := "set"
+		if change.NewValue == nil {
+			action = "reset"
+		}
+		actions = append(actions, action+" "+change.Scope+" "+change.Who+"/"+change.Key)
+	}
+
+	// public_network is read only and the bootstrap keys were not set through
+	// MicroCeph, so they are never pruned.
+	assert.Equal(s.T(), []string{
+		"set cluster /rgw_keystone_url",
+		"reset cluster /rgw_keystone_api_version",
+		"set advanced osd/osd_max_backfills",
+		"reset advanced osd/osd_recovery_sleep",
+		"set client /rbd_cache",
+		"reset client /rbd_cache_size",
+	}, actions)
+
+	// Runtime options need no restart, the keystone keys restart rgw once.
+	assert.Equal(s.T(), []string{"rgw"}, plan.Services)
+}
+
+func (s *configApplySuite) TestPlanRefusesInvalidDocument() {
+	r := mocks.NewRunner(s.T())
+	s.addDumpExpectation(r)
+	common.ProcessExec = r
+
+	// Changing a read only key is refused before anything is applied.
+	req := types.ConfigApply{Document: types.ConfigDocument{
+		Cluster: map[string]string{"public_network": "10.1.0.0/24"},
+	}}
+
+	_, err := PlanConfigApply(context.Background(), s.TestStateInterface, req)
+	assert.Error(s.T(), err)
+
+	// Unsupported keys belong under advanced.
+	s.addDumpExpectation(r)
+	req = types.ConfigApply{Document: types.ConfigDocument{
+		Cluster: map[string]string{"osd_max_backfills": "2"},
+	}}
+
+	_, err = PlanConfigApply(context.Background(), s.TestStateInterface, req)
+	assert.Error(s.T(), err)
+}
+
+func (s *configApplySuite) TestApplyRollsBackOnFailure() {
+	ccq := mocks.NewClientConfigQueryIntf(s.T())
+	ccq.On("AddNew", s.TestStateInterface.ClusterState(), "rbd_cache", "true", constants.ClientConfigGlobalHostConst).Return(nil).Once()
+	ccq.On("AddNew", s.TestStateInterface.ClusterState(), "rbd_cache_size", "2048", constants.ClientConfigGlobalHostConst).Return(fmt.Errorf("db error")).Once()
+	database.ClientConfigQuery = ccq
+
+	removed := []string{}
+	origRemove := database.RemoveGlobalClientConfigItemDb
+	s.T().Cleanup(func() { database.RemoveGlobalClientConfigItemDb = origRemove })
+	database.RemoveGlobalClientConfigItemDb = func(_ context.Context, _ mcTypes.State, key string) error {
+		removed = append(removed, key)
+		return nil
+	}
+
+	newCache := "true"
+	oldSize := "1024"
+	newSize := "2048"
+	plan := types.ConfigPlan{Changes: []types.ConfigPlanChange{
+		{Scope: types.ConfigScopeClient, Key: "rbd_cache", NewValue: &newCache},
+		{Scope: types.ConfigScopeClient, Key: "rbd_cache_size", OldValue: &oldSize, NewValue: &newSize},
+	}}
+
+	applied, err := ApplyConfigPlan(context.Background(), s.TestStateInterface, plan, "tester")
+	assert.ErrorContains(s.T(), err, "were rolled back")
+	assert.Empty(s.T(), applied)
+	// rbd_cache was unset before the apply, it is removed again.
+	assert.Equal(s.T(), []string{"rbd_cache"}, removed)
+}
+
+func (s *configApplySuite) TestApplyReportsChangesLeftApplied() {
+	ccq := mocks.NewClientConfigQueryIntf(s.T())
+	ccq.On("AddNew", s.TestStateInterface.ClusterState(), "rbd_cache", "true", constants.ClientConfigGlobalHostConst).Return(nil).Once()
+	ccq.On("AddNew", s.TestStateInterface.ClusterState(), "rbd_cache_size", "2048", constants.ClientConfigGlobalHostConst).Return(fmt.Errorf("db error")).Once()
+	database.ClientConfigQuery = ccq
+
+	origRemove := database.RemoveGlobalClientConfigItemDb
+	s.T().Cleanup(func() { database.RemoveGlobalClientConfigItemDb = origRemove })
+	database.RemoveGlobalClientConfigItemDb = func(_ context.Context, _ mcTypes.State, _ string) error {
+		return fmt.Errorf("db error")
+	}
+
+	newCache := "true"
+	newSize := "2048"
+	plan := types.ConfigPlan{Changes: []types.ConfigPlanChange{
+		{Scope: types.ConfigScopeClient, Key: "rbd_cache", NewValue: &newCache},
+		{Scope: types.ConfigScopeClient, Key: "rbd_cache_size", NewValue: &newSize},
+	}}
+
+	applied, err := ApplyConfigPlan(context.Background(), s.TestStateInterface, plan, "tester")
+	assert.ErrorContains(s.T(), err, "could not roll back rbd_cache")
+	assert.Len(s.T(), applied, 1)
+	assert.Equal(s.T(), "rbd_cache", applied[0].Key)
+}
@@ -0,0 +1,161 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// cephInternalConfigOptions are set by MicroCeph itself outside of cluster
+// config, so they are not reported as set only in Ceph.
+var cephInternalConfigOptions = []string{"osd_pool_default_size", "mon_allow_pool_size_one"}
+
+// getTrackedConfigs fetches the ceph options MicroCeph believes are set,
+// keyed by section and option like the config dump. A value set through
+// MicroCeph takes precedence over the one MicroCeph set on its own.
+func getTrackedConfigs(ctx context.Context, s interfaces.StateInterface) (map[string]string, error) {
+	config, err := fetchConfigDb(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config db: %w", err)
+	}
+
+	tracked := map[string]string{}
+	for _, prefix := range []string{constants.CephDefaultKeyPrefix, constants.CephOptionKeyPrefix} {
+		for key, value := range config {
+			record, found := strings.CutPrefix(key, prefix)
+			if found {
+				tracked[record] = value
+			}
+		}
+	}
+
+	pubNet, ok := config["public_network"]
+	if ok {
+		tracked["global/public_network"] = pubNet
+	}
+
+	return tracked, nil
+}
+
+// CheckConfigDrift compares the options MicroCeph tracks with the monitors'
+// config database. It reports tracked options changed or removed outside of
+// MicroCeph and options only set in Ceph.
+func CheckConfigDrift(ctx context.Context, s interfaces.StateInterface) (types.ConfigDrifts, error) {
+	dump, err := getConfigDump()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cluster config: %w", err)
+	}
+
+	tracked, err := getTrackedConfigs(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := types.ConfigDrifts{}
+	for _, record := range sortedKeys(tracked) {
+		who, key, _ := strings.Cut(record, "/")
+		expected := optionalValue(tracked, record)
+		actual := optionalValue(dump, record)
+		if actual == nil {
+			drifts = append(drifts, types.ConfigDrift{Kind: types.ConfigDriftMissing, Who: who, Key: key, Expected: expected})
+		} else if *actual != *expected {
+			drifts = append(drifts, types.ConfigDrift{Kind: types.ConfigDriftChanged, Who: who, Key: key, Expected: expected, Actual: actual})
+		}
+	}
+
+	for _, record := range sortedKeys(dump) {
+		_, ok := tracked[record]
+		who, key, _ := strings.Cut(record, "/")
+		// Manager module options are owned by their modules.
+		if ok || strings.Contains(key, "/") || slices.Contains(cephInternalConfigOptions, key) {
+			continue
+		}
+
+		drifts = append(drifts, types.ConfigDrift{Kind: types.ConfigDriftCephOnly, Who: who, Key: key, Actual: optionalValue(dump, record)})
+	}
+
+	return drifts, nil
+}
+
+// reconcileToCeph sets the options MicroCeph tracks back in Ceph. Options
+// only set in Ceph are left alone as MicroCeph cannot tell who owns them.
+func reconcileToCeph(ctx context.Context, s interfaces.StateInterface, drift types.ConfigDrift, requester string) error {
+	if drift.Kind == types.ConfigDriftCephOnly {
+		return nil
+	}
+
+	_, err := cephRun("config", "set", drift.Who, drift.Key, *drift.Expected)
+	if err != nil {
+		return fmt.Errorf("config set(%s) failed: %w", drift.Key, err)
+	}
+
+	c := types.Config{
+		Key:       drift.Key,
+		Advanced:  !GetConstConfigTable().isKeyPresent(drift.Key),
+		Requester: requester,
+	}
+	return recordConfigChange(ctx, s, c, drift.Who, drift.Actual, drift.Expected)
+}
+
+// reconcileToMicroCeph updates the options MicroCeph tracks to the values set
+// in Ceph, adopting the options only set in Ceph. Only the ceph option records
+// are touched, the public network MicroCeph records at bootstrap is left alone.
+func reconcileToMicroCeph(ctx context.Context, s interfaces.StateInterface, drift types.ConfigDrift) error {
+	if drift.Who == "global" && drift.Key == "public_network" {
+		logger.Warnf("Config: public_network drifted from the recorded network, it is not reconciled towards MicroCeph")
+		return nil
+	}
+
+	config, err := fetchConfigDb(ctx, s)
+	if err != nil {
+		return fmt.Errorf("failed to get config db: %w", err)
+	}
+
+	// Options MicroCeph set on its own stay recorded as such.
+	key := trackedConfigKey(drift.Who, drift.Key)
+	_, userSet := config[key]
+	_, defaultSet := config[defaultConfigKey(drift.Who, drift.Key)]
+	if defaultSet && !userSet {
+		key = defaultConfigKey(drift.Who, drift.Key)
+	}
+
+	if drift.Actual == nil {
+		return database.DeleteConfigItemDb(ctx, s.ClusterState(), key)
+	}
+
+	return database.SetConfigItemDb(ctx, s.ClusterState(), key, *drift.Actual)
+}
+
+// ReconcileConfigDrift checks for config drift and reconciles it in the given
+// direction, returning the drift found. Daemons are not restarted.
+func ReconcileConfigDrift(ctx context.Context, s interfaces.StateInterface, req types.ConfigReconcile) (types.ConfigDrifts, error) {
+	if req.Direction != types.ConfigReconcileCeph && req.Direction != types.ConfigReconcileMicroCeph {
+		return nil, fmt.Errorf("invalid reconcile direction %q, expected %s or %s", req.Direction, types.ConfigReconcileCeph, types.ConfigReconcileMicroCeph)
+	}
+
+	drifts, err := CheckConfigDrift(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, drift := range drifts {
+		if req.Direction == types.ConfigReconcileCeph {
+			err = reconcileToCeph(ctx, s, drift, req.Requester)
+		} else {
+			err = reconcileToMicroCeph(ctx, s, drift)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile %s/%s: %w", drift.Who, drift.Key, err)
+		}
+	}
+
+	logger.Infof("Config: reconciled %d drifted options towards %s", len(drifts), req.Direction)
+	return drifts, nil
+}
@@ -0,0 +1,229 @@
+package ceph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type configDriftSuite struct {
+	tests.BaseSuite
+	TestStateInterface *mocks.StateInterface
+}
+
+func TestConfigDrift(t *testing.T) {
+	suite.Run(t, new(configDriftSuite))
+}
+
+func (s *configDriftSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+
+	s.TestStateInterface = mocks.NewStateInterface(s.T())
+	state := &mocks.MockState{URL: api.NewURL(), ClusterName: "foohost"}
+	s.TestStateInterface.On("ClusterState").Return(state).Maybe()
+
+	origFetch := fetchConfigDb
+	s.T().Cleanup(func() { fetchConfigDb = origFetch })
+	fetchConfigDb = func(_ context.Context, _ interfaces.StateInterface) (map[string]string, error) {
+		return map[string]string{
+			"fsid":           "abcd",
+			"public_network": "10.0.0.0/24",
+			constants.CephOptionKeyPrefix + "global/rgw_keystone_url": "http://keystone:5000",
+			constants.CephOptionKeyPrefix + "osd/osd_max_backfills":   "2",
+		}, nil
+	}
+}
+
+func (s *configDriftSuite) addDumpExpectation(r *mocks.Runner) {
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(`[
+{"section":"global","name":"public_network","value":"10.0.0.0/24"},
+{"section":"osd","name":"osd_max_backfills","value":"8"},
+{"section":"osd","name":"osd_recovery_sleep","value":"0.5"},
+{"section":"osd","mask":"class:ssd","name":"osd_recovery_sleep","value":"0"},
+{"section":"global","name":"osd_pool_default_size","value":"3"},
+{"section":"mgr","name":"mgr/dashboard/ssl","value":"false"}
+]`, nil).Once()
+}
+
+func (s *configDriftSuite) TestCheckConfigDrift() {
+	r := mocks.NewRunner(s.T())
+	s.addDumpExpectation(r)
+	common.ProcessExec = r
+
+	expectedURL := "http://keystone:5000"
+	expectedBackfills := "2"
+	actualBackfills := "8"
+	actualSleep := "0.5"
+
+	// Masked, internal and manager module options are not reported.
+	drifts, err := CheckConfigDrift(context.Background(), s.TestStateInterface)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), types.ConfigDrifts{
+		{Kind: types.ConfigDriftMissing, Who: "global", Key: "rgw_keystone_url", Expected: &expectedURL},
+		{Kind: types.ConfigDriftChanged, Who: "osd", Key: "osd_max_backfills", Expected: &expectedBackfills, Actual: &actualBackfills},
+		{Kind: types.ConfigDriftCephOnly, Who: "osd", Key: "osd_recovery_sleep", Actual: &actualSleep},
+	}, drifts)
+}
+
+func (s *configDriftSuite) TestReconcileToMicroCeph() {
+	r := mocks.NewRunner(s.T())
+	s.addDumpExpectation(r)
+	common.ProcessExec = r
+
+	tracked := map[string]string{}
+	origSet := database.SetConfigItemDb
+	s.T().Cleanup(func() { database.SetConfigItemDb = origSet })
+	database.SetConfigItemDb = func(_ context.Context, _ mcTypes.State, key string, value string) error {
+		tracked[key] = value
+		return nil
+	}
+
+	untracked := []string{}
+	origDelete := database.DeleteConfigItemDb
+	s.T().Cleanup(func() { database.DeleteConfigItemDb = origDelete })
+	database.DeleteConfigItemDb = func(_ context.Context, _ mcTypes.State, key string) error {
+		untracked = append(untracked, key)
+		return nil
+	}
+
+	drifts, err := ReconcileConfigDrift(context.Background(), s.TestStateInterface, types.ConfigReconcile{Direction: types.ConfigReconcileMicroCeph})
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), drifts, 3)
+	assert.Equal(s.T(), []string{constants.CephOptionKeyPrefix + "global/rgw_keystone_url"}, untracked)
+	assert.Equal(s.T(), map[string]string{
+		constants.CephOptionKeyPrefix + "osd/osd_max_backfills":  "8",
+		constants.CephOptionKeyPrefix + "osd/osd_recovery_sleep": "0.5",
+	}, tracked)
+}
+
+func (s *configDriftSuite) TestReconcileToCeph() {
+	r := mocks.NewRunner(s.T())
+	s.addDumpExpectation(r)
+	r.On("RunCommand", "ceph", "config", "set", "global", "rgw_keystone_url", "http://keystone:5000").Return("", nil).Once()
+	r.On("RunCommand", "ceph", "config", "set", "osd", "osd_max_backfills", "2").Return("", nil).Once()
+	common.ProcessExec = r
+
+	recorded := types.ConfigChanges{}
+	origRecord := database.RecordConfigChangeDb
+	s.T().Cleanup(func() { database.RecordConfigChangeDb = origRecord })
+	database.RecordConfigChangeDb = func(_ context.Context, _ mcTypes.State, change types.ConfigChange) error {
+		recorded = append(recorded, change)
+		return nil
+	}
+
+	// The option only set in Ceph is left alone.
+	_, err := ReconcileConfigDrift(context.Background(), s.TestStateInterface, types.ConfigReconcile{Direction: types.ConfigReconcileCeph, Requester: "bob@foohost"})
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), recorded, 2)
+	assert.False(s.T(), recorded[0].Advanced)
+	assert.True(s.T(), recorded[1].Advanced)
+	assert.Equal(s.T(), "bob@foohost", recorded[1].Requester)
+
+	_, err = ReconcileConfigDrift(context.Background(), s.TestStateInterface, types.ConfigReconcile{Direction: "sideways"})
+	assert.Error(s.T(), err)
+}
+
+func (s *configDriftSuite) TestReconcileToMicroCephKeepsInternalKeys() {
+	fetchConfigDb = func(_ context.Context, _ interfaces.StateInterface) (map[string]string, error) {
+		return map[string]string{
+			"public_network": "10.0.0.0/24",
+			constants.CephDefaultKeyPrefix + "global/cluster_network": "10.0.0.0/24",
+		}, nil
+	}
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(`[
+{"section":"global","name":"public_network","value":"10.1.0.0/24"},
+{"section":"global","name":"cluster_network","value":"10.2.0.0/24"}
+]`, nil).Once()
+	common.ProcessExec = r
+
+	tracked := map[string]string{}
+	origSet := database.SetConfigItemDb
+	s.T().Cleanup(func() { database.SetConfigItemDb = origSet })
+	database.SetConfigItemDb = func(_ context.Context, _ mcTypes.State, key string, value string) error {
+		tracked[key] = value
+		return nil
+	}
+
+	drifts, err := ReconcileConfigDrift(context.Background(), s.TestStateInterface, types.ConfigReconcile{Direction: types.ConfigReconcileMicroCeph})
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), drifts, 2)
+
+	// The recorded public network is left alone and the bootstrap key stays
+	// tracked as set by MicroCeph.
+	assert.Equal(s.T(), map[string]string{
+		constants.CephDefaultKeyPrefix + "global/cluster_network": "10.2.0.0/24",
+	}, tracked)
+}
+
+func (s *configDriftSuite) TestFreshBootstrapReportsNoDrift() {
+	// The monitors' config database and the config table as bootstrap leaves them.
+	monConfig := map[string]string{}
+	db := map[string]string{"public_network": "10.0.0.0/24"}
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "set", mock.Anything, mock.Anything, mock.Anything, "-f", "json-pretty").Return(
+		func(_ string, args ...string) (string, error) {
+			monConfig[args[2]+"/"+args[3]] = args[4]
+			return "", nil
+		})
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(
+		func(_ string, _ ...string) (string, error) {
+			dump := ConfigDump{}
+			for record, value := range monConfig {
+				who, key, _ := strings.Cut(record, "/")
+				dump = append(dump, ConfigDumpItem{Section: who, Name: key, Value: value})
+			}
+			out, err := json.Marshal(dump)
+			return string(out), err
+		})
+	r.On("RunCommand", "ceph", "osd", "crush", "rule", "dump", "microceph_auto_osd").Return(`{"rule_id": 1}`, nil).Once()
+	common.ProcessExec = r
+
+	origGet := database.GetConfigItemDb
+	origSet := database.SetConfigItemDb
+	origRecord := database.RecordConfigChangeDb
+	s.T().Cleanup(func() {
+		database.GetConfigItemDb = origGet
+		database.SetConfigItemDb = origSet
+		database.RecordConfigChangeDb = origRecord
+	})
+	database.GetConfigItemDb = func(_ context.Context, _ mcTypes.State, key string) (string, error) {
+		return db[key], nil
+	}
+	database.SetConfigItemDb = func(_ context.Context, _ mcTypes.State, key string, value string) error {
+		db[key] = value
+		return nil
+	}
+	database.RecordConfigChangeDb = func(_ context.Context, _ mcTypes.State, _ types.ConfigChange) error {
+		return nil
+	}
+	fetchConfigDb = func(_ context.Context, _ interfaces.StateInterface) (map[string]string, error) {
+		return db, nil
+	}
+
+	err := BootstrapCephConfigs(context.Background(), s.TestStateInterface, "10.0.0.0/24", "10.0.0.0/24")
+	assert.NoError(s.T(), err)
+	err = setDefaultCrushRule(context.Background(), s.TestStateInterface, "microceph_auto_osd")
+	assert.NoError(s.T(), err)
+
+	drifts, err := CheckConfigDrift(context.Background(), s.TestStateInterface)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), drifts)
+}
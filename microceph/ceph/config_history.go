@@ -0,0 +1,250 @@
+package ceph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// configChangeWho works out the section a cluster config change applies to,
+// checking that the key can be changed at all.
+func configChangeWho(c types.Config) (string, error) {
+	if c.Advanced {
+		return advancedConfigWho(c)
+	}
+
+	canSet, err := canSetConfig(c.Key)
+	if !canSet {
+		return "", fmt.Errorf("config set(%s) failed: %v", c.Key, err)
+	}
+
+	return GetConstConfigTable()[c.Key].Who, nil
+}
+
+// getConfigDump fetches the options explicitly set in the monitors' config
+// database, keyed by section and option as in "global/cluster_network".
+func getConfigDump() (map[string]string, error) {
+	var dump ConfigDump
+	output, err := cephRun("config", "dump", "-f", "json-pretty")
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(output), &dump)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal config dump output: %w", err)
+	}
+
+	values := map[string]string{}
+	for _, item := range dump {
+		// Options limited to a host or device class are not managed by MicroCeph.
+		if len(item.Mask) != 0 {
+			continue
+		}
+
+		values[item.Section+"/"+item.Name] = item.Value
+	}
+
+	return values, nil
+}
+
+// getConfigDumpValue fetches the value a key is explicitly set to in a
+// section of the monitors' config database, nil if it is unset.
+func getConfigDumpValue(who string, key string) (*string, error) {
+	dump, err := getConfigDump()
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := dump[who+"/"+key]
+	if !ok {
+		return nil, nil
+	}
+
+	return &value, nil
+}
+
+// recordConfigChange appends a change to the config history. The change is
+// already applied at this point, the error returned says so.
+func recordConfigChange(ctx context.Context, s interfaces.StateInterface, c types.Config, who string, oldValue *string, newValue *string) error {
+	requester := c.Requester
+	if len(requester) == 0 {
+		requester = "unknown"
+	}
+
+	change := types.ConfigChange{
+		Key:       c.Key,
+		Who:       who,
+		Advanced:  c.Advanced,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		Member:    s.ClusterState().Name(),
+		Requester: requester,
+		Timestamp: time.Now(),
+	}
+
+	err := database.RecordConfigChangeDb(ctx, s.ClusterState(), change)
+	if err != nil {
+		return fmt.Errorf("config change of %s applied but not recorded in the config history: %w", c.Key, err)
+	}
+
+	return nil
+}
+
+// SetInternalConfigItem sets a MicroCeph supported key on behalf of MicroCeph
+// itself, as at bootstrap or on a failure domain change, tracks its value and
+// records the change in the config history. Failing to track or record it is
+// logged but not returned.
+func SetInternalConfigItem(ctx context.Context, s interfaces.StateInterface, c types.Config) error {
+	who := GetConstConfigTable()[c.Key].Who
+	oldValue, dumpErr := getConfigDumpValue(who, c.Key)
+
+	err := SetConfigItem(c)
+	if err != nil {
+		return err
+	}
+
+	// A key set through MicroCeph keeps being tracked as such.
+	trackKey := defaultConfigKey(who, c.Key)
+	recorded, err := database.GetConfigItemDb(ctx, s.ClusterState(), trackedConfigKey(who, c.Key))
+	if err == nil && len(recorded) != 0 {
+		trackKey = trackedConfigKey(who, c.Key)
+	}
+
+	err = database.SetConfigItemDb(ctx, s.ClusterState(), trackKey, c.Value)
+	if err != nil {
+		logger.Errorf("failed to track the value of %s: %v", c.Key, err)
+	}
+
+	if dumpErr != nil {
+		logger.Errorf("failed to record config change of %s in the config history, cannot fetch its previous value: %v", c.Key, dumpErr)
+		return nil
+	}
+
+	if len(c.Requester) == 0 {
+		c.Requester = "microceph"
+	}
+
+	newValue := c.Value
+	err = recordConfigChange(ctx, s, c, who, oldValue, &newValue)
+	if err != nil {
+		logger.Errorf("%v", err)
+	}
+
+	return nil
+}
+
+// SetClusterConfig sets a cluster config, either a MicroCeph supported key or
+// an advanced option, tracks its value and records the change in the config
+// history. It returns the daemons to restart for the change to take effect.
+func SetClusterConfig(ctx context.Context, s interfaces.StateInterface, c types.Config) ([]string, error) {
+	who, err := configChangeWho(c)
+	if err != nil {
+		return nil, err
+	}
+
+	oldValue, err := getConfigDumpValue(who, c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch current value of %s: %w", c.Key, err)
+	}
+
+	services := GetConstConfigTable()[c.Key].Daemons
+	if c.Advanced {
+		services, err = SetAdvancedConfigItem(ctx, s, c)
+	} else {
+		err = SetConfigItem(c)
+		if err == nil {
+			err = database.SetConfigItemDb(ctx, s.ClusterState(), trackedConfigKey(who, c.Key), c.Value)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	newValue := c.Value
+	err = recordConfigChange(ctx, s, c, who, oldValue, &newValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// ResetClusterConfig unsets a cluster config, either a MicroCeph supported key
+// or an advanced option, drops its tracked value and records the change in the
+// config history. It returns the daemons to restart for the change to take
+// effect.
+func ResetClusterConfig(ctx context.Context, s interfaces.StateInterface, c types.Config) ([]string, error) {
+	who, err := configChangeWho(c)
+	if err != nil {
+		return nil, err
+	}
+
+	oldValue, err := getConfigDumpValue(who, c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch current value of %s: %w", c.Key, err)
+	}
+
+	services := GetConstConfigTable()[c.Key].Daemons
+	if c.Advanced {
+		services, err = RemoveAdvancedConfigItem(ctx, s, c)
+	} else {
+		err = RemoveConfigItem(c)
+		if err == nil {
+			err = database.DeleteConfigItemDb(ctx, s.ClusterState(), trackedConfigKey(who, c.Key))
+		}
+		if err == nil {
+			err = database.DeleteConfigItemDb(ctx, s.ClusterState(), defaultConfigKey(who, c.Key))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordConfigChange(ctx, s, c, who, oldValue, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// GetConfigHistory fetches the recorded config changes of a key, newest
+// first. An empty key fetches the changes of all keys.
+func GetConfigHistory(ctx context.Context, s interfaces.StateInterface, key string) (types.ConfigChanges, error) {
+	return database.GetConfigChangesDb(ctx, s.ClusterState(), key)
+}
+
+// RollbackConfigChange restores the value a recorded change replaced, unsetting
+// the key if it was unset before. The rollback is itself recorded as a change.
+// It returns the daemons to restart for the rollback to take effect.
+func RollbackConfigChange(ctx context.Context, s interfaces.StateInterface, id int64, req types.ConfigRollback) ([]string, error) {
+	change, err := database.GetConfigChangeDb(ctx, s.ClusterState(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	c := types.Config{
+		Key:       change.Key,
+		Advanced:  change.Advanced,
+		Requester: req.Requester,
+	}
+
+	if change.Advanced {
+		c.Who = change.Who
+	}
+
+	logger.Infof("Config: rolling back change %d of %s", id, change.Key)
+	if change.OldValue == nil {
+		return ResetClusterConfig(ctx, s, c)
+	}
+
+	c.Value = *change.OldValue
+	return SetClusterConfig(ctx, s, c)
+}
@@ -0,0 +1,109 @@
+package ceph
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Value formats of the ceph option types not covered by strconv.
+var (
+	cephSizeRegex     = regexp.MustCompile(`^[0-9]+([KMGTPE]i?)?B?$`)
+	cephTimespanRegex = regexp.MustCompile(`^([0-9]+\s*(ms|s|sec|m|min|h|hr|d|w|mo|y)?\s*)+$`)
+	cephOptionRegex   = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// cephOptionHelp is the subset of `config help` output we use.
+type cephOptionHelp struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Services   []string `json:"services"`
+	EnumValues []any    `json:"enum_values"`
+	Min        any      `json:"min"`
+	Max        any      `json:"max"`
+	// CanUpdateAtRuntime is unset for options daemons only read on startup.
+	CanUpdateAtRuntime bool     `json:"can_update_at_runtime"`
+	Flags              []string `json:"flags"`
+}
+
+// getCephOptionHelp fetches the schema of a ceph option.
+func getCephOptionHelp(option string) (cephOptionHelp, error) {
+	help := cephOptionHelp{}
+	if !cephOptionRegex.MatchString(option) {
+		return help, fmt.Errorf("invalid option name %q", option)
+	}
+
+	output, err := cephRun("config", "help", option, "-f", "json")
+	if err != nil {
+		return help, fmt.Errorf("unknown ceph option %s: %w", option, err)
+	}
+
+	err = json.Unmarshal([]byte(output), &help)
+	if err != nil {
+		return help, fmt.Errorf("cannot unmarshal config help output: %w", err)
+	}
+
+	return help, nil
+}
+
+// validate checks a value against the option type, its allowed values and bounds.
+func (o cephOptionHelp) validate(value string) error {
+	var number float64
+	var err error
+
+	switch o.Type {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int":
+		var n int64
+		n, err = strconv.ParseInt(value, 10, 64)
+		number = float64(n)
+	case "uint":
+		var n uint64
+		n, err = strconv.ParseUint(value, 10, 64)
+		number = float64(n)
+	case "float":
+		number, err = strconv.ParseFloat(value, 64)
+	case "size":
+		if !cephSizeRegex.MatchString(value) {
+			err = fmt.Errorf("expected a size such as 512K or 4Gi")
+		}
+	case "secs", "millisecs":
+		if !cephTimespanRegex.MatchString(value) {
+			err = fmt.Errorf("expected a duration such as 30 or 5m")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s value %q for %s: %v", o.Type, value, o.Name, err)
+	}
+
+	if len(o.EnumValues) != 0 {
+		allowed := []string{}
+		for _, enum := range o.EnumValues {
+			allowed = append(allowed, fmt.Sprint(enum))
+		}
+
+		if !slices.Contains(allowed, value) {
+			return fmt.Errorf("invalid value %q for %s, expected one of %s", value, o.Name, strings.Join(allowed, ", "))
+		}
+	}
+
+	if !slices.Contains([]string{"int", "uint", "float"}, o.Type) {
+		return nil
+	}
+
+	bound, err := strconv.ParseFloat(fmt.Sprint(o.Min), 64)
+	if err == nil && number < bound {
+		return fmt.Errorf("value %s of %s is below its minimum %v", value, o.Name, o.Min)
+	}
+
+	bound, err = strconv.ParseFloat(fmt.Sprint(o.Max), 64)
+	if err == nil && number > bound {
+		return fmt.Errorf("value %s of %s is above its maximum %v", value, o.Name, o.Max)
+	}
+
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -449,3 +450,213 @@ func (s *configSuite) TestCephConfFileRendersMultiSubnetPublicNetwork() {
 	assert.NoError(s.T(), err)
 	assert.Contains(s.T(), string(rendered), "public_network = "+multiSubnet)
 }
+
+func (s *configSuite) TestAdvancedConfigDaemons() {
+	// Options changeable at runtime need no restart.
+	runtime := cephOptionHelp{Name: "osd_max_backfills", Services: []string{"osd"}, CanUpdateAtRuntime: true}
+	assert.Equal(s.T(), []string{}, advancedConfigDaemons("global", runtime))
+
+	startup := cephOptionHelp{Name: "osd_op_num_shards", Services: []string{"osd"}}
+	assert.Equal(s.T(), []string{"osd"}, advancedConfigDaemons("global", startup))
+	assert.Equal(s.T(), []string{"osd"}, advancedConfigDaemons("osd", startup))
+	// A single OSD section only restarts that OSD.
+	assert.Equal(s.T(), []string{"osd.1"}, advancedConfigDaemons("osd.1", startup))
+	assert.Equal(s.T(), []string{}, advancedConfigDaemons("mon", startup))
+
+	// Common options are read by every daemon.
+	shared := cephOptionHelp{Name: "ms_type"}
+	assert.Equal(s.T(), []string{"mds", "mgr", "mon", "osd", "rgw"}, advancedConfigDaemons("global", shared))
+	assert.Equal(s.T(), []string{"mgr"}, advancedConfigDaemons("mgr", shared))
+
+	// Client sections are read by the RGW daemons.
+	assert.Equal(s.T(), []string{"rgw"}, advancedConfigDaemons("client", shared))
+	assert.Equal(s.T(), []string{"rgw"}, advancedConfigDaemons("client.radosgw.gateway", shared))
+	rgwOnly := cephOptionHelp{Name: "rgw_thread_pool_size", Services: []string{"rgw"}}
+	assert.Equal(s.T(), []string{"rgw"}, advancedConfigDaemons("client.radosgw.gateway", rgwOnly))
+}
+
+func (s *configSuite) TestSetAdvancedConfigItem() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "help", "osd_op_num_shards", "-f", "json").Return(
+		`{"name":"osd_op_num_shards","type":"int","services":["osd"],"min":"","max":"","can_update_at_runtime":false,"flags":["startup"]}`, nil).Twice()
+	addConfigOpExpectations(r, "set", "osd.2", "osd_op_num_shards", "8")
+	common.ProcessExec = r
+
+	recorded := map[string]string{}
+	origSet := database.SetConfigItemDb
+	s.T().Cleanup(func() { database.SetConfigItemDb = origSet })
+	database.SetConfigItemDb = func(_ context.Context, _ mcTypes.State, key string, value string) error {
+		recorded[key] = value
+		return nil
+	}
+
+	state := &mocks.MockState{URL: api.NewURL(), ClusterName: "foohost"}
+	si := mocks.NewStateInterface(s.T())
+	si.On("ClusterState").Return(state)
+
+	daemons, err := SetAdvancedConfigItem(context.Background(), si, types.Config{Key: "osd_op_num_shards", Value: "8", Who: "osd.2"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"osd.2"}, daemons)
+	assert.Equal(s.T(), map[string]string{constants.CephOptionKeyPrefix + "osd.2/osd_op_num_shards": "8"}, recorded)
+
+	// Values are checked against the option type before touching ceph.
+	_, err = SetAdvancedConfigItem(context.Background(), si, types.Config{Key: "osd_op_num_shards", Value: "many", Who: "osd.2"})
+	assert.Error(s.T(), err)
+
+	// Keys MicroCeph manages and bogus sections are refused.
+	_, err = SetAdvancedConfigItem(context.Background(), si, types.Config{Key: "cluster_network", Value: "10.0.0.0/24"})
+	assert.Error(s.T(), err)
+	_, err = SetAdvancedConfigItem(context.Background(), si, types.Config{Key: "osd_op_num_shards", Value: "8", Who: "rbd"})
+	assert.Error(s.T(), err)
+}
+
+func (s *configSuite) TestSetAdvancedConfigItemDenied() {
+	// Refused before consulting ceph.
+	r := mocks.NewRunner(s.T())
+	common.ProcessExec = r
+
+	for _, key := range []string{
+		"auth_cluster_required", "auth_client_required", "auth_allow_insecure_global_id_reclaim",
+		"keyring", "mon_host", "ms_cluster_mode", "ms_mon_client_mode", "ms_bind_ipv6",
+		"admin_socket", "public_addr", "fsid",
+	} {
+		_, err := SetAdvancedConfigItem(context.Background(), nil, types.Config{Key: key, Value: "none", Who: "global"})
+		assert.ErrorContains(s.T(), err, "cannot be set through MicroCeph", key)
+	}
+
+	assert.NoError(s.T(), checkAdvancedConfigAllowed("ms_type"))
+	assert.NoError(s.T(), checkAdvancedConfigAllowed("osd_op_num_shards"))
+}
+
+func (s *configSuite) TestListAdvancedConfigs() {
+	origFetch := fetchConfigDb
+	s.T().Cleanup(func() { fetchConfigDb = origFetch })
+	fetchConfigDb = func(_ context.Context, _ interfaces.StateInterface) (map[string]string, error) {
+		return map[string]string{
+			"fsid": "abcd",
+			constants.CephOptionKeyPrefix + "osd/osd_max_backfills": "4",
+			constants.CephOptionKeyPrefix + "global/ms_type":        "async+posix",
+			// Supported keys are tracked alongside, but are not advanced options.
+			constants.CephOptionKeyPrefix + "global/rgw_keystone_url": "http://keystone:5000",
+		}, nil
+	}
+
+	configs, err := ListAdvancedConfigs(context.Background(), nil)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), types.Configs{
+		{Key: "ms_type", Value: "async+posix", Who: "global", Advanced: true},
+		{Key: "osd_max_backfills", Value: "4", Who: "osd", Advanced: true},
+	}, configs)
+}
+
+func (s *configSuite) TestSetClusterConfigRecordsChange() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(
+		`[{"section":"global","name":"rgw_keystone_url","value":"http://old:5000"}]`, nil).Once()
+	addConfigSetExpectations(r, "rgw_keystone_url", "http://new:5000")
+	common.ProcessExec = r
+
+	tracked := map[string]string{}
+	origSet := database.SetConfigItemDb
+	s.T().Cleanup(func() { database.SetConfigItemDb = origSet })
+	database.SetConfigItemDb = func(_ context.Context, _ mcTypes.State, key string, value string) error {
+		tracked[key] = value
+		return nil
+	}
+
+	recorded := types.ConfigChanges{}
+	origRecord := database.RecordConfigChangeDb
+	s.T().Cleanup(func() { database.RecordConfigChangeDb = origRecord })
+	database.RecordConfigChangeDb = func(_ context.Context, _ mcTypes.State, change types.ConfigChange) error {
+		recorded = append(recorded, change)
+		return nil
+	}
+
+	state := &mocks.MockState{URL: api.NewURL(), ClusterName: "foohost"}
+	si := mocks.NewStateInterface(s.T())
+	si.On("ClusterState").Return(state)
+
+	services, err := SetClusterConfig(context.Background(), si, types.Config{Key: "rgw_keystone_url", Value: "http://new:5000", Requester: "local@foohost"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"rgw"}, services)
+	assert.Len(s.T(), recorded, 1)
+	assert.Equal(s.T(), "global", recorded[0].Who)
+	assert.Equal(s.T(), "http://old:5000", *recorded[0].OldValue)
+	assert.Equal(s.T(), "http://new:5000", *recorded[0].NewValue)
+	assert.Equal(s.T(), "foohost", recorded[0].Member)
+	assert.Equal(s.T(), "local@foohost", recorded[0].Requester)
+	assert.Equal(s.T(), map[string]string{constants.CephOptionKeyPrefix + "global/rgw_keystone_url": "http://new:5000"}, tracked)
+}
+
+func (s *configSuite) TestSetClusterConfigRecordFailure() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(`[]`, nil).Once()
+	addConfigSetExpectations(r, "rgw_keystone_url", "http://new:5000")
+	common.ProcessExec = r
+
+	origSet := database.SetConfigItemDb
+	origRecord := database.RecordConfigChangeDb
+	s.T().Cleanup(func() {
+		database.SetConfigItemDb = origSet
+		database.RecordConfigChangeDb = origRecord
+	})
+	database.SetConfigItemDb = func(_ context.Context, _ mcTypes.State, _ string, _ string) error {
+		return nil
+	}
+	database.RecordConfigChangeDb = func(_ context.Context, _ mcTypes.State, _ types.ConfigChange) error {
+		return errors.New("database is locked")
+	}
+
+	state := &mocks.MockState{URL: api.NewURL(), ClusterName: "foohost"}
+	si := mocks.NewStateInterface(s.T())
+	si.On("ClusterState").Return(state)
+
+	// The change is applied, but the caller learns it went unrecorded.
+	_, err := SetClusterConfig(context.Background(), si, types.Config{Key: "rgw_keystone_url", Value: "http://new:5000"})
+	assert.ErrorContains(s.T(), err, "applied but not recorded in the config history")
+}
+
+func (s *configSuite) TestRollbackConfigChangeOfUnsetKey() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(
+		`[{"section":"global","name":"rgw_keystone_url","value":"http://bad:5000"}]`, nil).Once()
+	addConfigOpExpectations(r, "rm", "global", "rgw_keystone_url", "")
+	common.ProcessExec = r
+
+	untracked := []string{}
+	origDelete := database.DeleteConfigItemDb
+	s.T().Cleanup(func() { database.DeleteConfigItemDb = origDelete })
+	database.DeleteConfigItemDb = func(_ context.Context, _ mcTypes.State, key string) error {
+		untracked = append(untracked, key)
+		return nil
+	}
+
+	newValue := "http://bad:5000"
+	origGet := database.GetConfigChangeDb
+	s.T().Cleanup(func() { database.GetConfigChangeDb = origGet })
+	database.GetConfigChangeDb = func(_ context.Context, _ mcTypes.State, id int64) (types.ConfigChange, error) {
+		return types.ConfigChange{ID: id, Key: "rgw_keystone_url", Who: "global", NewValue: &newValue}, nil
+	}
+
+	recorded := types.ConfigChanges{}
+	origRecord := database.RecordConfigChangeDb
+	s.T().Cleanup(func() { database.RecordConfigChangeDb = origRecord })
+	database.RecordConfigChangeDb = func(_ context.Context, _ mcTypes.State, change types.ConfigChange) error {
+		recorded = append(recorded, change)
+		return nil
+	}
+
+	state := &mocks.MockState{URL: api.NewURL(), ClusterName: "foohost"}
+	si := mocks.NewStateInterface(s.T())
+	si.On("ClusterState").Return(state)
+
+	// The key was unset before the change, rolling back resets it.
+	services, err := RollbackConfigChange(context.Background(), si, 7, types.ConfigRollback{})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"rgw"}, services)
+	assert.Len(s.T(), recorded, 1)
+	assert.Equal(s.T(), "http://bad:5000", *recorded[0].OldValue)
+	assert.Nil(s.T(), recorded[0].NewValue)
+	assert.Equal(s.T(), "unknown", recorded[0].Requester)
+	assert.Equal(s.T(), []string{constants.CephOptionKeyPrefix + "global/rgw_keystone_url"}, untracked)
+}
@@ -89,6 +89,12 @@ func (c *Config) WriteConfig(data map[string]any, mode int) error {
 
 // newRadosGWConfig creates a new radosgw config file
 func newRadosGWConfig(configDir string) *Config {
+	return newRadosGWInstanceConfig(configDir, "radosgw.conf", "radosgw.gateway")
+}
+
+// newRadosGWInstanceConfig creates a new radosgw config file for the RGW daemon
+// running as client.<name>.
+func newRadosGWInstanceConfig(configDir string, configFile string, name string) *Config {
 	return &Config{
 		configTemplate: template.Must(template.New("radosgwConfig").Parse(`# Generated by MicroCeph, DO NOT EDIT.
 [global]
@@ -96,11 +102,12 @@ mon host = {{.monitors}}
 run dir = {{.runDir}}
 auth allow insecure global id reclaim = false
 
-[client.radosgw.gateway]
+[client.` + name + `]
 rgw init timeout = 1200
 rgw frontends = beast{{if or (ne .rgwPort 0) (not .sslCertificatePath) (not .sslPrivateKeyPath)}} port={{.rgwPort}}{{end}}{{if and .sslCertificatePath .sslPrivateKeyPath}} ssl_port={{.sslPort}} ssl_certificate={{.sslCertificatePath}} ssl_private_key={{.sslPrivateKeyPath}}{{end}}
-`)),
-		configFile: "radosgw.conf",
+{{range .rgwZoneLines}}{{.}}
+{{end}}`)),
+		configFile: configFile,
 		configDir:  configDir,
 	}
 }
@@ -98,13 +98,10 @@ ms bind ipv6 = {{.ipv6}}
 # https://tracker.ceph.com/issues/70390
 bluestore_elastic_shared_blobs = false
 
-[client]
-{{if .isCache}}rbd_cache = {{.isCache}}{{end}}
-{{if .cacheSize}}rbd_cache_size = {{.cacheSize}}{{end}}
-{{if .isCacheWritethrough}}rbd_cache_writethrough_until_flush = {{.isCacheWritethrough}}{{end}}
-{{if .cacheMaxDirty}}rbd_cache_max_dirty = {{.cacheMaxDirty}}{{end}}
-{{if .cacheTargetDirty}}rbd_cache_target_dirty = {{.cacheTargetDirty}}{{end}}
-`)),
+{{range .clientSections}}
+[{{.Name}}]
+{{range .Options}}{{.Key}} = {{.Value}}
+{{end}}{{end}}`)),
 		configFile: configFile,
 		configDir:  constants.GetPathConst().ConfPath,
 	}
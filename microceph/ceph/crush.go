@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/interfaces"
 
 	"github.com/canonical/microceph/microceph/api/types"
 
@@ -26,7 +27,7 @@ func IsValidCrushName(name string) bool {
 // ##### Public Methods #####
 
 // BootstrapCrushRules sets up and configures the crush rules for failure domain handling.
-func BootstrapCrushRules() error {
+func BootstrapCrushRules(ctx context.Context, s interfaces.StateInterface) error {
 	// setup up crush rules
 	err := ensureCrushRules()
 	if err != nil {
@@ -34,7 +35,7 @@ func BootstrapCrushRules() error {
 	}
 
 	// configure the default crush rule for new pools
-	err = setDefaultCrushRule("microceph_auto_osd")
+	err = setDefaultCrushRule(ctx, s, "microceph_auto_osd")
 	if err != nil {
 		return err
 	}
@@ -130,12 +131,12 @@ func setPoolCrushRule(pool string, rule string) error {
 }
 
 // setDefaultCrushRule sets the default crush rule for new pools
-func setDefaultCrushRule(rule string) error {
+func setDefaultCrushRule(ctx context.Context, s interfaces.StateInterface, rule string) error {
 	rid, err := getCrushRuleID(rule)
 	if err != nil {
 		return err
 	}
-	err = SetConfigItem(types.Config{
+	err = SetInternalConfigItem(ctx, s, types.Config{
 		Key:   "osd_pool_default_crush_rule",
 		Value: rid,
 	})
@@ -30,6 +30,24 @@ func bootstrapMgr(hostname string, path string) error {
 	return nil
 }
 
+// IsMgrModuleEnabled checks whether a mgr module is enabled, or always on, on the local cluster.
+func IsMgrModuleEnabled(ctx context.Context, module string) (bool, error) {
+	output, err := cephRunContext(ctx, "mgr", "module", "ls", "--format", "json")
+	if err != nil {
+		return false, fmt.Errorf("failed to list mgr modules: %w", err)
+	}
+
+	for _, modules := range gjson.GetMany(output, "always_on_modules", "enabled_modules") {
+		for _, enabled := range modules.Array() {
+			if enabled.String() == module {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
 func getActiveMgrs() ([]string, error) {
 	output, err := common.ProcessExec.RunCommand("ceph", "mgr", "dump", "-f", "json")
 	if err != nil {
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/canonical/microceph/microceph/common"
@@ -299,3 +301,60 @@ func getActiveMons() ([]string, error) {
 
 	return activeMons, nil
 }
+
+// extractMonmap extracts the monmap of a stopped monitor to a file and returns
+// the names of the monitors it holds.
+func extractMonmap(name string, monDataPath string, monmap string) ([]string, error) {
+	_, err := common.ProcessExec.RunCommand("ceph-mon", "-i", name, "--mon-data", monDataPath, "--extract-monmap", monmap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract monmap of mon.%s: %w", name, err)
+	}
+
+	output, err := common.ProcessExec.RunCommand("monmaptool", "--print", monmap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read monmap of mon.%s: %w", name, err)
+	}
+
+	return parseMonmapNames(output), nil
+}
+
+// parseMonmapNames reads the monitor names from the output of monmaptool
+// --print, whose monitor lines look like "0: [v2:...,v1:...] mon.<name>".
+func parseMonmapNames(output string) []string {
+	names := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[len(fields)-1], "mon.") {
+			continue
+		}
+
+		_, err := strconv.Atoi(strings.TrimSuffix(fields[0], ":"))
+		if err != nil || !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+
+		names = append(names, strings.TrimPrefix(fields[len(fields)-1], "mon."))
+	}
+
+	return names
+}
+
+// rmMonmap removes a monitor from a monmap file.
+func rmMonmap(path string, name string) error {
+	_, err := common.ProcessExec.RunCommand("monmaptool", "--rm", name, path)
+	if err != nil {
+		return fmt.Errorf("failed to remove mon.%s from monmap: %w", name, err)
+	}
+
+	return nil
+}
+
+// injectMonmap replaces the monmap of a stopped monitor.
+func injectMonmap(name string, monDataPath string, monmap string) error {
+	_, err := common.ProcessExec.RunCommand("ceph-mon", "-i", name, "--mon-data", monDataPath, "--inject-monmap", monmap)
+	if err != nil {
+		return fmt.Errorf("failed to inject monmap into mon.%s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,584 @@
+package ceph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/tidwall/gjson"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// NFS exports are kept the way the ceph mgr nfs module keeps them: one
+// export-<id> object per export in the cluster's namespace of the .nfs pool,
+// each referenced by a %url line of the conf-nfs.<cluster-id> object that the
+// Ganesha gateways watch. Every export object starts with a comment holding
+// the export as JSON so that it can be read back without parsing Ganesha
+// configuration.
+//
+// Only the CEPH FSAL is rendered, the snap does not ship the Ganesha RGW FSAL
+// so buckets cannot be exported.
+const nfsExportSpecPrefix = "# microceph-export: "
+
+// nfsExportsLock is the RADOS lock on the conf-nfs object that serializes
+// changes to the exports of a cluster across members.
+const nfsExportsLock = "microceph-exports"
+
+// How long the exports lock is held at most, and how long to wait for it.
+const (
+	nfsExportsLockDuration = 60 * time.Second
+	nfsExportsLockTimeout  = 30 * time.Second
+)
+
+// nfsExportsLockRetry is the delay between attempts to take the exports lock.
+var nfsExportsLockRetry = time.Second
+
+// nfsExportURLRegex matches the %url lines of the conf-nfs object.
+var nfsExportURLRegex = regexp.MustCompile(`^%url\s+"?rados://\.nfs/[^/]+/export-(\d+)"?\s*$`)
+
+// Ganesha Squash values by the names accepted in NFSExport.
+var nfsSquashValues = map[string]string{
+	"none":    "No_Root_Squash",
+	"root":    "Root_Squash",
+	"root_id": "Root_Id_Squash",
+	"all":     "All_Squash",
+}
+
+var nfsAccessTypes = []string{"RW", "RO", "NONE"}
+
+// nfsConfObject is the object the Ganesha gateways of a cluster watch.
+func nfsConfObject(clusterID string) string {
+	return fmt.Sprintf("conf-nfs.%s", clusterID)
+}
+
+// nfsExportObject is the object holding the Ganesha configuration of an export.
+func nfsExportObject(exportID int) string {
+	return fmt.Sprintf("export-%d", exportID)
+}
+
+// nfsExportUser is the ceph client the Ganesha gateways access an export as.
+// Its prefix sets it apart from the gateway clients, nfs.<cluster>.<hostname>.
+func nfsExportUser(clusterID string, exportID int) string {
+	return fmt.Sprintf("nfs-export.%s.%d", clusterID, exportID)
+}
+
+// nfsRadosGet reads an object of the cluster's namespace in the .nfs pool.
+func nfsRadosGet(clusterID, object string) (string, error) {
+	return radosRun("get", "--pool", ".nfs", "-N", clusterID, object, "-")
+}
+
+// nfsRadosPut writes an object of the cluster's namespace in the .nfs pool.
+func nfsRadosPut(clusterID, object, content string) error {
+	f, err := os.CreateTemp("", "microceph-nfs-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(content)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	_, err = radosRun("put", "--pool", ".nfs", "-N", clusterID, object, f.Name())
+	return err
+}
+
+// nfsNotify asks the Ganesha gateways of the cluster to reload their exports.
+func nfsNotify(clusterID string) error {
+	object := nfsConfObject(clusterID)
+	_, err := radosRun("notify", "--pool", ".nfs", "-N", clusterID, object, object)
+	if err != nil {
+		return fmt.Errorf("failed to notify NFS gateways of cluster %s: %w", clusterID, err)
+	}
+
+	return nil
+}
+
+// checkNFSCluster verifies that an NFS cluster with the given ID is deployed.
+func checkNFSCluster(ctx context.Context, s interfaces.StateInterface, clusterID string) error {
+	services, err := database.GroupedServicesQuery.GetGroupedServices(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		if service.Service == "nfs" && service.GroupID == clusterID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("NFS cluster %s not found", clusterID)
+}
+
+// getNFSExportIDs reads the IDs of the exports referenced by the conf-nfs object.
+func getNFSExportIDs(clusterID string) ([]int, error) {
+	output, err := nfsRadosGet(clusterID, nfsConfObject(clusterID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read NFS configuration of cluster %s: %w", clusterID, err)
+	}
+
+	ids := []int{}
+	for _, line := range strings.Split(output, "\n") {
+		match := nfsExportURLRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		id, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// putNFSExportIDs rewrites the conf-nfs object to reference the given exports.
+// Lines other than export references, e.g. those of the mgr nfs module, are kept.
+func putNFSExportIDs(clusterID string, ids []int) error {
+	output, err := nfsRadosGet(clusterID, nfsConfObject(clusterID))
+	if err != nil {
+		return fmt.Errorf("failed to read NFS configuration of cluster %s: %w", clusterID, err)
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if len(strings.TrimSpace(line)) == 0 || nfsExportURLRegex.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	for _, id := range ids {
+		lines = append(lines, fmt.Sprintf("%%url \"rados://.nfs/%s/%s\"", clusterID, nfsExportObject(id)))
+	}
+
+	content := ""
+	if len(lines) != 0 {
+		content = strings.Join(lines, "\n") + "\n"
+	}
+
+	err = nfsRadosPut(clusterID, nfsConfObject(clusterID), content)
+	if err != nil {
+		return fmt.Errorf("failed to write NFS configuration of cluster %s: %w", clusterID, err)
+	}
+
+	return nil
+}
+
+// lockNFSExports takes the exports lock of a cluster, waiting for other
+// members to release it. The returned function releases the lock.
+func lockNFSExports(clusterID string) (func(), error) {
+	object := nfsConfObject(clusterID)
+	cookie := uuid.NewRandom().String()
+	duration := strconv.Itoa(int(nfsExportsLockDuration.Seconds()))
+
+	deadline := time.Now().Add(nfsExportsLockTimeout)
+	for {
+		_, err := radosRun("--pool", ".nfs", "-N", clusterID, "lock", "get", object, nfsExportsLock,
+			"--lock-cookie", cookie, "--lock-duration", duration)
+		if err == nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to lock the exports of NFS cluster %s: %w", clusterID, err)
+		}
+
+		time.Sleep(nfsExportsLockRetry)
+	}
+
+	return func() {
+		err := unlockNFSExports(clusterID, cookie)
+		if err != nil {
+			logger.Warnf("NFS: failed to unlock the exports of cluster %s, the lock expires in %s: %v", clusterID, nfsExportsLockDuration, err)
+		}
+	}, nil
+}
+
+// unlockNFSExports releases the exports lock held with the given cookie.
+func unlockNFSExports(clusterID string, cookie string) error {
+	object := nfsConfObject(clusterID)
+	output, err := radosRun("--pool", ".nfs", "-N", clusterID, "lock", "info", object, nfsExportsLock, "--format", "json")
+	if err != nil {
+		return err
+	}
+
+	for _, locker := range gjson.Get(output, "lockers").Array() {
+		if locker.Get("cookie").String() != cookie {
+			continue
+		}
+
+		_, err = radosRun("--pool", ".nfs", "-N", clusterID, "lock", "break", object, nfsExportsLock,
+			locker.Get("name").String(), "--lock-cookie", cookie)
+		return err
+	}
+
+	return fmt.Errorf("lock %s is not held", nfsExportsLock)
+}
+
+// parseNFSExport reads back the export recorded in an export object.
+func parseNFSExport(exportID int, content string) types.NFSExport {
+	for _, line := range strings.Split(content, "\n") {
+		spec, found := strings.CutPrefix(line, nfsExportSpecPrefix)
+		if !found {
+			continue
+		}
+
+		export := types.NFSExport{}
+		err := json.Unmarshal([]byte(spec), &export)
+		if err == nil {
+			return export
+		}
+	}
+
+	// Not created by MicroCeph, only the ID is known.
+	logger.Debugf("NFS: export %d has no MicroCeph record", exportID)
+	return types.NFSExport{ExportID: exportID}
+}
+
+// ListNFSExports fetches the exports of an NFS cluster.
+func ListNFSExports(ctx context.Context, s interfaces.StateInterface, clusterID string) (types.NFSExports, error) {
+	err := checkNFSCluster(ctx, s, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := getNFSExportIDs(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := types.NFSExports{}
+	for _, id := range ids {
+		content, err := nfsRadosGet(clusterID, nfsExportObject(id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read NFS export %d: %w", id, err)
+		}
+
+		exports = append(exports, parseNFSExport(id, content))
+	}
+
+	return exports, nil
+}
+
+// validateNFSExport checks an export and fills in its defaults.
+func validateNFSExport(export *types.NFSExport) error {
+	if !strings.HasPrefix(export.PseudoPath, "/") || export.PseudoPath == "/" {
+		return fmt.Errorf("pseudo path %q must be an absolute path other than /", export.PseudoPath)
+	}
+	export.PseudoPath = path.Clean(export.PseudoPath)
+
+	if len(export.FsName) == 0 {
+		return fmt.Errorf("a CephFS volume is required")
+	}
+
+	if len(export.Path) == 0 {
+		export.Path = "/"
+	}
+	if !strings.HasPrefix(export.Path, "/") {
+		return fmt.Errorf("path %q must be absolute", export.Path)
+	}
+	export.Path = path.Clean(export.Path)
+
+	if len(export.AccessType) == 0 {
+		export.AccessType = "RW"
+	}
+	if len(export.Squash) == 0 {
+		export.Squash = "none"
+	}
+
+	err := validateNFSAccess(export.AccessType, export.Squash)
+	if err != nil {
+		return err
+	}
+
+	for i := range export.Clients {
+		client := &export.Clients[i]
+		if len(client.Addresses) == 0 {
+			return fmt.Errorf("client rule %d has no addresses", i+1)
+		}
+
+		for _, address := range client.Addresses {
+			_, _, err := net.ParseCIDR(address)
+			if err != nil && net.ParseIP(address) == nil {
+				return fmt.Errorf("invalid client address %q, expected an IP address or CIDR", address)
+			}
+		}
+
+		if len(client.AccessType) == 0 {
+			client.AccessType = export.AccessType
+		}
+		if len(client.Squash) == 0 {
+			client.Squash = export.Squash
+		}
+
+		err := validateNFSAccess(client.AccessType, client.Squash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateNFSAccess checks an access type and squash value.
+func validateNFSAccess(accessType string, squash string) error {
+	if !slices.Contains(nfsAccessTypes, accessType) {
+		return fmt.Errorf("invalid access type %q, expected one of %s", accessType, strings.Join(nfsAccessTypes, ", "))
+	}
+
+	_, ok := nfsSquashValues[squash]
+	if !ok {
+		return fmt.Errorf("invalid squash %q, expected one of none, root, root_id, all", squash)
+	}
+
+	return nil
+}
+
+// renderNFSExport renders the Ganesha configuration of an export, preceded by its record.
+func renderNFSExport(clusterID string, export types.NFSExport, secret string) (string, error) {
+	spec, err := json.Marshal(export)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%s\n", nfsExportSpecPrefix, spec)
+	fmt.Fprintf(&b, "EXPORT {\n")
+	fmt.Fprintf(&b, "\tExport_Id = %d;\n", export.ExportID)
+	fmt.Fprintf(&b, "\tPath = %q;\n", export.Path)
+	fmt.Fprintf(&b, "\tPseudo = %q;\n", export.PseudoPath)
+	fmt.Fprintf(&b, "\tAccess_Type = %q;\n", export.AccessType)
+	fmt.Fprintf(&b, "\tSquash = %q;\n", nfsSquashValues[export.Squash])
+	fmt.Fprintf(&b, "\tProtocols = 4;\n")
+	fmt.Fprintf(&b, "\tTransports = \"TCP\";\n")
+	fmt.Fprintf(&b, "\tFSAL {\n")
+	fmt.Fprintf(&b, "\t\tName = \"CEPH\";\n")
+	fmt.Fprintf(&b, "\t\tFilesystem = %q;\n", export.FsName)
+	fmt.Fprintf(&b, "\t\tUser_Id = %q;\n", nfsExportUser(clusterID, export.ExportID))
+	fmt.Fprintf(&b, "\t\tSecret_Access_Key = %q;\n", secret)
+	fmt.Fprintf(&b, "\t}\n")
+	for _, client := range export.Clients {
+		fmt.Fprintf(&b, "\tCLIENT {\n")
+		fmt.Fprintf(&b, "\t\tClients = %s;\n", strings.Join(client.Addresses, ", "))
+		fmt.Fprintf(&b, "\t\tAccess_Type = %q;\n", client.AccessType)
+		fmt.Fprintf(&b, "\t\tSquash = %q;\n", nfsSquashValues[client.Squash])
+		fmt.Fprintf(&b, "\t}\n")
+	}
+	fmt.Fprintf(&b, "}\n")
+
+	return b.String(), nil
+}
+
+// nfsExportCaps are the caps `ceph fs authorize` grants on the exported path.
+func nfsExportCaps(export types.NFSExport) [][]string {
+	perm := "rw"
+	if export.AccessType != "RW" {
+		perm = "r"
+	}
+
+	return [][]string{
+		{"mon", fmt.Sprintf("allow r fsname=%s", export.FsName)},
+		{"mds", fmt.Sprintf("allow %s fsname=%s path=%s", perm, export.FsName, export.Path)},
+		{"osd", fmt.Sprintf("allow %s tag cephfs data=%s", perm, export.FsName)},
+	}
+}
+
+// authorizeNFSExport creates the ceph client of an export, or updates its caps
+// in place so that the gateways keep serving the export with the same key, and
+// returns its key.
+func authorizeNFSExport(clusterID string, export types.NFSExport) (string, error) {
+	user := nfsExportUser(clusterID, export.ExportID)
+	caps := nfsExportCaps(export)
+
+	key, err := cephRun("auth", "get-key", fmt.Sprintf("client.%s", user))
+	if err != nil {
+		key, err = CreateClientKey(user, caps...)
+		if err != nil {
+			return "", fmt.Errorf("failed to authorize NFS export on %s:%s: %w", export.FsName, export.Path, err)
+		}
+
+		return strings.TrimSpace(key), nil
+	}
+
+	args := []string{"auth", "caps", fmt.Sprintf("client.%s", user)}
+	for _, capability := range caps {
+		args = append(args, capability...)
+	}
+
+	_, err = cephRun(args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to authorize NFS export on %s:%s: %w", export.FsName, export.Path, err)
+	}
+
+	return strings.TrimSpace(key), nil
+}
+
+// writeNFSExport authorizes and stores an export, then has the gateways reload.
+func writeNFSExport(clusterID string, export types.NFSExport) error {
+	secret, err := authorizeNFSExport(clusterID, export)
+	if err != nil {
+		return err
+	}
+
+	content, err := renderNFSExport(clusterID, export, secret)
+	if err != nil {
+		return err
+	}
+
+	err = nfsRadosPut(clusterID, nfsExportObject(export.ExportID), content)
+	if err != nil {
+		return fmt.Errorf("failed to write NFS export %d: %w", export.ExportID, err)
+	}
+
+	return nil
+}
+
+// CreateNFSExport adds an export to an NFS cluster and returns it with its ID.
+func CreateNFSExport(ctx context.Context, s interfaces.StateInterface, clusterID string, export types.NFSExport) (types.NFSExport, error) {
+	err := validateNFSExport(&export)
+	if err != nil {
+		return types.NFSExport{}, err
+	}
+
+	unlock, err := lockNFSExports(clusterID)
+	if err != nil {
+		return types.NFSExport{}, err
+	}
+	defer unlock()
+
+	exports, err := ListNFSExports(ctx, s, clusterID)
+	if err != nil {
+		return types.NFSExport{}, err
+	}
+
+	ids := []int{}
+	export.ExportID = 1
+	for _, existing := range exports {
+		if existing.PseudoPath == export.PseudoPath {
+			return types.NFSExport{}, fmt.Errorf("pseudo path %s is already exported by export %d", export.PseudoPath, existing.ExportID)
+		}
+
+		ids = append(ids, existing.ExportID)
+		export.ExportID = max(export.ExportID, existing.ExportID+1)
+	}
+
+	err = writeNFSExport(clusterID, export)
+	if err != nil {
+		return types.NFSExport{}, err
+	}
+
+	err = putNFSExportIDs(clusterID, append(ids, export.ExportID))
+	if err != nil {
+		return types.NFSExport{}, err
+	}
+
+	logger.Infof("NFS: created export %d (%s) of cluster %s", export.ExportID, export.PseudoPath, clusterID)
+	return export, nfsNotify(clusterID)
+}
+
+// UpdateNFSExport replaces an export of an NFS cluster.
+func UpdateNFSExport(ctx context.Context, s interfaces.StateInterface, clusterID string, export types.NFSExport) error {
+	err := validateNFSExport(&export)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockNFSExports(clusterID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	exports, err := ListNFSExports(ctx, s, clusterID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, existing := range exports {
+		if existing.ExportID == export.ExportID {
+			found = true
+		} else if existing.PseudoPath == export.PseudoPath {
+			return fmt.Errorf("pseudo path %s is already exported by export %d", export.PseudoPath, existing.ExportID)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("NFS export %d not found in cluster %s", export.ExportID, clusterID)
+	}
+
+	err = writeNFSExport(clusterID, export)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("NFS: updated export %d (%s) of cluster %s", export.ExportID, export.PseudoPath, clusterID)
+	return nfsNotify(clusterID)
+}
+
+// DeleteNFSExport removes an export from an NFS cluster.
+func DeleteNFSExport(ctx context.Context, s interfaces.StateInterface, clusterID string, exportID int) error {
+	err := checkNFSCluster(ctx, s, clusterID)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockNFSExports(clusterID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ids, err := getNFSExportIDs(clusterID)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(ids, exportID) {
+		return fmt.Errorf("NFS export %d not found in cluster %s", exportID, clusterID)
+	}
+
+	// Unreference the export first so that the gateways drop it.
+	err = putNFSExportIDs(clusterID, slices.DeleteFunc(ids, func(id int) bool { return id == exportID }))
+	if err != nil {
+		return err
+	}
+
+	err = nfsNotify(clusterID)
+	if err != nil {
+		return err
+	}
+
+	_, err = radosRun("rm", "--pool", ".nfs", "-N", clusterID, nfsExportObject(exportID))
+	if err != nil {
+		return fmt.Errorf("failed to remove NFS export %d: %w", exportID, err)
+	}
+
+	err = DeleteClientKey(nfsExportUser(clusterID, exportID))
+	if err != nil {
+		logger.Warnf("NFS: failed to remove client of export %d: %v", exportID, err)
+	}
+
+	logger.Infof("NFS: removed export %d of cluster %s", exportID, clusterID)
+	return nil
+}
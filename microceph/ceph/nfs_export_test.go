@@ -0,0 +1,168 @@
+package ceph
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type nfsExportSuite struct {
+	tests.BaseSuite
+}
+
+func TestNFSExport(t *testing.T) {
+	suite.Run(t, new(nfsExportSuite))
+}
+
+func (s *nfsExportSuite) TestValidateNFSExportDefaults() {
+	export := types.NFSExport{
+		PseudoPath: "/data/",
+		FsName:     "vol",
+		Clients:    []types.NFSExportClient{{Addresses: []string{"10.0.0.0/24", "fd00::1"}, AccessType: "RO"}},
+	}
+
+	assert.NoError(s.T(), validateNFSExport(&export))
+	assert.Equal(s.T(), "/data", export.PseudoPath)
+	assert.Equal(s.T(), "/", export.Path)
+	assert.Equal(s.T(), "RW", export.AccessType)
+	assert.Equal(s.T(), "none", export.Squash)
+	assert.Equal(s.T(), "RO", export.Clients[0].AccessType)
+	assert.Equal(s.T(), "none", export.Clients[0].Squash)
+}
+
+func (s *nfsExportSuite) TestValidateNFSExportErrors() {
+	for name, export := range map[string]types.NFSExport{
+		"root pseudo path":  {PseudoPath: "/", FsName: "vol"},
+		"relative path":     {PseudoPath: "/data", FsName: "vol", Path: "dir"},
+		"missing volume":    {PseudoPath: "/data"},
+		"bad access type":   {PseudoPath: "/data", FsName: "vol", AccessType: "RX"},
+		"bad squash":        {PseudoPath: "/data", FsName: "vol", Squash: "everyone"},
+		"bad client":        {PseudoPath: "/data", FsName: "vol", Clients: []types.NFSExportClient{{Addresses: []string{"nohost"}}}},
+		"client no address": {PseudoPath: "/data", FsName: "vol", Clients: []types.NFSExportClient{{}}},
+	} {
+		assert.Error(s.T(), validateNFSExport(&export), name)
+	}
+}
+
+func (s *nfsExportSuite) TestRenderParseNFSExport() {
+	export := types.NFSExport{
+		ExportID:   3,
+		PseudoPath: "/data",
+		FsName:     "vol",
+		Path:       "/dir",
+		AccessType: "RW",
+		Squash:     "root",
+		Clients:    []types.NFSExportClient{{Addresses: []string{"10.0.0.0/24"}, AccessType: "RO", Squash: "all"}},
+	}
+
+	content, err := renderNFSExport("foo", export, "secret")
+	assert.NoError(s.T(), err)
+	assert.Contains(s.T(), content, "\tSquash = \"Root_Squash\";\n")
+	assert.Contains(s.T(), content, "\t\tUser_Id = \"nfs-export.foo.3\";\n")
+	assert.Contains(s.T(), content, "\t\tClients = 10.0.0.0/24;\n")
+
+	assert.Equal(s.T(), export, parseNFSExport(3, content))
+
+	// Exports not created by MicroCeph only carry their ID.
+	assert.Equal(s.T(), types.NFSExport{ExportID: 7}, parseNFSExport(7, "EXPORT {\n}\n"))
+}
+
+func (s *nfsExportSuite) TestGetNFSExportIDs() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", []interface{}{
+		"rados", "get", "--pool", ".nfs", "-N", "foo", "conf-nfs.foo", "-"}...).Return(
+		"%url \"rados://.nfs/foo/export-1\"\n%url rados://.nfs/foo/export-4\n# comment\n", nil).Once()
+	common.ProcessExec = r
+
+	ids, err := getNFSExportIDs("foo")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int{1, 4}, ids)
+}
+
+func (s *nfsExportSuite) TestPutNFSExportIDsKeepsOtherLines() {
+	content := ""
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", []interface{}{
+		"rados", "get", "--pool", ".nfs", "-N", "foo", "conf-nfs.foo", "-"}...).Return(
+		"%url \"rados://.nfs/foo/export-1\"\n%url \"rados://.nfs/foo/userconf-nfs.foo\"\n", nil).Once()
+	r.On("RunCommand", []interface{}{
+		"rados", "put", "--pool", ".nfs", "-N", "foo", "conf-nfs.foo", mock.Anything}...).Return("", nil).Run(func(args mock.Arguments) {
+		data, err := os.ReadFile(args.String(7))
+		assert.NoError(s.T(), err)
+		content = string(data)
+	}).Once()
+	common.ProcessExec = r
+
+	assert.NoError(s.T(), putNFSExportIDs("foo", []int{1, 2}))
+	assert.Equal(s.T(), "%url \"rados://.nfs/foo/userconf-nfs.foo\"\n%url \"rados://.nfs/foo/export-1\"\n%url \"rados://.nfs/foo/export-2\"\n", content)
+}
+
+func (s *nfsExportSuite) TestLockNFSExports() {
+	cookie := ""
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", []interface{}{
+		"rados", "--pool", ".nfs", "-N", "foo", "lock", "get", "conf-nfs.foo", "microceph-exports",
+		"--lock-cookie", mock.Anything, "--lock-duration", "60"}...).Return("", fmt.Errorf("busy")).Once()
+	r.On("RunCommand", []interface{}{
+		"rados", "--pool", ".nfs", "-N", "foo", "lock", "get", "conf-nfs.foo", "microceph-exports",
+		"--lock-cookie", mock.Anything, "--lock-duration", "60"}...).Return("", nil).Run(func(args mock.Arguments) {
+		cookie = args.String(10)
+	}).Once()
+	r.On("RunCommand", []interface{}{
+		"rados", "--pool", ".nfs", "-N", "foo", "lock", "info", "conf-nfs.foo", "microceph-exports", "--format", "json"}...).Return(
+		func(string, ...string) (string, error) {
+			return fmt.Sprintf(`{"name": "microceph-exports", "lockers": [{"name": "client.4121", "cookie": %q}]}`, cookie), nil
+		}).Once()
+	r.On("RunCommand", []interface{}{
+		"rados", "--pool", ".nfs", "-N", "foo", "lock", "break", "conf-nfs.foo", "microceph-exports",
+		"client.4121", "--lock-cookie", mock.Anything}...).Return("", nil).Once()
+	common.ProcessExec = r
+
+	retry := nfsExportsLockRetry
+	nfsExportsLockRetry = 0
+	defer func() { nfsExportsLockRetry = retry }()
+
+	unlock, err := lockNFSExports("foo")
+	assert.NoError(s.T(), err)
+	assert.NotEmpty(s.T(), cookie)
+	unlock()
+}
+
+func (s *nfsExportSuite) TestAuthorizeNFSExportCreates() {
+	export := types.NFSExport{ExportID: 3, FsName: "vol", Path: "/dir", AccessType: "RO"}
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "auth", "get-key", "client.nfs-export.foo.3").Return("", fmt.Errorf("ENOENT")).Once()
+	r.On("RunCommand", "ceph", "auth", "get-or-create", "client.nfs-export.foo.3",
+		"mon", "allow r fsname=vol", "mds", "allow r fsname=vol path=/dir", "osd", "allow r tag cephfs data=vol").Return("", nil).Once()
+	r.On("RunCommand", "ceph", "auth", "print-key", "client.nfs-export.foo.3").Return("KEY\n", nil).Once()
+	common.ProcessExec = r
+
+	key, err := authorizeNFSExport("foo", export)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "KEY", key)
+}
+
+func (s *nfsExportSuite) TestAuthorizeNFSExportUpdatesInPlace() {
+	export := types.NFSExport{ExportID: 3, FsName: "vol", Path: "/other", AccessType: "RW"}
+
+	// The key is kept, no auth del.
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "auth", "get-key", "client.nfs-export.foo.3").Return("KEY\n", nil).Once()
+	r.On("RunCommand", "ceph", "auth", "caps", "client.nfs-export.foo.3",
+		"mon", "allow r fsname=vol", "mds", "allow rw fsname=vol path=/other", "osd", "allow rw tag cephfs data=vol").Return("", nil).Once()
+	common.ProcessExec = r
+
+	key, err := authorizeNFSExport("foo", export)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "KEY", key)
+}
@@ -0,0 +1,266 @@
+package ceph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// adminGraceRun runs ganesha-rados-grace against the grace database of an NFS
+// cluster as the admin client, so that it works on members without a gateway.
+func adminGraceRun(clusterID string, args ...string) (string, error) {
+	cephConf := filepath.Join(constants.GetPathConst().ConfPath, "ceph.conf")
+	args = append([]string{"--cephconf", cephConf, "--pool", ".nfs", "--ns", clusterID, "--userid", "admin"}, args...)
+	return ganeshaRadosGraceRun(args...)
+}
+
+// parseGraceDump parses the output of ganesha-rados-grace dump, a header
+// line with the epochs followed by one line per node with its flags.
+func parseGraceDump(output string) (types.NFSGraceStatus, error) {
+	grace := types.NFSGraceStatus{Entries: []types.NFSGraceEntry{}}
+
+	lines := strings.Split(output, "\n")
+	for _, field := range strings.Fields(lines[0]) {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+
+		epoch, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return grace, fmt.Errorf("failed to parse grace epoch %q: %w", field, err)
+		}
+
+		switch key {
+		case "cur":
+			grace.CurrentEpoch = epoch
+		case "rec":
+			grace.RecoveryEpoch = epoch
+		}
+	}
+
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "=") {
+			continue
+		}
+
+		flags := strings.Join(fields[1:], "")
+		grace.Entries = append(grace.Entries, types.NFSGraceEntry{
+			Node:       fields[0],
+			NeedsGrace: strings.Contains(flags, "N"),
+			Enforcing:  strings.Contains(flags, "E"),
+		})
+	}
+
+	return grace, nil
+}
+
+// nfsMemberClients returns a client for every cluster member.
+var nfsMemberClients = getMemberClients
+
+// fetchNFSNodeStatus asks a member for the gateway it runs for an NFS cluster.
+var fetchNFSNodeStatus = func(ctx context.Context, c mcTypes.Client, clusterID string) (types.NFSNodeStatus, error) {
+	return client.GetNFSNodeStatus(ctx, c, clusterID)
+}
+
+// GetLocalNFSNodeStatus reports the gateway this member runs for an NFS
+// cluster. Gateways enabled before the hostname was recorded get it
+// backfilled, it is the name they register in the grace database.
+func GetLocalNFSNodeStatus(ctx context.Context, s interfaces.StateInterface, clusterID string) (types.NFSNodeStatus, error) {
+	services, err := database.GroupedServicesQuery.GetGroupedServicesOnHost(ctx, s)
+	if err != nil {
+		return types.NFSNodeStatus{}, err
+	}
+
+	for _, service := range services {
+		if service.Service != "nfs" || service.GroupID != clusterID {
+			continue
+		}
+
+		info := database.NFSServiceInfo{}
+		err := json.Unmarshal([]byte(service.Info), &info)
+		if err != nil {
+			return types.NFSNodeStatus{}, fmt.Errorf("failed to parse NFS service info of %s: %w", service.Member, err)
+		}
+
+		if len(info.Hostname) == 0 {
+			info.Hostname, err = os.Hostname()
+			if err != nil {
+				return types.NFSNodeStatus{}, err
+			}
+
+			err = database.GroupedServicesQuery.UpdateInfoOnHost(ctx, s, "nfs", clusterID, info)
+			if err != nil {
+				return types.NFSNodeStatus{}, fmt.Errorf("failed to record the hostname of the NFS gateway: %w", err)
+			}
+
+			logger.Infof("NFS: recorded hostname %s of the gateway of cluster %s", info.Hostname, clusterID)
+		}
+
+		node := types.NFSNodeStatus{
+			Member:      service.Member,
+			Hostname:    info.Hostname,
+			BindAddress: info.BindAddress,
+			BindPort:    info.BindPort,
+			State:       "active",
+		}
+		if snapCheckActive("nfs") != nil {
+			node.State = "inactive"
+		}
+
+		return node, nil
+	}
+
+	return types.NFSNodeStatus{}, fmt.Errorf("NFS cluster %s has no gateway on %s", clusterID, s.ClusterState().Name())
+}
+
+// GetNFSClusterStatus reports the gateways of an NFS cluster and its grace
+// database. Every member running a gateway is asked for its service state,
+// unreachable ones are reported as such.
+func GetNFSClusterStatus(ctx context.Context, s interfaces.StateInterface, clusterID string) (types.NFSClusterStatus, error) {
+	status := types.NFSClusterStatus{ClusterID: clusterID, Nodes: []types.NFSNodeStatus{}}
+
+	services, err := database.GroupedServicesQuery.GetGroupedServices(ctx, s)
+	if err != nil {
+		return status, err
+	}
+
+	var clients map[string]mcTypes.Client
+	for _, service := range services {
+		if service.Service != "nfs" || service.GroupID != clusterID {
+			continue
+		}
+
+		info := database.NFSServiceInfo{}
+		err := json.Unmarshal([]byte(service.Info), &info)
+		if err != nil {
+			return status, fmt.Errorf("failed to parse NFS service info of %s: %w", service.Member, err)
+		}
+
+		node := types.NFSNodeStatus{
+			Member:      service.Member,
+			Hostname:    info.Hostname,
+			BindAddress: info.BindAddress,
+			BindPort:    info.BindPort,
+			State:       "unreachable",
+		}
+
+		var local types.NFSNodeStatus
+		if service.Member == s.ClusterState().Name() {
+			local, err = GetLocalNFSNodeStatus(ctx, s, clusterID)
+		} else {
+			if clients == nil {
+				clients, err = nfsMemberClients(s)
+				if err != nil {
+					logger.Warnf("NFS: %v", err)
+					clients = map[string]mcTypes.Client{}
+				}
+			}
+
+			c, ok := clients[service.Member]
+			if ok {
+				local, err = fetchNFSNodeStatus(ctx, c, clusterID)
+			} else {
+				err = fmt.Errorf("member is not reachable")
+			}
+		}
+
+		if err != nil {
+			logger.Debugf("NFS: unable to fetch the gateway status of %s: %v", service.Member, err)
+		} else {
+			node.State = local.State
+			node.Hostname = local.Hostname
+		}
+
+		status.Nodes = append(status.Nodes, node)
+	}
+
+	if len(status.Nodes) == 0 {
+		return status, fmt.Errorf("NFS cluster %s not found", clusterID)
+	}
+
+	output, err := adminGraceRun(clusterID, "dump")
+	if err != nil {
+		return status, fmt.Errorf("failed to dump the grace database of NFS cluster %s: %w", clusterID, err)
+	}
+
+	status.Grace, err = parseGraceDump(output)
+	if err != nil {
+		return status, err
+	}
+
+	// A gateway with an unknown hostname may register under any name, no
+	// entry can be told stale then.
+	hostnames := map[string]bool{}
+	for _, node := range status.Nodes {
+		if len(node.Hostname) == 0 {
+			return status, nil
+		}
+
+		hostnames[node.Hostname] = true
+	}
+
+	for i := range status.Grace.Entries {
+		status.Grace.Entries[i].Stale = !hostnames[status.Grace.Entries[i].Node]
+	}
+
+	return status, nil
+}
+
+// RepairNFSCluster removes the grace database entries, and the ceph clients,
+// of nodes no longer backed by a gateway of the NFS cluster. Such nodes are
+// left behind by a failed DisableNFS and keep the cluster from lifting grace.
+func RepairNFSCluster(ctx context.Context, s interfaces.StateInterface, clusterID string) ([]string, error) {
+	status, err := GetNFSClusterStatus(ctx, s, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	unknown := []string{}
+	for _, node := range status.Nodes {
+		if len(node.Hostname) == 0 {
+			unknown = append(unknown, node.Member)
+		}
+	}
+
+	if len(unknown) != 0 {
+		return nil, fmt.Errorf("cannot tell stale nodes apart, the gateways on %s have no recorded hostname and could not be reached to record it", strings.Join(unknown, ", "))
+	}
+
+	removed := []string{}
+	for _, entry := range status.Grace.Entries {
+		if !entry.Stale {
+			continue
+		}
+
+		logger.Infof("NFS: removing stale node %s from the grace database of cluster %s", entry.Node, clusterID)
+		_, err := adminGraceRun(clusterID, "remove", entry.Node)
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove node %s from the grace database: %w", entry.Node, err)
+		}
+
+		// The ceph client may already be gone, removal is best effort.
+		err = DeleteClientKey(fmt.Sprintf("nfs.%s.%s", clusterID, entry.Node))
+		if err != nil {
+			logger.Warnf("NFS: failed to remove ceph client of stale node %s: %v", entry.Node, err)
+		}
+
+		removed = append(removed, entry.Node)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,141 @@
+package ceph
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+const graceDump = `cur=4 rec=3
+======================================================
+node0	NE
+node1
+node9	N
+`
+
+type nfsStatusSuite struct {
+	tests.BaseSuite
+}
+
+func TestNFSStatus(t *testing.T) {
+	suite.Run(t, new(nfsStatusSuite))
+}
+
+func (s *nfsStatusSuite) TestParseGraceDump() {
+	grace, err := parseGraceDump(graceDump)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), uint64(4), grace.CurrentEpoch)
+	assert.Equal(s.T(), uint64(3), grace.RecoveryEpoch)
+	assert.Equal(s.T(), []types.NFSGraceEntry{
+		{Node: "node0", NeedsGrace: true, Enforcing: true},
+		{Node: "node1"},
+		{Node: "node9", NeedsGrace: true},
+	}, grace.Entries)
+}
+
+// nfsStatusServices are the gateways of the foo cluster, node1 predates recording the hostname.
+var nfsStatusServices = []database.GroupedService{
+	{Service: "nfs", GroupID: "foo", Member: "member0", Info: `{"bind_address":"0.0.0.0","bind_port":2049,"hostname":"node0"}`},
+	{Service: "nfs", GroupID: "foo", Member: "node1", Info: `{"bind_address":"0.0.0.0","bind_port":2049}`},
+	{Service: "nfs", GroupID: "bar", Member: "node2", Info: `{"bind_address":"0.0.0.0","bind_port":2049}`},
+}
+
+// mockNFSMembers makes node1 reachable, reporting the given gateway, or unreachable when nil.
+func mockNFSMembers(node1 *types.NFSNodeStatus) func() {
+	memberClients := nfsMemberClients
+	fetch := fetchNFSNodeStatus
+
+	nfsMemberClients = func(s interfaces.StateInterface) (map[string]mcTypes.Client, error) {
+		if node1 == nil {
+			return map[string]mcTypes.Client{}, nil
+		}
+
+		return map[string]mcTypes.Client{"node1": nil}, nil
+	}
+	fetchNFSNodeStatus = func(ctx context.Context, c mcTypes.Client, clusterID string) (types.NFSNodeStatus, error) {
+		return *node1, nil
+	}
+
+	return func() {
+		nfsMemberClients = memberClients
+		fetchNFSNodeStatus = fetch
+	}
+}
+
+func (s *nfsStatusSuite) TestRepairRemovesStaleNodes() {
+	state := interfaces.CephState{State: &mocks.MockState{ClusterName: "member0"}}
+	ctx := context.Background()
+
+	db := mocks.NewGroupedServiceQueryIntf(s.T())
+	db.On("GetGroupedServices", ctx, state).Return(nfsStatusServices, nil).Once()
+	db.On("GetGroupedServicesOnHost", ctx, state).Return(nfsStatusServices[:1], nil).Once()
+
+	originalDB := database.GroupedServicesQuery
+	defer func() { database.GroupedServicesQuery = originalDB }()
+	database.GroupedServicesQuery = db
+
+	// node1 records its hostname when asked for its gateway.
+	defer mockNFSMembers(&types.NFSNodeStatus{Member: "node1", Hostname: "node1", State: "active"})()
+
+	grace := []interface{}{"ganesha-rados-grace", "--cephconf", filepath.Join(constants.GetPathConst().ConfPath, "ceph.conf"), "--pool", ".nfs", "--ns", "foo", "--userid", "admin"}
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "snapctl", "services", "microceph.nfs").Return("microceph.nfs enabled active", nil).Once()
+	r.On("RunCommand", append(grace, "dump")...).Return(graceDump, nil).Once()
+	r.On("RunCommand", append(grace, "remove", "node9")...).Return("", nil).Once()
+	r.On("RunCommand", "ceph", "auth", "del", "client.nfs.foo.node9").Return("", nil).Once()
+	common.ProcessExec = r
+
+	removed, err := RepairNFSCluster(ctx, state, "foo")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"node9"}, removed)
+}
+
+func (s *nfsStatusSuite) TestRepairRefusesUnknownHostnames() {
+	state := interfaces.CephState{State: &mocks.MockState{ClusterName: "member0"}}
+	ctx := context.Background()
+
+	db := mocks.NewGroupedServiceQueryIntf(s.T())
+	db.On("GetGroupedServices", ctx, state).Return(nfsStatusServices, nil).Once()
+	db.On("GetGroupedServicesOnHost", ctx, state).Return(nfsStatusServices[:1], nil).Once()
+
+	originalDB := database.GroupedServicesQuery
+	defer func() { database.GroupedServicesQuery = originalDB }()
+	database.GroupedServicesQuery = db
+
+	defer mockNFSMembers(nil)()
+
+	grace := []interface{}{"ganesha-rados-grace", "--cephconf", filepath.Join(constants.GetPathConst().ConfPath, "ceph.conf"), "--pool", ".nfs", "--ns", "foo", "--userid", "admin"}
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "snapctl", "services", "microceph.nfs").Return("microceph.nfs enabled active", nil).Once()
+	r.On("RunCommand", append(grace, "dump")...).Return(graceDump, nil).Once()
+	common.ProcessExec = r
+
+	status, err := GetNFSClusterStatus(ctx, state, "foo")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "unreachable", status.Nodes[1].State)
+	for _, entry := range status.Grace.Entries {
+		assert.False(s.T(), entry.Stale, entry.Node)
+	}
+
+	db.On("GetGroupedServices", ctx, state).Return(nfsStatusServices, nil).Once()
+	db.On("GetGroupedServicesOnHost", ctx, state).Return(nfsStatusServices[:1], nil).Once()
+	r.On("RunCommand", "snapctl", "services", "microceph.nfs").Return("microceph.nfs enabled active", nil).Once()
+	r.On("RunCommand", append(grace, "dump")...).Return(graceDump, nil).Once()
+
+	_, err = RepairNFSCluster(ctx, state, "foo")
+	assert.ErrorContains(s.T(), err, "gateways on node1 have no recorded hostname")
+}
@@ -334,12 +334,12 @@ func (m *OSDManager) CheckEncryptSupport() error {
 }
 
 // switchFailureDomain switches the crush rules failure domain from old to new
-func (m *OSDManager) switchFailureDomain(old string, new string) error {
+func (m *OSDManager) switchFailureDomain(ctx context.Context, old string, new string) error {
 	var err error
 
 	newRule := fmt.Sprintf("microceph_auto_%s", new)
 	logger.Debugf("Setting default crush rule to %v", newRule)
-	err = setDefaultCrushRule(newRule)
+	err = setDefaultCrushRule(ctx, interfaces.CephState{State: m.state}, newRule)
 	if err != nil {
 		return err
 	}
@@ -454,12 +454,12 @@ func (m *OSDManager) updateTopology(ctx context.Context) error {
 				return fmt.Errorf("failed to add rack crush rule: %w", err)
 			}
 		}
-		err = m.switchFailureDomain("osd", "rack")
+		err = m.switchFailureDomain(ctx, "osd", "rack")
 		if err != nil {
 			return fmt.Errorf("failed to switch to rack failure domain: %w", err)
 		}
 		// Also switch any pools that were on host domain to rack.
-		err = m.switchFailureDomain("host", "rack")
+		err = m.switchFailureDomain(ctx, "host", "rack")
 		if err != nil {
 			return fmt.Errorf("failed to switch host pools to rack failure domain: %w", err)
 		}
@@ -492,7 +492,7 @@ func (m *OSDManager) updateFailureDomain(ctx context.Context, s mcTypes.State) e
 
 	if numNodes >= 3 {
 		logger.Infof("We have %d nodes, switching failure domain to host", numNodes)
-		err = m.switchFailureDomain("osd", "host")
+		err = m.switchFailureDomain(ctx, "osd", "host")
 		if err != nil {
 			return fmt.Errorf("failed to set host failure domain: %w", err)
 		}
@@ -1919,7 +1919,7 @@ func scaleDownFailureDomain(ctx context.Context, s interfaces.StateInterface, os
 	if !needDowngrade {
 		return nil
 	}
-	err = m.switchFailureDomain("host", "osd")
+	err = m.switchFailureDomain(ctx, "host", "osd")
 	if err != nil {
 		return fmt.Errorf("failed to switch failure domain: %w", err)
 	}
@@ -173,9 +173,39 @@ func addOsdPoolSetExpectations(r *mocks.Runner) {
 
 // Expect: run ceph config set
 func addSetDefaultRuleExpectations(r *mocks.Runner) {
+	// the previous default rule is fetched for the config history
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return("[]", nil).Once()
 	r.On("RunCommand", tests.CmdAny("ceph", 7)...).Return("ok", nil).Once()
 }
 
+// stubConfigHistory records the config changes made by a test instead of
+// writing them to the database, tracked values are dropped.
+func stubConfigHistory(t *testing.T) *types.ConfigChanges {
+	orig := database.RecordConfigChangeDb
+	origGet := database.GetConfigItemDb
+	origSet := database.SetConfigItemDb
+	t.Cleanup(func() {
+		database.RecordConfigChangeDb = orig
+		database.GetConfigItemDb = origGet
+		database.SetConfigItemDb = origSet
+	})
+
+	database.GetConfigItemDb = func(_ context.Context, _ mcTypes.State, _ string) (string, error) {
+		return "", nil
+	}
+	database.SetConfigItemDb = func(_ context.Context, _ mcTypes.State, _ string, _ string) error {
+		return nil
+	}
+
+	changes := types.ConfigChanges{}
+	database.RecordConfigChangeDb = func(_ context.Context, _ mcTypes.State, change types.ConfigChange) error {
+		changes = append(changes, change)
+		return nil
+	}
+
+	return &changes
+}
+
 // Expect: run ceph osd tree
 func addOsdTreeExpectations(r *mocks.Runner) {
 	json := `{
@@ -286,11 +316,18 @@ func (s *osdSuite) TestSwitchHostFailureDomain() {
 	addOsdPoolSetExpectations(r)
 
 	common.ProcessExec = r
+	changes := stubConfigHistory(s.T())
 
-	mgr := NewOSDManager(nil)
+	mgr := NewOSDManager(&mocks.MockState{URL: api.NewURL(), ClusterName: "foohost"})
 	mgr.fs = afero.NewMemMapFs()
-	err := mgr.switchFailureDomain("osd", "host")
+	err := mgr.switchFailureDomain(context.Background(), "osd", "host")
 	assert.NoError(s.T(), err)
+
+	// The default rule change is recorded on behalf of MicroCeph.
+	assert.Len(s.T(), *changes, 1)
+	assert.Equal(s.T(), "osd_pool_default_crush_rule", (*changes)[0].Key)
+	assert.Equal(s.T(), "microceph", (*changes)[0].Requester)
+	assert.Nil(s.T(), (*changes)[0].OldValue)
 }
 
 // TestUpdateFailureDomain tests the updateFailureDomain function
@@ -320,6 +357,7 @@ func (s *osdSuite) TestUpdateFailureDomain() {
 	addOsdPoolSetExpectations(r)
 
 	common.ProcessExec = r
+	stubConfigHistory(s.T())
 
 	c := mocks.NewMemberCounterInterface(s.T())
 	c.On("Count", mock.Anything).Return(3, nil).Once()
@@ -538,7 +538,7 @@ func GetPlacementStatus(ctx context.Context, s interfaces.StateInterface) (*type
 			return err
 		}
 		for _, gs := range groupedServices {
-			if gs.Service != "nfs" {
+			if gs.Service != "nfs" && gs.Service != "rgw" {
 				continue
 			}
 			om, ok := observedByMember[gs.Member]
@@ -546,6 +546,11 @@ func GetPlacementStatus(ctx context.Context, s interfaces.StateInterface) (*type
 				om = &types.PlacementObservedMember{Member: gs.Member}
 				observedByMember[gs.Member] = om
 			}
+			if gs.Service == "rgw" {
+				// Grouped RGW daemons count as RGW on the member.
+				om.Rgw = true
+				continue
+			}
 			om.Nfs = append(om.Nfs, gs.GroupID)
 		}
 
@@ -0,0 +1,327 @@
+package ceph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// quorumDatabaseTimeout bounds the update of the database after a quorum
+// recovery, the database may have lost its quorum along with the monitors.
+var quorumDatabaseTimeout = 30 * time.Second
+
+// quorumMonTimeout bounds the wait on the local monitor to form a quorum with
+// the reduced monmap.
+var quorumMonTimeout = 5 * time.Minute
+
+// quorumDatabaseError reports the records of the removed monitors could not
+// be dropped from the database, after their removal from the monmap if any.
+func quorumDatabaseError(removed []string, remove []string, err error) error {
+	err = fmt.Errorf("the MicroCeph database was not updated: %w, run 'microceph cluster recover-quorum --remove %s' again once it is available", err, strings.Join(remove, ","))
+	if len(removed) != 0 {
+		return fmt.Errorf("removed %s from the monmap but %w", strings.Join(removed, ", "), err)
+	}
+
+	return err
+}
+
+// parseMonStatus reads the monitors of the monmap from the mon_status of the
+// monitor of a member. While no quorum is formed, the monitors outside of it
+// are those the monitor is in contact with.
+func parseMonStatus(member string, output string) (types.QuorumStatus, error) {
+	var monStatus struct {
+		State         string   `json:"state"`
+		Quorum        []int    `json:"quorum"`
+		OutsideQuorum []string `json:"outside_quorum"`
+		Monmap        struct {
+			Mons []struct {
+				Rank       int    `json:"rank"`
+				Name       string `json:"name"`
+				Addr       string `json:"addr"`
+				PublicAddr string `json:"public_addr"`
+			} `json:"mons"`
+		} `json:"monmap"`
+	}
+
+	err := json.Unmarshal([]byte(output), &monStatus)
+	if err != nil {
+		return types.QuorumStatus{}, fmt.Errorf("failed to parse mon_status of mon.%s: %w", member, err)
+	}
+
+	if len(monStatus.Monmap.Mons) == 0 {
+		return types.QuorumStatus{}, fmt.Errorf("mon_status of mon.%s holds no monitors", member)
+	}
+
+	quorum := map[int]bool{}
+	for _, rank := range monStatus.Quorum {
+		quorum[rank] = true
+	}
+
+	outside := map[string]bool{}
+	for _, name := range monStatus.OutsideQuorum {
+		outside[name] = true
+	}
+
+	status := types.QuorumStatus{Member: member, State: monStatus.State, Monitors: []types.QuorumMonitor{}}
+	for _, mon := range monStatus.Monmap.Mons {
+		address := mon.PublicAddr
+		if len(address) == 0 {
+			address = mon.Addr
+		}
+
+		inQuorum := quorum[mon.Rank]
+		status.Monitors = append(status.Monitors, types.QuorumMonitor{
+			Name:      mon.Name,
+			Address:   address,
+			InQuorum:  inQuorum,
+			Reachable: inQuorum || outside[mon.Name] || mon.Name == member,
+		})
+	}
+
+	return status, nil
+}
+
+// GetQuorumStatus asks the local monitor for the monitor quorum through its
+// admin socket, which answers without a quorum.
+func GetQuorumStatus(s interfaces.StateInterface) (types.QuorumStatus, error) {
+	member := s.ClusterState().Name()
+	output, err := common.ProcessExec.RunCommand("ceph", "daemon", fmt.Sprintf("mon.%s", member), "mon_status")
+	if err != nil {
+		return types.QuorumStatus{}, fmt.Errorf("failed to query mon.%s, is it running on this member: %w", member, err)
+	}
+
+	return parseMonStatus(member, output)
+}
+
+// planQuorumRecovery checks the monitors to remove and returns those still in
+// the monmap: only unreachable monitors can be removed, and only while there
+// is no quorum. The reachable monitors left must be enough for a quorum.
+func planQuorumRecovery(status types.QuorumStatus, remove []string) ([]string, error) {
+	if len(remove) == 0 {
+		return nil, fmt.Errorf("no monitors to remove")
+	}
+
+	removed := map[string]bool{}
+	for _, name := range remove {
+		removed[name] = true
+	}
+
+	hasQuorum := false
+	pending := []string{}
+	remaining := 0
+	reachable := 0
+	for _, mon := range status.Monitors {
+		if mon.InQuorum {
+			hasQuorum = true
+		}
+
+		if !removed[mon.Name] {
+			remaining++
+			if mon.Reachable {
+				reachable++
+			}
+
+			continue
+		}
+
+		if mon.Name == status.Member {
+			return nil, fmt.Errorf("cannot remove mon.%s, the recovery runs on it", mon.Name)
+		}
+
+		if mon.Reachable {
+			return nil, fmt.Errorf("mon.%s is reachable, only unreachable monitors can be removed", mon.Name)
+		}
+
+		pending = append(pending, mon.Name)
+	}
+
+	// Monitors missing from the monmap were removed by an earlier recovery,
+	// only their records are left.
+	if len(pending) == 0 {
+		return pending, nil
+	}
+
+	if hasQuorum {
+		return nil, fmt.Errorf("the monitors have a quorum, remove monitors with 'microceph cluster remove' instead")
+	}
+
+	if reachable <= remaining/2 {
+		return nil, fmt.Errorf("%d of the %d monitors left are reachable, not enough for a quorum", reachable, remaining)
+	}
+
+	return pending, nil
+}
+
+// removeFromMonmap removes monitors from the monmap of the local monitor,
+// which is stopped meanwhile.
+func removeFromMonmap(member string, monDataPath string, remove []string) error {
+	tmpPath, err := os.MkdirTemp("", "")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary path: %w", err)
+	}
+	defer os.RemoveAll(tmpPath)
+
+	err = snapStop("mon", false)
+	if err != nil {
+		return fmt.Errorf("failed to stop mon.%s: %w", member, err)
+	}
+
+	monmap := filepath.Join(tmpPath, "mon.map")
+	_, err = extractMonmap(member, monDataPath, monmap)
+	for _, name := range remove {
+		if err != nil {
+			break
+		}
+
+		err = rmMonmap(monmap, name)
+	}
+
+	if err == nil {
+		err = injectMonmap(member, monDataPath, monmap)
+	}
+
+	startErr := snapStart("mon", false)
+	if startErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to start mon.%s: %w", member, startErr))
+	}
+
+	return err
+}
+
+// dropMonitorRecords removes the services and mon host configs of removed
+// monitors from the database, so that every member renders a mon_host
+// without them.
+func dropMonitorRecords(ctx context.Context, s interfaces.StateInterface, remove []string) error {
+	return s.ClusterState().Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		for _, name := range remove {
+			err := database.DeleteService(ctx, tx, name, "mon")
+			if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return fmt.Errorf("failed to remove mon service of %s: %w", name, err)
+			}
+
+			err = database.DeleteConfigItem(ctx, tx, fmt.Sprintf("mon.host.%s", name))
+			if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return fmt.Errorf("failed to remove mon host of %s: %w", name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RecoverQuorum restores the monitor quorum after losing a majority of the
+// monitors: the unreachable monitors are removed from the monmap of the local
+// monitor, which is restarted with the reduced monmap, and their records
+// dropped from the database. The config of every member is then refreshed.
+// A failed database update is returned as an error, running the recovery
+// again once the database is available finishes it.
+func RecoverQuorum(ctx context.Context, s interfaces.StateInterface, req types.QuorumRecovery) (types.QuorumRecoveryResult, error) {
+	result := types.QuorumRecoveryResult{Removed: []string{}, Monitors: []string{}}
+	member := s.ClusterState().Name()
+
+	// Serialize with startup re-enablement, which could restart the monitor
+	// while its monmap is rewritten.
+	serviceStartMu.Lock()
+	defer serviceStartMu.Unlock()
+
+	status, err := GetQuorumStatus(s)
+	if err != nil {
+		return result, err
+	}
+
+	pending, err := planQuorumRecovery(status, req.Remove)
+	if err != nil {
+		return result, err
+	}
+
+	removed := map[string]bool{}
+	for _, name := range pending {
+		removed[name] = true
+	}
+
+	for _, mon := range status.Monitors {
+		if !removed[mon.Name] {
+			result.Monitors = append(result.Monitors, mon.Name)
+		}
+	}
+
+	if len(pending) != 0 {
+		monDataPath := filepath.Join(constants.GetPathConst().DataPath, "mon", fmt.Sprintf("ceph-%s", member))
+		err = removeFromMonmap(member, monDataPath, pending)
+		if err != nil {
+			return result, err
+		}
+
+		result.Removed = pending
+		logger.Warnf("Quorum: removed %v from the monmap of mon.%s", pending, member)
+
+		if !waitForControlServiceReady(ctx, "mon", member, time.Now().Add(quorumMonTimeout)) {
+			return result, fmt.Errorf("mon.%s did not form a quorum with the reduced monmap", member)
+		}
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, quorumDatabaseTimeout)
+	defer cancel()
+
+	// Without monitors left to remove from the monmap only records are
+	// dropped, which must belong to monitors.
+	if len(pending) == 0 {
+		services, err := database.ServiceQuery.List(dbCtx, s.ClusterState())
+		if err != nil {
+			logger.Errorf("Quorum: failed fetching services from db: %v", err)
+			return result, quorumDatabaseError(result.Removed, req.Remove, err)
+		}
+
+		err = checkMonitorRecords(services, req.Remove)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	err = dropMonitorRecords(dbCtx, s, req.Remove)
+	if err != nil {
+		logger.Errorf("Quorum: failed to drop records of removed monitors: %v", err)
+		return result, quorumDatabaseError(result.Removed, req.Remove, err)
+	}
+
+	err = UpdateConfig(ctx, s)
+	if err != nil {
+		return result, err
+	}
+
+	// The other members render a mon_host without the removed monitors.
+	result.Unrefreshed, err = refreshMemberConfigs(ctx, s)
+	if err != nil {
+		return result, fmt.Errorf("failed to refresh the config of cluster members: %w", err)
+	}
+
+	return result, nil
+}
+
+// checkMonitorRecords checks monitors missing from the monmap are monitors in
+// the database, left behind by an earlier recovery.
+func checkMonitorRecords(services types.Services, remove []string) error {
+	for _, name := range remove {
+		if !isServicePlacementOnHost(services, "mon", name) {
+			return fmt.Errorf("mon.%s is neither in the monmap nor in the database", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package ceph
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type recoverQuorumSuite struct {
+	tests.BaseSuite
+}
+
+func TestRecoverQuorum(t *testing.T) {
+	suite.Run(t, new(recoverQuorumSuite))
+}
+
+func (s *recoverQuorumSuite) TestParseMonStatusWithoutQuorum() {
+	status, err := parseMonStatus("node1", `{
+  "name": "node1",
+  "rank": 0,
+  "state": "probing",
+  "quorum": [],
+  "outside_quorum": ["node1"],
+  "monmap": {
+    "mons": [
+      {"rank": 0, "name": "node1", "public_addr": "10.0.0.1:6789/0", "addr": "10.0.0.1:6789/0"},
+      {"rank": 1, "name": "node2", "addr": "10.0.0.2:6789/0"},
+      {"rank": 2, "name": "node3", "public_addr": "10.0.0.3:6789/0"}
+    ]
+  }
+}`)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "probing", status.State)
+	assert.Equal(s.T(), []types.QuorumMonitor{
+		{Name: "node1", Address: "10.0.0.1:6789/0", Reachable: true},
+		{Name: "node2", Address: "10.0.0.2:6789/0"},
+		{Name: "node3", Address: "10.0.0.3:6789/0"},
+	}, status.Monitors)
+
+	_, err = parseMonStatus("node1", `{"state": "probing", "monmap": {"mons": []}}`)
+	assert.ErrorContains(s.T(), err, "holds no monitors")
+}
+
+func (s *recoverQuorumSuite) TestPlanQuorumRecovery() {
+	status := types.QuorumStatus{Member: "node1", State: "probing", Monitors: []types.QuorumMonitor{
+		{Name: "node1", Reachable: true},
+		{Name: "node2"},
+		{Name: "node3"},
+	}}
+
+	pending, err := planQuorumRecovery(status, []string{"node2", "node3"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"node2", "node3"}, pending)
+
+	_, err = planQuorumRecovery(status, nil)
+	assert.ErrorContains(s.T(), err, "no monitors to remove")
+
+	_, err = planQuorumRecovery(status, []string{"node1"})
+	assert.ErrorContains(s.T(), err, "the recovery runs on it")
+
+	// A single unreachable monitor left out is a minority of two.
+	_, err = planQuorumRecovery(status, []string{"node2"})
+	assert.ErrorContains(s.T(), err, "1 of the 2 monitors left are reachable")
+
+	// Monitors gone from the monmap only have their records left.
+	pending, err = planQuorumRecovery(status, []string{"node4"})
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), pending)
+
+	status.Monitors[1].Reachable = true
+	_, err = planQuorumRecovery(status, []string{"node2"})
+	assert.ErrorContains(s.T(), err, "mon.node2 is reachable")
+
+	status.Monitors[0].InQuorum = true
+	status.Monitors[1].InQuorum = true
+	_, err = planQuorumRecovery(status, []string{"node3"})
+	assert.ErrorContains(s.T(), err, "the monitors have a quorum")
+}
+
+func (s *recoverQuorumSuite) TestRemoveFromMonmap() {
+	monDataPath := "/var/snap/microceph/common/data/mon/ceph-node1"
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "snapctl", "stop", "microceph.mon").Return("", nil).Once()
+	r.On("RunCommand", "ceph-mon", "-i", "node1", "--mon-data", monDataPath, "--extract-monmap", mock.Anything).Return("", nil).Once()
+	r.On("RunCommand", "monmaptool", "--print", mock.Anything).Return(`epoch 3
+0: [v2:10.0.0.1:3300/0,v1:10.0.0.1:6789/0] mon.node1
+1: [v2:10.0.0.2:3300/0,v1:10.0.0.2:6789/0] mon.node2
+2: [v2:10.0.0.3:3300/0,v1:10.0.0.3:6789/0] mon.node3
+`, nil).Once()
+	r.On("RunCommand", "monmaptool", "--rm", "node2", mock.Anything).Return("", nil).Once()
+	r.On("RunCommand", "monmaptool", "--rm", "node3", mock.Anything).Return("", nil).Once()
+	r.On("RunCommand", "ceph-mon", "-i", "node1", "--mon-data", monDataPath, "--inject-monmap", mock.Anything).Return("", nil).Once()
+	r.On("RunCommand", "snapctl", "start", "microceph.mon").Return("", nil).Once()
+	common.ProcessExec = r
+
+	err := removeFromMonmap("node1", monDataPath, []string{"node2", "node3"})
+	assert.NoError(s.T(), err)
+}
+
+func (s *recoverQuorumSuite) TestCheckMonitorRecords() {
+	services := types.Services{
+		{Service: "mon", Location: "node1"},
+		{Service: "mon", Location: "node2"},
+		{Service: "osd", Location: "node3"},
+	}
+
+	assert.NoError(s.T(), checkMonitorRecords(services, []string{"node2"}))
+	assert.EqualError(s.T(), checkMonitorRecords(services, []string{"node2", "node3"}), "mon.node3 is neither in the monmap nor in the database")
+}
+
+func (s *recoverQuorumSuite) TestQuorumDatabaseError() {
+	err := quorumDatabaseError([]string{"node2", "node3"}, []string{"node2", "node3"}, errors.New("no quorum"))
+	assert.EqualError(s.T(), err, "removed node2, node3 from the monmap but the MicroCeph database was not updated: no quorum, run 'microceph cluster recover-quorum --remove node2,node3' again once it is available")
+
+	// A rerun only dropping the records names no monitor removed from the monmap.
+	err = quorumDatabaseError(nil, []string{"node2"}, errors.New("no quorum"))
+	assert.EqualError(s.T(), err, "the MicroCeph database was not updated: no quorum, run 'microceph cluster recover-quorum --remove node2' again once it is available")
+}
@@ -0,0 +1,187 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// refreshClaimTTL is how long an allowed refresh holds up the refresh of other
+// members when the member does not come back from it. A refresh restarts the
+// daemons of the member within minutes, an older claim is from a refresh that
+// never came back.
+const refreshClaimTTL = 20 * time.Minute
+
+// checkLastReplicas checks stopping the monitor and manager of a member keeps
+// the cluster available: the monitor must be ok-to-stop and the manager must
+// not be the active one without a standby. A cluster with a single monitor or
+// manager cannot avoid the outage, its refresh is not held up.
+func checkLastReplicas(member string, services types.Services) error {
+	if isServicePlacementOnHost(services, "mon", member) && countServices(services, "mon") > 1 {
+		_, err := common.ProcessExec.RunCommand("ceph", "mon", "ok-to-stop", member)
+		if err != nil {
+			return fmt.Errorf("mon.%s is not ok-to-stop", member)
+		}
+	}
+
+	if isServicePlacementOnHost(services, "mgr", member) && countServices(services, "mgr") > 1 {
+		// mgr dump is not used as it emits invalid JSON on some releases.
+		output, err := common.ProcessExec.RunCommand("ceph", "mgr", "stat", "-f", "json")
+		if err != nil {
+			return fmt.Errorf("failed to fetch mgr status: %w", err)
+		}
+
+		if gjson.Get(output, "active_name").String() == member && gjson.Get(output, "num_standby").Int() == 0 {
+			return fmt.Errorf("mgr.%s is the active mgr and has no standby", member)
+		}
+	}
+
+	return nil
+}
+
+// countServices counts the members hosting a service.
+func countServices(services types.Services, service string) int {
+	count := 0
+	for _, svc := range services {
+		if svc.Service == service {
+			count++
+		}
+	}
+
+	return count
+}
+
+// refreshBlocker works out why the local member cannot be refreshed, an empty
+// string if it can.
+func refreshBlocker(ctx context.Context, s interfaces.StateInterface, member string, tolerated []string) (string, error) {
+	decisions, err := database.GetRefreshDecisionsDb(ctx, s.ClusterState())
+	if err != nil {
+		return "", err
+	}
+
+	// A refresh claimed by "cluster upgrade" runs with the member in
+	// maintenance mode, it is already gated.
+	for _, decision := range decisions {
+		if decision.Member == member && decision.Allowed && !decision.Completed && time.Since(decision.Timestamp) < refreshClaimTTL {
+			return "", nil
+		}
+	}
+
+	other := database.RefreshInProgress(decisions, member, time.Now(), refreshClaimTTL)
+	if other != nil {
+		return fmt.Sprintf("%s is mid-refresh since %s", other.Member, other.Timestamp.Format(time.RFC3339)), nil
+	}
+
+	services, err := database.ServiceQuery.List(ctx, s.ClusterState())
+	if err != nil {
+		return "", fmt.Errorf("failed fetching services from db: %w", err)
+	}
+
+	disks, err := ListOSD(ctx, s.ClusterState())
+	if err != nil {
+		return "", fmt.Errorf("failed to list OSDs: %w", err)
+	}
+
+	osds := []int64{}
+	for _, disk := range disks {
+		if disk.Location == member {
+			osds = append(osds, disk.OSD)
+		}
+	}
+
+	err = checkRestartHealth(toSet(tolerated), osds)
+	if err != nil {
+		return err.Error(), nil
+	}
+
+	err = checkLastReplicas(member, services)
+	if err != nil {
+		return err.Error(), nil
+	}
+
+	return "", nil
+}
+
+// CheckRefresh decides whether the local member can be refreshed: the cluster
+// must be healthy, no other member mid-refresh and the daemons of the member
+// ok to stop. The decision is recorded and an allowed refresh claimed, unless
+// this is a dry run.
+func CheckRefresh(ctx context.Context, s interfaces.StateInterface, req types.RefreshCheck) (types.RefreshDecision, error) {
+	decision := types.RefreshDecision{Member: s.ClusterState().Name(), Revision: os.Getenv("SNAP_REVISION"), Timestamp: time.Now()}
+
+	reason, err := refreshBlocker(ctx, s, decision.Member, req.ToleratedWarnings)
+	if err != nil {
+		return decision, err
+	}
+
+	decision.Allowed = len(reason) == 0
+	decision.Reason = reason
+	if req.DryRun {
+		return decision, nil
+	}
+
+	if decision.Allowed {
+		decision, err = database.ClaimRefreshDb(ctx, s.ClusterState(), decision, refreshClaimTTL)
+	} else {
+		err = database.SetRefreshDecisionDb(ctx, s.ClusterState(), decision)
+	}
+	if err != nil {
+		return decision, err
+	}
+
+	if decision.Allowed {
+		logger.Infof("Refresh: allowed refresh of %s", decision.Member)
+	} else {
+		logger.Warnf("Refresh: refused refresh of %s: %s", decision.Member, decision.Reason)
+	}
+
+	return decision, nil
+}
+
+// GetRefreshDecisions fetches the latest refresh decision of every member.
+func GetRefreshDecisions(ctx context.Context, s interfaces.StateInterface) (types.RefreshDecisions, error) {
+	return database.GetRefreshDecisionsDb(ctx, s.ClusterState())
+}
+
+// completeRefresh marks the refresh of the local member completed, letting
+// other members refresh. It runs whichever revision the daemon starts on, so
+// a refresh rolled back to the claiming revision, or whose post-refresh
+// failed with postRefreshErr, releases the claim too and records why.
+func completeRefresh(ctx context.Context, s interfaces.StateInterface, postRefreshErr error) error {
+	decisions, err := database.GetRefreshDecisionsDb(ctx, s.ClusterState())
+	if err != nil {
+		return err
+	}
+
+	for _, decision := range decisions {
+		if decision.Member != s.ClusterState().Name() || !decision.Allowed || decision.Completed {
+			continue
+		}
+
+		revision := os.Getenv("SNAP_REVISION")
+		switch {
+		case postRefreshErr != nil:
+			decision.Reason = fmt.Sprintf("post-refresh failed: %v", postRefreshErr)
+		case len(decision.Revision) > 0 && decision.Revision == revision:
+			decision.Reason = fmt.Sprintf("rolled back to revision %s", revision)
+		}
+
+		if len(decision.Reason) > 0 {
+			logger.Warnf("Refresh: refresh of %s ended: %s", decision.Member, decision.Reason)
+		}
+
+		decision.Completed = true
+		return database.SetRefreshDecisionDb(ctx, s.ClusterState(), decision)
+	}
+
+	return nil
+}
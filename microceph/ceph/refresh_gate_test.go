@@ -0,0 +1,162 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type refreshGateSuite struct {
+	tests.BaseSuite
+	TestStateInterface *mocks.StateInterface
+	decisions          types.RefreshDecisions
+	recorded           types.RefreshDecisions
+}
+
+func TestRefreshGate(t *testing.T) {
+	suite.Run(t, new(refreshGateSuite))
+}
+
+func (s *refreshGateSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+
+	s.TestStateInterface = mocks.NewStateInterface(s.T())
+	state := &mocks.MockState{URL: api.NewURL(), ClusterName: "foohost"}
+	s.TestStateInterface.On("ClusterState").Return(state).Maybe()
+
+	s.decisions = types.RefreshDecisions{}
+	s.recorded = types.RefreshDecisions{}
+
+	origGet := database.GetRefreshDecisionsDb
+	origSet := database.SetRefreshDecisionDb
+	origClaim := database.ClaimRefreshDb
+	s.T().Cleanup(func() {
+		database.GetRefreshDecisionsDb = origGet
+		database.SetRefreshDecisionDb = origSet
+		database.ClaimRefreshDb = origClaim
+	})
+
+	database.GetRefreshDecisionsDb = func(_ context.Context, _ mcTypes.State) (types.RefreshDecisions, error) {
+		return s.decisions, nil
+	}
+	database.SetRefreshDecisionDb = func(_ context.Context, _ mcTypes.State, decision types.RefreshDecision) error {
+		s.recorded = append(s.recorded, decision)
+		return nil
+	}
+	database.ClaimRefreshDb = func(_ context.Context, _ mcTypes.State, decision types.RefreshDecision, _ time.Duration) (types.RefreshDecision, error) {
+		s.recorded = append(s.recorded, decision)
+		return decision, nil
+	}
+}
+
+func (s *refreshGateSuite) TestCheckLastReplicas() {
+	services := types.Services{
+		{Service: "mon", Location: "foohost"},
+		{Service: "mgr", Location: "foohost"},
+		{Service: "mon", Location: "otherhost"},
+		{Service: "mgr", Location: "otherhost"},
+	}
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "mon", "ok-to-stop", "foohost").Return("", nil).Twice()
+	r.On("RunCommand", "ceph", "mgr", "stat", "-f", "json").Return(`{"epoch":12,"available":true,"active_name":"foohost","num_standby":1}`, nil).Once()
+	r.On("RunCommand", "ceph", "mgr", "stat", "-f", "json").Return(`{"epoch":12,"available":true,"active_name":"foohost","num_standby":0}`, nil).Once()
+	common.ProcessExec = r
+
+	assert.NoError(s.T(), checkLastReplicas("foohost", services))
+	assert.EqualError(s.T(), checkLastReplicas("foohost", services), "mgr.foohost is the active mgr and has no standby")
+
+	r.On("RunCommand", "ceph", "mon", "ok-to-stop", "foohost").Return("", fmt.Errorf("not ok")).Once()
+	assert.EqualError(s.T(), checkLastReplicas("foohost", services), "mon.foohost is not ok-to-stop")
+}
+
+func (s *refreshGateSuite) TestCheckLastReplicasSingleReplica() {
+	services := types.Services{
+		{Service: "mon", Location: "foohost"},
+		{Service: "mgr", Location: "foohost"},
+	}
+
+	// A single monitor and manager are never ok to stop, no ceph command runs.
+	common.ProcessExec = mocks.NewRunner(s.T())
+
+	assert.NoError(s.T(), checkLastReplicas("foohost", services))
+}
+
+func (s *refreshGateSuite) TestCheckRefreshOtherMemberRefreshing() {
+	since := time.Now().Add(-time.Minute)
+	s.decisions = types.RefreshDecisions{{Member: "otherhost", Allowed: true, Timestamp: since}}
+
+	decision, err := CheckRefresh(context.Background(), s.TestStateInterface, types.RefreshCheck{})
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), decision.Allowed)
+	assert.Equal(s.T(), fmt.Sprintf("otherhost is mid-refresh since %s", since.Format(time.RFC3339)), decision.Reason)
+	assert.Len(s.T(), s.recorded, 1)
+
+	// A dry run is not recorded.
+	_, err = CheckRefresh(context.Background(), s.TestStateInterface, types.RefreshCheck{DryRun: true})
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), s.recorded, 1)
+}
+
+func (s *refreshGateSuite) TestCheckRefreshClaimedByUpgrade() {
+	s.decisions = types.RefreshDecisions{{Member: "foohost", Allowed: true, Timestamp: time.Now().Add(-time.Minute)}}
+
+	decision, err := CheckRefresh(context.Background(), s.TestStateInterface, types.RefreshCheck{})
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), decision.Allowed)
+}
+
+func (s *refreshGateSuite) TestCompleteRefresh() {
+	s.T().Setenv("SNAP_REVISION", "101")
+	s.decisions = types.RefreshDecisions{
+		{Member: "otherhost", Allowed: true},
+		{Member: "foohost", Allowed: true, Revision: "100"},
+	}
+
+	err := completeRefresh(context.Background(), s.TestStateInterface, nil)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), types.RefreshDecisions{{Member: "foohost", Allowed: true, Completed: true, Revision: "100"}}, s.recorded)
+}
+
+func (s *refreshGateSuite) TestCompleteRefreshRolledBack() {
+	s.T().Setenv("SNAP_REVISION", "100")
+	s.decisions = types.RefreshDecisions{{Member: "foohost", Allowed: true, Revision: "100"}}
+
+	err := completeRefresh(context.Background(), s.TestStateInterface, nil)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), types.RefreshDecisions{{Member: "foohost", Allowed: true, Completed: true, Reason: "rolled back to revision 100", Revision: "100"}}, s.recorded)
+}
+
+func (s *refreshGateSuite) TestCompleteRefreshPostRefreshFailed() {
+	s.T().Setenv("SNAP_REVISION", "101")
+	s.decisions = types.RefreshDecisions{{Member: "foohost", Allowed: true, Revision: "100"}}
+
+	err := completeRefresh(context.Background(), s.TestStateInterface, fmt.Errorf("version check failed"))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), types.RefreshDecisions{{Member: "foohost", Allowed: true, Completed: true, Reason: "post-refresh failed: version check failed", Revision: "100"}}, s.recorded)
+}
+
+func (s *refreshGateSuite) TestRefreshMemberReleasesClaim() {
+	origRefresh := snapRefreshFunc
+	s.T().Cleanup(func() { snapRefreshFunc = origRefresh })
+	snapRefreshFunc = func(_ string) (bool, error) { return false, nil }
+
+	result, err := RefreshMember(context.Background(), s.TestStateInterface, "squid/stable")
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), result.Refreshing)
+	assert.Len(s.T(), s.recorded, 2)
+	assert.False(s.T(), s.recorded[0].Completed)
+	assert.True(s.T(), s.recorded[1].Completed)
+}
@@ -202,7 +202,34 @@ func (rh *CephfsReplicationHandler) DisableHandler(ctx context.Context, args ...
 // ConfigureHandler configures replication properties for requested cephfs subvolume/directory.
 func (rh *CephfsReplicationHandler) ConfigureHandler(ctx context.Context, args ...any) error {
 	logger.Debugf("REPCFS: Configure handler, Req %v", rh.Request)
-	return fmt.Errorf("%s not implemented for cephfs", types.ConfigureReplicationRequest)
+
+	if len(rh.Request.Schedule) == 0 && len(rh.Request.RetentionPolicy) == 0 {
+		return fmt.Errorf("nothing to configure, provide a snapshot schedule and/or retention policy")
+	}
+
+	req := types.FsSnapScheduleRequest{
+		Schedule:  rh.Request.Schedule,
+		Retention: rh.Request.RetentionPolicy,
+	}
+
+	switch rh.Request.ResourceType {
+	case types.CephfsResourceSubvolume:
+		req.Subvolume = rh.Request.Subvolume
+		req.Group = rh.Request.SubvolumeGroup
+	case types.CephfsResourceDirectory:
+		req.Path = rh.Request.DirPath
+	default:
+		return fmt.Errorf("REPCFS: Configure request failed, invalid resource type found (%s)", rh.Request.ResourceType)
+	}
+
+	err := AddCephFSSnapSchedule(ctx, rh.Request.Volume, req)
+	if err != nil {
+		err = fmt.Errorf("REPCFS: Failed to configure snapshot schedule on CephFS resource %+v: %w", rh.Request, err)
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
 }
 
 // ListHandler fetches a list of directories configured for the requested FS or all FSs.
@@ -242,10 +269,18 @@ func (rh *CephfsReplicationHandler) ListHandler(ctx context.Context, args ...any
 func (rh *CephfsReplicationHandler) StatusHandler(ctx context.Context, args ...any) error {
 	logger.Debugf("REPCFS: Status handler, Req %v", rh.Request)
 
+	st := args[repArgState].(interfaces.CephState)
+	record, err := GetCephFsMirrorRoleRecord(ctx, st, rh.Request.Volume)
+	if err != nil {
+		logger.Warnf("REPCFS: failed to fetch failover record of volume %s: %v", rh.Request.Volume, err)
+	}
+
 	response := types.CephFsReplicationResponseStatus{
 		Volume:              rh.Request.Volume,
+		Role:                record.Role,
 		MirrorResourceCount: len(rh.MirrorList),
 		Peers:               make(map[string]types.CephFsReplicationResponsePeerItem),
+		LastSnapshot:        make(map[string]string),
 	}
 	for peer, mirrorMap := range rh.Status {
 		response.Peers[peer] = types.CephFsReplicationResponsePeerItem{MirrorStatus: mirrorMap}
@@ -267,6 +302,26 @@ func (rh *CephfsReplicationHandler) StatusHandler(ctx context.Context, args ...a
 		}
 	}
 
+	// scheduled snapshots are what get mirrored, report the latest one per resource.
+	scheduled, err := IsMgrModuleEnabled(ctx, constants.MgrModuleSnapSchedule)
+	if err != nil {
+		logger.Warnf("REPCFS: failed to check snapshot schedules: %v", err)
+	}
+
+	for _, path := range rh.MirrorList {
+		if !scheduled {
+			break
+		}
+
+		last, err := GetCephFSLastSnapshot(ctx, rh.Request.Volume, cephFSMirrorPathSnapScheduleRequest(path))
+		if err != nil {
+			logger.Warnf("REPCFS: failed to fetch last snapshot of %s: %v", path, err)
+			continue
+		}
+
+		response.LastSnapshot[path] = last
+	}
+
 	data, err := json.Marshal(response)
 	if err != nil {
 		err := fmt.Errorf("failed to marshal resource status: %w", err)
@@ -279,16 +334,21 @@ func (rh *CephfsReplicationHandler) StatusHandler(ctx context.Context, args ...a
 	return nil
 }
 
-// PromoteHandler is not implemented for cephfs workload.
+// PromoteHandler promotes the local mirrored CephFS volumes to primary, optionally reversing the mirror direction.
 func (rh *CephfsReplicationHandler) PromoteHandler(ctx context.Context, args ...any) error {
 	logger.Debugf("REPCFS: Promote handler, Req %v", rh.Request)
-	return fmt.Errorf("%s not implemented for cephfs", types.PromoteReplicationRequest)
+	return handleCephFsSiteOp(ctx, rh, args[repArgState].(interfaces.CephState))
 }
 
-// DemoteHandler is not implemented for cephfs workload.
+// DemoteHandler stops mirroring from the local CephFS volumes and makes them read-only.
 func (rh *CephfsReplicationHandler) DemoteHandler(ctx context.Context, args ...any) error {
 	logger.Debugf("REPCFS: Demote handler, Req %v", rh.Request)
-	return fmt.Errorf("%s not implemented for cephfs", types.DemoteReplicationRequest)
+
+	if !rh.Request.IsForceOp {
+		return fmt.Errorf("demotion may cause data loss on this cluster. %s", constants.CliForcePrompt)
+	}
+
+	return handleCephFsSiteOp(ctx, rh, args[repArgState].(interfaces.CephState))
 }
 
 // #### CephFS Mirroring Specific Helpers ####
@@ -330,6 +390,20 @@ func (rh *CephfsReplicationHandler) GetCephFSMirrorStatus(ctx context.Context) e
 	return nil
 }
 
+// cephFSMirrorPathSnapScheduleRequest maps a mirrored path to the subvolume or directory holding its schedule.
+func cephFSMirrorPathSnapScheduleRequest(path string) types.FsSnapScheduleRequest {
+	group, subvolume, err := CephFsSubvolumePathDeconstruct(path)
+	if err != nil {
+		return types.FsSnapScheduleRequest{Path: path}
+	}
+
+	if group == constants.CephFSSubvolumeNoGroup {
+		group = ""
+	}
+
+	return types.FsSnapScheduleRequest{Subvolume: subvolume, Group: group}
+}
+
 func verifyEnableRequestData(ctx context.Context, s interfaces.CephState, request types.CephfsReplicationRequest) error {
 	if len(request.Volume) == 0 {
 		return fmt.Errorf("missing CephFS volume name")
@@ -540,3 +614,286 @@ func GetCephFsPerVolumeListResponse(volume Volume, mirrorList MirrorPathList) ([
 
 	return response, err
 }
+
+// #### CephFS Failover Helpers ####
+
+// CephFsMirrorRoleRecord is the failover state of a mirrored CephFS volume, recorded on promotion and demotion.
+type CephFsMirrorRoleRecord struct {
+	Role   string   `json:"role"`
+	Remote string   `json:"remote"`
+	Paths  []string `json:"paths"`
+}
+
+// GetCephFsMirrorRoleRecord fetches the failover record of a volume, an empty record is returned if none exists.
+func GetCephFsMirrorRoleRecord(ctx context.Context, st interfaces.CephState, volume string) (CephFsMirrorRoleRecord, error) {
+	record := CephFsMirrorRoleRecord{}
+
+	value, err := database.GetConfigItemDb(ctx, st.ClusterState(), fmt.Sprintf(constants.CephFSMirrorRoleKeyTemplate, volume))
+	if err != nil || len(value) == 0 {
+		return record, err
+	}
+
+	err = json.Unmarshal([]byte(value), &record)
+	if err != nil {
+		return record, fmt.Errorf("failed to parse failover record of volume %s: %w", volume, err)
+	}
+
+	return record, nil
+}
+
+// recordCephFsMirrorRole persists the failover record of a volume.
+func recordCephFsMirrorRole(ctx context.Context, st interfaces.CephState, volume string, record CephFsMirrorRoleRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	err = database.SetConfigItemDb(ctx, st.ClusterState(), fmt.Sprintf(constants.CephFSMirrorRoleKeyTemplate, volume), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to record %s role for volume %s: %w", record.Role, volume, err)
+	}
+
+	logger.Infof("REPCFS: volume %s recorded as %s for remote %s", volume, record.Role, record.Remote)
+	return nil
+}
+
+// handleCephFsSiteOp performs the requested promotion or demotion on the requested, or all, CephFS volumes.
+func handleCephFsSiteOp(ctx context.Context, rh *CephfsReplicationHandler, st interfaces.CephState) error {
+	if len(rh.Request.RemoteName) == 0 {
+		return fmt.Errorf("missing remote cluster name")
+	}
+
+	dbRec, err := database.GetRemoteDb(ctx, st.ClusterState(), rh.Request.RemoteName)
+	if err != nil {
+		err := fmt.Errorf("remote (%s) does not exist: %w", rh.Request.RemoteName, err)
+		logger.Error(err.Error())
+		return err
+	}
+
+	volumes := []Volume{Volume(rh.Request.Volume)}
+	if len(rh.Request.Volume) == 0 {
+		volumes, err = listCephFsMirroredVolumes(ctx, st, dbRec[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Debugf("REPCFS: Scan volumes %v", volumes)
+
+	for _, volume := range volumes {
+		switch rh.Request.RequestType {
+		case types.PromoteReplicationRequest:
+			err = promoteCephFsVolume(ctx, st, string(volume), dbRec[0], rh.Request)
+		case types.DemoteReplicationRequest:
+			err = demoteCephFsVolume(ctx, st, string(volume), dbRec[0])
+		default:
+			err = fmt.Errorf("invalid site operation (%s) for CephFS replication", rh.Request.RequestType)
+		}
+
+		if err != nil {
+			logger.Errorf("REPCFS: %s failed for volume %s: %v", rh.Request.RequestType, volume, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listCephFsMirroredVolumes lists the volumes mirrored with a remote: those with mirroring enabled
+// locally, those the remote mirrors to the local cluster and those failed over with the remote.
+func listCephFsMirroredVolumes(ctx context.Context, st interfaces.CephState, remote types.RemoteRecord) ([]Volume, error) {
+	volumes, err := ListCephFSVolumes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CephFS volumes: %w", err)
+	}
+
+	mirrored := []Volume{}
+	for _, volume := range volumes {
+		_, err = cephFSSnapshotMirrorPeerList(ctx, string(volume), "", "")
+		if err == nil {
+			mirrored = append(mirrored, volume)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), constants.VolumeNotMirrored) {
+			return nil, err
+		}
+
+		// The remote may be unreachable, as on a forced promotion.
+		peers, err := cephFSSnapshotMirrorPeerList(ctx, string(volume), remote.Name, remote.LocalName)
+		if err == nil && len(getCephFsPairedPeerIDs(peers, remote)) != 0 {
+			mirrored = append(mirrored, volume)
+			continue
+		}
+
+		record, err := GetCephFsMirrorRoleRecord(ctx, st, string(volume))
+		if err != nil {
+			return nil, err
+		}
+
+		if record.Remote == remote.Name {
+			mirrored = append(mirrored, volume)
+			continue
+		}
+
+		logger.Debugf("REPCFS: volume(%s) is not mirrored with remote %s, skipping", volume, remote.Name)
+	}
+
+	return mirrored, nil
+}
+
+// demoteCephFsVolume breaks the mirroring peers of a primary volume and fences its clients: new client
+// sessions are refused and existing ones evicted, so that the volume no longer takes writes.
+func demoteCephFsVolume(ctx context.Context, st interfaces.CephState, volume string, remote types.RemoteRecord) error {
+	peers, err := cephFSSnapshotMirrorPeerList(ctx, volume, "", "")
+	if err != nil {
+		if strings.Contains(err.Error(), constants.VolumeNotMirrored) {
+			logger.Infof("REPCFS: volume(%s) is not mirrored, skipping", volume)
+			return nil
+		}
+
+		return err
+	}
+
+	peerIDs := getCephFsPairedPeerIDs(peers, remote)
+	if len(peerIDs) == 0 {
+		logger.Infof("REPCFS: volume(%s) has no peer(%s), skipping", volume, remote.Name)
+		return nil
+	}
+
+	paths, err := GetCephFSVolumeMirrorList(ctx, volume)
+	if err != nil {
+		return err
+	}
+
+	for _, peerID := range peerIDs {
+		err = cephFSSnapshotMirrorPeerRemove(ctx, volume, peerID, "", "")
+		if err != nil {
+			return fmt.Errorf("failed to remove mirroring peer %s: %w", peerID, err)
+		}
+	}
+
+	err = SetCephFSRefuseClientSession(ctx, volume, true, "", "")
+	if err != nil {
+		return err
+	}
+
+	err = EvictCephFSClients(ctx, volume)
+	if err != nil {
+		return err
+	}
+
+	return recordCephFsMirrorRole(ctx, st, volume, CephFsMirrorRoleRecord{
+		Role:   constants.CephFSMirrorRoleSecondary,
+		Remote: remote.Name,
+		Paths:  paths,
+	})
+}
+
+// promoteCephFsVolume breaks the peer held by the old primary and makes the local volume writable.
+func promoteCephFsVolume(ctx context.Context, st interfaces.CephState, volume string, remote types.RemoteRecord, request types.CephfsReplicationRequest) error {
+	// Without a demotion on the remote, both sites would accept writes.
+	demoted, err := GetCephFSRefuseClientSession(ctx, volume, remote.Name, remote.LocalName)
+	if err != nil || !demoted {
+		if !request.IsForceOp {
+			return fmt.Errorf("volume %s is not demoted on remote %s or the remote is unreachable. %s", volume, remote.Name, constants.CliForcePrompt)
+		}
+
+		logger.Warnf("REPCFS: forcefully promoting volume %s, remote %s is not demoted: %v", volume, remote.Name, err)
+	}
+
+	peers, err := cephFSSnapshotMirrorPeerList(ctx, volume, remote.Name, remote.LocalName)
+	if err == nil {
+		for _, peerID := range getCephFsPairedPeerIDs(peers, remote) {
+			err = cephFSSnapshotMirrorPeerRemove(ctx, volume, peerID, remote.Name, remote.LocalName)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil && !strings.Contains(err.Error(), constants.VolumeNotMirrored) {
+		if !request.IsForceOp {
+			return fmt.Errorf("failed to break mirroring peer on remote %s: %w. %s", remote.Name, err, constants.CliForcePrompt)
+		}
+
+		logger.Warnf("REPCFS: failed to break mirroring peer of volume %s on remote %s: %v", volume, remote.Name, err)
+	}
+
+	err = SetCephFSRefuseClientSession(ctx, volume, false, "", "")
+	if err != nil {
+		return err
+	}
+
+	record := CephFsMirrorRoleRecord{Role: constants.CephFSMirrorRolePrimary, Remote: remote.Name}
+	if request.ReverseDirection {
+		record.Paths, err = reverseCephFsVolumeMirror(ctx, volume, remote)
+		if err != nil {
+			return fmt.Errorf("failed to reverse mirroring of volume %s: %w", volume, err)
+		}
+	}
+
+	return recordCephFsMirrorRole(ctx, st, volume, record)
+}
+
+// reverseCephFsVolumeMirror mirrors the paths of the old primary from the local volume back to it.
+func reverseCephFsVolumeMirror(ctx context.Context, volume string, remote types.RemoteRecord) ([]string, error) {
+	output, err := cephFSSnapshotMirrorRemoteList(ctx, volume, remote.Name, remote.LocalName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch mirrored paths from remote %s: %w", remote.Name, err)
+	}
+
+	paths := MirrorPathList{}
+	err = json.Unmarshal([]byte(output), &paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mirrored paths from remote %s: %w", remote.Name, err)
+	}
+
+	// The mirror daemon needs client sessions on the old primary to push snapshots.
+	err = SetCephFSRefuseClientSession(ctx, volume, false, remote.Name, remote.LocalName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EnableMgrModule(ctx, constants.MgrModuleMirroring, remote.Name, remote.LocalName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable mgr module %s on remote cluster %s: %w", constants.MgrModuleMirroring, remote.Name, err)
+	}
+
+	err = bootstrapCephFSMirrorPeerForVolume(ctx, volume, remote.Name, remote.LocalName)
+	if err != nil {
+		return nil, err
+	}
+
+	localPaths, err := GetCephFSVolumeMirrorList(ctx, volume)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if slices.Contains(localPaths, path) {
+			continue
+		}
+
+		err = cephFSSnapshotMirrorAddPath(ctx, volume, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mirror path %s: %w", path, err)
+		}
+	}
+
+	logger.Infof("REPCFS: reversed mirroring of volume %s towards %s for %v", volume, remote.Name, paths)
+	return paths, nil
+}
+
+// getCephFsPairedPeerIDs filters the peers paired with the remote, which carry either site name.
+func getCephFsPairedPeerIDs(peers map[string]CephFSSnapshotMirrorPeer, remote types.RemoteRecord) []string {
+	peerIDs := []string{}
+	for peerID, peer := range peers {
+		if peer.SiteName == remote.Name || peer.SiteName == remote.LocalName {
+			peerIDs = append(peerIDs, peerID)
+		}
+	}
+
+	slices.Sort(peerIDs)
+	return peerIDs
+}
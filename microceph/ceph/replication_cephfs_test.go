@@ -0,0 +1,196 @@
+package ceph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type CephfsReplicationSuite struct {
+	tests.BaseSuite
+	records map[string]string
+
+	getRemoteDb     func(context.Context, mcTypes.State, string) (types.RemoteRecords, error)
+	setConfigItemDb func(context.Context, mcTypes.State, string, string) error
+}
+
+func TestCephfsReplication(t *testing.T) {
+	suite.Run(t, new(CephfsReplicationSuite))
+}
+
+func (s *CephfsReplicationSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+
+	s.records = map[string]string{}
+	s.getRemoteDb = database.GetRemoteDb
+	s.setConfigItemDb = database.SetConfigItemDb
+
+	database.GetRemoteDb = func(ctx context.Context, st mcTypes.State, name string) (types.RemoteRecords, error) {
+		return types.RemoteRecords{{ID: 1, Name: name, LocalName: "siteb"}}, nil
+	}
+	database.SetConfigItemDb = func(ctx context.Context, st mcTypes.State, key string, value string) error {
+		s.records[key] = value
+		return nil
+	}
+}
+
+func (s *CephfsReplicationSuite) TearDownTest() {
+	database.GetRemoteDb = s.getRemoteDb
+	database.SetConfigItemDb = s.setConfigItemDb
+	s.BaseSuite.TearDownTest()
+}
+
+func (s *CephfsReplicationSuite) siteOpArgs() []any {
+	var resp string
+	return []any{nil, &resp, interfaces.CephState{State: &mocks.MockState{}}}
+}
+
+func (s *CephfsReplicationSuite) recordedRole(volume string) CephFsMirrorRoleRecord {
+	record := CephFsMirrorRoleRecord{}
+	err := json.Unmarshal([]byte(s.records["cephfs.mirror."+volume]), &record)
+	assert.NoError(s.T(), err)
+	return record
+}
+
+func (s *CephfsReplicationSuite) TestDemoteRequiresForce() {
+	rh := CephfsReplicationHandler{Request: types.CephfsReplicationRequest{
+		Volume:      "vol1",
+		RemoteName:  "sitea",
+		RequestType: types.DemoteReplicationRequest,
+	}}
+
+	err := rh.DemoteHandler(context.Background(), s.siteOpArgs()...)
+	assert.ErrorContains(s.T(), err, constants.CliForcePrompt)
+}
+
+func (s *CephfsReplicationSuite) TestDemoteVolume() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "peer_list", "vol1", "--format=json").
+		Return(`{"a1b2": {"client_name": "client.mirror_remote", "site_name": "sitea", "fs_name": "vol1"}}`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "ls", "vol1", "--format=json").
+		Return(`["/volumes/_nogroup/share1"]`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "peer_remove", "vol1", "a1b2").
+		Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "set", "vol1", "refuse_client_session", "true").
+		Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "get", "vol1", "--format=json").
+		Return(`{"mdsmap": {"in": [0, 1]}}`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "tell", "mds.vol1:0", "client", "evict").Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "tell", "mds.vol1:1", "client", "evict").Return("", nil).Once()
+	common.ProcessExec = r
+
+	rh := CephfsReplicationHandler{Request: types.CephfsReplicationRequest{
+		Volume:      "vol1",
+		RemoteName:  "sitea",
+		RequestType: types.DemoteReplicationRequest,
+		IsForceOp:   true,
+	}}
+
+	err := rh.DemoteHandler(context.Background(), s.siteOpArgs()...)
+	assert.NoError(s.T(), err)
+
+	record := s.recordedRole("vol1")
+	assert.Equal(s.T(), constants.CephFSMirrorRoleSecondary, record.Role)
+	assert.Equal(s.T(), []string{"/volumes/_nogroup/share1"}, record.Paths)
+}
+
+func (s *CephfsReplicationSuite) TestPromoteRefusesUndemotedRemote() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "get", "vol1", "--format=json", "--cluster", "sitea", "--id", "siteb").
+		Return(`{"mdsmap": {"flags_state": {"refuse_client_session": false}}}`, nil).Once()
+	common.ProcessExec = r
+
+	rh := CephfsReplicationHandler{Request: types.CephfsReplicationRequest{
+		Volume:      "vol1",
+		RemoteName:  "sitea",
+		RequestType: types.PromoteReplicationRequest,
+	}}
+
+	err := rh.PromoteHandler(context.Background(), s.siteOpArgs()...)
+	assert.ErrorContains(s.T(), err, constants.CliForcePrompt)
+	assert.Empty(s.T(), s.records)
+}
+
+func (s *CephfsReplicationSuite) TestPromoteVolume() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "get", "vol1", "--format=json", "--cluster", "sitea", "--id", "siteb").
+		Return(`{"mdsmap": {"flags_state": {"refuse_client_session": true}}}`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "peer_list", "vol1", "--format=json", "--cluster", "sitea", "--id", "siteb").
+		Return(`{"c3d4": {"client_name": "client.mirror_remote", "site_name": "sitea", "fs_name": "vol1"}}`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "peer_remove", "vol1", "c3d4", "--cluster", "sitea", "--id", "siteb").
+		Return("", nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "set", "vol1", "refuse_client_session", "false").
+		Return("", nil).Once()
+	common.ProcessExec = r
+
+	rh := CephfsReplicationHandler{Request: types.CephfsReplicationRequest{
+		Volume:      "vol1",
+		RemoteName:  "sitea",
+		RequestType: types.PromoteReplicationRequest,
+	}}
+
+	err := rh.PromoteHandler(context.Background(), s.siteOpArgs()...)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), constants.CephFSMirrorRolePrimary, s.recordedRole("vol1").Role)
+}
+
+func (s *CephfsReplicationSuite) TestListCephFsMirroredVolumes() {
+	getConfigItemDb := database.GetConfigItemDb
+	defer func() { database.GetConfigItemDb = getConfigItemDb }()
+	database.GetConfigItemDb = func(ctx context.Context, st mcTypes.State, key string) (string, error) {
+		return s.records[key], nil
+	}
+	s.records["cephfs.mirror.vol3"] = `{"role": "primary", "remote": "sitea"}`
+
+	notMirrored := fmt.Errorf("%s", constants.VolumeNotMirrored)
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "fs", "volume", "ls", "--format=json").
+		Return(`[{"name": "vol1"}, {"name": "vol2"}, {"name": "vol3"}, {"name": "vol4"}]`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "peer_list", "vol1", "--format=json").
+		Return(`{}`, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "peer_list", "vol2", "--format=json").
+		Return("", notMirrored).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "peer_list", "vol2", "--format=json", "--cluster", "sitea", "--id", "siteb").
+		Return(`{"e5f6": {"client_name": "client.mirror_remote", "site_name": "siteb", "fs_name": "vol2"}}`, nil).Once()
+	for _, volume := range []string{"vol3", "vol4"} {
+		r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "peer_list", volume, "--format=json").
+			Return("", notMirrored).Once()
+		r.On("RunCommandContext", mock.Anything, "ceph", "fs", "snapshot", "mirror", "peer_list", volume, "--format=json", "--cluster", "sitea", "--id", "siteb").
+			Return("", notMirrored).Once()
+	}
+	common.ProcessExec = r
+
+	// vol4 is not mirrored at all and must be left alone.
+	volumes, err := listCephFsMirroredVolumes(context.Background(), interfaces.CephState{State: &mocks.MockState{}},
+		types.RemoteRecord{Name: "sitea", LocalName: "siteb"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []Volume{"vol1", "vol2", "vol3"}, volumes)
+}
+
+func (s *CephfsReplicationSuite) TestGetCephFsPairedPeerIDs() {
+	peers := map[string]CephFSSnapshotMirrorPeer{
+		"p2": {SiteName: "siteb"},
+		"p1": {SiteName: "sitea"},
+		"p3": {SiteName: "sitec"},
+	}
+
+	peerIDs := getCephFsPairedPeerIDs(peers, types.RemoteRecord{Name: "sitea", LocalName: "siteb"})
+	assert.Equal(s.T(), []string{"p1", "p2"}, peerIDs)
+}
@@ -0,0 +1,544 @@
+package ceph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+)
+
+// RgwReplicationHandler implements RGW multisite replication. Zones are
+// named after the cluster hosting them, so a zone maps to an imported remote.
+type RgwReplicationHandler struct {
+	// Prefill objects: Always populated before any handler is called.
+	Realm     RgwRealm
+	ZoneGroup RgwZoneGroup
+	Zone      RgwZone
+	// Request Info
+	Request types.RgwReplicationRequest
+}
+
+// PreFill populates the handler struct with the local realm, zonegroup and zone.
+func (rh *RgwReplicationHandler) PreFill(ctx context.Context, request types.ReplicationRequest) error {
+	var err error
+	rh.Request = request.(types.RgwReplicationRequest)
+
+	rh.Realm, err = GetRgwRealm("", "")
+	if err != nil {
+		return err
+	}
+
+	rh.ZoneGroup, err = GetRgwZoneGroup("", "")
+	if err != nil {
+		return err
+	}
+
+	rh.Zone, err = GetRgwZone("", "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetResourceState reports replication as enabled once the local zonegroup spans more than one zone.
+func (rh *RgwReplicationHandler) GetResourceState() (ReplicationState, error) {
+	if len(rh.Realm.ID) == 0 || len(rh.ZoneGroup.Zones) < 2 {
+		return StateDisabledReplication, nil
+	}
+
+	return StateEnabledReplication, nil
+}
+
+// EnableHandler makes the local zone the master of a realm and joins the remote cluster to it as a secondary zone.
+func (rh *RgwReplicationHandler) EnableHandler(ctx context.Context, args ...any) error {
+	logger.Debugf("REPRGW: Enable handler, Req %v", rh.Request)
+
+	st := args[repArgState].(interfaces.CephState)
+	if len(rh.Request.RemoteName) == 0 || len(rh.Request.Endpoints) == 0 || len(rh.Request.RemoteEndpoints) == 0 {
+		return fmt.Errorf("remote name, local endpoints and remote endpoints are required")
+	}
+
+	dbRec, err := database.GetRemoteDb(ctx, st.ClusterState(), rh.Request.RemoteName)
+	if err != nil {
+		err := fmt.Errorf("remote (%s) does not exist: %w", rh.Request.RemoteName, err)
+		logger.Error(err.Error())
+		return err
+	}
+
+	logger.Infof("REPRGW: LocalName(%s) RemoteName(%s)", dbRec[0].LocalName, dbRec[0].Name)
+
+	key, err := rh.setupMasterZone(ctx, st, dbRec[0].LocalName)
+	if err != nil {
+		logger.Errorf("REPRGW: failed to set up master zone: %v", err)
+		return err
+	}
+
+	// The master zone is kept on failure, enabling again resumes the join.
+	err = rh.joinSecondaryZone(dbRec[0], key)
+	if err != nil {
+		logger.Errorf("REPRGW: failed to join remote %s as secondary zone: %v", dbRec[0].Name, err)
+		return fmt.Errorf("%w, enable replication again to resume", err)
+	}
+
+	return nil
+}
+
+// DisableHandler splits the zonegroup: the remote zone is removed from the local zonegroup and, when the
+// remote is reachable, the local zone from the remote one, which then stands alone as its own master.
+func (rh *RgwReplicationHandler) DisableHandler(ctx context.Context, args ...any) error {
+	logger.Debugf("REPRGW: Disable handler, Req %v", rh.Request)
+
+	if !rh.Request.IsForceOp {
+		return fmt.Errorf("the remote zone will stop syncing from this zonegroup. %s", constants.CliForcePrompt)
+	}
+
+	if len(rh.Request.RemoteName) == 0 {
+		return fmt.Errorf("missing remote cluster name")
+	}
+
+	st := args[repArgState].(interfaces.CephState)
+	dbRec, err := database.GetRemoteDb(ctx, st.ClusterState(), rh.Request.RemoteName)
+	if err != nil {
+		err := fmt.Errorf("remote (%s) does not exist: %w", rh.Request.RemoteName, err)
+		logger.Error(err.Error())
+		return err
+	}
+
+	_, err = radosgwAdminRun("zonegroup", "remove", "--rgw-zonegroup", rh.ZoneGroup.Name, "--rgw-zone", rh.Request.RemoteName)
+	if err != nil {
+		return fmt.Errorf("failed to remove zone %s from zonegroup %s: %w", rh.Request.RemoteName, rh.ZoneGroup.Name, err)
+	}
+
+	err = rgwPeriodCommit("", "")
+	if err != nil {
+		return err
+	}
+
+	err = detachRgwRemoteZone(dbRec[0], rh.ZoneGroup.Name, rh.Zone.Name)
+	if err != nil {
+		logger.Warnf("REPRGW: zone %s was removed locally but remote %s still syncs from this zone: %v", rh.Request.RemoteName, rh.Request.RemoteName, err)
+	}
+
+	// The daemons keep syncing with the removed zone until restarted.
+	return restartClusterRgw(ctx, st)
+}
+
+// ConfigureHandler is not implemented for rgw workload.
+func (rh *RgwReplicationHandler) ConfigureHandler(ctx context.Context, args ...any) error {
+	logger.Debugf("REPRGW: Configure handler, Req %v", rh.Request)
+	return fmt.Errorf("%s not implemented for rgw", types.ConfigureReplicationRequest)
+}
+
+// ListHandler fetches the zones of the local zonegroup.
+func (rh *RgwReplicationHandler) ListHandler(ctx context.Context, args ...any) error {
+	logger.Debugf("REPRGW: List handler, Req %v", rh.Request)
+
+	data, err := json.Marshal(types.RgwReplicationResponseList(getRgwReplicationZones(rh.ZoneGroup)))
+	if err != nil {
+		return err
+	}
+
+	// pass response for API
+	*args[repArgResponse].(*string) = string(data)
+	return nil
+}
+
+// StatusHandler fetches the metadata and data sync verdicts of the local zone.
+func (rh *RgwReplicationHandler) StatusHandler(ctx context.Context, args ...any) error {
+	logger.Debugf("REPRGW: Status handler, Req %v", rh.Request)
+
+	st := args[repArgState].(interfaces.CephState)
+	response, err := GetRgwReplicationStatus(ctx, st.ClusterState(), rh.Realm, rh.ZoneGroup, rh.Zone)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		err := fmt.Errorf("failed to marshal resource status: %w", err)
+		logger.Error(err.Error())
+		return err
+	}
+
+	// pass response for API
+	*args[repArgResponse].(*string) = string(data)
+	return nil
+}
+
+// PromoteHandler fails the master role of the zonegroup over to the local zone.
+func (rh *RgwReplicationHandler) PromoteHandler(ctx context.Context, args ...any) error {
+	logger.Debugf("REPRGW: Promote handler, Req %v", rh.Request)
+
+	if len(rh.Zone.ID) == 0 {
+		return fmt.Errorf("no RGW zone found on the local cluster")
+	}
+
+	master, isMaster := getRgwMasterZone(rh.ZoneGroup, rh.Zone)
+	if isMaster && !master.ReadOnly {
+		logger.Infof("REPRGW: zone %s is already the master zone", rh.Zone.Name)
+		return nil
+	}
+
+	// The demotion of the old master reaches this zone with the period it committed.
+	if !isMaster && !master.ReadOnly && !rh.Request.IsForceOp {
+		return fmt.Errorf("master zone %s is not demoted or demotion is not propagated yet. %s", master.Name, constants.CliForcePrompt)
+	}
+
+	_, err := radosgwAdminRun("zone", "modify", "--rgw-zone", rh.Zone.Name, "--master", "--default", "--read-only=false")
+	if err != nil {
+		return fmt.Errorf("failed to promote zone %s: %w", rh.Zone.Name, err)
+	}
+
+	err = rgwPeriodCommit("", "")
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("REPRGW: zone %s promoted to master, was %s", rh.Zone.Name, master.Name)
+	return restartClusterRgw(ctx, args[repArgState].(interfaces.CephState))
+}
+
+// DemoteHandler makes the local zone read-only so that a peer zone can take over as master.
+func (rh *RgwReplicationHandler) DemoteHandler(ctx context.Context, args ...any) error {
+	logger.Debugf("REPRGW: Demote handler, Req %v", rh.Request)
+
+	if !rh.Request.IsForceOp {
+		return fmt.Errorf("demotion may cause data loss on this cluster. %s", constants.CliForcePrompt)
+	}
+
+	if len(rh.Zone.ID) == 0 {
+		return fmt.Errorf("no RGW zone found on the local cluster")
+	}
+
+	_, err := radosgwAdminRun("zone", "modify", "--rgw-zone", rh.Zone.Name, "--read-only=true")
+	if err != nil {
+		return fmt.Errorf("failed to demote zone %s: %w", rh.Zone.Name, err)
+	}
+
+	err = rgwPeriodCommit("", "")
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("REPRGW: zone %s demoted to read-only", rh.Zone.Name)
+	return restartClusterRgw(ctx, args[repArgState].(interfaces.CephState))
+}
+
+// #### RGW Multisite Specific Helpers ####
+
+// setupMasterZone converts the local gateway into the master zone of a realm, keeping the data of a single site setup.
+func (rh *RgwReplicationHandler) setupMasterZone(ctx context.Context, st interfaces.CephState, zoneName string) (RgwZoneSystemKey, error) {
+	realm := rh.Realm.Name
+	if len(rh.Realm.ID) == 0 {
+		realm = getRgwNameOrDefault(rh.Request.Realm, constants.RgwDefaultRealm)
+		_, err := radosgwAdminRun("realm", "create", "--rgw-realm", realm, "--default")
+		if err != nil {
+			return RgwZoneSystemKey{}, fmt.Errorf("failed to create realm %s: %w", realm, err)
+		}
+	}
+
+	zonegroup := rh.ZoneGroup.Name
+	if len(zonegroup) == 0 || zonegroup == constants.RgwDefaultName {
+		zonegroup = getRgwNameOrDefault(rh.Request.ZoneGroup, constants.RgwDefaultZoneGroup)
+		_, err := radosgwAdminRun("zonegroup", "rename", "--rgw-zonegroup", constants.RgwDefaultName, "--zonegroup-new-name", zonegroup)
+		if err != nil {
+			return RgwZoneSystemKey{}, fmt.Errorf("failed to rename zonegroup to %s: %w", zonegroup, err)
+		}
+	}
+
+	_, err := radosgwAdminRun("zonegroup", "modify", "--rgw-zonegroup", zonegroup, "--rgw-realm", realm,
+		"--master", "--default", "--endpoints", rh.Request.Endpoints)
+	if err != nil {
+		return RgwZoneSystemKey{}, fmt.Errorf("failed to make zonegroup %s master: %w", zonegroup, err)
+	}
+
+	if len(rh.Zone.Name) != 0 && rh.Zone.Name != constants.RgwDefaultName {
+		zoneName = rh.Zone.Name
+	} else {
+		_, err = radosgwAdminRun("zone", "rename", "--rgw-zone", constants.RgwDefaultName, "--zone-new-name", zoneName, "--rgw-zonegroup", zonegroup)
+		if err != nil {
+			return RgwZoneSystemKey{}, fmt.Errorf("failed to rename zone to %s: %w", zoneName, err)
+		}
+	}
+
+	key := rh.Zone.SystemKey
+	if len(key.AccessKey) == 0 {
+		key, err = GetRgwSystemUserKey("", "")
+		if err != nil {
+			return RgwZoneSystemKey{}, err
+		}
+	}
+
+	_, err = radosgwAdminRun("zone", "modify", "--rgw-zone", zoneName, "--rgw-zonegroup", zonegroup, "--master", "--default",
+		"--endpoints", rh.Request.Endpoints, "--access-key", key.AccessKey, "--secret", key.SecretKey)
+	if err != nil {
+		return RgwZoneSystemKey{}, fmt.Errorf("failed to make zone %s master: %w", zoneName, err)
+	}
+
+	err = rgwPeriodCommit("", "")
+	if err != nil {
+		return RgwZoneSystemKey{}, err
+	}
+
+	err = recordRgwZone(ctx, st, RgwZoneConfig{Realm: realm, ZoneGroup: zonegroup, Zone: zoneName})
+	if err != nil {
+		return RgwZoneSystemKey{}, err
+	}
+
+	// Record the names for joining the secondary zone.
+	rh.Realm.Name = realm
+	rh.ZoneGroup.Name = zonegroup
+	rh.Zone.Name = zoneName
+
+	logger.Infof("REPRGW: zone %s is the master of zonegroup %s in realm %s", zoneName, zonegroup, realm)
+	return key, applyRgwZoneConfig(ctx, st)
+}
+
+// joinSecondaryZone pulls the realm on the remote cluster and creates its zone in the master zonegroup. The zone is
+// handed over to the MicroCeph of the remote, which records it and restarts its RGW daemons to serve it. The period
+// is committed last, so that a join failing earlier leaves the zonegroup alone and the enable can be run again.
+func (rh *RgwReplicationHandler) joinSecondaryZone(remote types.RemoteRecord, key RgwZoneSystemKey) error {
+	masterURL := strings.Split(rh.Request.Endpoints, ",")[0]
+
+	_, err := radosgwAdminRunRemote(remote.Name, remote.LocalName, "realm", "pull", "--url", masterURL,
+		"--access-key", key.AccessKey, "--secret", key.SecretKey, "--default")
+	if err != nil {
+		return fmt.Errorf("failed to pull realm %s from %s: %w", rh.Realm.Name, masterURL, err)
+	}
+
+	// An earlier enable may have created the zone already.
+	zone, err := GetRgwZone(remote.Name, remote.LocalName)
+	if err != nil {
+		return err
+	}
+
+	op := "create"
+	if zone.Name == remote.Name {
+		op = "modify"
+	}
+
+	_, err = radosgwAdminRunRemote(remote.Name, remote.LocalName, "zone", op, "--rgw-zonegroup", rh.ZoneGroup.Name,
+		"--rgw-zone", remote.Name, "--endpoints", rh.Request.RemoteEndpoints,
+		"--access-key", key.AccessKey, "--secret", key.SecretKey, "--default")
+	if err != nil {
+		return fmt.Errorf("failed to %s zone %s: %w", op, remote.Name, err)
+	}
+
+	err = handOverRgwZone(remote.Name, remote.LocalName, RgwZoneConfig{Realm: rh.Realm.Name, ZoneGroup: rh.ZoneGroup.Name, Zone: remote.Name})
+	if err != nil {
+		return err
+	}
+
+	err = rgwPeriodCommit(remote.Name, remote.LocalName)
+	if err != nil {
+		return errors.Join(err, withdrawRgwZone(remote.Name, remote.LocalName))
+	}
+
+	logger.Infof("REPRGW: zone %s joined zonegroup %s, remote %s serves it once its MicroCeph adopts it", remote.Name, rh.ZoneGroup.Name, remote.Name)
+	return nil
+}
+
+// detachRgwRemoteZone removes the local zone from the zonegroup of the remote and makes the remote zone the
+// master of it, so that the remote stops syncing from this site. Its RGW daemons pick it up on restart.
+func detachRgwRemoteZone(remote types.RemoteRecord, zonegroup string, localZone string) error {
+	_, err := radosgwAdminRunRemote(remote.Name, remote.LocalName, "zonegroup", "remove", "--rgw-zonegroup", zonegroup, "--rgw-zone", localZone)
+	if err != nil {
+		return fmt.Errorf("failed to remove zone %s from zonegroup %s: %w", localZone, zonegroup, err)
+	}
+
+	_, err = radosgwAdminRunRemote(remote.Name, remote.LocalName, "zone", "modify", "--rgw-zone", remote.Name, "--master", "--default", "--read-only=false")
+	if err != nil {
+		return fmt.Errorf("failed to make zone %s master: %w", remote.Name, err)
+	}
+
+	err = rgwPeriodCommit(remote.Name, remote.LocalName)
+	if err != nil {
+		return err
+	}
+
+	logger.Warnf("REPRGW: zone %s detached from this site, RGW daemons on remote %s must be restarted", remote.Name, remote.Name)
+	return nil
+}
+
+// GetRgwSyncStatus computes the sync verdicts of the local zone.
+func GetRgwSyncStatus(ctx context.Context, s mcTypes.State) (types.RgwReplicationResponseStatus, error) {
+	realm, err := GetRgwRealm("", "")
+	if err != nil {
+		return types.RgwReplicationResponseStatus{}, err
+	}
+
+	zonegroup, err := GetRgwZoneGroup("", "")
+	if err != nil {
+		return types.RgwReplicationResponseStatus{}, err
+	}
+
+	zone, err := GetRgwZone("", "")
+	if err != nil {
+		return types.RgwReplicationResponseStatus{}, err
+	}
+
+	if len(realm.ID) == 0 || len(zone.ID) == 0 {
+		return types.RgwReplicationResponseStatus{}, fmt.Errorf("no RGW multisite zone configured on the local cluster")
+	}
+
+	return GetRgwReplicationStatus(ctx, s, realm, zonegroup, zone)
+}
+
+// GetRgwReplicationStatus computes the sync verdicts of the local zone against
+// the master zone and every peer zone hosted on an imported remote.
+func GetRgwReplicationStatus(ctx context.Context, s mcTypes.State, realm RgwRealm, zonegroup RgwZoneGroup, zone RgwZone) (types.RgwReplicationResponseStatus, error) {
+	master, isMaster := getRgwMasterZone(zonegroup, zone)
+	response := types.RgwReplicationResponseStatus{
+		Realm:     realm.Name,
+		ZoneGroup: zonegroup.Name,
+		Zone:      zone.Name,
+		IsMaster:  isMaster,
+		Zones:     getRgwReplicationZones(zonegroup),
+		DataSync:  map[string]types.RgwSyncVerdict{},
+	}
+
+	remotes, err := database.GetRemoteDb(ctx, s, "")
+	if err != nil {
+		return response, fmt.Errorf("failed to fetch remotes: %w", err)
+	}
+
+	// Metadata is only synced from the master zone.
+	if !isMaster {
+		var masterLog []RgwLogShard
+		cluster, client, ok := getRgwZoneRemote(remotes, master.Name)
+		if ok {
+			masterLog, err = GetRgwMdlogStatus(cluster, client)
+			if err != nil {
+				return response, err
+			}
+		}
+
+		local, err := GetRgwMetadataSyncStatus("", "")
+		if err != nil {
+			return response, err
+		}
+
+		verdict := getRgwSyncVerdictResponse(ComputeRgwMetadataSyncVerdict(local, masterLog, realm.CurrentPeriod))
+		response.MetadataSync = &verdict
+	}
+
+	for _, peer := range zonegroup.Zones {
+		if peer.ID == zone.ID {
+			continue
+		}
+
+		var sourceLog []RgwLogShard
+		cluster, client, ok := getRgwZoneRemote(remotes, peer.Name)
+		if ok {
+			sourceLog, err = GetRgwDatalogStatus(cluster, client)
+			if err != nil {
+				return response, err
+			}
+		}
+
+		local, err := GetRgwDataSyncStatus(peer.Name, "", "")
+		if err != nil {
+			return response, err
+		}
+
+		response.DataSync[peer.Name] = getRgwSyncVerdictResponse(ComputeRgwDataSyncVerdict(local, sourceLog))
+	}
+
+	return response, nil
+}
+
+// getRgwMasterZone finds the master zone of a zonegroup and whether it is the given zone.
+func getRgwMasterZone(zonegroup RgwZoneGroup, zone RgwZone) (RgwZoneGroupZone, bool) {
+	for _, z := range zonegroup.Zones {
+		if z.ID == zonegroup.MasterZone {
+			return z, z.ID == zone.ID
+		}
+	}
+
+	return RgwZoneGroupZone{}, false
+}
+
+// getRgwZoneRemote finds the imported remote hosting the named zone.
+func getRgwZoneRemote(remotes types.RemoteRecords, zoneName string) (string, string, bool) {
+	for _, remote := range remotes {
+		if remote.Name == zoneName {
+			return remote.Name, remote.LocalName, true
+		}
+	}
+
+	logger.Warnf("REPRGW: no imported remote hosts zone %s", zoneName)
+	return "", "", false
+}
+
+func getRgwReplicationZones(zonegroup RgwZoneGroup) []types.RgwReplicationZone {
+	zones := make([]types.RgwReplicationZone, 0, len(zonegroup.Zones))
+	for _, zone := range zonegroup.Zones {
+		zones = append(zones, types.RgwReplicationZone{
+			Name:      zone.Name,
+			ID:        zone.ID,
+			Endpoints: zone.Endpoints,
+			IsMaster:  zone.ID == zonegroup.MasterZone,
+			ReadOnly:  zone.ReadOnly,
+		})
+	}
+
+	return zones
+}
+
+func getRgwSyncVerdictResponse(verdict RgwSyncVerdict) types.RgwSyncVerdict {
+	return types.RgwSyncVerdict{
+		CaughtUp:           verdict.CaughtUp,
+		BehindShards:       verdict.BehindShards,
+		FullSyncShards:     verdict.FullSyncShards,
+		PeriodMismatch:     verdict.PeriodMismatch,
+		PeerLogUnavailable: verdict.PeerLogUnavailable,
+	}
+}
+
+func getRgwNameOrDefault(name string, defaultName string) string {
+	if len(name) == 0 {
+		return defaultName
+	}
+
+	return name
+}
+
+// restartMemberRgws restarts the RGW daemons of every other cluster member.
+var restartMemberRgws = func(ctx context.Context, s interfaces.StateInterface) error {
+	return client.SendRestartRequestToClusterMembers(ctx, s.ClusterState(), []string{"rgw"})
+}
+
+// restartClusterRgw restarts the RGW daemons of every cluster member to apply zone changes.
+func restartClusterRgw(ctx context.Context, s interfaces.StateInterface) error {
+	err := restartLocalRgw()
+	if err != nil {
+		return err
+	}
+
+	err = restartMemberRgws(ctx, s)
+	if err != nil {
+		return fmt.Errorf("failed to restart RGW on cluster members: %w", err)
+	}
+
+	return nil
+}
+
+// restartLocalRgw restarts the RGW daemon of this host, if it runs one, to apply zone changes.
+func restartLocalRgw() error {
+	if snapCheckActive("rgw") != nil {
+		logger.Infof("REPRGW: RGW is not running on this host, skipping restart")
+		return nil
+	}
+
+	return RestartRGW()
+}
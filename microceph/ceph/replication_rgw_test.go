@@ -0,0 +1,226 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type RgwReplicationSuite struct {
+	tests.BaseSuite
+	memberRestarts int
+
+	getRemoteDb       func(context.Context, mcTypes.State, string) (types.RemoteRecords, error)
+	restartMemberRgws func(context.Context, interfaces.StateInterface) error
+}
+
+func TestRgwReplication(t *testing.T) {
+	suite.Run(t, new(RgwReplicationSuite))
+}
+
+func (s *RgwReplicationSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+
+	s.memberRestarts = 0
+	s.getRemoteDb = database.GetRemoteDb
+	s.restartMemberRgws = restartMemberRgws
+
+	database.GetRemoteDb = func(ctx context.Context, st mcTypes.State, name string) (types.RemoteRecords, error) {
+		return types.RemoteRecords{{ID: 1, Name: name, LocalName: "sitea"}}, nil
+	}
+	restartMemberRgws = func(ctx context.Context, st interfaces.StateInterface) error {
+		s.memberRestarts++
+		return nil
+	}
+}
+
+func (s *RgwReplicationSuite) TearDownTest() {
+	database.GetRemoteDb = s.getRemoteDb
+	restartMemberRgws = s.restartMemberRgws
+	s.BaseSuite.TearDownTest()
+}
+
+func (s *RgwReplicationSuite) siteOpArgs() []any {
+	var resp string
+	return []any{nil, &resp, interfaces.CephState{State: &mocks.MockState{}}}
+}
+
+// zonegroup with zone "sitea" (z1) as master and "siteb" (z2) as secondary.
+func (s *RgwReplicationSuite) zoneGroup(masterReadOnly bool) RgwZoneGroup {
+	return RgwZoneGroup{
+		ID:         "zg1",
+		Name:       "microceph",
+		MasterZone: "z1",
+		Zones: []RgwZoneGroupZone{
+			{ID: "z1", Name: "sitea", ReadOnly: masterReadOnly},
+			{ID: "z2", Name: "siteb"},
+		},
+	}
+}
+
+func (s *RgwReplicationSuite) TestDemoteRequiresForce() {
+	rh := RgwReplicationHandler{
+		ZoneGroup: s.zoneGroup(false),
+		Zone:      RgwZone{ID: "z1", Name: "sitea"},
+		Request:   types.RgwReplicationRequest{RequestType: types.DemoteReplicationRequest},
+	}
+
+	err := rh.DemoteHandler(context.Background(), s.siteOpArgs()...)
+	assert.ErrorContains(s.T(), err, constants.CliForcePrompt)
+}
+
+func (s *RgwReplicationSuite) TestDemoteZone() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", "radosgw-admin", "zone", "modify", "--rgw-zone", "sitea", "--read-only=true").Return("", nil).Once()
+	r.On("RunCommand", "radosgw-admin", "period", "update", "--commit").Return("", nil).Once()
+	r.On("RunCommand", "snapctl", "services", "microceph.rgw").Return("microceph.rgw  enabled  inactive  -", nil).Once()
+	common.ProcessExec = r
+
+	rh := RgwReplicationHandler{
+		ZoneGroup: s.zoneGroup(false),
+		Zone:      RgwZone{ID: "z1", Name: "sitea"},
+		Request:   types.RgwReplicationRequest{RequestType: types.DemoteReplicationRequest, IsForceOp: true},
+	}
+
+	err := rh.DemoteHandler(context.Background(), s.siteOpArgs()...)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, s.memberRestarts)
+}
+
+func (s *RgwReplicationSuite) TestDisableSplitsZoneGroup() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", "radosgw-admin", "zonegroup", "remove", "--rgw-zonegroup", "microceph", "--rgw-zone", "siteb").Return("", nil).Once()
+	r.On("RunCommand", "radosgw-admin", "period", "update", "--commit").Return("", nil).Once()
+	r.On("RunCommand", "radosgw-admin", "zonegroup", "remove", "--rgw-zonegroup", "microceph", "--rgw-zone", "sitea",
+		"--cluster", "siteb", "--id", "sitea").Return("", nil).Once()
+	r.On("RunCommand", "radosgw-admin", "zone", "modify", "--rgw-zone", "siteb", "--master", "--default", "--read-only=false",
+		"--cluster", "siteb", "--id", "sitea").Return("", nil).Once()
+	r.On("RunCommand", "radosgw-admin", "period", "update", "--commit", "--cluster", "siteb", "--id", "sitea").Return("", nil).Once()
+	r.On("RunCommand", "snapctl", "services", "microceph.rgw").Return("microceph.rgw  enabled  inactive  -", nil).Once()
+	common.ProcessExec = r
+
+	rh := RgwReplicationHandler{
+		ZoneGroup: s.zoneGroup(false),
+		Zone:      RgwZone{ID: "z1", Name: "sitea"},
+		Request:   types.RgwReplicationRequest{RequestType: types.DisableReplicationRequest, RemoteName: "siteb", IsForceOp: true},
+	}
+
+	err := rh.DisableHandler(context.Background(), s.siteOpArgs()...)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, s.memberRestarts)
+}
+
+func (s *RgwReplicationSuite) TestPromoteRefusesUndemotedMaster() {
+	rh := RgwReplicationHandler{
+		ZoneGroup: s.zoneGroup(false),
+		Zone:      RgwZone{ID: "z2", Name: "siteb"},
+		Request:   types.RgwReplicationRequest{RequestType: types.PromoteReplicationRequest},
+	}
+
+	err := rh.PromoteHandler(context.Background(), s.siteOpArgs()...)
+	assert.ErrorContains(s.T(), err, constants.CliForcePrompt)
+}
+
+func (s *RgwReplicationSuite) TestPromoteZone() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", "radosgw-admin", "zone", "modify", "--rgw-zone", "siteb", "--master", "--default", "--read-only=false").Return("", nil).Once()
+	r.On("RunCommand", "radosgw-admin", "period", "update", "--commit").Return("", nil).Once()
+	r.On("RunCommand", "snapctl", "services", "microceph.rgw").Return("microceph.rgw  enabled  inactive  -", nil).Once()
+	common.ProcessExec = r
+
+	rh := RgwReplicationHandler{
+		ZoneGroup: s.zoneGroup(true),
+		Zone:      RgwZone{ID: "z2", Name: "siteb"},
+		Request:   types.RgwReplicationRequest{RequestType: types.PromoteReplicationRequest},
+	}
+
+	err := rh.PromoteHandler(context.Background(), s.siteOpArgs()...)
+	assert.NoError(s.T(), err)
+}
+
+func (s *RgwReplicationSuite) TestGetRgwMasterZone() {
+	master, isMaster := getRgwMasterZone(s.zoneGroup(false), RgwZone{ID: "z2"})
+	assert.Equal(s.T(), "sitea", master.Name)
+	assert.False(s.T(), isMaster)
+
+	_, isMaster = getRgwMasterZone(s.zoneGroup(false), RgwZone{ID: "z1"})
+	assert.True(s.T(), isMaster)
+}
+
+func (s *RgwReplicationSuite) TestGetRgwSyncStatusWithoutZone() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", "radosgw-admin", "realm", "get").Return("", fmt.Errorf("no realm")).Once()
+	r.On("RunCommand", "radosgw-admin", "zonegroup", "get").Return(`{"id": "zg1", "name": "default"}`, nil).Once()
+	r.On("RunCommand", "radosgw-admin", "zone", "get").Return(`{"id": "z1", "name": "default"}`, nil).Once()
+	common.ProcessExec = r
+
+	_, err := GetRgwSyncStatus(context.Background(), &mocks.MockState{})
+	assert.ErrorContains(s.T(), err, "no RGW multisite zone configured")
+}
+
+func (s *RgwReplicationSuite) TestJoinSecondaryZoneResumes() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", "radosgw-admin", "realm", "pull", "--url", "http://10.0.0.1:80", "--access-key", "AK", "--secret", "SK", "--default",
+		"--cluster", "siteb", "--id", "sitea").Return("{}", nil).Once()
+	// The zone was created by an earlier enable which failed later on.
+	r.On("RunCommand", "radosgw-admin", "zone", "get", "--cluster", "siteb", "--id", "sitea").Return(`{"id": "z2", "name": "siteb"}`, nil).Once()
+	r.On("RunCommand", "radosgw-admin", "zone", "modify", "--rgw-zonegroup", "microceph", "--rgw-zone", "siteb", "--endpoints", "http://10.0.0.2:80",
+		"--access-key", "AK", "--secret", "SK", "--default", "--cluster", "siteb", "--id", "sitea").Return("{}", nil).Once()
+	r.On("RunCommand", "ceph", "config", "set", "client", "rgw_realm", "gold", "--cluster", "siteb", "--id", "sitea").Return("", nil).Once()
+	r.On("RunCommand", "ceph", "config", "set", "client", "rgw_zonegroup", "microceph", "--cluster", "siteb", "--id", "sitea").Return("", nil).Once()
+	r.On("RunCommand", "ceph", "config", "set", "client", "rgw_zone", "siteb", "--cluster", "siteb", "--id", "sitea").Return("", nil).Once()
+	r.On("RunCommand", "radosgw-admin", "period", "update", "--commit", "--cluster", "siteb", "--id", "sitea").Return("", nil).Once()
+	common.ProcessExec = r
+
+	rh := RgwReplicationHandler{
+		Realm:     RgwRealm{Name: "gold"},
+		ZoneGroup: RgwZoneGroup{Name: "microceph"},
+		Request:   types.RgwReplicationRequest{Endpoints: "http://10.0.0.1:80", RemoteEndpoints: "http://10.0.0.2:80"},
+	}
+
+	err := rh.joinSecondaryZone(types.RemoteRecord{Name: "siteb", LocalName: "sitea"}, RgwZoneSystemKey{AccessKey: "AK", SecretKey: "SK"})
+	assert.NoError(s.T(), err)
+}
+
+func (s *RgwReplicationSuite) TestJoinSecondaryZoneWithdrawsOnFailedCommit() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", "radosgw-admin", "realm", "pull", "--url", "http://10.0.0.1:80", "--access-key", "AK", "--secret", "SK", "--default",
+		"--cluster", "siteb", "--id", "sitea").Return("{}", nil).Once()
+	r.On("RunCommand", "radosgw-admin", "zone", "get", "--cluster", "siteb", "--id", "sitea").Return(`{"id": "z0", "name": "default"}`, nil).Once()
+	r.On("RunCommand", "radosgw-admin", "zone", "create", "--rgw-zonegroup", "microceph", "--rgw-zone", "siteb", "--endpoints", "http://10.0.0.2:80",
+		"--access-key", "AK", "--secret", "SK", "--default", "--cluster", "siteb", "--id", "sitea").Return("{}", nil).Once()
+	for _, option := range rgwZoneHandoverOptions {
+		r.On("RunCommand", "ceph", "config", "set", "client", option, mock.Anything, "--cluster", "siteb", "--id", "sitea").Return("", nil).Once()
+		r.On("RunCommand", "ceph", "config", "rm", "client", option, "--cluster", "siteb", "--id", "sitea").Return("", nil).Once()
+	}
+	r.On("RunCommand", "radosgw-admin", "period", "update", "--commit", "--cluster", "siteb", "--id", "sitea").Return("", fmt.Errorf("period conflict")).Once()
+	common.ProcessExec = r
+
+	rh := RgwReplicationHandler{
+		Realm:     RgwRealm{Name: "gold"},
+		ZoneGroup: RgwZoneGroup{Name: "microceph"},
+		Request:   types.RgwReplicationRequest{Endpoints: "http://10.0.0.1:80", RemoteEndpoints: "http://10.0.0.2:80"},
+	}
+
+	err := rh.joinSecondaryZone(types.RemoteRecord{Name: "siteb", LocalName: "sitea"}, RgwZoneSystemKey{AccessKey: "AK", SecretKey: "SK"})
+	assert.ErrorContains(s.T(), err, "period conflict")
+}
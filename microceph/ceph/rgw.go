@@ -5,12 +5,77 @@ import (
 	"encoding/base64"
 	"fmt"
 	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
 	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 )
 
+// rgwInstance locates the files of an RGW daemon on this host. The default
+// daemon has no group ID, grouped daemons are named after their group.
+type rgwInstance struct {
+	groupID string
+}
+
+// name is the ceph client name the daemon runs as, without the client. prefix.
+func (i rgwInstance) name() string {
+	if len(i.groupID) == 0 {
+		return "radosgw.gateway"
+	}
+
+	return fmt.Sprintf("radosgw.%s", i.groupID)
+}
+
+// confFile is the name of the daemon configuration file in the conf directory.
+func (i rgwInstance) confFile() string {
+	if len(i.groupID) == 0 {
+		return "radosgw.conf"
+	}
+
+	return fmt.Sprintf("radosgw-%s.conf", i.groupID)
+}
+
+// dataDir is the directory holding the daemon keyring.
+func (i rgwInstance) dataDir(dataPath string) string {
+	return filepath.Join(dataPath, "radosgw", fmt.Sprintf("ceph-%s", i.name()))
+}
+
+// sslDir is the directory holding the daemon SSL certificate and key.
+func (i rgwInstance) sslDir(sslFilesPath string) string {
+	if len(i.groupID) == 0 {
+		return sslFilesPath
+	}
+
+	return filepath.Join(sslFilesPath, fmt.Sprintf("rgw-%s", i.groupID))
+}
+
+// rgwInstances lists the RGW daemons configured in confDir.
+func rgwInstances(confDir string) []rgwInstance {
+	instances := []rgwInstance{}
+	_, err := os.Stat(filepath.Join(confDir, rgwInstance{}.confFile()))
+	if err == nil {
+		instances = append(instances, rgwInstance{})
+	}
+
+	for _, file := range rgwGroupConfFiles(confDir) {
+		groupID := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "radosgw-"), ".conf")
+		instances = append(instances, rgwInstance{groupID: groupID})
+	}
+
+	return instances
+}
+
+// rgwGroupConfFiles lists the configuration files of the grouped RGW daemons in confDir.
+func rgwGroupConfFiles(confDir string) []string {
+	// The pattern is well formed, Glob can only fail with ErrBadPattern.
+	files, _ := filepath.Glob(filepath.Join(confDir, "radosgw-*.conf"))
+	return files
+}
+
 // writeSSLFiles decodes base64-encoded SSL certificate and key, and writes them to disk.
 // Returns the paths to the written certificate and key files.
 func writeSSLFiles(sslFilesPath string, sslCertificate string, sslPrivateKey string) (certPath string, keyPath string, err error) {
@@ -64,14 +129,34 @@ func writeSSLFiles(sslFilesPath string, sslCertificate string, sslPrivateKey str
 }
 
 // EnableRGW enables the RGW service on the cluster and adds initial configuration given a service port number.
-func EnableRGW(s interfaces.StateInterface, port int, sslPort int, sslCertificate string, sslPrivateKey string, monitors []string) error {
+// The daemon serves the given multisite zone, if any.
+func EnableRGW(s interfaces.StateInterface, port int, sslPort int, sslCertificate string, sslPrivateKey string, monitors []string, zone RgwZoneConfig) error {
+	return enableRGWInstance(rgwInstance{}, port, sslPort, sslCertificate, sslPrivateKey, monitors, zone)
+}
+
+// EnableRGWGroup adds an RGW daemon of the given group to this host, next to
+// the default daemon and those of other groups. Each group listens on its own
+// ports with its own certificate and may serve its own multisite zone.
+func EnableRGWGroup(s interfaces.StateInterface, rgw *RgwServicePlacement, monitors []string) error {
+	logger.Debugf("Enabling RGW on node with GroupID '%s'", rgw.GroupID)
+	zone := RgwZoneConfig{Realm: rgw.Realm, ZoneGroup: rgw.ZoneGroup, Zone: rgw.Zone}
+	return enableRGWInstance(rgwInstance{groupID: rgw.GroupID}, rgw.Port, rgw.SSLPort, rgw.SSLCertificate, rgw.SSLPrivateKey, monitors, zone)
+}
+
+// enableRGWInstance writes the configuration and keyring of an RGW daemon and starts it.
+func enableRGWInstance(inst rgwInstance, port int, sslPort int, sslCertificate string, sslPrivateKey string, monitors []string, zone RgwZoneConfig) error {
 	pathConsts := constants.GetPathConst()
 
 	sslCertificatePath := ""
 	sslPrivateKeyPath := ""
 	if sslCertificate != "" && sslPrivateKey != "" {
-		var err error
-		sslCertificatePath, sslPrivateKeyPath, err = writeSSLFiles(pathConsts.SSLFilesPath, sslCertificate, sslPrivateKey)
+		sslDir := inst.sslDir(pathConsts.SSLFilesPath)
+		err := os.MkdirAll(sslDir, 0700)
+		if err != nil {
+			return fmt.Errorf("failed to create RGW SSL directory: %w", err)
+		}
+
+		sslCertificatePath, sslPrivateKeyPath, err = writeSSLFiles(sslDir, sslCertificate, sslPrivateKey)
 		if err != nil {
 			return err
 		}
@@ -90,24 +175,31 @@ func EnableRGW(s interfaces.StateInterface, port int, sslPort int, sslCertificat
 		"sslPort":            sslPort,
 		"sslCertificatePath": sslCertificatePath,
 		"sslPrivateKeyPath":  sslPrivateKeyPath,
+		"rgwZoneLines":       zone.confLines(),
 	}
 
 	// Create RGW configuration.
-	rgwConf := newRadosGWConfig(pathConsts.ConfPath)
+	rgwConf := newRadosGWInstanceConfig(pathConsts.ConfPath, inst.confFile(), inst.name())
 	err := rgwConf.WriteConfig(configs, 0644)
 	if err != nil {
 		return err
 	}
 	// Create RGW keyring.
-	path := filepath.Join(pathConsts.DataPath, "radosgw", "ceph-radosgw.gateway")
-	if err = createRGWKeyring(path); err != nil {
+	path := inst.dataDir(pathConsts.DataPath)
+	if err = createRGWKeyring(path, inst.name()); err != nil {
 		return err
 	}
 	// Symlink the keyring to the conf directory for usage with the radosgw-admin command.
-	if err = symlinkRGWKeyring(path, pathConsts.ConfPath); err != nil {
+	if err = symlinkRGWKeyring(path, pathConsts.ConfPath, inst.name()); err != nil {
 		return err
 	}
 
+	// The RGW service runs every daemon configured on the host, it needs a
+	// reload to pick up a new one while others are running.
+	if len(inst.groupID) != 0 || len(rgwGroupConfFiles(pathConsts.ConfPath)) != 0 {
+		return reloadRGW()
+	}
+
 	if err = startRGW(); err != nil {
 		return err
 	}
@@ -118,6 +210,17 @@ func EnableRGW(s interfaces.StateInterface, port int, sslPort int, sslCertificat
 // UpdateRGWCertificates decodes base64 SSL certificate and key, and writes them to disk.
 // RGW must be active for this operation.
 func UpdateRGWCertificates(s interfaces.StateInterface, sslCertificate string, sslPrivateKey string) error {
+	return updateRGWInstanceCertificates(rgwInstance{}, sslCertificate, sslPrivateKey)
+}
+
+// UpdateRGWGroupCertificates writes the SSL certificate and key of the RGW daemon of the given group.
+// RGW must be active for this operation.
+func UpdateRGWGroupCertificates(s interfaces.StateInterface, groupID string, sslCertificate string, sslPrivateKey string) error {
+	return updateRGWInstanceCertificates(rgwInstance{groupID: groupID}, sslCertificate, sslPrivateKey)
+}
+
+// updateRGWInstanceCertificates replaces the SSL certificate and key of an RGW daemon.
+func updateRGWInstanceCertificates(inst rgwInstance, sslCertificate string, sslPrivateKey string) error {
 	if err := snapCheckActive("rgw"); err != nil {
 		return fmt.Errorf("RGW service is not running: %w", err)
 	}
@@ -125,7 +228,7 @@ func UpdateRGWCertificates(s interfaces.StateInterface, sslCertificate string, s
 	pathConsts := constants.GetPathConst()
 
 	// Verify that RGW was configured with SSL by checking the config file.
-	confPath := filepath.Join(pathConsts.ConfPath, "radosgw.conf")
+	confPath := filepath.Join(pathConsts.ConfPath, inst.confFile())
 	confData, err := os.ReadFile(confPath)
 	if err != nil {
 		return fmt.Errorf("failed to read RGW configuration: %w", err)
@@ -134,8 +237,13 @@ func UpdateRGWCertificates(s interfaces.StateInterface, sslCertificate string, s
 		return fmt.Errorf("RGW is not configured with SSL; enable RGW with --ssl-certificate and --ssl-private-key first")
 	}
 
-	_, _, err = writeSSLFiles(pathConsts.SSLFilesPath, sslCertificate, sslPrivateKey)
-	return err
+	_, _, err = writeSSLFiles(inst.sslDir(pathConsts.SSLFilesPath), sslCertificate, sslPrivateKey)
+	if err != nil {
+		return err
+	}
+
+	// The certificate is no longer the self-managed one, if it ever was.
+	return writeRGWCAFile(inst, nil)
 }
 
 // RestartRGW restarts the RGW service for immediate certificate pickup.
@@ -150,40 +258,105 @@ func RestartRGW() error {
 func DisableRGW(ctx context.Context, s interfaces.StateInterface) error {
 	pathConsts := constants.GetPathConst()
 
-	err := stopRGW()
+	// Leave the grouped daemons running, if any.
+	if len(rgwGroupConfFiles(pathConsts.ConfPath)) == 0 {
+		err := stopRGW()
+		if err != nil {
+			return fmt.Errorf("Failed to stop RGW service: %w", err)
+		}
+	}
+
+	err := removeServiceDatabase(ctx, s, "rgw")
 	if err != nil {
-		return fmt.Errorf("Failed to stop RGW service: %w", err)
+		return err
+	}
+
+	err = removeRGWInstanceFiles(rgwInstance{})
+	if err != nil {
+		return err
+	}
+
+	if len(rgwGroupConfFiles(pathConsts.ConfPath)) != 0 {
+		return reloadRGW()
+	}
+
+	return nil
+}
+
+// DisableRGWGroup removes the RGW daemon of the given group from this host.
+func DisableRGWGroup(ctx context.Context, s interfaces.StateInterface, groupID string) error {
+	exists, err := database.GroupedServicesQuery.ExistsOnHost(ctx, s, "rgw", groupID)
+	if err != nil {
+		return fmt.Errorf("failed to verify the node's RGW service GroupID: %w", err)
+	} else if !exists {
+		return fmt.Errorf("RGW service with GroupID '%s' not found on node '%s'", groupID, s.ClusterState().Name())
 	}
 
-	err = removeServiceDatabase(ctx, s, "rgw")
+	logger.Debugf("Disabling RGW on node with GroupID '%s'", groupID)
+	pathConsts := constants.GetPathConst()
+	inst := rgwInstance{groupID: groupID}
+
+	err = removeRGWInstanceFiles(inst)
 	if err != nil {
 		return err
 	}
 
+	// Reload the service so the daemon goes away, or stop it if it was the last one.
+	_, err = os.Stat(filepath.Join(pathConsts.ConfPath, "radosgw.conf"))
+	if err == nil || len(rgwGroupConfFiles(pathConsts.ConfPath)) != 0 {
+		err = reloadRGW()
+	} else {
+		err = stopRGW()
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Removing ceph client 'client.%s' (GroupID '%s')", inst.name(), groupID)
+	err = DeleteClientKey(inst.name())
+	if err != nil {
+		return fmt.Errorf("failed to remove RGW keyring: %w", err)
+	}
+
+	logger.Debugf("Removing RGW service records from database (GroupID '%s')", groupID)
+	return database.GroupedServicesQuery.RemoveForHost(ctx, s, "rgw", groupID)
+}
+
+// removeRGWInstanceFiles removes the keyring, SSL files and configuration of an RGW daemon.
+func removeRGWInstanceFiles(inst rgwInstance) error {
+	pathConsts := constants.GetPathConst()
+
 	// Remove the keyring symlink.
-	err = os.Remove(filepath.Join(pathConsts.ConfPath, "ceph.client.radosgw.gateway.keyring"))
+	err := os.Remove(filepath.Join(pathConsts.ConfPath, fmt.Sprintf("ceph.client.%s.keyring", inst.name())))
 	if err != nil {
 		return fmt.Errorf("failed to remove RGW keyring symlink: %w", err)
 	}
 
 	// Remove the keyring.
-	err = os.Remove(filepath.Join(pathConsts.DataPath, "radosgw", "ceph-radosgw.gateway", "keyring"))
+	err = os.Remove(filepath.Join(inst.dataDir(pathConsts.DataPath), "keyring"))
 	if err != nil {
 		return fmt.Errorf("failed to remove RGW keyring: %w", err)
 	}
 
 	// Remove the SSL files.
-	err = os.Remove(filepath.Join(pathConsts.SSLFilesPath, "server.crt"))
+	sslDir := inst.sslDir(pathConsts.SSLFilesPath)
+	err = os.Remove(filepath.Join(sslDir, "server.crt"))
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove RGW SSL Certificate file: %w", err)
 	}
-	err = os.Remove(filepath.Join(pathConsts.SSLFilesPath, "server.key"))
+	err = os.Remove(filepath.Join(sslDir, "server.key"))
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove RGW SSL Private Key file: %w", err)
 	}
+	if len(inst.groupID) != 0 {
+		err = os.Remove(sslDir)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove RGW SSL directory: %w", err)
+		}
+	}
 
 	// Remove the configuration.
-	err = os.Remove(filepath.Join(pathConsts.ConfPath, "radosgw.conf"))
+	err = os.Remove(filepath.Join(pathConsts.ConfPath, inst.confFile()))
 	if err != nil {
 		return fmt.Errorf("failed to remove RGW configuration: %w", err)
 	}
@@ -201,6 +374,31 @@ func startRGW() error {
 	return nil
 }
 
+// reloadRGW starts the RGW service, or asks it to pick up added or removed
+// daemons if it is already running. The daemons of other groups keep running,
+// the service is only restarted if it cannot be signalled.
+func reloadRGW() error {
+	if snapCheckActive("rgw") != nil {
+		return startRGW()
+	}
+
+	pidFile := filepath.Join(constants.GetPathConst().RunPath, "rgw.pid")
+	data, err := os.ReadFile(pidFile)
+	if err == nil {
+		var pid int
+		pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+		if err == nil {
+			err = syscall.Kill(pid, syscall.SIGHUP)
+		}
+	}
+	if err != nil {
+		logger.Warnf("Failed to signal the RGW service, restarting it: %v", err)
+		return RestartRGW()
+	}
+
+	return nil
+}
+
 // stopRGW stops the RGW service.
 func stopRGW() error {
 	err := snapStop("rgw", true)
@@ -211,8 +409,8 @@ func stopRGW() error {
 	return nil
 }
 
-// createRGWKeyring creates the RGW keyring.
-func createRGWKeyring(path string) error {
+// createRGWKeyring creates the keyring of the RGW daemon running as client.<name>.
+func createRGWKeyring(path string, name string) error {
 	if err := os.MkdirAll(path, 0770); err != nil {
 		return err
 	}
@@ -224,7 +422,7 @@ func createRGWKeyring(path string) error {
 
 	err := genAuth(
 		keyringPath,
-		"client.radosgw.gateway",
+		fmt.Sprintf("client.%s", name),
 		[]string{"mon", "allow rw"},
 		[]string{"osd", "allow rwx"})
 	if err != nil {
@@ -235,10 +433,10 @@ func createRGWKeyring(path string) error {
 }
 
 // symlinkRGWKeyring creates a symlink to the RGW keyring in the conf directory for use with the radosgw-admin command.
-func symlinkRGWKeyring(keyPath, ConfPath string) error {
+func symlinkRGWKeyring(keyPath, ConfPath string, name string) error {
 	if err := os.Symlink(
 		filepath.Join(keyPath, "keyring"),
-		filepath.Join(ConfPath, "ceph.client.radosgw.gateway.keyring")); err != nil {
+		filepath.Join(ConfPath, fmt.Sprintf("ceph.client.%s.keyring", name))); err != nil {
 		return fmt.Errorf("Failed to create symlink to RGW keyring: %w", err)
 	}
 
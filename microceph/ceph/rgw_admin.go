@@ -0,0 +1,330 @@
+package ceph
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// RGW quota scopes as named by radosgw-admin.
+const (
+	rgwQuotaScopeUser   = "user"
+	rgwQuotaScopeBucket = "bucket"
+)
+
+// rgwUser is the subset of `user info` output we use.
+type rgwUser struct {
+	UserID      string             `json:"user_id"`
+	DisplayName string             `json:"display_name"`
+	Email       string             `json:"email"`
+	Suspended   int                `json:"suspended"`
+	MaxBuckets  int                `json:"max_buckets"`
+	Keys        []types.RgwUserKey `json:"keys"`
+	UserQuota   types.RgwQuota     `json:"user_quota"`
+	BucketQuota types.RgwQuota     `json:"bucket_quota"`
+}
+
+// rgwBucketStats is the subset of `bucket stats` output we use.
+type rgwBucketStats struct {
+	Bucket string `json:"bucket"`
+	Owner  string `json:"owner"`
+	Usage  map[string]struct {
+		SizeActual int64 `json:"size_actual"`
+		NumObjects int64 `json:"num_objects"`
+	} `json:"usage"`
+}
+
+// parseRgwUser converts `user info` style output into an RgwUser.
+func parseRgwUser(output string) (types.RgwUser, error) {
+	user := rgwUser{}
+	err := json.Unmarshal([]byte(output), &user)
+	if err != nil {
+		return types.RgwUser{}, fmt.Errorf("cannot unmarshal user info output: %w", err)
+	}
+
+	return types.RgwUser{
+		UserID:      user.UserID,
+		DisplayName: user.DisplayName,
+		Email:       user.Email,
+		Suspended:   user.Suspended != 0,
+		MaxBuckets:  user.MaxBuckets,
+		Keys:        user.Keys,
+		UserQuota:   user.UserQuota,
+		BucketQuota: user.BucketQuota,
+	}, nil
+}
+
+// ListRgwUsers fetches the IDs of the RGW users in a single call, use
+// GetRgwUser for the details of a user.
+func ListRgwUsers() ([]string, error) {
+	output, err := radosgwAdminRun("user", "list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list RGW users: %w", err)
+	}
+
+	uids := []string{}
+	err = json.Unmarshal([]byte(output), &uids)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal user list output: %w", err)
+	}
+
+	return uids, nil
+}
+
+// GetRgwUser fetches an RGW user along with its access key IDs and quotas.
+// Secret keys are only handed out when a user or key is created.
+func GetRgwUser(uid string) (types.RgwUser, error) {
+	output, err := radosgwAdminRun("user", "info", "--uid", uid)
+	if err != nil {
+		return types.RgwUser{}, fmt.Errorf("failed to fetch RGW user %s: %w", uid, err)
+	}
+
+	user, err := parseRgwUser(output)
+	if err != nil {
+		return types.RgwUser{}, err
+	}
+
+	for i := range user.Keys {
+		user.Keys[i].SecretKey = ""
+	}
+
+	return user, nil
+}
+
+// CheckRgwUserChangeable refuses changes to the multisite system user, its
+// keys authenticate the sync between zones and are managed by MicroCeph.
+func CheckRgwUserChangeable(uid string) error {
+	if uid == constants.RgwSystemUserID {
+		return fmt.Errorf("RGW user %s authenticates multisite sync and is managed by MicroCeph", uid)
+	}
+
+	return nil
+}
+
+// CreateRgwUser creates an RGW user, RGW generates its first S3 key.
+func CreateRgwUser(req types.RgwUserRequest) (types.RgwUser, error) {
+	err := CheckRgwUserChangeable(req.UserID)
+	if err != nil {
+		return types.RgwUser{}, err
+	}
+
+	args := []string{"user", "create", "--uid", req.UserID, "--display-name", req.DisplayName}
+	if len(req.Email) != 0 {
+		args = append(args, "--email", req.Email)
+	}
+	if req.MaxBuckets != 0 {
+		args = append(args, "--max-buckets", strconv.Itoa(req.MaxBuckets))
+	}
+
+	output, err := radosgwAdminRun(args...)
+	if err != nil {
+		return types.RgwUser{}, fmt.Errorf("failed to create RGW user %s: %w", req.UserID, err)
+	}
+
+	logger.Infof("RGW: created user %s", req.UserID)
+	return parseRgwUser(output)
+}
+
+// SetRgwUser suspends or re-enables an RGW user and updates its bucket limit.
+func SetRgwUser(uid string, req types.RgwUserSetRequest) error {
+	err := CheckRgwUserChangeable(uid)
+	if err != nil {
+		return err
+	}
+
+	if req.Suspended != nil {
+		op := "enable"
+		if *req.Suspended {
+			op = "suspend"
+		}
+
+		_, err = radosgwAdminRun("user", op, "--uid", uid)
+		if err != nil {
+			return fmt.Errorf("failed to %s RGW user %s: %w", op, uid, err)
+		}
+
+		logger.Infof("RGW: %s user %s", op, uid)
+	}
+
+	if req.MaxBuckets != nil {
+		_, err = radosgwAdminRun("user", "modify", "--uid", uid, "--max-buckets", strconv.Itoa(*req.MaxBuckets))
+		if err != nil {
+			return fmt.Errorf("failed to set max buckets of RGW user %s: %w", uid, err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteRgwUser removes an RGW user, its buckets and objects too if purging data.
+func DeleteRgwUser(uid string, req types.RgwUserDeleteRequest) error {
+	err := CheckRgwUserChangeable(uid)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"user", "rm", "--uid", uid}
+	if req.PurgeData {
+		args = append(args, "--purge-data")
+	}
+
+	_, err = radosgwAdminRun(args...)
+	if err != nil {
+		return fmt.Errorf("failed to remove RGW user %s: %w", uid, err)
+	}
+
+	logger.Infof("RGW: removed user %s, purge data %t", uid, req.PurgeData)
+	return nil
+}
+
+// CreateRgwUserKey generates a new S3 key for an RGW user. When rotating,
+// the old key is removed only once the new one exists.
+func CreateRgwUserKey(uid string, req types.RgwKeyRequest) (types.RgwUserKey, error) {
+	err := CheckRgwUserChangeable(uid)
+	if err != nil {
+		return types.RgwUserKey{}, err
+	}
+
+	user, err := GetRgwUser(uid)
+	if err != nil {
+		return types.RgwUserKey{}, err
+	}
+
+	existing := []string{}
+	for _, key := range user.Keys {
+		existing = append(existing, key.AccessKey)
+	}
+
+	if len(req.Rotate) != 0 && !slices.Contains(existing, req.Rotate) {
+		return types.RgwUserKey{}, fmt.Errorf("RGW user %s has no access key %s", uid, req.Rotate)
+	}
+
+	output, err := radosgwAdminRun("key", "create", "--uid", uid, "--key-type", "s3", "--gen-access-key", "--gen-secret")
+	if err != nil {
+		return types.RgwUserKey{}, fmt.Errorf("failed to generate key for RGW user %s: %w", uid, err)
+	}
+
+	user, err = parseRgwUser(output)
+	if err != nil {
+		return types.RgwUserKey{}, err
+	}
+
+	newKey := types.RgwUserKey{}
+	for _, key := range user.Keys {
+		if !slices.Contains(existing, key.AccessKey) {
+			newKey = key
+		}
+	}
+
+	if len(newKey.AccessKey) == 0 {
+		return types.RgwUserKey{}, fmt.Errorf("no new key found for RGW user %s", uid)
+	}
+
+	if len(req.Rotate) != 0 {
+		err = RemoveRgwUserKey(uid, req.Rotate)
+		if err != nil {
+			return newKey, err
+		}
+
+		logger.Infof("RGW: rotated key %s of user %s", req.Rotate, uid)
+	}
+
+	return newKey, nil
+}
+
+// RemoveRgwUserKey removes an S3 key of an RGW user.
+func RemoveRgwUserKey(uid string, accessKey string) error {
+	err := CheckRgwUserChangeable(uid)
+	if err != nil {
+		return err
+	}
+
+	_, err = radosgwAdminRun("key", "rm", "--uid", uid, "--key-type", "s3", "--access-key", accessKey)
+	if err != nil {
+		return fmt.Errorf("failed to remove key %s of RGW user %s: %w", accessKey, uid, err)
+	}
+
+	return nil
+}
+
+// SetRgwUserQuota sets and enables or disables the user or per-bucket quota of an RGW user.
+func SetRgwUserQuota(uid string, req types.RgwQuotaRequest) error {
+	err := CheckRgwUserChangeable(uid)
+	if err != nil {
+		return err
+	}
+
+	if req.Scope != rgwQuotaScopeUser && req.Scope != rgwQuotaScopeBucket {
+		return fmt.Errorf("invalid quota scope %q, expected %s or %s", req.Scope, rgwQuotaScopeUser, rgwQuotaScopeBucket)
+	}
+
+	_, err = radosgwAdminRun("quota", "set", "--quota-scope", req.Scope, "--uid", uid,
+		"--max-size", strconv.FormatInt(req.MaxSize, 10), "--max-objects", strconv.FormatInt(req.MaxObjects, 10))
+	if err != nil {
+		return fmt.Errorf("failed to set %s quota of RGW user %s: %w", req.Scope, uid, err)
+	}
+
+	op := "disable"
+	if req.Enabled {
+		op = "enable"
+	}
+
+	_, err = radosgwAdminRun("quota", op, "--quota-scope", req.Scope, "--uid", uid)
+	if err != nil {
+		return fmt.Errorf("failed to %s %s quota of RGW user %s: %w", op, req.Scope, uid, err)
+	}
+
+	return nil
+}
+
+// ListRgwBuckets fetches the RGW buckets with their usage, optionally only those of an owner.
+func ListRgwBuckets(owner string) (types.RgwBuckets, error) {
+	output, err := radosgwAdminRun("bucket", "stats")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list RGW buckets: %w", err)
+	}
+
+	stats := []rgwBucketStats{}
+	err = json.Unmarshal([]byte(output), &stats)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal bucket stats output: %w", err)
+	}
+
+	buckets := types.RgwBuckets{}
+	for _, stat := range stats {
+		if len(owner) != 0 && stat.Owner != owner {
+			continue
+		}
+
+		bucket := types.RgwBucket{Name: stat.Bucket, Owner: stat.Owner}
+		for _, usage := range stat.Usage {
+			bucket.Size += usage.SizeActual
+			bucket.NumObjects += usage.NumObjects
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, nil
+}
+
+// SetRgwBucketOwner links a bucket to a new owner and hands its objects over to it.
+func SetRgwBucketOwner(bucket string, owner string) error {
+	_, err := radosgwAdminRun("bucket", "link", "--bucket", bucket, "--uid", owner)
+	if err != nil {
+		return fmt.Errorf("failed to link RGW bucket %s to %s: %w", bucket, owner, err)
+	}
+
+	_, err = radosgwAdminRun("bucket", "chown", "--bucket", bucket, "--uid", owner)
+	if err != nil {
+		return fmt.Errorf("failed to change owner of objects in RGW bucket %s to %s: %w", bucket, owner, err)
+	}
+
+	logger.Infof("RGW: bucket %s now owned by %s", bucket, owner)
+	return nil
+}
@@ -0,0 +1,125 @@
+package ceph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type rgwAdminSuite struct {
+	tests.BaseSuite
+}
+
+func TestRgwAdmin(t *testing.T) {
+	suite.Run(t, new(rgwAdminSuite))
+}
+
+const rgwAdminUserInfo = `{
+	"user_id": "tenant1",
+	"display_name": "Tenant One",
+	"email": "",
+	"suspended": 1,
+	"max_buckets": 1000,
+	"keys": [{"user": "tenant1", "access_key": "AK1", "secret_key": "SK1"}],
+	"bucket_quota": {"enabled": false, "max_size": -1, "max_objects": -1},
+	"user_quota": {"enabled": true, "max_size": 1073741824, "max_objects": -1}
+}`
+
+func (s *rgwAdminSuite) TestGetRgwUser() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "radosgw-admin", "user", "info", "--uid", "tenant1").Return(rgwAdminUserInfo, nil).Once()
+	common.ProcessExec = r
+
+	user, err := GetRgwUser("tenant1")
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), user.Suspended)
+	assert.Equal(s.T(), types.RgwUserKey{User: "tenant1", AccessKey: "AK1"}, user.Keys[0])
+	assert.Equal(s.T(), types.RgwQuota{Enabled: true, MaxSize: 1073741824, MaxObjects: -1}, user.UserQuota)
+}
+
+func (s *rgwAdminSuite) TestListRgwUsers() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "radosgw-admin", "user", "list").Return(`["tenant1", "tenant2"]`, nil).Once()
+	common.ProcessExec = r
+
+	users, err := ListRgwUsers()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"tenant1", "tenant2"}, users)
+}
+
+func (s *rgwAdminSuite) TestRotateRgwUserKey() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "radosgw-admin", "user", "info", "--uid", "tenant1").Return(rgwAdminUserInfo, nil).Once()
+	r.On("RunCommand", "radosgw-admin", "key", "create", "--uid", "tenant1", "--key-type", "s3", "--gen-access-key", "--gen-secret").
+		Return(`{"user_id": "tenant1", "keys": [
+			{"user": "tenant1", "access_key": "AK1", "secret_key": "SK1"},
+			{"user": "tenant1", "access_key": "AK2", "secret_key": "SK2"}]}`, nil).Once()
+	r.On("RunCommand", "radosgw-admin", "key", "rm", "--uid", "tenant1", "--key-type", "s3", "--access-key", "AK1").Return("", nil).Once()
+	common.ProcessExec = r
+
+	key, err := CreateRgwUserKey("tenant1", types.RgwKeyRequest{Rotate: "AK1"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), types.RgwUserKey{User: "tenant1", AccessKey: "AK2", SecretKey: "SK2"}, key)
+}
+
+func (s *rgwAdminSuite) TestRotateUnknownRgwUserKey() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "radosgw-admin", "user", "info", "--uid", "tenant1").Return(rgwAdminUserInfo, nil).Once()
+	common.ProcessExec = r
+
+	_, err := CreateRgwUserKey("tenant1", types.RgwKeyRequest{Rotate: "AK9"})
+	assert.ErrorContains(s.T(), err, "has no access key AK9")
+}
+
+func (s *rgwAdminSuite) TestSetRgwUserQuotaInvalidScope() {
+	err := SetRgwUserQuota("tenant1", types.RgwQuotaRequest{Scope: "zone"})
+	assert.ErrorContains(s.T(), err, "invalid quota scope")
+}
+
+func (s *rgwAdminSuite) TestRgwSystemUserRefused() {
+	// No command may run against the multisite system user.
+	r := mocks.NewRunner(s.T())
+	common.ProcessExec = r
+
+	suspended := true
+	err := SetRgwUser(constants.RgwSystemUserID, types.RgwUserSetRequest{Suspended: &suspended})
+	assert.ErrorContains(s.T(), err, "managed by MicroCeph")
+
+	err = DeleteRgwUser(constants.RgwSystemUserID, types.RgwUserDeleteRequest{PurgeData: true, IsForceOp: true})
+	assert.ErrorContains(s.T(), err, "managed by MicroCeph")
+
+	_, err = CreateRgwUserKey(constants.RgwSystemUserID, types.RgwKeyRequest{})
+	assert.ErrorContains(s.T(), err, "managed by MicroCeph")
+
+	err = RemoveRgwUserKey(constants.RgwSystemUserID, "AK1")
+	assert.ErrorContains(s.T(), err, "managed by MicroCeph")
+
+	err = SetRgwUserQuota(constants.RgwSystemUserID, types.RgwQuotaRequest{Scope: "user"})
+	assert.ErrorContains(s.T(), err, "managed by MicroCeph")
+}
+
+func (s *rgwAdminSuite) TestListRgwBuckets() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "radosgw-admin", "bucket", "stats").Return(`[
+		{"bucket": "b1", "owner": "tenant1", "usage": {
+			"rgw.main": {"size_actual": 4096, "num_objects": 2},
+			"rgw.multimeta": {"size_actual": 0, "num_objects": 1}}},
+		{"bucket": "b2", "owner": "tenant2", "usage": {}}]`, nil).Twice()
+	common.ProcessExec = r
+
+	buckets, err := ListRgwBuckets("")
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), buckets, 2)
+	assert.Equal(s.T(), types.RgwBucket{Name: "b1", Owner: "tenant1", NumObjects: 3, Size: 4096}, buckets[0])
+
+	buckets, err = ListRgwBuckets("tenant2")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), types.RgwBuckets{{Name: "b2", Owner: "tenant2"}}, buckets)
+}
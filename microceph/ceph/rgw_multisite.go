@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
 	"github.com/canonical/microceph/microceph/logger"
 )
 
@@ -454,3 +455,50 @@ func ComputeRgwDataSyncVerdict(local RgwDataSyncStatus, sourceLog []RgwLogShard)
 		len(verdict.BehindShards) == 0 && verdict.FullSyncShards == 0
 	return verdict
 }
+
+// ###### Multisite configuration ######
+
+// rgwUserInfo is the subset of `user create` and `user info` output we use.
+type rgwUserInfo struct {
+	UserID string             `json:"user_id"`
+	Keys   []RgwZoneSystemKey `json:"keys"`
+}
+
+// GetRgwSystemUserKey fetches the S3 keys of the multisite system user,
+// creating the user first when it does not exist yet. A non-empty
+// cluster/client pair targets a remote cluster.
+func GetRgwSystemUserKey(cluster string, client string) (RgwZoneSystemKey, error) {
+	user := rgwUserInfo{}
+
+	output, err := radosgwAdminRunRemote(cluster, client, "user", "info", "--uid", constants.RgwSystemUserID)
+	if err != nil {
+		logger.Infof("REPRGW: creating multisite system user %s", constants.RgwSystemUserID)
+		output, err = radosgwAdminRunRemote(cluster, client, "user", "create", "--uid", constants.RgwSystemUserID,
+			"--display-name", "MicroCeph Multisite", "--system")
+		if err != nil {
+			return RgwZoneSystemKey{}, fmt.Errorf("failed to create multisite system user: %w", err)
+		}
+	}
+
+	err = json.Unmarshal([]byte(output), &user)
+	if err != nil {
+		return RgwZoneSystemKey{}, fmt.Errorf("cannot unmarshal user info output: %w", err)
+	}
+
+	if len(user.Keys) == 0 {
+		return RgwZoneSystemKey{}, fmt.Errorf("multisite system user %s has no keys", constants.RgwSystemUserID)
+	}
+
+	return user.Keys[0], nil
+}
+
+// rgwPeriodCommit updates and commits the current period, publishing
+// realm changes to every zone.
+func rgwPeriodCommit(cluster string, client string) error {
+	_, err := radosgwAdminRunRemote(cluster, client, "period", "update", "--commit")
+	if err != nil {
+		return fmt.Errorf("failed to commit period: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,377 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// RgwZoneConfig is the multisite realm, zonegroup and zone the RGW daemons serve.
+type RgwZoneConfig struct {
+	Realm     string
+	ZoneGroup string
+	Zone      string
+}
+
+// getRgwZoneConfig reads the multisite zone from the config table. It is
+// empty until a zone is created, so the daemons keep serving the default one.
+func getRgwZoneConfig(config map[string]string) RgwZoneConfig {
+	if len(config[constants.RgwMultisiteZoneKey]) == 0 {
+		return RgwZoneConfig{}
+	}
+
+	return RgwZoneConfig{
+		Realm:     config[constants.RgwMultisiteRealmKey],
+		ZoneGroup: config[constants.RgwMultisiteZoneGroupKey],
+		Zone:      config[constants.RgwMultisiteZoneKey],
+	}
+}
+
+// confLines renders the zone as radosgw.conf lines.
+func (zone RgwZoneConfig) confLines() []string {
+	lines := []string{}
+	if len(zone.Realm) != 0 {
+		lines = append(lines, fmt.Sprintf("rgw realm = %s", zone.Realm))
+	}
+	if len(zone.ZoneGroup) != 0 {
+		lines = append(lines, fmt.Sprintf("rgw zonegroup = %s", zone.ZoneGroup))
+	}
+	if len(zone.Zone) != 0 {
+		lines = append(lines, fmt.Sprintf("rgw zone = %s", zone.Zone))
+	}
+
+	return lines
+}
+
+// CreateRgwRealm creates a realm and makes it the default one.
+func CreateRgwRealm(ctx context.Context, s interfaces.StateInterface, req types.RgwRealmRequest) error {
+	_, err := radosgwAdminRun("realm", "create", "--rgw-realm", req.Name, "--default")
+	if err != nil {
+		return fmt.Errorf("failed to create realm %s: %w", req.Name, err)
+	}
+
+	return database.SetConfigItemDb(ctx, s.ClusterState(), constants.RgwMultisiteRealmKey, req.Name)
+}
+
+// PullRgwRealm pulls a realm from the master zone of another site. The
+// system user keys used for the pull authenticate the zones of this site.
+func PullRgwRealm(ctx context.Context, s interfaces.StateInterface, req types.RgwRealmRequest) error {
+	if len(req.URL) == 0 || len(req.AccessKey) == 0 || len(req.SecretKey) == 0 {
+		return fmt.Errorf("url, access key and secret key of the master zone are required to pull a realm")
+	}
+
+	_, err := radosgwAdminRun("realm", "pull", "--rgw-realm", req.Name, "--url", req.URL,
+		"--access-key", req.AccessKey, "--secret", req.SecretKey, "--default")
+	if err != nil {
+		return fmt.Errorf("failed to pull realm %s from %s: %w", req.Name, req.URL, err)
+	}
+
+	records := [][]string{
+		{constants.RgwMultisiteRealmKey, req.Name},
+		{constants.RgwMultisiteAccessKey, req.AccessKey},
+		{constants.RgwMultisiteSecretKey, req.SecretKey},
+	}
+
+	for _, record := range records {
+		err = database.SetConfigItemDb(ctx, s.ClusterState(), record[0], record[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CommitRgwPeriod commits the pending period of a realm and restarts the
+// local RGW daemons to serve it.
+func CommitRgwPeriod(ctx context.Context, s interfaces.StateInterface, realm string) error {
+	_, err := radosgwAdminRun("period", "update", "--commit", "--rgw-realm", realm)
+	if err != nil {
+		return fmt.Errorf("failed to commit period of realm %s: %w", realm, err)
+	}
+
+	return applyRgwZoneConfig(ctx, s)
+}
+
+// CreateRgwZoneGroup creates a zonegroup in the given or recorded realm.
+func CreateRgwZoneGroup(ctx context.Context, s interfaces.StateInterface, req types.RgwZoneGroupRequest) error {
+	realm, err := getRgwRecordedName(ctx, s, req.Realm, constants.RgwMultisiteRealmKey, "realm")
+	if err != nil {
+		return err
+	}
+
+	args := []string{"zonegroup", "create", "--rgw-zonegroup", req.Name, "--rgw-realm", realm, "--default"}
+	if len(req.Endpoints) != 0 {
+		args = append(args, "--endpoints", strings.Join(req.Endpoints, ","))
+	}
+	if req.IsMaster {
+		args = append(args, "--master")
+	}
+
+	_, err = radosgwAdminRun(args...)
+	if err != nil {
+		return fmt.Errorf("failed to create zonegroup %s: %w", req.Name, err)
+	}
+
+	return database.SetConfigItemDb(ctx, s.ClusterState(), constants.RgwMultisiteZoneGroupKey, req.Name)
+}
+
+// CreateRgwZone creates a zone in the given or recorded zonegroup, binds the
+// multisite system user keys to it, commits the period and points the RGW
+// daemons at it. The master zone creates the system user, other zones use
+// the keys recorded when pulling the realm.
+func CreateRgwZone(ctx context.Context, s interfaces.StateInterface, req types.RgwZoneRequest) error {
+	zonegroup, err := getRgwRecordedName(ctx, s, req.ZoneGroup, constants.RgwMultisiteZoneGroupKey, "zonegroup")
+	if err != nil {
+		return err
+	}
+
+	key, err := getRgwRecordedSystemKey(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if !req.IsMaster && len(key.AccessKey) == 0 {
+		return fmt.Errorf("no multisite system user keys recorded, pull the realm from the master zone first")
+	}
+
+	args := []string{"zone", "create", "--rgw-zonegroup", zonegroup, "--rgw-zone", req.Name, "--default"}
+	if len(req.Endpoints) != 0 {
+		args = append(args, "--endpoints", strings.Join(req.Endpoints, ","))
+	}
+	if req.IsMaster {
+		args = append(args, "--master")
+	}
+
+	_, err = radosgwAdminRun(args...)
+	if err != nil {
+		return fmt.Errorf("failed to create zone %s: %w", req.Name, err)
+	}
+
+	// The system user lives in the zone, so it can only be created now.
+	if req.IsMaster {
+		key, err = GetRgwSystemUserKey("", "")
+		if err != nil {
+			return err
+		}
+
+		err = database.SetConfigItemDb(ctx, s.ClusterState(), constants.RgwMultisiteAccessKey, key.AccessKey)
+		if err != nil {
+			return err
+		}
+
+		err = database.SetConfigItemDb(ctx, s.ClusterState(), constants.RgwMultisiteSecretKey, key.SecretKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = radosgwAdminRun("zone", "modify", "--rgw-zonegroup", zonegroup, "--rgw-zone", req.Name,
+		"--access-key", key.AccessKey, "--secret", key.SecretKey)
+	if err != nil {
+		return fmt.Errorf("failed to set system user keys of zone %s: %w", req.Name, err)
+	}
+
+	err = rgwPeriodCommit("", "")
+	if err != nil {
+		return err
+	}
+
+	err = database.SetConfigItemDb(ctx, s.ClusterState(), constants.RgwMultisiteZoneGroupKey, zonegroup)
+	if err != nil {
+		return err
+	}
+
+	err = database.SetConfigItemDb(ctx, s.ClusterState(), constants.RgwMultisiteZoneKey, req.Name)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("REPRGW: zone %s created in zonegroup %s", req.Name, zonegroup)
+	return applyRgwZoneConfig(ctx, s)
+}
+
+// getRgwRecordedName returns name, or the one recorded under key when name is empty.
+func getRgwRecordedName(ctx context.Context, s interfaces.StateInterface, name string, key string, kind string) (string, error) {
+	if len(name) != 0 {
+		return name, nil
+	}
+
+	name, err := database.GetConfigItemDb(ctx, s.ClusterState(), key)
+	if err != nil {
+		return "", err
+	}
+
+	if len(name) == 0 {
+		return "", fmt.Errorf("no %s given and none created on this cluster", kind)
+	}
+
+	return name, nil
+}
+
+// getRgwRecordedSystemKey fetches the multisite system user keys from the config table.
+func getRgwRecordedSystemKey(ctx context.Context, s interfaces.StateInterface) (RgwZoneSystemKey, error) {
+	accessKey, err := database.GetConfigItemDb(ctx, s.ClusterState(), constants.RgwMultisiteAccessKey)
+	if err != nil {
+		return RgwZoneSystemKey{}, err
+	}
+
+	secretKey, err := database.GetConfigItemDb(ctx, s.ClusterState(), constants.RgwMultisiteSecretKey)
+	if err != nil {
+		return RgwZoneSystemKey{}, err
+	}
+
+	return RgwZoneSystemKey{AccessKey: accessKey, SecretKey: secretKey}, nil
+}
+
+// recordRgwZone records the multisite zone the RGW daemons of every member
+// serve, see applyRgwZoneConfig.
+func recordRgwZone(ctx context.Context, s interfaces.StateInterface, zone RgwZoneConfig) error {
+	records := [][]string{
+		{constants.RgwMultisiteRealmKey, zone.Realm},
+		{constants.RgwMultisiteZoneGroupKey, zone.ZoneGroup},
+		{constants.RgwMultisiteZoneKey, zone.Zone},
+	}
+
+	for _, record := range records {
+		err := database.SetConfigItemDb(ctx, s.ClusterState(), record[0], record[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplyRgwZone renders the recorded zone into the radosgw.conf of the local
+// member and restarts its RGW daemons to serve it.
+func ApplyRgwZone(ctx context.Context, s interfaces.StateInterface) error {
+	config, err := fetchConfigDb(ctx, s)
+	if err != nil {
+		return fmt.Errorf("failed to get config db: %w", err)
+	}
+
+	_, err = updateRadosGWZone(constants.GetPathConst().ConfPath, getRgwZoneConfig(config))
+	if err != nil {
+		return fmt.Errorf("failed to render RGW multisite zone: %w", err)
+	}
+
+	return restartLocalRgw()
+}
+
+// applyMemberRgwZones applies the recorded zone on every other cluster
+// member. Members which cannot apply it are returned rather than failing the
+// others.
+var applyMemberRgwZones = func(ctx context.Context, s interfaces.StateInterface) ([]string, error) {
+	clients, err := getMemberClients(s)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := []string{}
+	for member, remoteClient := range clients {
+		err = client.ApplyRgwZone(ctx, remoteClient)
+		if err != nil {
+			logger.Warnf("failed to apply the RGW multisite zone on %s: %v", member, err)
+			failed = append(failed, member)
+		}
+	}
+
+	sort.Strings(failed)
+	return failed, nil
+}
+
+// applyRgwZoneConfig applies the recorded zone on every member, see
+// ApplyRgwZone.
+func applyRgwZoneConfig(ctx context.Context, s interfaces.StateInterface) error {
+	err := ApplyRgwZone(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	failed, err := applyMemberRgwZones(ctx, s)
+	if err != nil {
+		return fmt.Errorf("failed to apply RGW multisite zone on cluster members: %w", err)
+	}
+
+	if len(failed) != 0 {
+		return fmt.Errorf("failed to apply RGW multisite zone on %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// rgwZoneHandoverOptions are the Ceph options of the client section another
+// site hands a zone over through when joining this cluster to its zonegroup.
+var rgwZoneHandoverOptions = []string{"rgw_realm", "rgw_zonegroup", "rgw_zone"}
+
+// handOverRgwZone hands a zone over to the MicroCeph of a remote cluster, see
+// AdoptRgwZone. The remote is only reachable through Ceph.
+func handOverRgwZone(cluster string, client string, zone RgwZoneConfig) error {
+	values := []string{zone.Realm, zone.ZoneGroup, zone.Zone}
+	for i, option := range rgwZoneHandoverOptions {
+		_, err := cephRun(appendRemoteClusterArgs([]string{"config", "set", "client", option, values[i]}, cluster, client)...)
+		if err != nil {
+			return fmt.Errorf("failed to hand zone %s over to %s: %w", zone.Zone, cluster, err)
+		}
+	}
+
+	return nil
+}
+
+// withdrawRgwZone takes back a zone handed over to a remote cluster.
+func withdrawRgwZone(cluster string, client string) error {
+	for _, option := range rgwZoneHandoverOptions {
+		_, err := cephRun(appendRemoteClusterArgs([]string{"config", "rm", "client", option}, cluster, client)...)
+		if err != nil {
+			return fmt.Errorf("failed to withdraw the zone handed over to %s: %w", cluster, err)
+		}
+	}
+
+	return nil
+}
+
+// AdoptRgwZone records a zone handed over by another site which joined this
+// cluster to its zonegroup and points the RGW daemons at it. The handed over
+// options are removed once the zone is applied, the record taking over.
+func AdoptRgwZone(ctx context.Context, s interfaces.StateInterface) error {
+	dump, err := getConfigDump()
+	if err != nil {
+		return err
+	}
+
+	zone := RgwZoneConfig{
+		Realm:     dump["client/rgw_realm"],
+		ZoneGroup: dump["client/rgw_zonegroup"],
+		Zone:      dump["client/rgw_zone"],
+	}
+	if len(zone.Zone) == 0 {
+		return nil
+	}
+
+	err = recordRgwZone(ctx, s, zone)
+	if err != nil {
+		return err
+	}
+
+	err = applyRgwZoneConfig(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	err = withdrawRgwZone("", "")
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("REPRGW: adopted zone %s of zonegroup %s handed over by another site", zone.Zone, zone.ZoneGroup)
+	return nil
+}
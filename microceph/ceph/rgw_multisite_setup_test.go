@@ -0,0 +1,152 @@
+package ceph
+
+import (
+	"context"
+	"testing"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type rgwMultisiteSetupSuite struct {
+	tests.BaseSuite
+	records map[string]string
+
+	getConfigItemDb func(context.Context, mcTypes.State, string) (string, error)
+	setConfigItemDb func(context.Context, mcTypes.State, string, string) error
+}
+
+func TestRgwMultisiteSetup(t *testing.T) {
+	suite.Run(t, new(rgwMultisiteSetupSuite))
+}
+
+func (s *rgwMultisiteSetupSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+
+	s.records = map[string]string{}
+	s.getConfigItemDb = database.GetConfigItemDb
+	s.setConfigItemDb = database.SetConfigItemDb
+
+	database.GetConfigItemDb = func(ctx context.Context, st mcTypes.State, key string) (string, error) {
+		return s.records[key], nil
+	}
+	database.SetConfigItemDb = func(ctx context.Context, st mcTypes.State, key string, value string) error {
+		s.records[key] = value
+		return nil
+	}
+}
+
+func (s *rgwMultisiteSetupSuite) TearDownTest() {
+	database.GetConfigItemDb = s.getConfigItemDb
+	database.SetConfigItemDb = s.setConfigItemDb
+	s.BaseSuite.TearDownTest()
+}
+
+func (s *rgwMultisiteSetupSuite) state() interfaces.CephState {
+	return interfaces.CephState{State: &mocks.MockState{}}
+}
+
+func (s *rgwMultisiteSetupSuite) TestCreateZoneGroupUsesRecordedRealm() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "radosgw-admin", "zonegroup", "create", "--rgw-zonegroup", "eu", "--rgw-realm", "gold", "--default",
+		"--endpoints", "http://10.0.0.1:80", "--master").Return("{}", nil).Once()
+	common.ProcessExec = r
+
+	s.records[constants.RgwMultisiteRealmKey] = "gold"
+	req := types.RgwZoneGroupRequest{Name: "eu", Endpoints: []string{"http://10.0.0.1:80"}, IsMaster: true}
+
+	err := CreateRgwZoneGroup(context.Background(), s.state(), req)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "eu", s.records[constants.RgwMultisiteZoneGroupKey])
+}
+
+func (s *rgwMultisiteSetupSuite) TestCreateZoneGroupWithoutRealm() {
+	err := CreateRgwZoneGroup(context.Background(), s.state(), types.RgwZoneGroupRequest{Name: "eu"})
+	assert.ErrorContains(s.T(), err, "no realm given")
+}
+
+func (s *rgwMultisiteSetupSuite) TestCreateSecondaryZoneRequiresPulledKeys() {
+	s.records[constants.RgwMultisiteZoneGroupKey] = "eu"
+
+	err := CreateRgwZone(context.Background(), s.state(), types.RgwZoneRequest{Name: "siteb"})
+	assert.ErrorContains(s.T(), err, "pull the realm")
+	assert.Empty(s.T(), s.records[constants.RgwMultisiteZoneKey])
+}
+
+func (s *rgwMultisiteSetupSuite) TestPullRealmRecordsKeys() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "radosgw-admin", "realm", "pull", "--rgw-realm", "gold", "--url", "http://10.0.0.1:80",
+		"--access-key", "AK", "--secret", "SK", "--default").Return("{}", nil).Once()
+	common.ProcessExec = r
+
+	req := types.RgwRealmRequest{Name: "gold", URL: "http://10.0.0.1:80", AccessKey: "AK", SecretKey: "SK"}
+	err := PullRgwRealm(context.Background(), s.state(), req)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "gold", s.records[constants.RgwMultisiteRealmKey])
+	assert.Equal(s.T(), "AK", s.records[constants.RgwMultisiteAccessKey])
+	assert.Equal(s.T(), "SK", s.records[constants.RgwMultisiteSecretKey])
+}
+
+func (s *rgwMultisiteSetupSuite) TestGetRgwZoneConfig() {
+	config := map[string]string{
+		constants.RgwMultisiteRealmKey:     "gold",
+		constants.RgwMultisiteZoneGroupKey: "eu",
+	}
+	assert.Equal(s.T(), RgwZoneConfig{}, getRgwZoneConfig(config))
+
+	config[constants.RgwMultisiteZoneKey] = "sitea"
+	zone := getRgwZoneConfig(config)
+	assert.Equal(s.T(), []string{"rgw realm = gold", "rgw zonegroup = eu", "rgw zone = sitea"}, zone.confLines())
+}
+
+func (s *rgwMultisiteSetupSuite) TestAdoptRgwZone() {
+	origFetch := fetchConfigDb
+	origApply := applyMemberRgwZones
+	s.T().Cleanup(func() {
+		fetchConfigDb = origFetch
+		applyMemberRgwZones = origApply
+	})
+	fetchConfigDb = func(_ context.Context, _ interfaces.StateInterface) (map[string]string, error) {
+		return s.records, nil
+	}
+	applyMemberRgwZones = func(_ context.Context, _ interfaces.StateInterface) ([]string, error) {
+		return []string{}, nil
+	}
+
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(`[
+		{"section": "client", "name": "rgw_realm", "value": "gold"},
+		{"section": "client", "name": "rgw_zonegroup", "value": "eu"},
+		{"section": "client", "name": "rgw_zone", "value": "siteb"}
+	]`, nil).Once()
+	r.On("RunCommand", "snapctl", "services", "microceph.rgw").Return("microceph.rgw  enabled  inactive  -", nil).Once()
+	for _, option := range rgwZoneHandoverOptions {
+		r.On("RunCommand", "ceph", "config", "rm", "client", option).Return("", nil).Once()
+	}
+	common.ProcessExec = r
+
+	err := AdoptRgwZone(context.Background(), s.state())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "gold", s.records[constants.RgwMultisiteRealmKey])
+	assert.Equal(s.T(), "eu", s.records[constants.RgwMultisiteZoneGroupKey])
+	assert.Equal(s.T(), "siteb", s.records[constants.RgwMultisiteZoneKey])
+}
+
+func (s *rgwMultisiteSetupSuite) TestAdoptRgwZoneNothingHandedOver() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return(`[]`, nil).Once()
+	common.ProcessExec = r
+
+	err := AdoptRgwZone(context.Background(), s.state())
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), s.records)
+}
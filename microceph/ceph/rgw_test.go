@@ -66,7 +66,7 @@ func (s *rgwSuite) TestEnableRGW() {
 
 	common.ProcessExec = r
 
-	err := EnableRGW(s.TestStateInterface, 8081, 443, "", "", []string{"10.1.1.1", "10.2.2.2"})
+	err := EnableRGW(s.TestStateInterface, 8081, 443, "", "", []string{"10.1.1.1", "10.2.2.2"}, RgwZoneConfig{})
 
 	assert.NoError(s.T(), err)
 
@@ -84,7 +84,7 @@ func (s *rgwSuite) TestEnableRGWWithInvalidSSLCertificate() {
 
 	common.ProcessExec = r
 
-	err := EnableRGW(s.TestStateInterface, 80, 443, "invalid-certificate", validSSLPrivateKey, []string{"10.1.1.1", "10.2.2.2"})
+	err := EnableRGW(s.TestStateInterface, 80, 443, "invalid-certificate", validSSLPrivateKey, []string{"10.1.1.1", "10.2.2.2"}, RgwZoneConfig{})
 
 	// we expect an illegal base64 data error
 	assert.EqualError(s.T(), err, "failed to decode SSL certificate: illegal base64 data at input byte 7")
@@ -100,7 +100,7 @@ func (s *rgwSuite) TestEnableRGWWithInvalidSSLPrivateKey() {
 
 	common.ProcessExec = r
 
-	err := EnableRGW(s.TestStateInterface, 80, 443, validSSLCertificate, "invalid-private-key", []string{"10.1.1.1", "10.2.2.2"})
+	err := EnableRGW(s.TestStateInterface, 80, 443, validSSLCertificate, "invalid-private-key", []string{"10.1.1.1", "10.2.2.2"}, RgwZoneConfig{})
 
 	// we expect an illegal base64 data error
 	assert.EqualError(s.T(), err, "failed to decode SSL private key: illegal base64 data at input byte 7")
@@ -118,7 +118,7 @@ func (s *rgwSuite) TestEnableRGWWithMissingSSLCertificate() {
 
 	common.ProcessExec = r
 
-	err := EnableRGW(s.TestStateInterface, 0, 443, "", validSSLPrivateKey, []string{"10.1.1.1", "10.2.2.2"})
+	err := EnableRGW(s.TestStateInterface, 0, 443, "", validSSLPrivateKey, []string{"10.1.1.1", "10.2.2.2"}, RgwZoneConfig{})
 
 	assert.NoError(s.T(), err)
 
@@ -135,7 +135,7 @@ func (s *rgwSuite) TestEnableRGWWithMissingSSLPrivateKey() {
 
 	common.ProcessExec = r
 
-	err := EnableRGW(s.TestStateInterface, 0, 443, validSSLCertificate, "", []string{"10.1.1.1", "10.2.2.2"})
+	err := EnableRGW(s.TestStateInterface, 0, 443, validSSLCertificate, "", []string{"10.1.1.1", "10.2.2.2"}, RgwZoneConfig{})
 
 	assert.NoError(s.T(), err)
 
@@ -152,7 +152,7 @@ func (s *rgwSuite) TestEnableRGWWithSSL() {
 
 	common.ProcessExec = r
 
-	err := EnableRGW(s.TestStateInterface, 8081, 443, validSSLCertificate, validSSLPrivateKey, []string{"10.1.1.1", "10.2.2.2"})
+	err := EnableRGW(s.TestStateInterface, 8081, 443, validSSLCertificate, validSSLPrivateKey, []string{"10.1.1.1", "10.2.2.2"}, RgwZoneConfig{})
 
 	assert.NoError(s.T(), err)
 
@@ -363,3 +363,64 @@ func (s *rgwSuite) TestDisableRGW() {
 	_, err = os.Stat(filepath.Join(s.Tmp, "SNAP_COMMON", "data", "radosgw", "ceph-radosgw.gateway", "keyring"))
 	assert.True(s.T(), os.IsNotExist(err))
 }
+
+func (s *rgwSuite) TestEnableRGWGroup() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", tests.CmdAny("ceph", 9)...).Return("ok", nil).Once()
+	r.On("RunCommand", "snapctl", "services", "microceph.rgw").Return("microceph.rgw  enabled  inactive", nil).Once()
+	r.On("RunCommand", "snapctl", "start", "microceph.rgw", "--enable").Return("ok", nil).Once()
+
+	common.ProcessExec = r
+
+	rgw := &RgwServicePlacement{Port: 8080, GroupID: "internal", Realm: "corp", ZoneGroup: "eu", Zone: "eu-internal"}
+	err := EnableRGWGroup(s.TestStateInterface, rgw, []string{"10.1.1.1"})
+	assert.NoError(s.T(), err)
+
+	conf := s.ReadCephConfig("radosgw-internal.conf")
+	assert.Contains(s.T(), conf, "[client.radosgw.internal]\n")
+	assert.Contains(s.T(), conf, "rgw frontends = beast port=8080\n")
+	assert.Contains(s.T(), conf, "rgw zone = eu-internal\n")
+
+	// the default daemon files are left alone
+	_, err = os.Stat(filepath.Join(s.Tmp, "SNAP_DATA", "conf", "radosgw.conf"))
+	assert.True(s.T(), os.IsNotExist(err))
+}
+
+func (s *rgwSuite) TestEnableRGWNextToGroup() {
+	r := mocks.NewRunner(s.T())
+
+	r.On("RunCommand", tests.CmdAny("ceph", 9)...).Return("ok", nil).Once()
+	r.On("RunCommand", "snapctl", "services", "microceph.rgw").Return("microceph.rgw  enabled  active", nil).Once()
+	r.On("RunCommand", "snapctl", "restart", "microceph.rgw").Return("ok", nil).Once()
+
+	common.ProcessExec = r
+
+	groupConf := filepath.Join(s.Tmp, "SNAP_DATA", "conf", "radosgw-internal.conf")
+	err := os.WriteFile(groupConf, []byte("[client.radosgw.internal]\n"), 0644)
+	assert.NoError(s.T(), err)
+
+	err = EnableRGW(s.TestStateInterface, 80, 443, "", "", []string{"10.1.1.1"}, RgwZoneConfig{})
+	assert.NoError(s.T(), err)
+
+	conf := s.ReadCephConfig("radosgw.conf")
+	assert.Contains(s.T(), conf, "[client.radosgw.gateway]\n")
+}
+
+func (s *rgwSuite) TestRgwPlacementGroupParams() {
+	rgw := &RgwServicePlacement{}
+	err := rgw.PopulateParams(s.TestStateInterface, `{"GroupID": "public", "SSLPort": 443}`)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 80, rgw.Port)
+
+	for _, payload := range []string{
+		`{"GroupID": "gateway"}`,
+		`{"GroupID": "bad_id"}`,
+		`{"Zone": "eu"}`,
+		`{"GroupID": "public", "Realm": "corp"}`,
+	} {
+		rgw = &RgwServicePlacement{}
+		err = rgw.PopulateParams(s.TestStateInterface, payload)
+		assert.Error(s.T(), err, payload)
+	}
+}
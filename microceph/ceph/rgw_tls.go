@@ -0,0 +1,371 @@
+package ceph
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// Validity of the certificates issued by the cluster-internal RGW CA.
+const (
+	rgwCAValidity   = 10 * 365 * 24 * time.Hour
+	rgwCertValidity = 365 * 24 * time.Hour
+)
+
+// rgwCAFile is written next to self-managed certificates so that S3 clients
+// can fetch the CA, its presence marks the certificate as self-managed.
+const rgwCAFile = "ca.crt"
+
+// rgwCA is the cluster-internal CA issuing self-managed RGW certificates.
+type rgwCA struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     *ecdsa.PrivateKey
+}
+
+// getRgwCA loads the cluster-internal RGW CA from the config table, creating it on first use.
+// The CA is recorded in a single transaction, members racing to create it all
+// end up with the one that was recorded first.
+func getRgwCA(ctx context.Context, s interfaces.StateInterface) (rgwCA, error) {
+	certPEM, err := database.GetConfigItemDb(ctx, s.ClusterState(), constants.RgwTLSCACertKey)
+	if err != nil {
+		return rgwCA{}, err
+	}
+
+	keyPEM, err := database.GetConfigItemDb(ctx, s.ClusterState(), constants.RgwTLSCAKeyKey)
+	if err != nil {
+		return rgwCA{}, err
+	}
+
+	if len(certPEM) != 0 && len(keyPEM) != 0 {
+		return parseRgwCA([]byte(certPEM), []byte(keyPEM))
+	}
+
+	ca, keyDER, err := newRgwCA(s.ClusterState().Name())
+	if err != nil {
+		return rgwCA{}, err
+	}
+
+	// The key is kept in plaintext in the replicated config table, like the
+	// other cluster secrets recorded there.
+	recorded, err := database.InitConfigItemsDb(ctx, s.ClusterState(), map[string]string{
+		constants.RgwTLSCACertKey: string(ca.certPEM),
+		constants.RgwTLSCAKeyKey:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	})
+	if err != nil {
+		return rgwCA{}, err
+	}
+
+	if recorded[constants.RgwTLSCACertKey] != string(ca.certPEM) {
+		logger.Debugf("RGW: cluster CA was created by another member")
+		return parseRgwCA([]byte(recorded[constants.RgwTLSCACertKey]), []byte(recorded[constants.RgwTLSCAKeyKey]))
+	}
+
+	logger.Infof("RGW: created cluster CA for self-managed certificates")
+	return ca, nil
+}
+
+// newRgwCA generates a self-signed CA, it returns the CA along with its DER encoded key.
+func newRgwCA(issuer string) (rgwCA, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return rgwCA{}, nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	serial, err := rgwCertSerial()
+	if err != nil {
+		return rgwCA{}, nil, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"MicroCeph"}, CommonName: fmt.Sprintf("MicroCeph RGW CA (%s)", issuer)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(rgwCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return rgwCA{}, nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return rgwCA{}, nil, fmt.Errorf("failed to encode CA key: %w", err)
+	}
+
+	ca, err := parseRgwCA(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return ca, keyDER, err
+}
+
+// parseRgwCA decodes the PEM encoded CA certificate and key.
+func parseRgwCA(certPEM []byte, keyPEM []byte) (rgwCA, error) {
+	cert, err := parseCertificatePEM(certPEM)
+	if err != nil {
+		return rgwCA{}, fmt.Errorf("invalid RGW CA certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return rgwCA{}, fmt.Errorf("invalid RGW CA key: no PEM data")
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return rgwCA{}, fmt.Errorf("invalid RGW CA key: %w", err)
+	}
+
+	return rgwCA{cert: cert, certPEM: certPEM, key: key}, nil
+}
+
+// issue signs a server certificate for the given SANs, it returns the PEM encoded certificate and key.
+func (ca rgwCA) issue(commonName string, dnsNames []string, ips []net.IP) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := rgwCertSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"MicroCeph"}, CommonName: commonName},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(rgwCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// rgwCertSerial returns a random certificate serial number.
+func rgwCertSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	return serial, nil
+}
+
+// parseCertificatePEM decodes the first certificate of PEM data.
+func parseCertificatePEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// rgwCertificateSANs collects the names S3 clients reach this member by: its
+// hostname, member name, cluster address and public network address.
+func rgwCertificateSANs(ctx context.Context, s interfaces.StateInterface) ([]string, []net.IP) {
+	dnsNames := []string{}
+	ips := []net.IP{}
+
+	add := func(name string) {
+		if len(name) == 0 {
+			return
+		}
+
+		ip := net.ParseIP(name)
+		if ip == nil {
+			if !slices.Contains(dnsNames, name) {
+				dnsNames = append(dnsNames, name)
+			}
+			return
+		}
+
+		if !slices.ContainsFunc(ips, ip.Equal) {
+			ips = append(ips, ip)
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err == nil {
+		add(hostname)
+	}
+
+	add(s.ClusterState().Name())
+	add(s.ClusterState().Address().Hostname())
+
+	config, err := GetConfigDb(ctx, s)
+	if err == nil && len(config["public_network"]) != 0 {
+		publicIP, err := common.Network.FindIpOnSubnet(config["public_network"])
+		if err == nil {
+			add(publicIP)
+		}
+	}
+
+	return dnsNames, ips
+}
+
+// issueRGWCertificate signs a certificate for this member with the cluster-internal CA.
+// It returns the base64 encoded certificate and key, as accepted by UpdateRGWCertificates,
+// along with the PEM encoded CA certificate.
+func issueRGWCertificate(ctx context.Context, s interfaces.StateInterface) (string, string, []byte, error) {
+	ca, err := getRgwCA(ctx, s)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	dnsNames, ips := rgwCertificateSANs(ctx, s)
+	certPEM, keyPEM, err := ca.issue(s.ClusterState().Name(), dnsNames, ips)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return base64.StdEncoding.EncodeToString(certPEM), base64.StdEncoding.EncodeToString(keyPEM), ca.certPEM, nil
+}
+
+// writeRGWCAFile installs the CA certificate next to a self-managed certificate,
+// or removes it when the certificate was supplied by the operator.
+func writeRGWCAFile(inst rgwInstance, caPEM []byte) error {
+	path := filepath.Join(inst.sslDir(constants.GetPathConst().SSLFilesPath), rgwCAFile)
+	if caPEM == nil {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove RGW CA certificate: %w", err)
+		}
+
+		return nil
+	}
+
+	err := os.WriteFile(path, caPEM, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write RGW CA certificate: %w", err)
+	}
+
+	return nil
+}
+
+// SetRGWSelfManagedCertificate issues a certificate from the cluster-internal CA
+// for the RGW daemon of the given group, the default one when empty, and installs it.
+func SetRGWSelfManagedCertificate(ctx context.Context, s interfaces.StateInterface, groupID string) error {
+	cert, key, caPEM, err := issueRGWCertificate(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if len(groupID) == 0 {
+		err = UpdateRGWCertificates(s, cert, key)
+	} else {
+		err = UpdateRGWGroupCertificates(s, groupID, cert, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeRGWCAFile(rgwInstance{groupID: groupID}, caPEM)
+}
+
+// ListRGWCertificates describes the SSL certificates of the RGW daemons on this host.
+func ListRGWCertificates() (types.CertificateInfos, error) {
+	pathConsts := constants.GetPathConst()
+	infos := types.CertificateInfos{}
+
+	for _, inst := range rgwInstances(pathConsts.ConfPath) {
+		sslDir := inst.sslDir(pathConsts.SSLFilesPath)
+		data, err := os.ReadFile(filepath.Join(sslDir, "server.crt"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RGW certificate: %w", err)
+		}
+
+		cert, err := parseCertificatePEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RGW certificate of %s: %w", inst.name(), err)
+		}
+
+		info := types.CertificateInfo{
+			Service:  "rgw",
+			GroupID:  inst.groupID,
+			Subject:  cert.Subject.CommonName,
+			Issuer:   cert.Issuer.CommonName,
+			DNSNames: cert.DNSNames,
+			NotAfter: cert.NotAfter,
+		}
+		for _, ip := range cert.IPAddresses {
+			info.IPAddresses = append(info.IPAddresses, ip.String())
+		}
+
+		_, err = os.Stat(filepath.Join(sslDir, rgwCAFile))
+		info.SelfManaged = err == nil
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// RenewRGWCertificates renews the self-managed RGW certificates on this host
+// that are within the renewal window, and restarts RGW to load them.
+func RenewRGWCertificates(ctx context.Context, s interfaces.StateInterface) error {
+	infos, err := ListRGWCertificates()
+	if err != nil {
+		return err
+	}
+
+	renewed := 0
+	for _, info := range infos {
+		if !info.SelfManaged || time.Until(info.NotAfter) > constants.RgwCertRenewalWindow {
+			continue
+		}
+
+		logger.Infof("RGW: renewing self-managed certificate of group '%s' expiring on %s", info.GroupID, info.NotAfter)
+		err = SetRGWSelfManagedCertificate(ctx, s, info.GroupID)
+		if err != nil {
+			return fmt.Errorf("failed to renew RGW certificate: %w", err)
+		}
+
+		renewed++
+	}
+
+	if renewed == 0 {
+		return nil
+	}
+
+	return RestartRGW()
+}
@@ -0,0 +1,152 @@
+package ceph
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type rgwTLSSuite struct {
+	tests.BaseSuite
+	records map[string]string
+
+	getConfigItemDb func(context.Context, mcTypes.State, string) (string, error)
+	setConfigItemDb func(context.Context, mcTypes.State, string, string) error
+	initConfigItems func(context.Context, mcTypes.State, map[string]string) (map[string]string, error)
+}
+
+func TestRgwTLS(t *testing.T) {
+	suite.Run(t, new(rgwTLSSuite))
+}
+
+func (s *rgwTLSSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+	s.CopyCephConfigs()
+
+	s.records = map[string]string{}
+	s.getConfigItemDb = database.GetConfigItemDb
+	s.setConfigItemDb = database.SetConfigItemDb
+	s.initConfigItems = database.InitConfigItemsDb
+
+	database.GetConfigItemDb = func(ctx context.Context, st mcTypes.State, key string) (string, error) {
+		return s.records[key], nil
+	}
+	database.SetConfigItemDb = func(ctx context.Context, st mcTypes.State, key string, value string) error {
+		s.records[key] = value
+		return nil
+	}
+	database.InitConfigItemsDb = func(ctx context.Context, st mcTypes.State, items map[string]string) (map[string]string, error) {
+		recorded := map[string]string{}
+		for key, value := range items {
+			if len(s.records[key]) == 0 {
+				s.records[key] = value
+			}
+			recorded[key] = s.records[key]
+		}
+		return recorded, nil
+	}
+}
+
+func (s *rgwTLSSuite) TearDownTest() {
+	database.GetConfigItemDb = s.getConfigItemDb
+	database.SetConfigItemDb = s.setConfigItemDb
+	database.InitConfigItemsDb = s.initConfigItems
+	s.BaseSuite.TearDownTest()
+}
+
+func (s *rgwTLSSuite) TestGetRgwCAIsCreatedOnce() {
+	state := interfaces.CephState{State: &mocks.MockState{ClusterName: "node0"}}
+
+	ca, err := getRgwCA(context.Background(), state)
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), ca.cert.IsCA)
+	assert.NotEmpty(s.T(), s.records[constants.RgwTLSCACertKey])
+	assert.NotEmpty(s.T(), s.records[constants.RgwTLSCAKeyKey])
+
+	again, err := getRgwCA(context.Background(), state)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), ca.certPEM, again.certPEM)
+}
+
+func (s *rgwTLSSuite) TestGetRgwCAKeepsConcurrentlyCreatedCA() {
+	state := interfaces.CephState{State: &mocks.MockState{ClusterName: "node0"}}
+
+	// Another member records its CA between our lookup and our insert.
+	other, keyDER, err := newRgwCA("node1")
+	assert.NoError(s.T(), err)
+	getConfigItemDb := database.GetConfigItemDb
+	database.GetConfigItemDb = func(ctx context.Context, st mcTypes.State, key string) (string, error) {
+		s.records[constants.RgwTLSCACertKey] = string(other.certPEM)
+		s.records[constants.RgwTLSCAKeyKey] = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+		return "", nil
+	}
+	defer func() { database.GetConfigItemDb = getConfigItemDb }()
+
+	ca, err := getRgwCA(context.Background(), state)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), other.certPEM, ca.certPEM)
+}
+
+func (s *rgwTLSSuite) TestIssueVerifiesAgainstCA() {
+	ca, _, err := newRgwCA("node0")
+	assert.NoError(s.T(), err)
+
+	certPEM, _, err := ca.issue("node0", []string{"node0"}, []net.IP{net.ParseIP("10.0.0.1")})
+	assert.NoError(s.T(), err)
+
+	cert, err := parseCertificatePEM(certPEM)
+	assert.NoError(s.T(), err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	for _, name := range []string{"node0", "10.0.0.1"} {
+		_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: name})
+		assert.NoError(s.T(), err, name)
+	}
+}
+
+func (s *rgwTLSSuite) TestListRGWCertificates() {
+	pathConsts := constants.GetPathConst()
+
+	ca, _, err := newRgwCA("node0")
+	assert.NoError(s.T(), err)
+	certPEM, _, err := ca.issue("node0", []string{"node0"}, nil)
+	assert.NoError(s.T(), err)
+
+	// A self-managed default daemon and an operator-managed grouped one.
+	groupSSLDir := rgwInstance{groupID: "internal"}.sslDir(pathConsts.SSLFilesPath)
+	assert.NoError(s.T(), os.MkdirAll(groupSSLDir, 0700))
+	for _, file := range []string{"radosgw.conf", "radosgw-internal.conf"} {
+		assert.NoError(s.T(), os.WriteFile(filepath.Join(pathConsts.ConfPath, file), []byte{}, 0644))
+	}
+	for _, dir := range []string{pathConsts.SSLFilesPath, groupSSLDir} {
+		assert.NoError(s.T(), os.WriteFile(filepath.Join(dir, "server.crt"), certPEM, 0600))
+	}
+	assert.NoError(s.T(), os.WriteFile(filepath.Join(pathConsts.SSLFilesPath, rgwCAFile), ca.certPEM, 0644))
+
+	certs, err := ListRGWCertificates()
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), certs, 2)
+
+	assert.Equal(s.T(), "", certs[0].GroupID)
+	assert.True(s.T(), certs[0].SelfManaged)
+	assert.Equal(s.T(), "internal", certs[1].GroupID)
+	assert.False(s.T(), certs[1].SelfManaged)
+	assert.Equal(s.T(), []string{"node0"}, certs[1].DNSNames)
+	assert.WithinDuration(s.T(), time.Now().Add(rgwCertValidity), certs[1].NotAfter, time.Hour*2)
+}
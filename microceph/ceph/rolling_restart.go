@@ -0,0 +1,411 @@
+package ceph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/tidwall/gjson"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// DefaultRestartHealthTimeout is the time waited for the cluster to become
+// healthy before a rolling restart step, when the request does not set one.
+const DefaultRestartHealthTimeout = 10 * time.Minute
+
+// restartHealthPollInterval is the delay between two health checks while
+// waiting on the cluster, overridden in tests.
+var restartHealthPollInterval = 10 * time.Second
+
+// restartMemberFunc restarts services on a cluster member, overridden in tests.
+var restartMemberFunc = restartMember
+
+// restartStep is a set of members restarted at once, along with the OSDs
+// they host which must be ok-to-stop beforehand.
+type restartStep struct {
+	Members []string
+	OSDs    []int64
+}
+
+// planRestartSteps splits members into the steps of a rolling restart. Members
+// under the same CRUSH failure domain bucket form a failure domain, members
+// without one are a failure domain of their own. At most maxParallel members
+// of a single failure domain are restarted at once, and a step never spans two
+// failure domains.
+func planRestartSteps(members []string, memberDomains map[string]string, osds map[string][]int64, maxParallel int) []restartStep {
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	domains := map[string][]string{}
+	for _, member := range members {
+		domain := "host/" + member
+		bucket, ok := memberDomains[member]
+		if ok && len(bucket) != 0 {
+			domain = "bucket/" + bucket
+		}
+
+		domains[domain] = append(domains[domain], member)
+	}
+
+	steps := []restartStep{}
+	for _, domain := range sortedKeys(domains) {
+		domainMembers := domains[domain]
+		sort.Strings(domainMembers)
+
+		for start := 0; start < len(domainMembers); start += maxParallel {
+			end := min(start+maxParallel, len(domainMembers))
+			step := restartStep{Members: domainMembers[start:end], OSDs: []int64{}}
+			for _, member := range step.Members {
+				step.OSDs = append(step.OSDs, osds[member]...)
+			}
+
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
+
+// checkHealthStatus checks the output of "ceph health" is HEALTH_OK, or that
+// every raised check is a warning listed in tolerated.
+func checkHealthStatus(output string, tolerated common.Set) error {
+	status := gjson.Get(output, "status").String()
+	if status == "HEALTH_OK" {
+		return nil
+	}
+
+	blocking := []string{}
+	gjson.Get(output, "checks").ForEach(func(code, check gjson.Result) bool {
+		_, ok := tolerated[code.String()]
+		if !ok || check.Get("severity").String() != "HEALTH_WARN" {
+			blocking = append(blocking, code.String())
+		}
+
+		return true
+	})
+
+	if len(blocking) == 0 {
+		return nil
+	}
+
+	sort.Strings(blocking)
+	return fmt.Errorf("cluster is %s: %s", status, strings.Join(blocking, ", "))
+}
+
+// checkPGStatus checks the output of "ceph pg stat" reports every PG as
+// active+clean.
+func checkPGStatus(output string) error {
+	total := gjson.Get(output, "pg_summary.num_pgs").Int()
+	clean := gjson.Get(output, `pg_summary.num_pg_by_state.#(name=="active+clean").num`).Int()
+	if clean != total {
+		return fmt.Errorf("%d of %d PGs are active+clean", clean, total)
+	}
+
+	return nil
+}
+
+// checkRestartHealth checks the cluster can go through a rolling restart step,
+// with the OSDs of the step ok-to-stop.
+func checkRestartHealth(tolerated common.Set, osds []int64) error {
+	output, err := common.ProcessExec.RunCommand("ceph", "health", "-f", "json")
+	if err != nil {
+		return fmt.Errorf("failed to fetch cluster health: %w", err)
+	}
+
+	err = checkHealthStatus(output, tolerated)
+	if err != nil {
+		return err
+	}
+
+	output, err = common.ProcessExec.RunCommand("ceph", "pg", "stat", "-f", "json")
+	if err != nil {
+		return fmt.Errorf("failed to fetch PG status: %w", err)
+	}
+
+	err = checkPGStatus(output)
+	if err != nil {
+		return err
+	}
+
+	if len(osds) == 0 {
+		return nil
+	}
+
+	args := []string{"osd", "ok-to-stop"}
+	for _, osd := range osds {
+		args = append(args, fmt.Sprintf("osd.%d", osd))
+	}
+
+	_, err = common.ProcessExec.RunCommand("ceph", args...)
+	if err != nil {
+		return fmt.Errorf("osd.%v are not ok-to-stop", osds)
+	}
+
+	return nil
+}
+
+// waitForRestartHealth polls the cluster health until a rolling restart step
+// can go ahead or the timeout expires.
+func waitForRestartHealth(ctx context.Context, tolerated common.Set, osds []int64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := checkRestartHealth(tolerated, osds)
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("cluster not healthy after %v: %w", timeout, err)
+		}
+
+		logger.Infof("Restart: waiting on cluster health: %v", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(restartHealthPollInterval):
+		}
+	}
+}
+
+// getMemberClients maps the name of every other cluster member to a client.
+func getMemberClients(s interfaces.StateInterface) (map[string]mcTypes.Client, error) {
+	cluster, err := s.ClusterState().Connect().Cluster(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a client for every cluster member: %w", err)
+	}
+
+	names := map[string]string{}
+	for name, remote := range s.ClusterState().Truststore().RemotesByName() {
+		names[remote.Address.String()] = name
+	}
+
+	clients := map[string]mcTypes.Client{}
+	for _, remoteClient := range cluster {
+		clientURL := remoteClient.URL()
+		name, ok := names[clientURL.Host]
+		if ok {
+			clients[name] = remoteClient
+		}
+	}
+
+	return clients, nil
+}
+
+// restartMember restarts services on a cluster member, locally when the member
+// is the current host.
+func restartMember(ctx context.Context, s interfaces.StateInterface, clients map[string]mcTypes.Client, clusterServices types.Services, member string, services []string) error {
+	if member == s.ClusterState().Name() {
+		for _, service := range services {
+			err := RestartCephService(clusterServices, service, member)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	remoteClient, ok := clients[member]
+	if !ok {
+		return fmt.Errorf("no client for cluster member %s", member)
+	}
+
+	data := types.Services{}
+	for _, service := range services {
+		data = append(data, types.Service{Service: service})
+	}
+
+	return client.RestartService(ctx, remoteClient, &data)
+}
+
+// getDefaultRuleFailureDomain fetches the bucket type the default CRUSH rule
+// spreads replicas across.
+func getDefaultRuleFailureDomain() (string, error) {
+	ruleID, err := getDefaultCrushRule()
+	if err != nil {
+		return "", fmt.Errorf("failed to get default crush rule: %w", err)
+	}
+
+	output, err := common.ProcessExec.RunCommand("ceph", "osd", "crush", "rule", "dump", "-f", "json")
+	if err != nil {
+		return "", fmt.Errorf("failed to dump crush rules: %w", err)
+	}
+
+	return gjson.Get(output, fmt.Sprintf(`#(rule_id==%s).steps.#(op%%"choose*").type`, ruleID)).String(), nil
+}
+
+// getCrushFailureDomains maps members to the CRUSH bucket of the default rule's
+// failure domain their host bucket sits under. Members are left out when the
+// rule spreads replicas across hosts or OSDs, or when their host is not in the
+// CRUSH map.
+func getCrushFailureDomains(ctx context.Context, members []string) (map[string]string, error) {
+	domains := map[string]string{}
+	domainType, err := getDefaultRuleFailureDomain()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(domainType) == 0 || domainType == "osd" || domainType == "host" {
+		return domains, nil
+	}
+
+	nodes, err := getOSDTreeNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := map[int64]int64{}
+	byID := map[int64]gjson.Result{}
+	hosts := map[string]int64{}
+	for _, node := range nodes.Array() {
+		id := node.Get("id").Int()
+		byID[id] = node
+		for _, child := range node.Get("children").Array() {
+			parents[child.Int()] = id
+		}
+
+		if node.Get("type").String() == "host" {
+			hosts[node.Get("name").String()] = id
+		}
+	}
+
+	for _, member := range members {
+		id, ok := hosts[member]
+		for ok {
+			id, ok = parents[id]
+			if ok && byID[id].Get("type").String() == domainType {
+				domains[member] = byID[id].Get("name").String()
+				break
+			}
+		}
+	}
+
+	return domains, nil
+}
+
+// getRestartTopology fetches the CRUSH failure domain of every member and,
+// when OSDs are restarted, the OSDs every member hosts.
+func getRestartTopology(ctx context.Context, s interfaces.StateInterface, members []string, services []string) (map[string]string, map[string][]int64, error) {
+	domains, err := getCrushFailureDomains(ctx, members)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	osds := map[string][]int64{}
+	_, ok := toSet(services)["osd"]
+	if !ok {
+		return domains, osds, nil
+	}
+
+	disks, err := ListOSD(ctx, s.ClusterState())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list OSDs: %w", err)
+	}
+
+	for _, disk := range disks {
+		osds[disk.Location] = append(osds[disk.Location], disk.OSD)
+	}
+
+	return domains, osds, nil
+}
+
+// toSet builds a set of strings.
+func toSet(items []string) common.Set {
+	set := common.Set{}
+	set.Add(items)
+	return set
+}
+
+// RollingRestartCephServices restarts services member by member across the
+// cluster. Before every step it waits for the cluster to be healthy, with all
+// PGs active+clean and the OSDs of the step ok-to-stop. A cluster with a
+// single member has nothing to roll, its services are restarted straight away.
+func RollingRestartCephServices(ctx context.Context, s interfaces.StateInterface, req types.ServiceRestart) error {
+	clusterServices, err := database.ServiceQuery.List(ctx, s.ClusterState())
+	if err != nil {
+		return fmt.Errorf("failed fetching services from db: %w", err)
+	}
+
+	requested := toSet(req.Services)
+	hosts := common.Set{}
+	for _, service := range clusterServices {
+		_, ok := requested[service.Service]
+		if ok {
+			hosts[service.Location] = struct{}{}
+		}
+	}
+
+	members := hosts.Keys()
+	if len(members) == 0 {
+		logger.Infof("Restart: no member hosts %v", req.Services)
+		return nil
+	}
+
+	domains, osds, err := getRestartTopology(ctx, s, members, req.Services)
+	if err != nil {
+		return err
+	}
+
+	clients, err := getMemberClients(s)
+	if err != nil {
+		return err
+	}
+
+	timeout := DefaultRestartHealthTimeout
+	if req.HealthTimeout > 0 {
+		timeout = time.Duration(req.HealthTimeout) * time.Second
+	}
+
+	tolerated := toSet(req.ToleratedWarnings)
+	steps := planRestartSteps(members, domains, osds, req.MaxParallel)
+	for i, step := range steps {
+		if len(members) > 1 {
+			err = waitForRestartHealth(ctx, tolerated, step.OSDs, timeout)
+			if err != nil {
+				return fmt.Errorf("restarted %d of %d steps, halted before %v: %w", i, len(steps), step.Members, err)
+			}
+		}
+
+		logger.Infof("Restart: restarting %v on %v (step %d of %d)", req.Services, step.Members, i+1, len(steps))
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(step.Members))
+		for j, member := range step.Members {
+			wg.Add(1)
+			go func(j int, member string) {
+				defer wg.Done()
+				errs[j] = restartMemberFunc(ctx, s, clients, clusterServices, member, req.Services)
+				if errs[j] != nil {
+					errs[j] = fmt.Errorf("failed restarting %v on %s: %w", req.Services, member, errs[j])
+				}
+			}(j, member)
+		}
+		wg.Wait()
+
+		err = errors.Join(errs...)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(members) > 1 {
+		err = waitForRestartHealth(ctx, tolerated, nil, timeout)
+		if err != nil {
+			return fmt.Errorf("restarted all steps: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type rollingRestartSuite struct {
+	tests.BaseSuite
+}
+
+func TestRollingRestart(t *testing.T) {
+	suite.Run(t, new(rollingRestartSuite))
+}
+
+func (s *rollingRestartSuite) SetupTest() {
+	s.BaseSuite.SetupTest()
+
+	origInterval := restartHealthPollInterval
+	s.T().Cleanup(func() { restartHealthPollInterval = origInterval })
+	restartHealthPollInterval = time.Millisecond
+}
+
+const healthyPGStat = `{"pg_ready":true,"pg_summary":{"num_pg_by_state":[{"name":"active+clean","num":33}],"num_pgs":33}}`
+
+func (s *rollingRestartSuite) TestPlanRestartSteps() {
+	members := []string{"node4", "node1", "node3", "node2", "node5"}
+	domains := map[string]string{"node1": "rack1", "node2": "rack1", "node3": "rack1", "node4": "rack2"}
+	osds := map[string][]int64{"node1": {0, 1}, "node2": {2}, "node4": {3}}
+
+	steps := planRestartSteps(members, domains, osds, 2)
+	assert.Equal(s.T(), []restartStep{
+		{Members: []string{"node5"}, OSDs: []int64{}},
+		{Members: []string{"node1", "node2"}, OSDs: []int64{0, 1, 2}},
+		{Members: []string{"node3"}, OSDs: []int64{}},
+		{Members: []string{"node4"}, OSDs: []int64{3}},
+	}, steps)
+
+	// Without failure domain buckets every member is a failure domain of its own.
+	steps = planRestartSteps([]string{"node2", "node1"}, nil, nil, 0)
+	assert.Equal(s.T(), []restartStep{
+		{Members: []string{"node1"}, OSDs: []int64{}},
+		{Members: []string{"node2"}, OSDs: []int64{}},
+	}, steps)
+}
+
+const rackRuleDump = `[
+{"rule_id":0,"rule_name":"replicated_rule","steps":[{"op":"take","item":-1,"item_name":"default"},{"op":"chooseleaf_firstn","num":0,"type":"host"},{"op":"emit"}]},
+{"rule_id":3,"rule_name":"microceph_auto_rack","steps":[{"op":"take","item":-1,"item_name":"default"},{"op":"chooseleaf_firstn","num":0,"type":"rack"},{"op":"emit"}]}
+]`
+
+func (s *rollingRestartSuite) TestGetCrushFailureDomains() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "get", "mon", "osd_pool_default_crush_rule").Return("3", nil).Once()
+	r.On("RunCommand", "ceph", "osd", "crush", "rule", "dump", "-f", "json").Return(rackRuleDump, nil).Once()
+	r.On("RunCommandContext", mock.Anything, "ceph", "osd", "tree", "-f", "json").Return(`{"nodes":[
+{"id":-1,"name":"default","type":"root","children":[-5,-6,-4]},
+{"id":-5,"name":"rack1","type":"rack","children":[-2,-3]},
+{"id":-6,"name":"rack2","type":"rack","children":[]},
+{"id":-2,"name":"node1","type":"host","children":[0]},
+{"id":-3,"name":"node2","type":"host","children":[1]},
+{"id":-4,"name":"node3","type":"host","children":[2]},
+{"id":0,"name":"osd.0","type":"osd"},
+{"id":1,"name":"osd.1","type":"osd"},
+{"id":2,"name":"osd.2","type":"osd"}
+]}`, nil).Once()
+	common.ProcessExec = r
+
+	// Hosts outside of a rack, or not in the CRUSH map, are left out.
+	domains, err := getCrushFailureDomains(context.Background(), []string{"node1", "node2", "node3", "node4"})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), map[string]string{"node1": "rack1", "node2": "rack1"}, domains)
+}
+
+func (s *rollingRestartSuite) TestGetCrushFailureDomainsHostRule() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "config", "get", "mon", "osd_pool_default_crush_rule").Return("0", nil).Once()
+	r.On("RunCommand", "ceph", "osd", "crush", "rule", "dump", "-f", "json").Return(rackRuleDump, nil).Once()
+	common.ProcessExec = r
+
+	// The CRUSH tree is not needed when replicas spread across hosts.
+	domains, err := getCrushFailureDomains(context.Background(), []string{"node1", "node2"})
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), domains)
+}
+
+func (s *rollingRestartSuite) TestCheckHealthStatus() {
+	tolerated := common.Set{"OSDMAP_FLAGS": struct{}{}, "MON_DOWN": struct{}{}}
+
+	assert.NoError(s.T(), checkHealthStatus(`{"status":"HEALTH_OK","checks":{}}`, tolerated))
+	assert.NoError(s.T(), checkHealthStatus(`{"status":"HEALTH_WARN","checks":{"OSDMAP_FLAGS":{"severity":"HEALTH_WARN"}}}`, tolerated))
+
+	err := checkHealthStatus(`{"status":"HEALTH_WARN","checks":{"POOL_NO_REDUNDANCY":{"severity":"HEALTH_WARN"},"OSDMAP_FLAGS":{"severity":"HEALTH_WARN"}}}`, tolerated)
+	assert.EqualError(s.T(), err, "cluster is HEALTH_WARN: POOL_NO_REDUNDANCY")
+
+	// Errors are never tolerated.
+	err = checkHealthStatus(`{"status":"HEALTH_ERR","checks":{"MON_DOWN":{"severity":"HEALTH_ERR"}}}`, tolerated)
+	assert.EqualError(s.T(), err, "cluster is HEALTH_ERR: MON_DOWN")
+}
+
+func (s *rollingRestartSuite) TestCheckPGStatus() {
+	assert.NoError(s.T(), checkPGStatus(healthyPGStat))
+	assert.NoError(s.T(), checkPGStatus(`{"pg_ready":true,"pg_summary":{"num_pg_by_state":[],"num_pgs":0}}`))
+
+	err := checkPGStatus(`{"pg_summary":{"num_pg_by_state":[{"name":"active+clean","num":30},{"name":"active+undersized+degraded","num":3}],"num_pgs":33}}`)
+	assert.EqualError(s.T(), err, "30 of 33 PGs are active+clean")
+}
+
+func (s *rollingRestartSuite) TestWaitForRestartHealth() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "health", "-f", "json").Return(`{"status":"HEALTH_WARN","checks":{"PG_DEGRADED":{"severity":"HEALTH_WARN"}}}`, nil).Once()
+	r.On("RunCommand", "ceph", "health", "-f", "json").Return(`{"status":"HEALTH_OK","checks":{}}`, nil).Twice()
+	r.On("RunCommand", "ceph", "pg", "stat", "-f", "json").Return(healthyPGStat, nil).Twice()
+	r.On("RunCommand", "ceph", "osd", "ok-to-stop", "osd.0", "osd.1").Return("", fmt.Errorf("not ok")).Once()
+	r.On("RunCommand", "ceph", "osd", "ok-to-stop", "osd.0", "osd.1").Return("", nil).Once()
+	common.ProcessExec = r
+
+	err := waitForRestartHealth(context.Background(), common.Set{}, []int64{0, 1}, time.Minute)
+	assert.NoError(s.T(), err)
+}
+
+func (s *rollingRestartSuite) TestWaitForRestartHealthTimeout() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "health", "-f", "json").Return(`{"status":"HEALTH_ERR","checks":{"OSD_FULL":{"severity":"HEALTH_ERR"}}}`, nil)
+	common.ProcessExec = r
+
+	err := waitForRestartHealth(context.Background(), common.Set{}, nil, 0)
+	assert.ErrorContains(s.T(), err, "cluster is HEALTH_ERR: OSD_FULL")
+}
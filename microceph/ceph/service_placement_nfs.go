@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"syscall"
 
 	"github.com/canonical/microceph/microceph/api/types"
@@ -78,12 +79,18 @@ func (nfs *NFSServicePlacement) PostPlacementCheck(s interfaces.StateInterface)
 }
 
 func (nfs *NFSServicePlacement) DbUpdate(ctx context.Context, s interfaces.StateInterface) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
 	groupConfig := database.NFSServiceGroupConfig{
 		V4MinVersion: nfs.V4MinVersion,
 	}
 	serviceInfo := database.NFSServiceInfo{
 		BindAddress: nfs.BindAddress,
 		BindPort:    nfs.BindPort,
+		Hostname:    hostname,
 	}
 
 	return database.GroupedServicesQuery.AddNew(ctx, s, "nfs", nfs.ClusterID, groupConfig, serviceInfo)
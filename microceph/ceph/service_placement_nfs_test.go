@@ -3,6 +3,7 @@ package ceph
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 
@@ -101,9 +102,13 @@ func (s *servicePlacementNFSSuite) TestDBUpdate() {
 		V4MinVersion: nfs.V4MinVersion,
 	}
 
+	hostname, err := os.Hostname()
+	assert.NoError(s.T(), err)
+
 	serviceInfo := database.NFSServiceInfo{
 		BindAddress: nfs.BindAddress,
 		BindPort:    nfs.BindPort,
+		Hostname:    hostname,
 	}
 
 	db := mocks.NewGroupedServiceQueryIntf(s.T())
@@ -117,7 +122,7 @@ func (s *servicePlacementNFSSuite) TestDBUpdate() {
 	defer func() { database.GroupedServicesQuery = originalDB }()
 	database.GroupedServicesQuery = db
 
-	err := nfs.DbUpdate(ctx, s.TestStateInterface)
+	err = nfs.DbUpdate(ctx, s.TestStateInterface)
 
 	assert.NoError(s.T(), err)
 }
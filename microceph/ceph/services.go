@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/canonical/microceph/microceph/constants"
@@ -22,6 +26,9 @@ import (
 	"github.com/tidwall/gjson"
 )
 
+// osdDaemonRegex matches the name of a single OSD daemon.
+var osdDaemonRegex = regexp.MustCompile(`^osd\.[0-9]+$`)
+
 // Table to map fetchFunc for workers (daemons) to a service.
 var serviceWorkerTable = map[string](func() (common.Set, error)){
 	"osd": getUpOsds,
@@ -48,8 +55,13 @@ func RestartCephServices(ctx context.Context, s interfaces.StateInterface, servi
 	return nil
 }
 
-// Restart provided ceph service ("mon"/"osd"...) on the host.
+// Restart provided ceph service ("mon"/"osd"...) on the host. A single OSD
+// ("osd.N") is restarted on the host holding it, leaving the others running.
 func RestartCephService(clusterServices types.Services, service string, hostname string) error {
+	if IsOSDDaemon(service) {
+		return restartOSDDaemon(strings.TrimPrefix(service, "osd."))
+	}
+
 	// check if incorrect services are requested.
 	if _, ok := serviceWorkerTable[service]; !ok {
 		err := fmt.Errorf("no handler defined for service %s", service)
@@ -103,6 +115,114 @@ func RestartCephService(clusterServices types.Services, service string, hostname
 	return nil
 }
 
+// IsOSDDaemon reports whether name designates a single OSD, as in osd.3.
+func IsOSDDaemon(name string) bool {
+	return osdDaemonRegex.MatchString(name)
+}
+
+// findOSDPid looks up the pid of the ceph-osd process running the given OSD.
+func findOSDPid(id string) (int, error) {
+	procPath := constants.GetPathConst().ProcPath
+	entries, err := os.ReadDir(procPath)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		cmdline, err := os.ReadFile(filepath.Join(procPath, entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue
+		}
+
+		args := strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+		if filepath.Base(args[0]) != "ceph-osd" {
+			continue
+		}
+
+		for i := 1; i < len(args)-1; i++ {
+			if args[i] == "--id" && args[i+1] == id {
+				return pid, nil
+			}
+		}
+	}
+
+	return 0, os.ErrNotExist
+}
+
+// isOSDUp reports whether the OSD is marked up in the OSD map.
+func isOSDUp(id string) (bool, error) {
+	output, err := common.ProcessExec.RunCommand("ceph", "osd", "dump", "-f", "json")
+	if err != nil {
+		return false, err
+	}
+
+	return gjson.Get(output, fmt.Sprintf("osds.#(osd==%s).up", id)).Int() == 1, nil
+}
+
+// restartOSDDaemon restarts a single OSD if it is held by this host. The OSD is
+// stopped and the osd service is signalled to spawn the OSDs that are not
+// running, the other OSDs of the host are left alone.
+func restartOSDDaemon(id string) error {
+	_, err := os.Stat(filepath.Join(constants.GetPathConst().DataPath, "osd", fmt.Sprintf("ceph-%s", id)))
+	if err != nil {
+		logger.Infof("osd.%s is not held by the current host", id)
+		return nil
+	}
+
+	pid, err := findOSDPid(id)
+	if err == nil {
+		err = syscall.Kill(pid, syscall.SIGTERM)
+		if err != nil {
+			return fmt.Errorf("failed to stop osd.%s: %w", id, err)
+		}
+
+		err = retry.Retry(func(i uint) error {
+			_, err := findOSDPid(id)
+			if err == nil {
+				return fmt.Errorf("attempt %d: osd.%s still running", i, id)
+			}
+			return nil
+		}, strategy.Limit(30), strategy.Wait(2*time.Second))
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(constants.GetPathConst().RunPath, "ceph-osd.pid"))
+	if err != nil {
+		return fmt.Errorf("failed to read the osd service pid: %w", err)
+	}
+
+	servicePid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("invalid osd service pid: %w", err)
+	}
+
+	err = syscall.Kill(servicePid, syscall.SIGHUP)
+	if err != nil {
+		return fmt.Errorf("failed to signal the osd service: %w", err)
+	}
+
+	return retry.Retry(func(i uint) error {
+		up, err := isOSDUp(id)
+		if err != nil {
+			return err
+		}
+
+		if !up {
+			err := fmt.Errorf("attempt %d: osd.%s not up yet", i, id)
+			logger.Errorf("%v", err)
+			return err
+		}
+		return nil
+	}, strategy.Delay(5), strategy.Limit(10), strategy.Backoff(backoff.Linear(10*time.Second)))
+}
+
 func getUpRgws() (common.Set, error) {
 	// check if rgw was up for atleast 2 seconds.
 	rgwSocketFiles := common.FilterFilesInDir(constants.RgwSockPattern, constants.GetPathConst().RunPath)
@@ -4,7 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microceph/microceph/database"
 	"github.com/canonical/microceph/microceph/interfaces"
 )
 
@@ -13,6 +18,15 @@ type RgwServicePlacement struct {
 	SSLPort        int
 	SSLCertificate string
 	SSLPrivateKey  string
+	// SSLSelfManaged issues the SSL certificate from the cluster-internal CA.
+	SSLSelfManaged bool
+	// GroupID places an additional, grouped, RGW daemon. Grouped daemons
+	// serve the Realm, ZoneGroup and Zone given here rather than the zone
+	// recorded for the default daemon.
+	GroupID   string
+	Realm     string
+	ZoneGroup string
+	Zone      string
 }
 
 func (rgw *RgwServicePlacement) PopulateParams(s interfaces.StateInterface, payload string) error {
@@ -22,11 +36,84 @@ func (rgw *RgwServicePlacement) PopulateParams(s interfaces.StateInterface, payl
 		return err
 	}
 
+	if rgw.SSLSelfManaged && (len(rgw.SSLCertificate) != 0 || len(rgw.SSLPrivateKey) != 0) {
+		return fmt.Errorf("a self-managed SSL certificate cannot be combined with certificate material")
+	}
+
+	if len(rgw.GroupID) == 0 {
+		if len(rgw.Realm) != 0 || len(rgw.ZoneGroup) != 0 || len(rgw.Zone) != 0 {
+			return fmt.Errorf("realm, zonegroup and zone can only be set for grouped RGW services, the default one follows the recorded multisite zone")
+		}
+
+		return nil
+	}
+
+	// The default daemon runs as client.radosgw.gateway.
+	if !types.RGWGroupIDRegex.MatchString(rgw.GroupID) || rgw.GroupID == "gateway" {
+		return fmt.Errorf("expected group_id to be valid (regex: '%s', not 'gateway')", types.RGWGroupIDRegex.String())
+	}
+
+	for _, name := range []string{rgw.Realm, rgw.ZoneGroup, rgw.Zone} {
+		if len(name) != 0 && !types.RgwNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid multisite name %q, expected to match '%s'", name, types.RgwNameRegex.String())
+		}
+	}
+
+	if len(rgw.Zone) == 0 && (len(rgw.Realm) != 0 || len(rgw.ZoneGroup) != 0) {
+		return fmt.Errorf("a zone is required when setting the realm or zonegroup")
+	}
+
+	if !rgw.SSLSelfManaged && (len(rgw.SSLCertificate) == 0 || len(rgw.SSLPrivateKey) == 0) {
+		if rgw.Port == 0 {
+			rgw.Port = 80
+		}
+	}
+
 	return nil
 }
 
 func (rgw *RgwServicePlacement) HospitalityCheck(s interfaces.StateInterface) error {
-	return genericHospitalityCheck("rgw")
+	confDir := constants.GetPathConst().ConfPath
+
+	if len(rgw.GroupID) == 0 {
+		// With grouped daemons around the service is active regardless of
+		// the default daemon, look for its configuration instead.
+		if len(rgwGroupConfFiles(confDir)) == 0 {
+			return genericHospitalityCheck("rgw")
+		}
+
+		_, err := os.Stat(filepath.Join(confDir, rgwInstance{}.confFile()))
+		if err == nil {
+			return fmt.Errorf("rgw service already active on host")
+		}
+
+		return nil
+	}
+
+	_, err := os.Stat(filepath.Join(confDir, rgwInstance{groupID: rgw.GroupID}.confFile()))
+	if err == nil {
+		return fmt.Errorf("RGW service with GroupID '%s' already active on host", rgw.GroupID)
+	}
+
+	ports := []int{}
+	if rgw.Port != 0 {
+		ports = append(ports, rgw.Port)
+	}
+	if rgw.SSLSelfManaged || (len(rgw.SSLCertificate) != 0 && len(rgw.SSLPrivateKey) != 0) {
+		ports = append(ports, rgw.SSLPort)
+	}
+
+	for _, port := range ports {
+		address := fmt.Sprintf("0.0.0.0:%d", port)
+		available, err := isAddressAvailable(address)
+		if err != nil {
+			return fmt.Errorf("error encountered during address availability check: %w", err)
+		} else if !available {
+			return fmt.Errorf("address '%s' is currently in use.", address)
+		}
+	}
+
+	return nil
 }
 
 func (rgw *RgwServicePlacement) ServiceInit(ctx context.Context, s interfaces.StateInterface) error {
@@ -36,7 +123,24 @@ func (rgw *RgwServicePlacement) ServiceInit(ctx context.Context, s interfaces.St
 		return fmt.Errorf("failed to get config db: %w", err)
 	}
 
-	return EnableRGW(s, rgw.Port, rgw.SSLPort, rgw.SSLCertificate, rgw.SSLPrivateKey, getMonitorsFromConfig(config))
+	var caPEM []byte
+	if rgw.SSLSelfManaged {
+		rgw.SSLCertificate, rgw.SSLPrivateKey, caPEM, err = issueRGWCertificate(ctx, s)
+		if err != nil {
+			return fmt.Errorf("failed to issue RGW certificate: %w", err)
+		}
+	}
+
+	if len(rgw.GroupID) != 0 {
+		err = EnableRGWGroup(s, rgw, getMonitorsFromConfig(config))
+	} else {
+		err = EnableRGW(s, rgw.Port, rgw.SSLPort, rgw.SSLCertificate, rgw.SSLPrivateKey, getMonitorsFromConfig(config), getRgwZoneConfig(config))
+	}
+	if err != nil || caPEM == nil {
+		return err
+	}
+
+	return writeRGWCAFile(rgwInstance{groupID: rgw.GroupID}, caPEM)
 }
 
 func (rgw *RgwServicePlacement) PostPlacementCheck(s interfaces.StateInterface) error {
@@ -44,5 +148,20 @@ func (rgw *RgwServicePlacement) PostPlacementCheck(s interfaces.StateInterface)
 }
 
 func (rgw *RgwServicePlacement) DbUpdate(ctx context.Context, s interfaces.StateInterface) error {
-	return genericDbUpdate(ctx, s, "rgw")
+	if len(rgw.GroupID) == 0 {
+		return genericDbUpdate(ctx, s, "rgw")
+	}
+
+	groupConfig := database.RGWServiceGroupConfig{
+		Realm:     rgw.Realm,
+		ZoneGroup: rgw.ZoneGroup,
+		Zone:      rgw.Zone,
+	}
+	serviceInfo := database.RGWServiceInfo{
+		Port:    rgw.Port,
+		SSLPort: rgw.SSLPort,
+		SSL:     len(rgw.SSLCertificate) != 0 && len(rgw.SSLPrivateKey) != 0,
+	}
+
+	return database.GroupedServicesQuery.AddNew(ctx, s, "rgw", rgw.GroupID, groupConfig, serviceInfo)
 }
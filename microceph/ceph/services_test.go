@@ -99,6 +99,37 @@ func (s *servicesSuite) TestRestartServiceWorkerSuccess() {
 	assert.NoError(s.T(), err)
 }
 
+func (s *servicesSuite) TestFindOSDPid() {
+	root := filepath.Join(s.Tmp, "root")
+	s.T().Setenv("TEST_ROOT_PATH", root)
+	procs := map[string]string{
+		"101": "ceph-osd\x00--cluster\x00ceph\x00--id\x0012\x00",
+		"102": "/usr/bin/ceph-osd\x00--cluster\x00ceph\x00--id\x001\x00",
+		"103": "ceph-mon\x00--id\x001\x00",
+	}
+	for pid, cmdline := range procs {
+		_ = os.MkdirAll(filepath.Join(root, "proc", pid), 0755)
+		_ = os.WriteFile(filepath.Join(root, "proc", pid, "cmdline"), []byte(cmdline), 0644)
+	}
+
+	pid, err := findOSDPid("1")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 102, pid)
+
+	_, err = findOSDPid("3")
+	assert.ErrorIs(s.T(), err, os.ErrNotExist)
+}
+
+func (s *servicesSuite) TestRestartOSDDaemonSkipsOtherHosts() {
+	// No OSD data dir on this host, nothing is signalled.
+	err := RestartCephService(types.Services{}, "osd.7", "foohost")
+	assert.NoError(s.T(), err)
+
+	assert.True(s.T(), IsOSDDaemon("osd.7"))
+	assert.False(s.T(), IsOSDDaemon("osd"))
+	assert.False(s.T(), IsOSDDaemon("osd.foo"))
+}
+
 // TestCleanService tests the cleanService function.
 func (s *servicesSuite) TestCleanService() {
 	s.CopyCephConfigs()
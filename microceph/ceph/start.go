@@ -98,21 +98,31 @@ func checkVersions() (bool, error) {
 	return false, nil
 }
 
-func osdReleaseRequired(version string) (bool, error) {
+// getRequireOSDRelease fetches the minimum release OSDs are required to run.
+func getRequireOSDRelease() (string, error) {
 	out, err := common.ProcessExec.RunCommand("ceph", "osd", "dump", "-f", "json")
 	if err != nil {
-		return false, fmt.Errorf("failed to get OSD dump: %w", err)
+		return "", fmt.Errorf("failed to get OSD dump: %w", err)
 	}
 
 	var result map[string]any
 	err = json.Unmarshal([]byte(out), &result)
 	if err != nil {
-		return false, fmt.Errorf("failed to unmarshal OSD dump: %w", err)
+		return "", fmt.Errorf("failed to unmarshal OSD dump: %w", err)
 	}
 
 	releaseVersion, ok := result["require_osd_release"].(string)
 	if !ok {
-		return false, fmt.Errorf("invalid or missing require_osd_release in OSD dump")
+		return "", fmt.Errorf("invalid or missing require_osd_release in OSD dump")
+	}
+
+	return releaseVersion, nil
+}
+
+func osdReleaseRequired(version string) (bool, error) {
+	releaseVersion, err := getRequireOSDRelease()
+	if err != nil {
+		return false, err
 	}
 
 	return releaseVersion != version, nil
@@ -171,16 +181,18 @@ func PostRefresh() error {
 // services can be re-enabled successfully.
 func migrateStaleRunDir() {
 	pathConsts := constants.GetPathConst()
-	rgwConf := filepath.Join(pathConsts.ConfPath, "radosgw.conf")
-	changed, err := fixRadosGWRunDir(rgwConf, pathConsts.RunPath)
-	if err != nil {
-		logger.Warnf("migration: failed to update run dir in radosgw.conf: %v", err)
-	} else if changed {
-		logger.Infof("migration: fixed stale run dir in %s", rgwConf)
+	rgwConfs := append([]string{filepath.Join(pathConsts.ConfPath, "radosgw.conf")}, rgwGroupConfFiles(pathConsts.ConfPath)...)
+	for _, rgwConf := range rgwConfs {
+		changed, err := fixRadosGWRunDir(rgwConf, pathConsts.RunPath)
+		if err != nil {
+			logger.Warnf("migration: failed to update run dir in %s: %v", filepath.Base(rgwConf), err)
+		} else if changed {
+			logger.Infof("migration: fixed stale run dir in %s", rgwConf)
+		}
 	}
 
 	ganeshaConf := filepath.Join(pathConsts.ConfPath, "ganesha", "ganesha.conf")
-	changed, err = fixGaneshaRunDir(ganeshaConf, pathConsts.RunPath)
+	changed, err := fixGaneshaRunDir(ganeshaConf, pathConsts.RunPath)
 	if err != nil {
 		logger.Warnf("migration: failed to update run dir in ganesha.conf: %v", err)
 	} else if changed {
@@ -217,7 +229,8 @@ func fixRadosGWRunDir(confFile, correctRunDir string) (bool, error) {
 // re-rendered on every refresh, radosgw.conf is written once at RGW enable time
 // (see EnableRGW) because the RGW frontend port/SSL settings are not persisted.
 // Rewriting only the mon host line in place preserves those settings while
-// keeping the monitor list fresh. It is a no-op when radosgw.conf does not
+// keeping the monitor list fresh. The radosgw-<group>.conf files of grouped
+// RGW daemons are refreshed likewise. It is a no-op when radosgw.conf does not
 // exist (RGW is not enabled).
 func updateRadosGWMonHost(confDir string, monitors []string) error {
 	// Never wipe an existing mon host line on an empty/unknown monitor set:
@@ -237,24 +250,79 @@ func updateRadosGWMonHost(confDir string, monitors []string) error {
 
 	radosgwConfMu.Lock()
 	defer radosgwConfMu.Unlock()
-	confFile := filepath.Join(confDir, "radosgw.conf")
-	changed, err := fixConfigLine(confFile, func(line string) (string, bool) {
-		if !strings.HasPrefix(strings.TrimSpace(line), "mon host = ") {
-			return line, false
+	confFiles := append([]string{filepath.Join(confDir, "radosgw.conf")}, rgwGroupConfFiles(confDir)...)
+	for _, confFile := range confFiles {
+		changed, err := fixConfigLine(confFile, func(line string) (string, bool) {
+			if !strings.HasPrefix(strings.TrimSpace(line), "mon host = ") {
+				return line, false
+			}
+			correct := "mon host = " + strings.Join(sorted, ",")
+			if line == correct {
+				return line, false
+			}
+			return correct, true
+		})
+		if err != nil {
+			return err
 		}
-		correct := "mon host = " + strings.Join(sorted, ",")
-		if line == correct {
-			return line, false
+		if changed {
+			logger.Infof("updated radosgw.conf mon host in %s", confFile)
 		}
-		return correct, true
-	})
+	}
+	return nil
+}
+
+// updateRadosGWZone rewrites the multisite lines of <confDir>/radosgw.conf to
+// point the RGW daemons at the given realm, zonegroup and zone. Stale lines are
+// dropped and the current ones appended to the [client.radosgw.gateway]
+// section, which is the last one in the file. It reports whether the file
+// changed and is a no-op when radosgw.conf does not exist.
+func updateRadosGWZone(confDir string, zone RgwZoneConfig) (bool, error) {
+	radosgwConfMu.Lock()
+	defer radosgwConfMu.Unlock()
+
+	confFile := filepath.Join(confDir, "radosgw.conf")
+	data, err := os.ReadFile(confFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
 	if err != nil {
-		return err
+		return false, err
 	}
-	if changed {
-		logger.Infof("updated radosgw.conf mon host in %s", confFile)
+
+	lines := []string{}
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if isRadosGWZoneLine(line) {
+			continue
+		}
+		lines = append(lines, line)
 	}
-	return nil
+	lines = append(lines, zone.confLines()...)
+
+	content := strings.Join(lines, "\n") + "\n"
+	if content == string(data) {
+		return false, nil
+	}
+
+	err = replaceConfigFile(confFile, content)
+	if err != nil {
+		return false, err
+	}
+
+	logger.Infof("updated radosgw.conf multisite zone in %s", confFile)
+	return true, nil
+}
+
+// isRadosGWZoneLine checks if a radosgw.conf line is one rendered by RgwZoneConfig.
+func isRadosGWZoneLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"rgw realm = ", "rgw zonegroup = ", "rgw zone = "} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // fixGaneshaRunDir updates the CCacheDir line in ganesha.conf to use correctRunDir.
@@ -300,17 +368,26 @@ func fixConfigLine(confFile string, fn func(string) (string, bool)) (bool, error
 		return false, nil
 	}
 
+	err = replaceConfigFile(confFile, strings.Join(lines, "\n"))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// replaceConfigFile atomically replaces confFile with content.
+func replaceConfigFile(confFile string, content string) error {
 	tmpFile := confFile + ".tmp"
-	err = os.WriteFile(tmpFile, []byte(strings.Join(lines, "\n")), constants.PermissionUserRwWorldRAccess)
+	err := os.WriteFile(tmpFile, []byte(content), constants.PermissionUserRwWorldRAccess)
 	if err != nil {
-		return false, fmt.Errorf("failed to write %s: %w", tmpFile, err)
+		return fmt.Errorf("failed to write %s: %w", tmpFile, err)
 	}
 	err = os.Rename(tmpFile, confFile)
 	if err != nil {
 		os.Remove(tmpFile)
-		return false, fmt.Errorf("failed to replace %s: %w", confFile, err)
+		return fmt.Errorf("failed to replace %s: %w", confFile, err)
 	}
-	return true, nil
+	return nil
 }
 
 // reEnableServices checks which services are registered in the database for
@@ -467,6 +544,13 @@ func Start(ctx context.Context, s interfaces.StateInterface) error {
 				}
 				continue
 			}
+			if first {
+				err = backwardCompatDefaultConfigs(ctx, s)
+				if err != nil {
+					logger.Warnf("start: failed to track the bootstrap configs: %v", err)
+				}
+			}
+
 			logger.Debug("start: updated config, sleeping")
 			first = false // for subsequent runs
 			oldMonitors = monitors
@@ -497,15 +581,82 @@ func Start(ctx context.Context, s interfaces.StateInterface) error {
 		reEnableServices(ctx, s)
 	}()
 
+	// Serve the RGW zones handed over by sites joining this cluster to their
+	// zonegroup.
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Minute):
+			}
+
+			err := s.ClusterState().Database().IsOpen(ctx)
+			if err != nil {
+				continue
+			}
+
+			bootstrapped, err := cephIsBootstrappedFunc(ctx, s)
+			if err != nil || !bootstrapped {
+				continue
+			}
+
+			err = AdoptRgwZone(ctx, s)
+			if err != nil {
+				logger.Warnf("start: failed to adopt handed over RGW zone: %v", err)
+			}
+		}
+	}()
+
+	// Renew the self-managed RGW certificates ahead of their expiry.
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Hour):
+			}
+
+			err := s.ClusterState().Database().IsOpen(ctx)
+			if err != nil {
+				continue
+			}
+
+			err = RenewRGWCertificates(ctx, s)
+			if err != nil {
+				logger.Warnf("start: failed to renew RGW certificates: %v", err)
+			}
+		}
+	}()
+
 	go func() {
 		select {
 		case <-ctx.Done():
 			return
 		case <-time.After(10 * time.Second): // wait for the mons to converge
 		}
-		err := PostRefresh()
+		postRefreshErr := PostRefresh()
+		if postRefreshErr != nil {
+			logger.Errorf("PostRefresh failed: %v", postRefreshErr)
+		}
+
+		// The daemon is back, on the new revision or the one snapd rolled
+		// back to, let other members refresh.
+		for {
+			err := s.ClusterState().Database().IsOpen(ctx)
+			if err == nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(10 * time.Second):
+			}
+		}
+
+		err := completeRefresh(ctx, s, postRefreshErr)
 		if err != nil {
-			logger.Errorf("PostRefresh failed: %v", err)
+			logger.Warnf("start: failed to mark refresh completed: %v", err)
 		}
 	}()
 
@@ -495,3 +495,50 @@ func (s *startSuite) TestUpdateRadosGWMonHostUnchangedSetDifferentOrder() {
 
 	assert.Equal(s.T(), content, s.ReadCephConfig("radosgw.conf"))
 }
+
+// --- updateRadosGWZone tests ---
+
+func (s *startSuite) TestUpdateRadosGWZoneAppendsLines() {
+	s.CopyCephConfigs()
+	content := s.radosgwConfForTest("mon host = 192.168.123.11")
+
+	changed, err := updateRadosGWZone(constants.GetPathConst().ConfPath,
+		RgwZoneConfig{Realm: "gold", ZoneGroup: "eu", Zone: "sitea"})
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), changed)
+
+	expected := content + "rgw realm = gold\nrgw zonegroup = eu\nrgw zone = sitea\n"
+	assert.Equal(s.T(), expected, s.ReadCephConfig("radosgw.conf"))
+}
+
+func (s *startSuite) TestUpdateRadosGWZoneReplacesStaleLines() {
+	s.CopyCephConfigs()
+	content := s.radosgwConfForTest("mon host = 192.168.123.11")
+	s.writeConf("radosgw.conf", content+"rgw realm = gold\nrgw zonegroup = eu\nrgw zone = default\n")
+
+	changed, err := updateRadosGWZone(constants.GetPathConst().ConfPath,
+		RgwZoneConfig{Realm: "gold", ZoneGroup: "eu", Zone: "siteb"})
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), changed)
+
+	result := s.ReadCephConfig("radosgw.conf")
+	assert.Contains(s.T(), result, "rgw zone = siteb\n")
+	assert.NotContains(s.T(), result, "rgw zone = default")
+	assert.Contains(s.T(), result, "rgw frontends = beast port=80")
+
+	// A second pass is a no-op.
+	changed, err = updateRadosGWZone(constants.GetPathConst().ConfPath,
+		RgwZoneConfig{Realm: "gold", ZoneGroup: "eu", Zone: "siteb"})
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), changed)
+}
+
+func (s *startSuite) TestUpdateRadosGWZoneEmptyKeepsDefault() {
+	s.CopyCephConfigs()
+	content := s.radosgwConfForTest("mon host = 192.168.123.11")
+
+	changed, err := updateRadosGWZone(constants.GetPathConst().ConfPath, RgwZoneConfig{})
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), changed)
+	assert.Equal(s.T(), content, s.ReadCephConfig("radosgw.conf"))
+}
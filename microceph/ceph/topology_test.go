@@ -264,6 +264,7 @@ func (s *topologySuite) TestUpdateFailureDomainNoAZs() {
 	addOsdPoolSetExpectations(r)
 
 	common.ProcessExec = r
+	stubConfigHistory(s.T())
 
 	c := mocks.NewMemberCounterInterface(s.T())
 	c.On("Count", mock.Anything, mock.Anything).Return(3, nil).Once()
@@ -0,0 +1,312 @@
+package ceph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/logger"
+)
+
+// upgradePhases lists the services in the order Ceph recommends upgrading
+// them. Members hosting none of them are upgraded last.
+var upgradePhases = []string{"mon", "mgr", "osd"}
+
+// snapdSocket is the path of the snapd REST API socket, overridden in tests.
+var snapdSocket = "/run/snapd.socket"
+
+// snapRefreshFunc refreshes the local snap, overridden in tests.
+var snapRefreshFunc = snapRefresh
+
+// PlanUpgrade orders the members of the cluster for a rolling upgrade: monitor
+// hosts first, then manager hosts, then OSD hosts and finally the members
+// hosting only other services. A member is upgraded in the first phase any of
+// its services belongs to.
+func PlanUpgrade(members []string, services types.Services) []types.UpgradeStep {
+	hosted := map[string][]string{}
+	for _, member := range members {
+		hosted[member] = []string{}
+	}
+
+	for _, service := range services {
+		hosted[service.Location] = append(hosted[service.Location], service.Service)
+	}
+
+	phaseOf := func(member string) int {
+		for i, phase := range upgradePhases {
+			for _, service := range hosted[member] {
+				if service == phase {
+					return i
+				}
+			}
+		}
+
+		return len(upgradePhases)
+	}
+
+	names := sortedKeys(hosted)
+	sort.SliceStable(names, func(i, j int) bool {
+		return phaseOf(names[i]) < phaseOf(names[j])
+	})
+
+	steps := make([]types.UpgradeStep, 0, len(names))
+	for _, name := range names {
+		memberServices := hosted[name]
+		sort.Strings(memberServices)
+		steps = append(steps, types.UpgradeStep{Member: name, Services: memberServices})
+	}
+
+	return steps
+}
+
+// GetUpgradePlan orders the members of the cluster for a rolling upgrade.
+func GetUpgradePlan(ctx context.Context, s interfaces.StateInterface) (types.UpgradePlan, error) {
+	services, err := database.ServiceQuery.List(ctx, s.ClusterState())
+	if err != nil {
+		return types.UpgradePlan{}, fmt.Errorf("failed fetching services from db: %w", err)
+	}
+
+	members := []string{}
+	for name := range s.ClusterState().Truststore().RemotesByName() {
+		members = append(members, name)
+	}
+
+	return types.UpgradePlan{Steps: PlanUpgrade(members, services)}, nil
+}
+
+// snapRefresh asks snapd to refresh the snap to a channel. The refresh runs in
+// the background and restarts the daemon. It returns false when the snap
+// already runs the latest revision of the channel.
+func snapRefresh(channel string) (bool, error) {
+	if !isIntfConnected("snapd-control") {
+		return false, fmt.Errorf("the snapd-control interface is not connected, run 'snap connect microceph:snapd-control'")
+	}
+
+	name := os.Getenv("SNAP_INSTANCE_NAME")
+	if len(name) == 0 {
+		name = "microceph"
+	}
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", snapdSocket)
+			},
+		},
+	}
+
+	body, err := json.Marshal(map[string]string{"action": "refresh", "channel": channel})
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := httpClient.Post("http://localhost/v2/snaps/"+name, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to reach snapd: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Type   string `json:"type"`
+		Change string `json:"change"`
+		Result struct {
+			Message string `json:"message"`
+			Kind    string `json:"kind"`
+		} `json:"result"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode snapd response: %w", err)
+	}
+
+	if result.Type == "error" {
+		if result.Result.Kind == "snap-no-update-available" {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("snapd refused to refresh %s: %s", name, result.Result.Message)
+	}
+
+	logger.Infof("Upgrade: refreshing %s to %s (change %s)", name, channel, result.Change)
+	return true, nil
+}
+
+// RefreshMember starts the refresh of the local snap to a channel. The refresh
+// is claimed beforehand so that the pre-refresh health gate lets it through,
+// the member being in maintenance mode.
+func RefreshMember(ctx context.Context, s interfaces.StateInterface, channel string) (types.UpgradeRefreshResult, error) {
+	if len(channel) == 0 {
+		return types.UpgradeRefreshResult{}, fmt.Errorf("no channel to refresh to")
+	}
+
+	decision := types.RefreshDecision{Member: s.ClusterState().Name(), Allowed: true, Revision: os.Getenv("SNAP_REVISION"), Timestamp: time.Now()}
+	decision, err := database.ClaimRefreshDb(ctx, s.ClusterState(), decision, refreshClaimTTL)
+	if err != nil {
+		return types.UpgradeRefreshResult{}, err
+	}
+
+	if !decision.Allowed {
+		return types.UpgradeRefreshResult{}, fmt.Errorf("refresh refused: %s", decision.Reason)
+	}
+
+	refreshing, err := snapRefreshFunc(channel)
+	if err != nil || !refreshing {
+		// Nothing is refreshing, release the claim.
+		decision.Completed = true
+		claimErr := database.SetRefreshDecisionDb(ctx, s.ClusterState(), decision)
+		if claimErr != nil {
+			logger.Warnf("Upgrade: failed to release refresh claim: %v", claimErr)
+		}
+	}
+	if err != nil {
+		return types.UpgradeRefreshResult{}, err
+	}
+
+	return types.UpgradeRefreshResult{Refreshing: refreshing}, nil
+}
+
+// getVersionCounts fetches the number of daemons running every Ceph version.
+func getVersionCounts() (map[string]int32, error) {
+	out, err := common.ProcessExec.RunCommand("ceph", "versions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Ceph versions: %w", err)
+	}
+
+	var cephVer cephVersion
+	err = json.Unmarshal([]byte(out), &cephVer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Ceph versions: %w", err)
+	}
+
+	return cephVer.Overall, nil
+}
+
+// upgradeDaemonTypes lists the daemon types whose release is checked after a
+// member is refreshed.
+var upgradeDaemonTypes = []string{"mon", "mgr", "osd", "mds"}
+
+// getOutdatedDaemons lists the daemons running on host, or on any host if
+// empty, which do not report release, as "<type>.<id>".
+func getOutdatedDaemons(host string, release string) ([]string, error) {
+	outdated := []string{}
+	for _, daemonType := range upgradeDaemonTypes {
+		out, err := common.ProcessExec.RunCommand("ceph", daemonType, "metadata", "-f", "json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s metadata: %w", daemonType, err)
+		}
+
+		var daemons []struct {
+			Name        string      `json:"name"`
+			ID          json.Number `json:"id"`
+			Hostname    string      `json:"hostname"`
+			CephRelease string      `json:"ceph_release"`
+		}
+		err = json.Unmarshal([]byte(out), &daemons)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s metadata: %w", daemonType, err)
+		}
+
+		for _, d := range daemons {
+			if (len(host) > 0 && d.Hostname != host) || d.CephRelease == release {
+				continue
+			}
+
+			// OSDs are identified by their number, other daemons by name.
+			name := d.Name
+			if len(name) == 0 {
+				name = d.ID.String()
+			}
+
+			outdated = append(outdated, fmt.Sprintf("%s.%s", daemonType, name))
+		}
+	}
+
+	return outdated, nil
+}
+
+// GetUpgradeStatus reports the snap revision and Ceph release of the local
+// member, the Ceph versions running in the cluster, the daemons of the member
+// not yet running that release and whether the cluster is healthy enough for
+// the next upgrade step.
+func GetUpgradeStatus(s interfaces.StateInterface, tolerated []string) (types.UpgradeStatus, error) {
+	status := types.UpgradeStatus{
+		Member:   s.ClusterState().Name(),
+		Revision: os.Getenv("SNAP_REVISION"),
+	}
+
+	release, err := getCurrentVersion()
+	if err != nil {
+		return status, err
+	}
+	status.Release = release
+
+	status.Versions, err = getVersionCounts()
+	if err != nil {
+		return status, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return status, fmt.Errorf("failed to get hostname: %w", err)
+	}
+
+	status.Outdated, err = getOutdatedDaemons(hostname, release)
+	if err != nil {
+		return status, err
+	}
+
+	err = checkRestartHealth(toSet(tolerated), nil)
+	if err != nil {
+		status.Health = err.Error()
+	}
+
+	return status, nil
+}
+
+// FinalizeUpgrade checks every daemon runs the Ceph release of the local
+// member and raises require-osd-release to it. It returns the release, or an
+// error listing the daemons still running another release.
+func FinalizeUpgrade() (string, error) {
+	release, err := getCurrentVersion()
+	if err != nil {
+		return "", err
+	}
+
+	outdated, err := getOutdatedDaemons("", release)
+	if err != nil {
+		return "", err
+	}
+
+	if len(outdated) > 0 {
+		return "", fmt.Errorf("daemons not yet running %s: %s", release, strings.Join(outdated, ", "))
+	}
+
+	mustUpdate, err := osdReleaseRequired(release)
+	if err != nil {
+		return "", fmt.Errorf("OSD release check failed: %w", err)
+	}
+
+	if !mustUpdate {
+		return release, nil
+	}
+
+	err = updateOSDRelease(release)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Infof("Upgrade: raised require-osd-release to %s", release)
+	return release, nil
+}
@@ -0,0 +1,145 @@
+package ceph
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type upgradeSuite struct {
+	tests.BaseSuite
+}
+
+func TestUpgrade(t *testing.T) {
+	suite.Run(t, new(upgradeSuite))
+}
+
+func (s *upgradeSuite) TestPlanUpgrade() {
+	members := []string{"node1", "node2", "node3", "node4", "node5"}
+	services := types.Services{
+		{Service: "rgw", Location: "node1"},
+		{Service: "osd", Location: "node1"},
+		{Service: "osd", Location: "node2"},
+		{Service: "mgr", Location: "node3"},
+		{Service: "osd", Location: "node3"},
+		{Service: "mon", Location: "node4"},
+		{Service: "mgr", Location: "node4"},
+	}
+
+	steps := PlanUpgrade(members, services)
+	assert.Equal(s.T(), []types.UpgradeStep{
+		{Member: "node4", Services: []string{"mgr", "mon"}},
+		{Member: "node3", Services: []string{"mgr", "osd"}},
+		{Member: "node1", Services: []string{"osd", "rgw"}},
+		{Member: "node2", Services: []string{"osd"}},
+		{Member: "node5", Services: []string{}},
+	}, steps)
+}
+
+// addDaemonMetadata expects the metadata of a mon, mgr and OSD, all running
+// the squid release except the OSD which runs osdRelease.
+func addDaemonMetadata(r *mocks.Runner, osdRelease string) {
+	r.On("RunCommand", "ceph", "mon", "metadata", "-f", "json").Return(`[{"name": "node1", "hostname": "node1", "ceph_release": "squid"}]`, nil).Once()
+	r.On("RunCommand", "ceph", "mgr", "metadata", "-f", "json").Return(`[{"name": "node1", "hostname": "node1", "ceph_release": "squid"}]`, nil).Once()
+	r.On("RunCommand", "ceph", "osd", "metadata", "-f", "json").Return(`[{"id": 0, "hostname": "node2", "ceph_release": "`+osdRelease+`"}]`, nil).Once()
+	r.On("RunCommand", "ceph", "mds", "metadata", "-f", "json").Return(`[]`, nil).Once()
+}
+
+func (s *upgradeSuite) TestFinalizeUpgrade() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "-v").Return(`ceph version 19.2.0 (e7ad5345525c7aa95470c26863873b581076945d) squid (stable)`, nil).Once()
+	addDaemonMetadata(r, "squid")
+	r.On("RunCommand", "ceph", "osd", "dump", "-f", "json").Return(`{"require_osd_release": "reef"}`, nil).Once()
+	r.On("RunCommand", "ceph", "osd", "require-osd-release", "squid", "--yes-i-really-mean-it").Return("ok", nil).Once()
+	common.ProcessExec = r
+
+	release, err := FinalizeUpgrade()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "squid", release)
+}
+
+func (s *upgradeSuite) TestFinalizeUpgradeMixedVersions() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "-v").Return(`ceph version 19.2.0 (e7ad5345525c7aa95470c26863873b581076945d) squid (stable)`, nil).Once()
+	addDaemonMetadata(r, "reef")
+	common.ProcessExec = r
+
+	_, err := FinalizeUpgrade()
+	assert.EqualError(s.T(), err, "daemons not yet running squid: osd.0")
+}
+
+// serveSnapd answers snapd REST API requests on a unix socket with a response.
+func (s *upgradeSuite) serveSnapd(response map[string]any) *map[string]string {
+	dir, err := os.MkdirTemp("", "snapd")
+	assert.NoError(s.T(), err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	origSocket := snapdSocket
+	s.T().Cleanup(func() { snapdSocket = origSocket })
+	snapdSocket = filepath.Join(dir, "snapd.socket")
+
+	listener, err := net.Listen("unix", snapdSocket)
+	assert.NoError(s.T(), err)
+
+	received := map[string]string{}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received["path"] = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_ = json.NewEncoder(w).Encode(response)
+	})}
+	go func() { _ = server.Serve(listener) }()
+	s.T().Cleanup(func() { server.Close() })
+
+	return &received
+}
+
+func (s *upgradeSuite) TestSnapRefresh() {
+	s.T().Setenv("SNAP_INSTANCE_NAME", "microceph")
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "snapctl", "is-connected", "snapd-control").Return("", nil).Twice()
+	common.ProcessExec = r
+
+	received := s.serveSnapd(map[string]any{"type": "async", "change": "42"})
+	refreshing, err := snapRefresh("squid/stable")
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), refreshing)
+	assert.Equal(s.T(), map[string]string{"path": "/v2/snaps/microceph", "action": "refresh", "channel": "squid/stable"}, *received)
+
+	s.serveSnapd(map[string]any{"type": "error", "result": map[string]string{"kind": "snap-no-update-available", "message": "snap has no updates available"}})
+	refreshing, err = snapRefresh("squid/stable")
+	assert.NoError(s.T(), err)
+	assert.False(s.T(), refreshing)
+}
+
+func (s *upgradeSuite) TestSnapRefreshNotConnected() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "snapctl", "is-connected", "snapd-control").Return("", os.ErrNotExist).Once()
+	common.ProcessExec = r
+
+	_, err := snapRefresh("squid/stable")
+	assert.ErrorContains(s.T(), err, "snapd-control interface is not connected")
+}
+
+func (s *upgradeSuite) TestGetOutdatedDaemons() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "mon", "metadata", "-f", "json").Return(`[{"name": "node1", "hostname": "node1", "ceph_release": "squid"}, {"name": "node2", "hostname": "node2", "ceph_release": "reef"}]`, nil).Once()
+	r.On("RunCommand", "ceph", "mgr", "metadata", "-f", "json").Return(`[{"name": "node1", "hostname": "node1", "ceph_release": "reef"}]`, nil).Once()
+	r.On("RunCommand", "ceph", "osd", "metadata", "-f", "json").Return(`[{"id": 0, "hostname": "node1", "ceph_release": "reef"}, {"id": 1, "hostname": "node1", "ceph_release": "squid"}]`, nil).Once()
+	r.On("RunCommand", "ceph", "mds", "metadata", "-f", "json").Return(`[]`, nil).Once()
+	common.ProcessExec = r
+
+	outdated, err := getOutdatedDaemons("node1", "squid")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"mgr.node1", "osd.0"}, outdated)
+}
@@ -0,0 +1,158 @@
+package ceph
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/database"
+	"github.com/canonical/microceph/microceph/interfaces"
+	"github.com/canonical/microceph/microceph/version"
+)
+
+// versionedServices lists the services whose daemons answer "ceph tell version",
+// their daemons are named after the member hosting them.
+var versionedServices = []string{"mon", "mgr", "mds"}
+
+// GetMemberVersions reports the snap revision and microcephd version of the
+// local member.
+func GetMemberVersions(s interfaces.StateInterface) types.MemberVersions {
+	return types.MemberVersions{
+		Member:           s.ClusterState().Name(),
+		Revision:         os.Getenv("SNAP_REVISION"),
+		MicroCephVersion: version.Version(),
+		Daemons:          []types.DaemonVersion{},
+	}
+}
+
+// getMemberDaemons maps every member to the names of the Ceph daemons it hosts.
+func getMemberDaemons(services types.Services, disks types.Disks) map[string][]string {
+	daemons := map[string][]string{}
+	for _, service := range services {
+		for _, name := range versionedServices {
+			if service.Service == name {
+				daemons[service.Location] = append(daemons[service.Location], fmt.Sprintf("%s.%s", name, service.Location))
+			}
+		}
+	}
+
+	for _, disk := range disks {
+		daemons[disk.Location] = append(daemons[disk.Location], fmt.Sprintf("osd.%d", disk.OSD))
+	}
+
+	for member := range daemons {
+		sort.Strings(daemons[member])
+	}
+
+	return daemons
+}
+
+// getDaemonVersion asks a daemon the Ceph version it runs.
+func getDaemonVersion(name string) types.DaemonVersion {
+	daemon := types.DaemonVersion{Name: name}
+	output, err := common.ProcessExec.RunCommand("ceph", "tell", name, "version", "-f", "json")
+	if err != nil {
+		daemon.Error = err.Error()
+		return daemon
+	}
+
+	daemon.Version = gjson.Get(output, "version").String()
+	daemon.Release = gjson.Get(output, "release").String()
+	return daemon
+}
+
+// flagVersionSkew sets the mixed versions and pending require-osd-release
+// flags of a report.
+func flagVersionSkew(report *types.ClusterVersions) {
+	report.MixedCeph = len(report.Versions) > 1
+
+	revisions := common.Set{}
+	osdReleases := common.Set{}
+	for _, member := range report.Members {
+		if len(member.Revision) != 0 {
+			revisions.Insert(member.Revision)
+		}
+
+		for _, daemon := range member.Daemons {
+			if len(daemon.Release) != 0 && strings.HasPrefix(daemon.Name, "osd.") {
+				osdReleases.Insert(daemon.Release)
+			}
+		}
+	}
+
+	report.MixedSnap = len(revisions) > 1
+	if len(osdReleases) == 1 {
+		report.OSDRelease = osdReleases.Keys()[0]
+		report.OSDReleasePending = len(report.RequireOSDRelease) != 0 && report.OSDRelease != report.RequireOSDRelease
+	}
+}
+
+// GetClusterVersions reports the snap revision and microcephd version of every
+// member and the Ceph version of every daemon they host, flagging mixed
+// versions and a pending require-osd-release.
+func GetClusterVersions(ctx context.Context, s interfaces.StateInterface) (types.ClusterVersions, error) {
+	report := types.ClusterVersions{Members: []types.MemberVersions{}}
+
+	services, err := database.ServiceQuery.List(ctx, s.ClusterState())
+	if err != nil {
+		return report, fmt.Errorf("failed fetching services from db: %w", err)
+	}
+
+	disks, err := ListOSD(ctx, s.ClusterState())
+	if err != nil {
+		return report, fmt.Errorf("failed to list OSDs: %w", err)
+	}
+
+	clients, err := getMemberClients(s)
+	if err != nil {
+		return report, err
+	}
+
+	members := []string{}
+	for name := range s.ClusterState().Truststore().RemotesByName() {
+		members = append(members, name)
+	}
+	sort.Strings(members)
+
+	daemons := getMemberDaemons(services, disks)
+	for _, name := range members {
+		member := types.MemberVersions{Member: name}
+		if name == s.ClusterState().Name() {
+			member = GetMemberVersions(s)
+		} else if remoteClient, ok := clients[name]; ok {
+			member, err = client.GetMemberVersions(ctx, remoteClient)
+			if err != nil {
+				member = types.MemberVersions{Member: name, Error: err.Error()}
+			}
+		} else {
+			member.Error = "member is unreachable"
+		}
+
+		member.Daemons = []types.DaemonVersion{}
+		for _, daemon := range daemons[name] {
+			member.Daemons = append(member.Daemons, getDaemonVersion(daemon))
+		}
+
+		report.Members = append(report.Members, member)
+	}
+
+	report.Versions, err = getVersionCounts()
+	if err != nil {
+		return report, err
+	}
+
+	report.RequireOSDRelease, err = getRequireOSDRelease()
+	if err != nil {
+		return report, err
+	}
+
+	flagVersionSkew(&report)
+	return report, nil
+}
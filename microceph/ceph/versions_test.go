@@ -0,0 +1,75 @@
+package ceph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/mocks"
+	"github.com/canonical/microceph/microceph/tests"
+)
+
+type versionsSuite struct {
+	tests.BaseSuite
+}
+
+func TestVersions(t *testing.T) {
+	suite.Run(t, new(versionsSuite))
+}
+
+func (s *versionsSuite) TestGetMemberDaemons() {
+	services := types.Services{
+		{Service: "mon", Location: "node1"},
+		{Service: "mgr", Location: "node1"},
+		{Service: "rgw", Location: "node1"},
+		{Service: "mds", Location: "node2"},
+	}
+	disks := types.Disks{
+		{OSD: 3, Location: "node2"},
+		{OSD: 1, Location: "node2"},
+	}
+
+	assert.Equal(s.T(), map[string][]string{
+		"node1": {"mgr.node1", "mon.node1"},
+		"node2": {"mds.node2", "osd.1", "osd.3"},
+	}, getMemberDaemons(services, disks))
+}
+
+func (s *versionsSuite) TestGetDaemonVersion() {
+	r := mocks.NewRunner(s.T())
+	r.On("RunCommand", "ceph", "tell", "osd.1", "version", "-f", "json").Return(`{"version":"19.2.0","release":"squid","release_type":"stable"}`, nil).Once()
+	r.On("RunCommand", "ceph", "tell", "osd.2", "version", "-f", "json").Return("", fmt.Errorf("osd.2 is down")).Once()
+	common.ProcessExec = r
+
+	assert.Equal(s.T(), types.DaemonVersion{Name: "osd.1", Version: "19.2.0", Release: "squid"}, getDaemonVersion("osd.1"))
+	assert.Equal(s.T(), types.DaemonVersion{Name: "osd.2", Error: "osd.2 is down"}, getDaemonVersion("osd.2"))
+}
+
+func (s *versionsSuite) TestFlagVersionSkew() {
+	report := types.ClusterVersions{
+		Members: []types.MemberVersions{
+			{Member: "node1", Revision: "100", Daemons: []types.DaemonVersion{{Name: "mon.node1", Release: "reef"}, {Name: "osd.0", Release: "squid"}}},
+			{Member: "node2", Revision: "101", Daemons: []types.DaemonVersion{{Name: "osd.1", Release: "squid"}, {Name: "osd.2", Error: "down"}}},
+		},
+		Versions:          map[string]int32{"squid": 2, "reef": 1},
+		RequireOSDRelease: "reef",
+	}
+
+	flagVersionSkew(&report)
+	assert.True(s.T(), report.MixedCeph)
+	assert.True(s.T(), report.MixedSnap)
+	assert.Equal(s.T(), "squid", report.OSDRelease)
+	assert.True(s.T(), report.OSDReleasePending)
+
+	// OSDs still running mixed releases leave require-osd-release alone.
+	report.Members[1].Daemons[0].Release = "reef"
+	report.OSDRelease = ""
+	report.OSDReleasePending = false
+	flagVersionSkew(&report)
+	assert.Empty(s.T(), report.OSDRelease)
+	assert.False(s.T(), report.OSDReleasePending)
+}
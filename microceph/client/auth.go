@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// ListAuthClients fetches the cephx clients and their capabilities.
+func ListAuthClients(ctx context.Context, c mcTypes.Client) (types.AuthClients, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	clients := types.AuthClients{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("auth", "clients").URL, nil, &clients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cephx clients: %w", err)
+	}
+
+	return clients, nil
+}
+
+// CreateAuthClient creates a cephx client and returns it along with its key.
+func CreateAuthClient(ctx context.Context, c mcTypes.Client, req types.AuthClientRequest) (types.AuthClient, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	client := types.AuthClient{}
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("auth", "clients").URL, req, &client)
+	if err != nil {
+		return client, fmt.Errorf("failed to create cephx client %s: %w", req.Name, err)
+	}
+
+	return client, nil
+}
+
+// GetAuthClient fetches a cephx client along with its key and keyring.
+func GetAuthClient(ctx context.Context, c mcTypes.Client, name string) (types.AuthClient, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	client := types.AuthClient{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("auth", "clients", name).URL, nil, &client)
+	if err != nil {
+		return client, fmt.Errorf("failed to fetch cephx client %s: %w", name, err)
+	}
+
+	return client, nil
+}
+
+// RotateAuthClientKey replaces the key of a cephx client and returns the new one.
+func RotateAuthClientKey(ctx context.Context, c mcTypes.Client, name string) (types.AuthClient, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	client := types.AuthClient{}
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("auth", "clients", name, "rotate").URL, nil, &client)
+	if err != nil {
+		return client, fmt.Errorf("failed to rotate key of cephx client %s: %w", name, err)
+	}
+
+	return client, nil
+}
+
+// DeleteAuthClient removes a cephx client.
+func DeleteAuthClient(ctx context.Context, c mcTypes.Client, name string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "DELETE", types.ExtendedPathPrefix, &api.NewURL().Path("auth", "clients", name).URL, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove cephx client %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// BackupCluster writes a backup archive of the cluster on the local member.
+// The monitor store can be large, so no timeout is set beyond the one of the
+// context.
+func BackupCluster(ctx context.Context, c mcTypes.Client, data *types.ClusterBackup) (types.ClusterBackupResult, error) {
+	result := types.ClusterBackupResult{}
+	err := c.Query(ctx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("ops", "backup").URL, data, &result)
+	if err != nil {
+		return result, fmt.Errorf("failed to back up cluster: %w", err)
+	}
+
+	return result, nil
+}
+
+// RestoreCluster rebuilds the control plane on the local member from a backup
+// archive in its backup directory. No timeout is set beyond the one of the
+// context, the monitor has to form a quorum.
+func RestoreCluster(ctx context.Context, c mcTypes.Client, data *types.ClusterRestore) (types.ClusterRestoreResult, error) {
+	result := types.ClusterRestoreResult{}
+	err := c.Query(ctx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("ops", "restore").URL, data, &result)
+	if err != nil {
+		return result, fmt.Errorf("failed to restore cluster: %w", err)
+	}
+
+	return result, nil
+}
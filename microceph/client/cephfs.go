@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// ListFsVolumes fetches the CephFS volumes and their pools.
+func ListFsVolumes(ctx context.Context, c mcTypes.Client) (types.FsVolumes, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	volumes := types.FsVolumes{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("fs", "volumes").URL, nil, &volumes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CephFS volumes: %w", err)
+	}
+
+	return volumes, nil
+}
+
+// CreateFsVolume requests the creation of a CephFS volume.
+func CreateFsVolume(ctx context.Context, c mcTypes.Client, data types.FsVolumeCreateRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*300)
+	defer cancel()
+
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("fs", "volumes").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create CephFS volume %s: %w", data.Name, err)
+	}
+
+	return nil
+}
+
+// SetFsVolume requests an update of the MDS settings of a CephFS volume.
+func SetFsVolume(ctx context.Context, c mcTypes.Client, volume string, data types.FsVolumeSetRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "PUT", types.ExtendedPathPrefix, &api.NewURL().Path("fs", "volumes", volume).URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update CephFS volume %s: %w", volume, err)
+	}
+
+	return nil
+}
+
+// DeleteFsVolume requests the removal of a CephFS volume.
+func DeleteFsVolume(ctx context.Context, c mcTypes.Client, volume string, data types.FsVolumeDeleteRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*300)
+	defer cancel()
+
+	err := c.Query(queryCtx, "DELETE", types.ExtendedPathPrefix, &api.NewURL().Path("fs", "volumes", volume).URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove CephFS volume %s: %w", volume, err)
+	}
+
+	return nil
+}
+
+// ListFsSubvolumes fetches the subvolumes of a CephFS volume, optionally within a group.
+func ListFsSubvolumes(ctx context.Context, c mcTypes.Client, volume string, group string) (types.FsSubvolumes, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("fs", "volumes", volume, "subvolumes")
+	if len(group) != 0 {
+		endpoint = endpoint.WithQuery("group", group)
+	}
+
+	subvolumes := types.FsSubvolumes{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &endpoint.URL, nil, &subvolumes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subvolumes of CephFS volume %s: %w", volume, err)
+	}
+
+	return subvolumes, nil
+}
+
+// GetFsSubvolume fetches the path of a CephFS subvolume.
+func GetFsSubvolume(ctx context.Context, c mcTypes.Client, volume string, subvolume string, group string) (types.FsSubvolume, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("fs", "volumes", volume, "subvolumes", subvolume)
+	if len(group) != 0 {
+		endpoint = endpoint.WithQuery("group", group)
+	}
+
+	response := types.FsSubvolume{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &endpoint.URL, nil, &response)
+	if err != nil {
+		return response, fmt.Errorf("failed to get subvolume %s of CephFS volume %s: %w", subvolume, volume, err)
+	}
+
+	return response, nil
+}
+
+// CreateFsSubvolume requests the creation of a CephFS subvolume.
+func CreateFsSubvolume(ctx context.Context, c mcTypes.Client, volume string, data types.FsSubvolumeRequest) error {
+	return sendFsSubvolumeRequest(ctx, c, "POST", volume, "subvolumes", "", data)
+}
+
+// ResizeFsSubvolume requests a quota update of a CephFS subvolume.
+func ResizeFsSubvolume(ctx context.Context, c mcTypes.Client, volume string, data types.FsSubvolumeRequest) error {
+	return sendFsSubvolumeRequest(ctx, c, "PUT", volume, "subvolumes", data.Name, data)
+}
+
+// DeleteFsSubvolume requests the removal of a CephFS subvolume.
+func DeleteFsSubvolume(ctx context.Context, c mcTypes.Client, volume string, data types.FsSubvolumeRequest) error {
+	return sendFsSubvolumeRequest(ctx, c, "DELETE", volume, "subvolumes", data.Name, data)
+}
+
+// ListFsSubvolumeGroups fetches the subvolume groups of a CephFS volume.
+func ListFsSubvolumeGroups(ctx context.Context, c mcTypes.Client, volume string) (types.FsSubvolumes, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	groups := types.FsSubvolumes{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("fs", "volumes", volume, "subvolumegroups").URL, nil, &groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subvolume groups of CephFS volume %s: %w", volume, err)
+	}
+
+	return groups, nil
+}
+
+// GetFsSubvolumeGroup fetches the path of a CephFS subvolume group.
+func GetFsSubvolumeGroup(ctx context.Context, c mcTypes.Client, volume string, group string) (types.FsSubvolume, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	response := types.FsSubvolume{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("fs", "volumes", volume, "subvolumegroups", group).URL, nil, &response)
+	if err != nil {
+		return response, fmt.Errorf("failed to get subvolume group %s of CephFS volume %s: %w", group, volume, err)
+	}
+
+	return response, nil
+}
+
+// CreateFsSubvolumeGroup requests the creation of a CephFS subvolume group.
+func CreateFsSubvolumeGroup(ctx context.Context, c mcTypes.Client, volume string, data types.FsSubvolumeRequest) error {
+	return sendFsSubvolumeRequest(ctx, c, "POST", volume, "subvolumegroups", "", data)
+}
+
+// ResizeFsSubvolumeGroup requests a quota update of a CephFS subvolume group.
+func ResizeFsSubvolumeGroup(ctx context.Context, c mcTypes.Client, volume string, data types.FsSubvolumeRequest) error {
+	return sendFsSubvolumeRequest(ctx, c, "PUT", volume, "subvolumegroups", data.Name, data)
+}
+
+// DeleteFsSubvolumeGroup requests the removal of a CephFS subvolume group.
+func DeleteFsSubvolumeGroup(ctx context.Context, c mcTypes.Client, volume string, data types.FsSubvolumeRequest) error {
+	return sendFsSubvolumeRequest(ctx, c, "DELETE", volume, "subvolumegroups", data.Name, data)
+}
+
+// sendFsSubvolumeRequest sends a subvolume or subvolume group request, name is empty for collection requests.
+func sendFsSubvolumeRequest(ctx context.Context, c mcTypes.Client, method string, volume string, kind string, name string, data types.FsSubvolumeRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("fs", "volumes", volume, kind)
+	if len(name) != 0 {
+		endpoint = api.NewURL().Path("fs", "volumes", volume, kind, name)
+	}
+
+	err := c.Query(queryCtx, method, types.ExtendedPathPrefix, &endpoint.URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed %s request for %s %s of CephFS volume %s: %w", method, kind, data.Name, volume, err)
+	}
+
+	return nil
+}
+
+// ListFsSnapSchedules fetches the snapshot schedules of a path or subvolume in a CephFS volume.
+func ListFsSnapSchedules(ctx context.Context, c mcTypes.Client, volume string, data types.FsSnapScheduleRequest) (types.FsSnapSchedules, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("fs", "volumes", volume, "snap-schedules")
+	if len(data.Path) != 0 {
+		endpoint = endpoint.WithQuery("path", data.Path)
+	}
+	if len(data.Subvolume) != 0 {
+		endpoint = endpoint.WithQuery("subvolume", data.Subvolume)
+	}
+	if len(data.Group) != 0 {
+		endpoint = endpoint.WithQuery("group", data.Group)
+	}
+
+	schedules := types.FsSnapSchedules{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &endpoint.URL, nil, &schedules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot schedules of CephFS volume %s: %w", volume, err)
+	}
+
+	return schedules, nil
+}
+
+// AddFsSnapSchedule requests a snapshot schedule and/or retention policy to be added.
+func AddFsSnapSchedule(ctx context.Context, c mcTypes.Client, volume string, data types.FsSnapScheduleRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("fs", "volumes", volume, "snap-schedules").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to add snapshot schedule on CephFS volume %s: %w", volume, err)
+	}
+
+	return nil
+}
+
+// RemoveFsSnapSchedule requests a snapshot schedule and/or retention policy to be removed.
+func RemoveFsSnapSchedule(ctx context.Context, c mcTypes.Client, volume string, data types.FsSnapScheduleRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "DELETE", types.ExtendedPathPrefix, &api.NewURL().Path("fs", "volumes", volume, "snap-schedules").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove snapshot schedule on CephFS volume %s: %w", volume, err)
+	}
+
+	return nil
+}
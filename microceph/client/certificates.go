@@ -27,3 +27,21 @@ func SetRGWCertificate(ctx context.Context, c mcTypes.Client, req types.Certific
 
 	return nil
 }
+
+// GetRGWCertificates fetches the RGW SSL certificates installed on the target node.
+func GetRGWCertificates(ctx context.Context, c mcTypes.Client, target string) (types.CertificateInfos, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	if target != "" {
+		c = c.UseTarget(target)
+	}
+
+	certs := types.CertificateInfos{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("certificates", "rgw").URL, nil, &certs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch RGW certificates: %w", err)
+	}
+
+	return certs, nil
+}
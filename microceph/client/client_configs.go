@@ -98,3 +98,17 @@ func SendUpdateClientConfRequestToClusterMembers(ctx context.Context, s interfac
 
 	return nil
 }
+
+// GetClientBundle fetches the ceph.conf and keyring of a cephx client.
+func GetClientBundle(ctx context.Context, c mcTypes.Client, name string) (types.ClientBundle, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	bundle := types.ClientBundle{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("client", "bundles", name).URL, nil, &bundle)
+	if err != nil {
+		return bundle, fmt.Errorf("failed to fetch bundle of client %s: %w", name, err)
+	}
+
+	return bundle, nil
+}
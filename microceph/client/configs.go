@@ -47,3 +47,77 @@ func GetConfig(ctx context.Context, c mcTypes.Client, data *types.Config) (types
 
 	return configs, nil
 }
+
+// GetConfigHistory fetches the recorded cluster config changes, of all keys if key is empty.
+func GetConfigHistory(ctx context.Context, c mcTypes.Client, key string) (types.ConfigChanges, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	changes := types.ConfigChanges{}
+	endpoint := api.NewURL().Path("configs", "history")
+	if len(key) > 0 {
+		endpoint = endpoint.WithQuery("key", key)
+	}
+
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &endpoint.URL, nil, &changes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cluster config history: %w", err)
+	}
+
+	return changes, nil
+}
+
+// RollbackConfigChange restores the value a recorded cluster config change replaced.
+func RollbackConfigChange(ctx context.Context, c mcTypes.Client, id int64, data *types.ConfigRollback) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*200)
+	defer cancel()
+
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("configs", "history", fmt.Sprintf("%d", id), "rollback").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed rolling back cluster config change %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// ApplyConfig brings the cluster configuration to a config document and returns the plan.
+func ApplyConfig(ctx context.Context, c mcTypes.Client, data *types.ConfigApply) (types.ConfigPlan, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*600)
+	defer cancel()
+
+	plan := types.ConfigPlan{}
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("configs", "apply").URL, data, &plan)
+	if err != nil {
+		return plan, fmt.Errorf("failed applying cluster config: %w", err)
+	}
+
+	return plan, nil
+}
+
+// CheckConfigDrift fetches the drift between the options MicroCeph tracks and Ceph.
+func CheckConfigDrift(ctx context.Context, c mcTypes.Client) (types.ConfigDrifts, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	drifts := types.ConfigDrifts{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("configs", "check").URL, nil, &drifts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cluster config: %w", err)
+	}
+
+	return drifts, nil
+}
+
+// ReconcileConfigDrift reconciles the drift between the options MicroCeph tracks and Ceph.
+func ReconcileConfigDrift(ctx context.Context, c mcTypes.Client, data *types.ConfigReconcile) (types.ConfigDrifts, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	drifts := types.ConfigDrifts{}
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("configs", "check").URL, data, &drifts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile cluster config: %w", err)
+	}
+
+	return drifts, nil
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// ListNFSExports fetches the exports of an NFS cluster.
+func ListNFSExports(ctx context.Context, c mcTypes.Client, clusterID string) (types.NFSExports, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	exports := types.NFSExports{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("nfs", clusterID, "exports").URL, nil, &exports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports of NFS cluster %s: %w", clusterID, err)
+	}
+
+	return exports, nil
+}
+
+// CreateNFSExport adds an export to an NFS cluster and returns it with its ID.
+func CreateNFSExport(ctx context.Context, c mcTypes.Client, clusterID string, export types.NFSExport) (types.NFSExport, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	created := types.NFSExport{}
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("nfs", clusterID, "exports").URL, export, &created)
+	if err != nil {
+		return created, fmt.Errorf("failed to create export of NFS cluster %s: %w", clusterID, err)
+	}
+
+	return created, nil
+}
+
+// UpdateNFSExport replaces an export of an NFS cluster.
+func UpdateNFSExport(ctx context.Context, c mcTypes.Client, clusterID string, export types.NFSExport) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "PUT", types.ExtendedPathPrefix, &api.NewURL().Path("nfs", clusterID, "exports", strconv.Itoa(export.ExportID)).URL, export, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update export %d of NFS cluster %s: %w", export.ExportID, clusterID, err)
+	}
+
+	return nil
+}
+
+// DeleteNFSExport removes an export of an NFS cluster.
+func DeleteNFSExport(ctx context.Context, c mcTypes.Client, clusterID string, exportID int) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "DELETE", types.ExtendedPathPrefix, &api.NewURL().Path("nfs", clusterID, "exports", strconv.Itoa(exportID)).URL, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove export %d of NFS cluster %s: %w", exportID, clusterID, err)
+	}
+
+	return nil
+}
+
+// GetNFSStatus fetches the gateways and grace database of an NFS cluster.
+func GetNFSStatus(ctx context.Context, c mcTypes.Client, target string, clusterID string) (types.NFSClusterStatus, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	if target != "" {
+		c = c.UseTarget(target)
+	}
+
+	status := types.NFSClusterStatus{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("nfs", clusterID, "status").URL, nil, &status)
+	if err != nil {
+		return status, fmt.Errorf("failed to fetch status of NFS cluster %s: %w", clusterID, err)
+	}
+
+	return status, nil
+}
+
+// GetNFSNodeStatus fetches the gateway the member c points at runs for an NFS cluster.
+func GetNFSNodeStatus(ctx context.Context, c mcTypes.Client, clusterID string) (types.NFSNodeStatus, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("nfs", clusterID, "status").WithQuery("local", "true")
+
+	node := types.NFSNodeStatus{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &endpoint.URL, nil, &node)
+	if err != nil {
+		return node, fmt.Errorf("failed to fetch the gateway status of NFS cluster %s: %w", clusterID, err)
+	}
+
+	return node, nil
+}
+
+// RepairNFSCluster removes stale nodes from the grace database of an NFS cluster.
+func RepairNFSCluster(ctx context.Context, c mcTypes.Client, clusterID string) ([]string, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	removed := []string{}
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("nfs", clusterID, "repair").URL, nil, &removed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to repair NFS cluster %s: %w", clusterID, err)
+	}
+
+	return removed, nil
+}
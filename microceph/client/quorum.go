@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// GetQuorumStatus fetches the monitor quorum as seen by the local monitor.
+func GetQuorumStatus(ctx context.Context, c mcTypes.Client) (types.QuorumStatus, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*60)
+	defer cancel()
+
+	status := types.QuorumStatus{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("ops", "recover-quorum").URL, nil, &status)
+	if err != nil {
+		return status, fmt.Errorf("failed to fetch quorum status: %w", err)
+	}
+
+	return status, nil
+}
+
+// RecoverQuorum removes unreachable monitors from the monmap of the local
+// monitor. No timeout is set beyond the one of the context, the monitor has
+// to form a quorum.
+func RecoverQuorum(ctx context.Context, c mcTypes.Client, data *types.QuorumRecovery) (types.QuorumRecoveryResult, error) {
+	result := types.QuorumRecoveryResult{}
+	err := c.Query(ctx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("ops", "recover-quorum").URL, data, &result)
+	if err != nil {
+		return result, fmt.Errorf("failed to recover quorum: %w", err)
+	}
+
+	return result, nil
+}
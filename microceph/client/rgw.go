@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// CreateRgwRealm requests the creation of an RGW realm.
+func CreateRgwRealm(ctx context.Context, c mcTypes.Client, data types.RgwRealmRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "realms").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create RGW realm %s: %w", data.Name, err)
+	}
+
+	return nil
+}
+
+// PullRgwRealm requests pulling an RGW realm from the master zone of another site.
+func PullRgwRealm(ctx context.Context, c mcTypes.Client, data types.RgwRealmRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "realms", data.Name, "pull").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to pull RGW realm %s: %w", data.Name, err)
+	}
+
+	return nil
+}
+
+// CommitRgwRealm requests a period commit of an RGW realm.
+func CommitRgwRealm(ctx context.Context, c mcTypes.Client, realm string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*300)
+	defer cancel()
+
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "realms", realm, "commit").URL, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to commit period of RGW realm %s: %w", realm, err)
+	}
+
+	return nil
+}
+
+// ApplyRgwZone requests a member to serve the recorded RGW multisite zone.
+func ApplyRgwZone(ctx context.Context, c mcTypes.Client) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "PUT", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "zone").URL, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to apply RGW multisite zone: %w", err)
+	}
+
+	return nil
+}
+
+// CreateRgwZoneGroup requests the creation of an RGW zonegroup.
+func CreateRgwZoneGroup(ctx context.Context, c mcTypes.Client, data types.RgwZoneGroupRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "zonegroups").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create RGW zonegroup %s: %w", data.Name, err)
+	}
+
+	return nil
+}
+
+// CreateRgwZone requests the creation of an RGW zone.
+func CreateRgwZone(ctx context.Context, c mcTypes.Client, data types.RgwZoneRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*300)
+	defer cancel()
+
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "zones").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create RGW zone %s: %w", data.Name, err)
+	}
+
+	return nil
+}
+
+// GetRgwSyncStatus fetches the multisite sync status of the local zone.
+func GetRgwSyncStatus(ctx context.Context, c mcTypes.Client) (types.RgwReplicationResponseStatus, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	status := types.RgwReplicationResponseStatus{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "sync", "status").URL, nil, &status)
+	if err != nil {
+		return status, fmt.Errorf("failed to fetch RGW sync status: %w", err)
+	}
+
+	return status, nil
+}
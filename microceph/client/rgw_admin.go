@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// ListRgwUsers fetches the IDs of the RGW users.
+func ListRgwUsers(ctx context.Context, c mcTypes.Client) ([]string, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	users := []string{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "users").URL, nil, &users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list RGW users: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetRgwUser fetches an RGW user with its access key IDs and quotas.
+func GetRgwUser(ctx context.Context, c mcTypes.Client, uid string) (types.RgwUser, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	user := types.RgwUser{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "users", uid).URL, nil, &user)
+	if err != nil {
+		return user, fmt.Errorf("failed to fetch RGW user %s: %w", uid, err)
+	}
+
+	return user, nil
+}
+
+// CreateRgwUser requests the creation of an RGW user.
+func CreateRgwUser(ctx context.Context, c mcTypes.Client, data types.RgwUserRequest) (types.RgwUser, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	user := types.RgwUser{}
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "users").URL, data, &user)
+	if err != nil {
+		return user, fmt.Errorf("failed to create RGW user %s: %w", data.UserID, err)
+	}
+
+	return user, nil
+}
+
+// SetRgwUser requests an update of an RGW user.
+func SetRgwUser(ctx context.Context, c mcTypes.Client, uid string, data types.RgwUserSetRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "PUT", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "users", uid).URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update RGW user %s: %w", uid, err)
+	}
+
+	return nil
+}
+
+// DeleteRgwUser requests the removal of an RGW user.
+func DeleteRgwUser(ctx context.Context, c mcTypes.Client, uid string, data types.RgwUserDeleteRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*300)
+	defer cancel()
+
+	err := c.Query(queryCtx, "DELETE", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "users", uid).URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove RGW user %s: %w", uid, err)
+	}
+
+	return nil
+}
+
+// CreateRgwUserKey requests a new S3 key for an RGW user, optionally replacing an old one.
+func CreateRgwUserKey(ctx context.Context, c mcTypes.Client, uid string, data types.RgwKeyRequest) (types.RgwUserKey, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	key := types.RgwUserKey{}
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "users", uid, "keys").URL, data, &key)
+	if err != nil {
+		return key, fmt.Errorf("failed to generate key for RGW user %s: %w", uid, err)
+	}
+
+	return key, nil
+}
+
+// DeleteRgwUserKey requests the removal of an S3 key of an RGW user.
+func DeleteRgwUserKey(ctx context.Context, c mcTypes.Client, uid string, accessKey string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "DELETE", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "users", uid, "keys", accessKey).URL, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove key %s of RGW user %s: %w", accessKey, uid, err)
+	}
+
+	return nil
+}
+
+// SetRgwUserQuota requests setting the user or per-bucket quota of an RGW user.
+func SetRgwUserQuota(ctx context.Context, c mcTypes.Client, uid string, data types.RgwQuotaRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	err := c.Query(queryCtx, "PUT", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "users", uid, "quota").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to set %s quota of RGW user %s: %w", data.Scope, uid, err)
+	}
+
+	return nil
+}
+
+// ListRgwBuckets fetches the RGW buckets with their usage, optionally only those of an owner.
+func ListRgwBuckets(ctx context.Context, c mcTypes.Client, owner string) (types.RgwBuckets, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("rgw", "buckets")
+	if len(owner) != 0 {
+		endpoint = endpoint.WithQuery("owner", owner)
+	}
+
+	buckets := types.RgwBuckets{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &endpoint.URL, nil, &buckets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list RGW buckets: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// SetRgwBucketOwner requests a change of the owner of an RGW bucket.
+func SetRgwBucketOwner(ctx context.Context, c mcTypes.Client, bucket string, data types.RgwBucketSetRequest) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*300)
+	defer cancel()
+
+	err := c.Query(queryCtx, "PUT", types.ExtendedPathPrefix, &api.NewURL().Path("rgw", "buckets", bucket).URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to change owner of RGW bucket %s: %w", bucket, err)
+	}
+
+	return nil
+}
@@ -60,6 +60,22 @@ func DeleteNFSService(ctx context.Context, c mcTypes.Client, target string, svc
 	return nil
 }
 
+// DeleteRGWService requests MicroCeph to deconfigure a grouped RGW service on a given target node.
+func DeleteRGWService(ctx context.Context, c mcTypes.Client, target string, svc *types.RGWGroupService) error {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	// Send this request to target.
+	c = c.UseTarget(target)
+
+	err := c.Query(queryCtx, "DELETE", types.ExtendedPathPrefix, &api.NewURL().Path("services", "rgw").URL, svc, nil)
+	if err != nil {
+		return fmt.Errorf("failed deleting RGW service: %w", err)
+	}
+
+	return nil
+}
+
 // Send a request to start certain service at the target node (hostname for remote target).
 func SendServicePlacementReq(ctx context.Context, c mcTypes.Client, data *types.EnableService, target string) error {
 	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
@@ -91,6 +107,18 @@ func RestartService(ctx context.Context, c mcTypes.Client, data *types.Services)
 	return nil
 }
 
+// RollingRestartServices sends a request to restart services member by member
+// across the cluster. Every step waits on the cluster health, so no timeout is
+// set beyond the one of the context.
+func RollingRestartServices(ctx context.Context, c mcTypes.Client, data *types.ServiceRestart) error {
+	err := c.Query(ctx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("services", "restart", "rolling").URL, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed rolling restart of %v: %w", data.Services, err)
+	}
+
+	return nil
+}
+
 // Sends the desired list of services to be restarted on every other member of the cluster.
 func SendRestartRequestToClusterMembers(ctx context.Context, s mcTypes.State, services []string) error {
 	// Populate the restart request data.
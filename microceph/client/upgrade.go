@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// GetUpgradePlan fetches the order the cluster members are upgraded in.
+func GetUpgradePlan(ctx context.Context, c mcTypes.Client) (types.UpgradePlan, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*60)
+	defer cancel()
+
+	plan := types.UpgradePlan{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("ops", "upgrade").URL, nil, &plan)
+	if err != nil {
+		return plan, fmt.Errorf("failed to fetch upgrade plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// RefreshMember starts the snap refresh of a cluster member to a channel.
+func RefreshMember(ctx context.Context, c mcTypes.Client, member string, channel string) (types.UpgradeRefreshResult, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	result := types.UpgradeRefreshResult{}
+	c = c.UseTarget(member)
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("ops", "upgrade", "refresh").URL, types.UpgradeRefresh{Channel: channel}, &result)
+	if err != nil {
+		return result, fmt.Errorf("failed to refresh %s: %w", member, err)
+	}
+
+	return result, nil
+}
+
+// GetUpgradeStatus fetches the upgrade state of a cluster member, the local
+// one if member is empty. The listed health warnings do not count against the
+// cluster health.
+func GetUpgradeStatus(ctx context.Context, c mcTypes.Client, member string, tolerated []string) (types.UpgradeStatus, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*60)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("ops", "upgrade", "status")
+	if len(tolerated) != 0 {
+		endpoint = endpoint.WithQuery("tolerate", strings.Join(tolerated, ","))
+	}
+
+	status := types.UpgradeStatus{}
+	if len(member) != 0 {
+		c = c.UseTarget(member)
+	}
+
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &endpoint.URL, nil, &status)
+	if err != nil {
+		return status, fmt.Errorf("failed to fetch upgrade status of %s: %w", member, err)
+	}
+
+	return status, nil
+}
+
+// FinalizeUpgrade checks every daemon runs the new release and raises
+// require-osd-release to it.
+func FinalizeUpgrade(ctx context.Context, c mcTypes.Client) (string, error) {
+	// checking versions retries for a while when they are still mixed.
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*300)
+	defer cancel()
+
+	var release string
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("ops", "upgrade").URL, nil, &release)
+	if err != nil {
+		return "", fmt.Errorf("failed to finalize upgrade: %w", err)
+	}
+
+	return release, nil
+}
+
+// CheckRefresh asks the pre-refresh health gate whether the member the client
+// points at can be refreshed.
+func CheckRefresh(ctx context.Context, c mcTypes.Client, data *types.RefreshCheck) (types.RefreshDecision, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*120)
+	defer cancel()
+
+	decision := types.RefreshDecision{}
+	err := c.Query(queryCtx, "POST", types.ExtendedPathPrefix, &api.NewURL().Path("ops", "refresh").URL, data, &decision)
+	if err != nil {
+		return decision, fmt.Errorf("failed to check refresh: %w", err)
+	}
+
+	return decision, nil
+}
+
+// GetRefreshDecisions fetches the latest refresh decision of every member.
+func GetRefreshDecisions(ctx context.Context, c mcTypes.Client) (types.RefreshDecisions, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*60)
+	defer cancel()
+
+	decisions := types.RefreshDecisions{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("ops", "refresh").URL, nil, &decisions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch refresh decisions: %w", err)
+	}
+
+	return decisions, nil
+}
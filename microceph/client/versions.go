@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// GetClusterVersions fetches the versions running across the cluster.
+func GetClusterVersions(ctx context.Context, c mcTypes.Client) (types.ClusterVersions, error) {
+	// every daemon is asked its version in turn.
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*300)
+	defer cancel()
+
+	report := types.ClusterVersions{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("versions").URL, nil, &report)
+	if err != nil {
+		return report, fmt.Errorf("failed to fetch cluster versions: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetMemberVersions fetches the snap revision and microcephd version of the
+// member the client points at.
+func GetMemberVersions(ctx context.Context, c mcTypes.Client) (types.MemberVersions, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	member := types.MemberVersions{}
+	err := c.Query(queryCtx, "GET", types.ExtendedPathPrefix, &api.NewURL().Path("versions", "member").URL, nil, &member)
+	if err != nil {
+		return member, fmt.Errorf("failed to fetch member versions: %w", err)
+	}
+
+	return member, nil
+}
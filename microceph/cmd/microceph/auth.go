@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdAuth struct {
+	common *CmdControl
+}
+
+func (c *cmdAuth) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage the cephx clients of consumers",
+	}
+
+	// create
+	createCmd := cmdAuthCreate{common: c.common}
+	cmd.AddCommand(createCmd.Command())
+
+	// ls
+	listCmd := cmdAuthList{common: c.common}
+	cmd.AddCommand(listCmd.Command())
+
+	// export
+	exportCmd := cmdAuthExport{common: c.common}
+	cmd.AddCommand(exportCmd.Command())
+
+	// rotate
+	rotateCmd := cmdAuthRotate{common: c.common}
+	cmd.AddCommand(rotateCmd.Command())
+
+	// rm
+	removeCmd := cmdAuthRemove{common: c.common}
+	cmd.AddCommand(removeCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdAuthCreate struct {
+	common       *CmdControl
+	flagRbd      []string
+	flagCephFS   []string
+	flagRgw      bool
+	flagReadOnly bool
+	flagOutput   string
+	json         bool
+}
+
+func (c *cmdAuthCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <NAME>",
+		Short: "Create a cephx client limited to the given pools, volumes or the RADOS Gateway",
+		Long: `Create a cephx client limited to the given pools, volumes or the RADOS Gateway.
+The keyring of the client is printed, or written to the --output file.`,
+		Example: `  microceph auth create vm-host --rbd vms --rbd images --read-only
+  microceph auth create web --cephfs shared:/web`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringArrayVar(&c.flagRbd, "rbd", nil, "Grant RBD access to a pool, may be repeated")
+	cmd.Flags().StringArrayVar(&c.flagCephFS, "cephfs", nil, "Grant access to a CephFS volume as VOLUME[:PATH], may be repeated")
+	cmd.Flags().BoolVar(&c.flagRgw, "rgw", false, "Grant the access needed to run a RADOS Gateway, limited to the RGW pools")
+	cmd.Flags().BoolVar(&c.flagReadOnly, "read-only", false, "Grant read-only access to the RBD pools and CephFS volumes")
+	cmd.Flags().StringVarP(&c.flagOutput, "output", "o", "", "Write the keyring to a file")
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdAuthCreate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	req := types.AuthClientRequest{Name: args[0], Profiles: []types.AuthClientProfile{}}
+	for _, pool := range c.flagRbd {
+		req.Profiles = append(req.Profiles, types.AuthClientProfile{Type: types.AuthProfileRbd, Pool: pool, ReadOnly: c.flagReadOnly})
+	}
+	for _, spec := range c.flagCephFS {
+		fsName, fsPath, _ := strings.Cut(spec, ":")
+		req.Profiles = append(req.Profiles, types.AuthClientProfile{Type: types.AuthProfileCephFS, FsName: fsName, Path: fsPath, ReadOnly: c.flagReadOnly})
+	}
+	if c.flagRgw {
+		req.Profiles = append(req.Profiles, types.AuthClientProfile{Type: types.AuthProfileRgw})
+	}
+
+	if len(req.Profiles) == 0 {
+		return fmt.Errorf("at least one of --rbd, --cephfs or --rgw is required")
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	authClient, err := client.CreateAuthClient(context.Background(), cli, req)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(authClient)
+	}
+
+	return writeAuthKeyring(authClient, c.flagOutput)
+}
+
+type cmdAuthList struct {
+	common *CmdControl
+	json   bool
+}
+
+func (c *cmdAuthList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the cephx clients and their capabilities",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdAuthList) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	clients, err := client.ListAuthClients(context.Background(), cli)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(clients)
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Name", "Managed", "Caps"})
+	for _, authClient := range clients {
+		t.AppendRow(table.Row{authClient.Name, authClient.Managed, formatAuthCaps(authClient.Caps)})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+
+	return nil
+}
+
+type cmdAuthExport struct {
+	common     *CmdControl
+	flagOutput string
+}
+
+func (c *cmdAuthExport) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export <NAME>",
+		Short: "Print the keyring of a cephx client",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVarP(&c.flagOutput, "output", "o", "", "Write the keyring to a file")
+
+	return cmd
+}
+
+func (c *cmdAuthExport) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	authClient, err := client.GetAuthClient(context.Background(), cli, args[0])
+	if err != nil {
+		return err
+	}
+
+	return writeAuthKeyring(authClient, c.flagOutput)
+}
+
+type cmdAuthRotate struct {
+	common     *CmdControl
+	flagOutput string
+}
+
+func (c *cmdAuthRotate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate <NAME>",
+		Short: "Replace the key of a cephx client and print its new keyring",
+		Long: `Replace the key of a cephx client and print its new keyring.
+The previous key stops working immediately, consumers must be given the new keyring.`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringVarP(&c.flagOutput, "output", "o", "", "Write the keyring to a file")
+
+	return cmd
+}
+
+func (c *cmdAuthRotate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	authClient, err := client.RotateAuthClientKey(context.Background(), cli, args[0])
+	if err != nil {
+		return err
+	}
+
+	return writeAuthKeyring(authClient, c.flagOutput)
+}
+
+type cmdAuthRemove struct {
+	common *CmdControl
+}
+
+func (c *cmdAuthRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm <NAME>",
+		Aliases: []string{"remove"},
+		Short:   "Remove a cephx client",
+		RunE:    c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdAuthRemove) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	return client.DeleteAuthClient(context.Background(), cli, args[0])
+}
+
+// writeAuthKeyring prints the keyring of a client, or writes it to a file readable by the owner only.
+func writeAuthKeyring(authClient types.AuthClient, output string) error {
+	if len(output) == 0 {
+		fmt.Print(authClient.Keyring)
+		return nil
+	}
+
+	err := os.WriteFile(output, []byte(authClient.Keyring), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write keyring of client.%s: %w", authClient.Name, err)
+	}
+
+	return nil
+}
+
+// formatAuthCaps renders capabilities one daemon type per line.
+func formatAuthCaps(caps map[string]string) string {
+	daemons := []string{}
+	for daemon := range caps {
+		daemons = append(daemons, daemon)
+	}
+	sort.Strings(daemons)
+
+	lines := []string{}
+	for _, daemon := range daemons {
+		lines = append(lines, fmt.Sprintf("%s: %s", daemon, caps[daemon]))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -16,6 +16,10 @@ func (c *cmdCertificate) Command() *cobra.Command {
 	cmdSet := cmdCertificateSet{common: c.common}
 	cmd.AddCommand(cmdSet.Command())
 
+	// certificate list
+	cmdList := cmdCertificateList{common: c.common}
+	cmd.AddCommand(cmdList.Command())
+
 	// Workaround for subcommand usage errors.
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
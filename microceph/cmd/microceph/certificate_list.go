@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/clilogger"
+	"github.com/canonical/microceph/microceph/constants"
+)
+
+type cmdCertificateList struct {
+	common   *CmdControl
+	flagJson bool
+}
+
+func (c *cmdCertificateList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the SSL certificates installed on the cluster servers",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagJson, "json", false, "output as json string")
+	return cmd
+}
+
+// certificateListEntry is an installed certificate along with the server it is on.
+type certificateListEntry struct {
+	Location string `json:"location"`
+	types.CertificateInfo
+}
+
+func (c *cmdCertificateList) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	clusterMembers, err := m.GetClusterMembers(context.Background())
+	if err != nil {
+		return err
+	}
+
+	entries := []certificateListEntry{}
+	for _, member := range clusterMembers {
+		certs, err := client.GetRGWCertificates(context.Background(), cli, member.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to fetch certificates of %s: %v\n", member.Name, err)
+			continue
+		}
+
+		for _, cert := range certs {
+			entries = append(entries, certificateListEntry{Location: member.Name, CertificateInfo: cert})
+		}
+	}
+
+	if c.flagJson {
+		opStr, err := json.Marshal(entries)
+		if err != nil {
+			return fmt.Errorf("internal error: unable to encode json output: %w", err)
+		}
+
+		fmt.Printf("%s\n", opStr)
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Location", "Service", "Group", "Subject", "Issuer", "Expires", "Self-Managed"})
+	for _, entry := range entries {
+		t.AppendRow(table.Row{entry.Location, entry.Service, entry.GroupID, entry.Subject, entry.Issuer, formatCertificateExpiry(entry.NotAfter), entry.SelfManaged})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+
+	return nil
+}
+
+// formatCertificateExpiry renders an expiry date, flagging those within the renewal window.
+func formatCertificateExpiry(notAfter time.Time) string {
+	expiry := notAfter.Format(time.DateOnly)
+	remaining := time.Until(notAfter)
+	if remaining <= 0 {
+		return fmt.Sprintf("%s (expired)", expiry)
+	}
+
+	if remaining < constants.RgwCertRenewalWindow {
+		return fmt.Sprintf("%s (in %d days)", expiry, int(remaining.Hours()/24))
+	}
+
+	return expiry
+}
+
+// getExpiringCertificateWarnings fetches the RGW certificates of a server and
+// describes those expired or within the renewal window.
+func getExpiringCertificateWarnings(ctx context.Context, cli mcTypes.Client, server string) []string {
+	certs, err := client.GetRGWCertificates(ctx, cli, server)
+	if err != nil {
+		clilogger.Debugf("Unable to fetch certificates of %s: %v", server, err)
+		return nil
+	}
+
+	warnings := []string{}
+	for _, cert := range certs {
+		if time.Until(cert.NotAfter) >= constants.RgwCertRenewalWindow {
+			continue
+		}
+
+		name := cert.Service
+		if len(cert.GroupID) != 0 {
+			name = fmt.Sprintf("%s.%s", cert.Service, cert.GroupID)
+		}
+
+		warnings = append(warnings, fmt.Sprintf("%s certificate expires %s", name, formatCertificateExpiry(cert.NotAfter)))
+	}
+
+	return warnings
+}
@@ -18,18 +18,25 @@ type cmdCertificateSetRGW struct {
 	flagSSLPrivateKey  string
 	flagTarget         string
 	flagRestart        bool
+	flagGroupID        string
+	flagSelfManaged    bool
 }
 
 func (c *cmdCertificateSetRGW) Command() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "rgw --ssl-certificate <base64> --ssl-private-key <base64> [--target <server>] [--restart]",
+		Use:   "rgw (--ssl-certificate <base64> --ssl-private-key <base64> | --self-managed) [--group-id <group-id>] [--target <server>] [--restart]",
 		Short: "Set the SSL certificate for the RGW service",
 		Long: `Set or rotate SSL certificates for the RGW service.
 
 The new certificate and key are written to disk. Use --restart to restart
 the RGW service and pick up the new certificate immediately. Without
 --restart, the certificate is stored but the service must be restarted
-manually for the change to take effect.`,
+manually for the change to take effect.
+
+With --self-managed the certificate is issued by the cluster-internal CA for
+the server's addresses instead, and renewed ahead of its expiry. The CA private
+key is stored in plaintext in the cluster database, which is replicated to every
+member.`,
 		RunE: c.Run,
 	}
 
@@ -37,9 +44,11 @@ manually for the change to take effect.`,
 	cmd.Flags().StringVar(&c.flagSSLPrivateKey, "ssl-private-key", "", "base64 encoded SSL private key")
 	cmd.Flags().StringVar(&c.flagTarget, "target", "", "Server hostname (default: this server)")
 	cmd.Flags().BoolVar(&c.flagRestart, "restart", false, "Restart the RGW service for immediate certificate pickup")
+	cmd.Flags().StringVar(&c.flagGroupID, "group-id", "", "RGW Group ID (default: the non-grouped RGW service)")
+	cmd.Flags().BoolVar(&c.flagSelfManaged, "self-managed", false, "Issue the certificate from the cluster-internal CA")
 
-	_ = cmd.MarkFlagRequired("ssl-certificate")
-	_ = cmd.MarkFlagRequired("ssl-private-key")
+	cmd.MarkFlagsMutuallyExclusive("self-managed", "ssl-certificate")
+	cmd.MarkFlagsMutuallyExclusive("self-managed", "ssl-private-key")
 
 	return cmd
 }
@@ -62,8 +71,10 @@ func (c *cmdCertificateSetRGW) validateSSLInputs() error {
 }
 
 func (c *cmdCertificateSetRGW) Run(cmd *cobra.Command, args []string) error {
-	if err := c.validateSSLInputs(); err != nil {
-		return err
+	if !c.flagSelfManaged {
+		if err := c.validateSSLInputs(); err != nil {
+			return err
+		}
 	}
 
 	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
@@ -80,6 +91,8 @@ func (c *cmdCertificateSetRGW) Run(cmd *cobra.Command, args []string) error {
 		SSLCertificate: c.flagSSLCertificate,
 		SSLPrivateKey:  c.flagSSLPrivateKey,
 		Restart:        c.flagRestart,
+		GroupID:        c.flagGroupID,
+		SelfManaged:    c.flagSelfManaged,
 	}
 
 	err = client.SetRGWCertificate(context.Background(), cli, req, c.flagTarget)
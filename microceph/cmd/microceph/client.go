@@ -18,6 +18,10 @@ func (c *cmdClient) Command() *cobra.Command {
 	clientConfigCmd := cmdClientConfig{common: c.common, client: c}
 	cmd.AddCommand(clientConfigCmd.Command())
 
+	// Bundle Subcommand
+	clientBundleCmd := cmdClientBundle{common: c.common}
+	cmd.AddCommand(clientBundleCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClientBundle struct {
+	common     *CmdControl
+	flagOutput string
+}
+
+func (c *cmdClientBundle) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle <CEPHX_USER>",
+		Short: "Export the ceph.conf and keyring an external client needs",
+		Long: `Export the ceph.conf and keyring an external client needs as a tar archive.
+The archive holds ceph.conf and ceph.client.<CEPHX_USER>.keyring, extract it
+into /etc/ceph on the consumer. The cephx user is created with 'microceph auth create'.`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringVarP(&c.flagOutput, "output", "o", "", "Archive to write, - for stdout (default: <CEPHX_USER>-bundle.tar)")
+
+	return cmd
+}
+
+func (c *cmdClientBundle) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	bundle, err := client.GetClientBundle(context.Background(), cli, args[0])
+	if err != nil {
+		return err
+	}
+
+	output := c.flagOutput
+	if len(output) == 0 {
+		output = fmt.Sprintf("%s-bundle.tar", args[0])
+	}
+
+	if output == "-" {
+		return writeClientBundle(os.Stdout, bundle)
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	defer f.Close()
+
+	err = writeClientBundle(f, bundle)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote client bundle of client.%s to %s\n", bundle.User, output)
+	return nil
+}
+
+// writeClientBundle writes the ceph.conf and keyring of a bundle as a tar archive.
+func writeClientBundle(w io.Writer, bundle types.ClientBundle) error {
+	files := []struct {
+		name    string
+		mode    int64
+		content string
+	}{
+		{name: "ceph.conf", mode: 0644, content: bundle.CephConf},
+		{name: fmt.Sprintf("ceph.client.%s.keyring", bundle.User), mode: 0600, content: bundle.Keyring},
+	}
+
+	tw := tar.NewWriter(w)
+	for _, file := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    file.mode,
+			Size:    int64(len(file.content)),
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write client bundle: %w", err)
+		}
+
+		_, err = io.WriteString(tw, file.content)
+		if err != nil {
+			return fmt.Errorf("failed to write client bundle: %w", err)
+		}
+	}
+
+	return tw.Close()
+}
@@ -34,16 +34,14 @@ func (c *cmdClientConfigGet) Command() *cobra.Command {
 }
 
 func (c *cmdClientConfigGet) Run(cmd *cobra.Command, args []string) error {
-	allowList := ceph.GetClientConfigSet()
-
 	// Get can be called with a single key.
 	if len(args) != 1 {
 		return cmd.Help()
 	}
 
-	_, ok := allowList[args[0]]
-	if !ok {
-		return fmt.Errorf("key %s is invalid. \nSupported Keys: %v", args[0], allowList.Keys())
+	err := ceph.ValidateClientConfigKey(args[0])
+	if err != nil {
+		return fmt.Errorf("key %s is invalid: %w", args[0], err)
 	}
 
 	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
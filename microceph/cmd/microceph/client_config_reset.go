@@ -38,14 +38,13 @@ func (c *cmdClientConfigReset) Command() *cobra.Command {
 }
 
 func (c *cmdClientConfigReset) Run(cmd *cobra.Command, args []string) error {
-	allowList := ceph.GetClientConfigSet()
 	if len(args) != 1 {
 		return cmd.Help()
 	}
 
-	_, ok := allowList[args[0]]
-	if !ok {
-		return fmt.Errorf("resetting key %s is not supported.\nSupported Keys: %v", args[0], allowList.Keys())
+	err := ceph.ValidateClientConfigKey(args[0])
+	if err != nil {
+		return fmt.Errorf("resetting key %s is not supported: %w", args[0], err)
 	}
 
 	if !c.flagForce {
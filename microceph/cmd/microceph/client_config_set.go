@@ -8,7 +8,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/canonical/microceph/microceph/api/types"
-	"github.com/canonical/microceph/microceph/ceph"
 	"github.com/canonical/microceph/microceph/client"
 )
 
@@ -25,7 +24,14 @@ func (c *cmdClientConfigSet) Command() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "set <Key> <Value>",
 		Short: "Sets specified Ceph Client config",
-		RunE:  c.Run,
+		Long: `Sets specified Ceph Client config.
+The librbd (rbd_*) and CephFS client (client_*, fuse_*) options can be set, as well
+as the client admin socket, logging and RADOS timeouts. The value is checked
+against the option's type. Options are placed in the [client] section, prefix the key
+with a section name to place them in a named section instead.`,
+		Example: `  microceph client config set rbd_cache true
+  microceph client config set client.libvirt.admin_socket /var/run/ceph/libvirt.asok --target hv1`,
+		RunE: c.Run,
 	}
 
 	cmd.Flags().BoolVar(&c.flagWait, "wait", true, "Wait for configs to propagate across the cluster.")
@@ -35,16 +41,10 @@ func (c *cmdClientConfigSet) Command() *cobra.Command {
 }
 
 func (c *cmdClientConfigSet) Run(cmd *cobra.Command, args []string) error {
-	allowList := ceph.GetClientConfigSet()
 	if len(args) != 2 {
 		return cmd.Help()
 	}
 
-	_, ok := allowList[args[0]]
-	if !ok {
-		return fmt.Errorf("configuring key %s is not supported.\nSupported Keys: %v", args[0], allowList.Keys())
-	}
-
 	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
 	if err != nil {
 		return fmt.Errorf("unable to configure MicroCeph: %w", err)
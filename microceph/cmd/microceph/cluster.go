@@ -58,6 +58,34 @@ func (c *cmdCluster) Command() *cobra.Command {
 	clusterMigrateCmd := cmdClusterMigrate{common: c.common, cluster: c}
 	cmd.AddCommand(clusterMigrateCmd.Command())
 
+	// Restart Subcommand
+	clusterRestartCmd := cmdClusterRestart{common: c.common, cluster: c}
+	cmd.AddCommand(clusterRestartCmd.Command())
+
+	// Upgrade Subcommand
+	clusterUpgradeCmd := cmdClusterUpgrade{common: c.common, cluster: c}
+	cmd.AddCommand(clusterUpgradeCmd.Command())
+
+	// Refresh check Subcommand
+	clusterRefreshCheckCmd := cmdClusterRefreshCheck{common: c.common, cluster: c}
+	cmd.AddCommand(clusterRefreshCheckCmd.Command())
+
+	// Versions Subcommand
+	clusterVersionsCmd := cmdClusterVersions{common: c.common, cluster: c}
+	cmd.AddCommand(clusterVersionsCmd.Command())
+
+	// Backup Subcommand
+	clusterBackupCmd := cmdClusterBackup{common: c.common, cluster: c}
+	cmd.AddCommand(clusterBackupCmd.Command())
+
+	// Restore Subcommand
+	clusterRestoreCmd := cmdClusterRestore{common: c.common, cluster: c}
+	cmd.AddCommand(clusterRestoreCmd.Command())
+
+	// Recover quorum Subcommand
+	clusterRecoverQuorumCmd := cmdClusterRecoverQuorum{common: c.common, cluster: c}
+	cmd.AddCommand(clusterRecoverQuorumCmd.Command())
+
 	// Maintenance Subcommand
 	clusterMaintenance := cmdClusterMaintenance{common: c.common}
 	cmd.AddCommand(clusterMaintenance.Command())
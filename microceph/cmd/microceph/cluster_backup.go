@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClusterBackup struct {
+	common  *CmdControl
+	cluster *cmdCluster
+
+	flagForce bool
+}
+
+func (c *cmdClusterBackup) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup <FILE>",
+		Short: "Back up the cluster control plane to an archive",
+		Long: `Back up the cluster control plane to an archive.
+The archive holds the MicroCeph database (configs, services, disks, remotes,
+client configs, placement policy, config history and refresh decisions), the
+Ceph auth database, the keyrings of this member and the store of its monitor.
+The monitor of this member is always stopped while its store is archived. The
+backup is refused unless the other monitors keep the quorum meanwhile, use
+--force to accept the outage of a cluster with a single monitor. Run it on a
+monitor host, the archive is restored with 'microceph cluster restore'.`,
+		Example: `  microceph cluster backup /root/microceph-backup.tar.gz`,
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagForce, "force", false, "Back up even when stopping the monitor breaks the quorum, the cluster is unavailable meanwhile.")
+	return cmd
+}
+
+func (c *cmdClusterBackup) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.BackupCluster(context.Background(), cli, &types.ClusterBackup{Force: c.flagForce})
+	if err != nil {
+		return err
+	}
+
+	err = copyArchive(result.Path, args[0])
+	if err != nil {
+		return fmt.Errorf("backup is left in %s: %w", result.Path, err)
+	}
+
+	err = os.Remove(result.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove %s: %v\n", result.Path, err)
+	}
+
+	fmt.Printf("Backed up cluster %s from %s to %s\n", result.FsID, result.Member, args[0])
+	fmt.Printf("Monitors: %s\n", strings.Join(result.Monitors, ", "))
+	return nil
+}
+
+// copyArchive copies a backup archive, readable by root only.
+func copyArchive(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	return errors.Join(err, out.Close())
+}
@@ -31,6 +31,22 @@ func (c *cmdClusterConfig) Command() *cobra.Command {
 	clusterConfigListCmd := cmdClusterConfigList{common: c.common, cluster: c.cluster, clusterConfig: c}
 	cmd.AddCommand(clusterConfigListCmd.Command())
 
+	// Apply
+	clusterConfigApplyCmd := cmdClusterConfigApply{common: c.common, cluster: c.cluster, clusterConfig: c}
+	cmd.AddCommand(clusterConfigApplyCmd.Command())
+
+	// Check
+	clusterConfigCheckCmd := cmdClusterConfigCheck{common: c.common, cluster: c.cluster, clusterConfig: c}
+	cmd.AddCommand(clusterConfigCheckCmd.Command())
+
+	// History
+	clusterConfigHistoryCmd := cmdClusterConfigHistory{common: c.common, cluster: c.cluster, clusterConfig: c}
+	cmd.AddCommand(clusterConfigHistoryCmd.Command())
+
+	// Rollback
+	clusterConfigRollbackCmd := cmdClusterConfigRollback{common: c.common, cluster: c.cluster, clusterConfig: c}
+	cmd.AddCommand(clusterConfigRollbackCmd.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
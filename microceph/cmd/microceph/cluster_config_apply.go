@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	lxdCmd "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClusterConfigApply struct {
+	common        *CmdControl
+	cluster       *cmdCluster
+	clusterConfig *cmdClusterConfig
+
+	flagFile        string
+	flagPrune       bool
+	flagDryRun      bool
+	flagWait        bool
+	flagSkipRestart bool
+}
+
+func (c *cmdClusterConfigApply) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply -f <FILE>",
+		Short: "Bring the Ceph Cluster config to a YAML document",
+		Long: `Bring the Ceph Cluster config to a YAML document.
+The document is diffed against the cluster, the resulting plan is shown and
+applied with a single restart of each affected daemon. Applying the same
+document again changes nothing. With --prune the keys set through MicroCeph
+that are not in the document are reset, keys set at bootstrap or with the ceph
+CLI are left alone. If a change fails, the ones applied before it are rolled
+back.
+
+The document holds MicroCeph supported keys under cluster, any ceph option by
+section under advanced, and cluster wide client configs under client:
+
+  cluster:
+    rgw_keystone_url: http://keystone:5000
+  advanced:
+    osd:
+      osd_max_backfills: 2
+  client:
+    rbd_cache: true`,
+		Example: `  microceph cluster config apply -f config.yaml --dry-run
+  microceph cluster config apply -f config.yaml --prune --wait`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringVarP(&c.flagFile, "file", "f", "", "Config document to apply, - for stdin.")
+	cmd.Flags().BoolVar(&c.flagPrune, "prune", false, "Reset the keys set through MicroCeph that are not in the document.")
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, "Only show the plan.")
+	cmd.Flags().BoolVar(&c.flagWait, "wait", false, "Wait for required ceph services to restart post config apply.")
+	cmd.Flags().BoolVar(&c.flagSkipRestart, "skip-restart", false, "Don't perform the daemon restarts for the applied config.")
+	return cmd
+}
+
+func (c *cmdClusterConfigApply) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 || len(c.flagFile) == 0 {
+		return cmd.Help()
+	}
+
+	document, err := readConfigDocument(c.flagFile)
+	if err != nil {
+		return err
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := &types.ConfigApply{
+		Document:    document,
+		Prune:       c.flagPrune,
+		DryRun:      c.flagDryRun,
+		Wait:        c.flagWait,
+		SkipRestart: c.flagSkipRestart,
+	}
+
+	plan, err := client.ApplyConfig(context.Background(), cli, req)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Println("Cluster config is up to date")
+		return nil
+	}
+
+	err = renderConfigPlan(plan)
+	if err != nil {
+		return err
+	}
+
+	restart := "none"
+	if len(plan.Services) > 0 && !c.flagSkipRestart {
+		restart = strings.Join(plan.Services, ", ")
+	}
+
+	if !plan.Applied {
+		fmt.Printf("Dry run, %d changes not applied (daemons to restart: %s)\n", len(plan.Changes), restart)
+		return nil
+	}
+
+	fmt.Printf("Applied %d changes (daemons restarted: %s)\n", len(plan.Changes), restart)
+	return nil
+}
+
+// readConfigDocument parses a config document, unknown fields are refused so
+// that typos are not silently ignored.
+func readConfigDocument(path string) (types.ConfigDocument, error) {
+	var document types.ConfigDocument
+	var content []byte
+	var err error
+
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return document, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&document)
+	if err != nil && err != io.EOF {
+		return document, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return document, nil
+}
+
+// renderConfigPlan prints the changes of a config apply plan.
+func renderConfigPlan(plan types.ConfigPlan) error {
+	data := make([][]string, len(plan.Changes))
+	for i, change := range plan.Changes {
+		action := "set"
+		if change.NewValue == nil {
+			action = "reset"
+		}
+
+		data[i] = []string{
+			action,
+			change.Scope,
+			change.Who,
+			change.Key,
+			formatConfigHistoryValue(change.Key, change.OldValue),
+			formatConfigHistoryValue(change.Key, change.NewValue),
+		}
+	}
+
+	header := []string{"Action", "Scope", "Who", "Key", "Current Value", "Desired Value"}
+	return lxdCmd.RenderTable(lxdCmd.TableFormatTable, header, data, plan.Changes)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+func TestReadConfigDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`cluster:
+  rgw_keystone_url: http://keystone:5000
+advanced:
+  osd:
+    osd_max_backfills: 2
+client:
+  rbd_cache: true
+`), 0644)
+	assert.NoError(t, err)
+
+	// Unquoted scalars are read as strings.
+	document, err := readConfigDocument(path)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ConfigDocument{
+		Cluster:  map[string]string{"rgw_keystone_url": "http://keystone:5000"},
+		Advanced: map[string]map[string]string{"osd": {"osd_max_backfills": "2"}},
+		Client:   map[string]string{"rbd_cache": "true"},
+	}, document)
+
+	// Unknown sections are refused rather than ignored.
+	err = os.WriteFile(path, []byte("clients:\n  rbd_cache: true\n"), 0644)
+	assert.NoError(t, err)
+	_, err = readConfigDocument(path)
+	assert.Error(t, err)
+
+	// An empty document is an empty desired config.
+	err = os.WriteFile(path, []byte(""), 0644)
+	assert.NoError(t, err)
+	document, err = readConfigDocument(path)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ConfigDocument{}, document)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	lxdCmd "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClusterConfigCheck struct {
+	common        *CmdControl
+	cluster       *cmdCluster
+	clusterConfig *cmdClusterConfig
+
+	flagReconcile string
+}
+
+func (c *cmdClusterConfigCheck) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Report drift between MicroCeph and the Ceph config database",
+		Long: `Report drift between the options MicroCeph tracks and the Ceph config database.
+Options changed or removed with the ceph CLI and options only set in Ceph are
+listed. The drift is reconciled towards Ceph, setting the tracked values
+again, or towards MicroCeph, tracking the values set in Ceph. Options only set
+in Ceph are never removed. The keys MicroCeph sets at bootstrap are tracked
+too, the recorded public network is never reconciled towards MicroCeph.
+Daemons are not restarted.`,
+		Example: `  microceph cluster config check
+  microceph cluster config check --reconcile microceph`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagReconcile, "reconcile", "", "Reconcile the drift, making ceph or microceph the one that changes.")
+	return cmd
+}
+
+func (c *cmdClusterConfigCheck) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	if len(c.flagReconcile) > 0 && c.flagReconcile != types.ConfigReconcileCeph && c.flagReconcile != types.ConfigReconcileMicroCeph {
+		return fmt.Errorf("invalid --reconcile %q, expected %s or %s", c.flagReconcile, types.ConfigReconcileCeph, types.ConfigReconcileMicroCeph)
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	drifts, err := client.CheckConfigDrift(context.Background(), cli)
+	if err != nil {
+		return err
+	}
+
+	if len(drifts) == 0 {
+		fmt.Println("No config drift")
+		return nil
+	}
+
+	data := make([][]string, len(drifts))
+	for i, drift := range drifts {
+		data[i] = []string{
+			drift.Kind,
+			drift.Who,
+			drift.Key,
+			formatConfigHistoryValue(drift.Key, drift.Expected),
+			formatConfigHistoryValue(drift.Key, drift.Actual),
+		}
+	}
+
+	header := []string{"Drift", "Who", "Key", "MicroCeph", "Ceph"}
+	err = lxdCmd.RenderTable(lxdCmd.TableFormatTable, header, data, drifts)
+	if err != nil {
+		return err
+	}
+
+	direction := c.flagReconcile
+	if len(direction) == 0 && term.IsTerminal(0) && term.IsTerminal(1) {
+		direction, err = c.common.Asker.AskChoice("Reconcile by changing ceph, microceph or neither? (ceph/microceph/no) [default=no]: ", []string{types.ConfigReconcileCeph, types.ConfigReconcileMicroCeph, "no"}, "no")
+		if err != nil {
+			return err
+		}
+	}
+
+	if direction != types.ConfigReconcileCeph && direction != types.ConfigReconcileMicroCeph {
+		return fmt.Errorf("found %d drifted options", len(drifts))
+	}
+
+	req := &types.ConfigReconcile{
+		Direction: direction,
+	}
+
+	drifts, err = client.ReconcileConfigDrift(context.Background(), cli, req)
+	if err != nil {
+		return err
+	}
+
+	// Options only set in Ceph are left alone when changing ceph.
+	reconciled := 0
+	for _, drift := range drifts {
+		if direction == types.ConfigReconcileMicroCeph || drift.Kind != types.ConfigDriftCephOnly {
+			reconciled++
+		}
+	}
+
+	fmt.Printf("Reconciled %d of %d drifted options by changing %s\n", reconciled, len(drifts), direction)
+	return nil
+}
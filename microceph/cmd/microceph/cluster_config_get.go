@@ -16,6 +16,9 @@ type cmdClusterConfigGet struct {
 	common        *CmdControl
 	cluster       *cmdCluster
 	clusterConfig *cmdClusterConfig
+
+	flagAdvanced bool
+	flagWho      string
 }
 
 func (c *cmdClusterConfigGet) Command() *cobra.Command {
@@ -25,6 +28,8 @@ func (c *cmdClusterConfigGet) Command() *cobra.Command {
 		RunE:  c.Run,
 	}
 
+	cmd.Flags().BoolVar(&c.flagAdvanced, "advanced", false, "Get any ceph option, not only the keys supported by MicroCeph.")
+	cmd.Flags().StringVar(&c.flagWho, "who", "", "Section of the advanced option (default: global).")
 	return cmd
 }
 
@@ -45,7 +50,9 @@ func (c *cmdClusterConfigGet) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	req := &types.Config{
-		Key: args[0],
+		Key:      args[0],
+		Advanced: c.flagAdvanced,
+		Who:      c.flagWho,
 	}
 
 	configs, err := client.GetConfig(context.Background(), cli, req)
@@ -55,10 +62,10 @@ func (c *cmdClusterConfigGet) Run(cmd *cobra.Command, args []string) error {
 
 	data := make([][]string, len(configs))
 	for i, config := range configs {
-		data[i] = []string{fmt.Sprintf("%d", i), config.Key, config.Value}
+		data[i] = []string{fmt.Sprintf("%d", i), config.Key, config.Value, config.Who}
 	}
 
-	header := []string{"#", "Key", "Value"}
+	header := []string{"#", "Key", "Value", "Who"}
 	err = lxdCmd.RenderTable(lxdCmd.TableFormatTable, header, data, configs)
 	if err != nil {
 		return err
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	lxdCmd "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClusterConfigHistory struct {
+	common        *CmdControl
+	cluster       *cmdCluster
+	clusterConfig *cmdClusterConfig
+}
+
+func (c *cmdClusterConfigHistory) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history [<Key>]",
+		Short: "List the recorded Ceph Cluster config changes",
+		Long: `List the recorded Ceph Cluster config changes, newest first.
+Every set and reset is recorded with the previous and new value, the member
+that applied it and who requested it. Changes MicroCeph makes on its own, at
+bootstrap or when the failure domain changes, are requested by "microceph".
+A change is undone with
+'microceph cluster config rollback <ID>'.`,
+		RunE: c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdClusterConfigHistory) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	key := ""
+	if len(args) == 1 {
+		key = args[0]
+	}
+
+	changes, err := client.GetConfigHistory(context.Background(), cli, key)
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, len(changes))
+	for i, change := range changes {
+		data[i] = []string{
+			fmt.Sprintf("%d", change.ID),
+			change.Timestamp.Local().Format(time.DateTime),
+			change.Key,
+			change.Who,
+			formatConfigHistoryValue(change.Key, change.OldValue),
+			formatConfigHistoryValue(change.Key, change.NewValue),
+			change.Member,
+			change.Requester,
+		}
+	}
+
+	header := []string{"ID", "Time", "Key", "Who", "Old Value", "New Value", "Member", "Requester"}
+	return lxdCmd.RenderTable(lxdCmd.TableFormatTable, header, data, changes)
+}
+
+// formatConfigHistoryValue renders a recorded value, hiding credentials.
+func formatConfigHistoryValue(key string, value *string) string {
+	if value == nil {
+		return "(unset)"
+	}
+
+	if strings.HasSuffix(key, "_password") || strings.HasSuffix(key, "_token") || strings.Contains(key, "secret") {
+		return "********"
+	}
+
+	return *value
+}
@@ -55,10 +55,10 @@ func (c *cmdClusterConfigList) Run(cmd *cobra.Command, args []string) error {
 
 	data := make([][]string, len(configs))
 	for i, config := range configs {
-		data[i] = []string{fmt.Sprintf("%d", i), config.Key, config.Value}
+		data[i] = []string{fmt.Sprintf("%d", i), config.Key, config.Value, config.Who}
 	}
 
-	header := []string{"#", "Key", "Value"}
+	header := []string{"#", "Key", "Value", "Who"}
 	err = lxdCmd.RenderTable(lxdCmd.TableFormatTable, header, data, configs)
 	if err != nil {
 		return err
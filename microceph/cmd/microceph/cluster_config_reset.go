@@ -18,6 +18,8 @@ type cmdClusterConfigReset struct {
 
 	flagWait        bool
 	flagSkipRestart bool
+	flagAdvanced    bool
+	flagWho         string
 }
 
 func (c *cmdClusterConfigReset) Command() *cobra.Command {
@@ -29,6 +31,8 @@ func (c *cmdClusterConfigReset) Command() *cobra.Command {
 
 	cmd.Flags().BoolVar(&c.flagWait, "wait", false, "Wait for required ceph services to restart post config reset.")
 	cmd.Flags().BoolVar(&c.flagSkipRestart, "skip-restart", false, "Don't perform the daemon restart for current config.")
+	cmd.Flags().BoolVar(&c.flagAdvanced, "advanced", false, "Clear an advanced ceph option set with --advanced.")
+	cmd.Flags().StringVar(&c.flagWho, "who", "", "Section of the advanced option (default: global).")
 	return cmd
 }
 
@@ -51,6 +55,8 @@ func (c *cmdClusterConfigReset) Run(cmd *cobra.Command, args []string) error {
 		Key:         args[0],
 		Wait:        c.flagWait,
 		SkipRestart: c.flagSkipRestart,
+		Advanced:    c.flagAdvanced,
+		Who:         c.flagWho,
 	}
 
 	err = client.ClearConfig(context.Background(), cli, req)
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClusterConfigRollback struct {
+	common        *CmdControl
+	cluster       *cmdCluster
+	clusterConfig *cmdClusterConfig
+
+	flagWait        bool
+	flagSkipRestart bool
+}
+
+func (c *cmdClusterConfigRollback) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback <ID>",
+		Short: "Restore the value a recorded Ceph Cluster config change replaced",
+		Long: `Restore the value a recorded Ceph Cluster config change replaced.
+If the key was unset before the change it is reset. The rollback is recorded
+as a change of its own, see 'microceph cluster config history'.`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagWait, "wait", false, "Wait for required ceph services to restart post config rollback.")
+	cmd.Flags().BoolVar(&c.flagSkipRestart, "skip-restart", false, "Don't perform the daemon restart for current config.")
+	return cmd
+}
+
+func (c *cmdClusterConfigRollback) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid change ID %q: %w", args[0], err)
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := &types.ConfigRollback{
+		Wait:        c.flagWait,
+		SkipRestart: c.flagSkipRestart,
+	}
+
+	return client.RollbackConfigChange(context.Background(), cli, id, req)
+}
@@ -18,17 +18,27 @@ type cmdClusterConfigSet struct {
 
 	flagWait        bool
 	flagSkipRestart bool
+	flagAdvanced    bool
+	flagWho         string
 }
 
 func (c *cmdClusterConfigSet) Command() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "set <Key> <Value>",
 		Short: "Set specified Ceph Cluster config",
-		RunE:  c.Run,
+		Long: `Set specified Ceph Cluster config.
+With --advanced any ceph option can be set, it is checked against the option's
+type and range and recorded by MicroCeph. Daemons are only restarted for
+options that cannot be changed at runtime.`,
+		Example: `  microceph cluster config set cluster_network 10.0.1.0/24
+  microceph cluster config set osd_max_backfills 2 --advanced --who osd`,
+		RunE: c.Run,
 	}
 
 	cmd.Flags().BoolVar(&c.flagWait, "wait", false, "Wait for required ceph services to restart post config set.")
 	cmd.Flags().BoolVar(&c.flagSkipRestart, "skip-restart", false, "Don't perform the daemon restart for current config.")
+	cmd.Flags().BoolVar(&c.flagAdvanced, "advanced", false, "Set any ceph option, not only the keys supported by MicroCeph.")
+	cmd.Flags().StringVar(&c.flagWho, "who", "", "Section of an advanced option, such as osd or osd.3 (default: global).")
 	return cmd
 }
 
@@ -52,6 +62,8 @@ func (c *cmdClusterConfigSet) Run(cmd *cobra.Command, args []string) error {
 		Value:       args[1],
 		Wait:        c.flagWait,
 		SkipRestart: c.flagSkipRestart,
+		Advanced:    c.flagAdvanced,
+		Who:         c.flagWho,
 	}
 
 	err = client.SetConfig(context.Background(), cli, req)
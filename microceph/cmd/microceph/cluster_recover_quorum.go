@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClusterRecoverQuorum struct {
+	common  *CmdControl
+	cluster *cmdCluster
+
+	flagRemove []string
+	flagYes    bool
+}
+
+func (c *cmdClusterRecoverQuorum) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recover-quorum",
+		Short: "Restore the monitor quorum after losing a majority of the monitors",
+		Long: `Restore the monitor quorum after losing a majority of the monitors.
+Run it on a member whose monitor survived. The monitors it cannot reach are
+removed from its monmap, the monitor is restarted with the reduced monmap and
+the removed monitors are dropped from the MicroCeph database and the config of
+every member is refreshed to render a mon_host without them. Only remove monitors which are lost
+for good, and recover on a single member: the surviving monitors left out
+have to be in contact with it.`,
+		Example: `  microceph cluster recover-quorum
+  microceph cluster recover-quorum --remove node2,node3 --yes`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringSliceVar(&c.flagRemove, "remove", nil, "Monitors to remove (default: every unreachable monitor).")
+	cmd.Flags().BoolVar(&c.flagYes, "yes", false, "Do not ask for confirmation.")
+	return cmd
+}
+
+func (c *cmdClusterRecoverQuorum) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	status, err := client.GetQuorumStatus(ctx, cli)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("mon.%s is %s\n", status.Member, status.State)
+	printQuorumTable(status)
+
+	remove := c.flagRemove
+	if len(remove) == 0 {
+		for _, mon := range status.Monitors {
+			if !mon.Reachable {
+				remove = append(remove, mon.Name)
+			}
+		}
+	}
+
+	if len(remove) == 0 {
+		fmt.Println("Every monitor is reachable, nothing to recover")
+		return nil
+	}
+
+	if !c.flagYes {
+		question := fmt.Sprintf("Remove %s from the monmap of mon.%s? (yes/no) [default=no]: ", strings.Join(remove, ", "), status.Member)
+		confirmed, err := c.common.Asker.AskBool(question, "no")
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			return nil
+		}
+	}
+
+	result, err := client.RecoverQuorum(ctx, cli, &types.QuorumRecovery{Remove: remove})
+	if err != nil {
+		return err
+	}
+
+	if len(result.Removed) != 0 {
+		fmt.Printf("Removed %s from the monmap, monitors left: %s\n", strings.Join(result.Removed, ", "), strings.Join(result.Monitors, ", "))
+	}
+
+	fmt.Println("Removed the monitors from the MicroCeph database")
+	if len(result.Unrefreshed) != 0 {
+		fmt.Printf("The config of %s was not refreshed, they render it once they reach the MicroCeph database\n", strings.Join(result.Unrefreshed, ", "))
+	}
+
+	return nil
+}
+
+func printQuorumTable(status types.QuorumStatus) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Monitor", "Address", "State"})
+	for _, mon := range status.Monitors {
+		state := "unreachable"
+		if mon.InQuorum {
+			state = "in quorum"
+		} else if mon.Reachable {
+			state = "reachable"
+		}
+
+		t.AppendRow(table.Row{mon.Name, mon.Address, state})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClusterRefreshCheck struct {
+	common  *CmdControl
+	cluster *cmdCluster
+
+	flagTolerate []string
+	flagDryRun   bool
+}
+
+func (c *cmdClusterRefreshCheck) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh-check",
+		Short: "Check this member can be refreshed without harming the cluster",
+		Long: `Check this member can be refreshed without harming the cluster.
+The refresh is refused while the cluster is not healthy, while another member
+is mid-refresh or when the monitor, manager or OSDs of this member are not ok
+to stop. A single monitor or manager does not hold up the refresh. The
+decision is recorded and shown by 'microceph status'.
+
+The snap pre-refresh hook runs this check, failed automatic refreshes are
+retried by snapd later on. The health warnings tolerated by the hook are set
+with 'snap set microceph refresh-gate-tolerate=<CODE>[,<CODE>]' and the hook
+is turned off with 'snap set microceph refresh-gate=false'.`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringSliceVar(&c.flagTolerate, "tolerate", nil, "Health warning codes which do not hold up the refresh.")
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, "Only report the decision, without recording it.")
+	return cmd
+}
+
+func (c *cmdClusterRefreshCheck) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	// Nothing to protect on a member which is not running or not part of a cluster.
+	status, err := m.Status(context.Background())
+	if err != nil || !status.Ready {
+		fmt.Println("MicroCeph is not running a cluster on this member, refresh allowed")
+		return nil
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := &types.RefreshCheck{
+		ToleratedWarnings: c.flagTolerate,
+		DryRun:            c.flagDryRun,
+	}
+
+	decision, err := client.CheckRefresh(context.Background(), cli, req)
+	if err != nil {
+		return err
+	}
+
+	if !decision.Allowed {
+		return fmt.Errorf("refresh of %s refused: %s", decision.Member, decision.Reason)
+	}
+
+	fmt.Printf("Refresh of %s allowed\n", decision.Member)
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClusterRestart struct {
+	common  *CmdControl
+	cluster *cmdCluster
+
+	flagMaxParallel int
+	flagTolerate    []string
+	flagTimeout     int64
+}
+
+func (c *cmdClusterRestart) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restart <SERVICE>...",
+		Short: "Restart Ceph services across the cluster one member at a time",
+		Long: `Restart Ceph services (mon, mgr, osd, mds, rgw) across the cluster one member at a time.
+Before every step the cluster must report HEALTH_OK, or only the tolerated
+warnings, with all PGs active+clean and the OSDs of the step ok-to-stop.
+Members whose hosts sit under the same bucket of the failure domain of the
+default CRUSH rule, such as a rack, form a failure domain. With a host or osd
+failure domain every member is a failure domain of its own.`,
+		Example: `  microceph cluster restart osd --max-parallel 2 --tolerate OSDMAP_FLAGS`,
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().IntVar(&c.flagMaxParallel, "max-parallel", 1, "Number of members of a failure domain restarted at once.")
+	cmd.Flags().StringSliceVar(&c.flagTolerate, "tolerate", nil, "Health warning codes which do not hold up the restart.")
+	cmd.Flags().Int64Var(&c.flagTimeout, "timeout", 600, "Seconds to wait for the cluster to become healthy before every step.")
+	return cmd
+}
+
+func (c *cmdClusterRestart) Run(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+
+	if c.flagMaxParallel < 1 {
+		return fmt.Errorf("--max-parallel must be at least 1")
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := &types.ServiceRestart{
+		Services:          args,
+		MaxParallel:       c.flagMaxParallel,
+		ToleratedWarnings: c.flagTolerate,
+		HealthTimeout:     c.flagTimeout,
+	}
+
+	return client.RollingRestartServices(context.Background(), cli, req)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/constants"
+)
+
+type cmdClusterRestore struct {
+	common  *CmdControl
+	cluster *cmdCluster
+}
+
+func (c *cmdClusterRestore) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <FILE>",
+		Short: "Rebuild a single-node control plane from a backup archive",
+		Long: `Rebuild a single-node control plane from an archive of 'microceph cluster backup'.
+Run it on a member bootstrapped with 'microceph cluster bootstrap --defer-ceph'
+and no other member. The monitor store is restored with this member as its only
+monitor, the MicroCeph database is restored and the monitor and manager are
+started. Records of other members and of the other daemons of this member
+are left out, add members back with 'microceph cluster add' and enable the
+other daemons again once the control plane is up. The monitor store of this
+member is put back if the restore fails before the database is restored.`,
+		Example: `  microceph cluster bootstrap --defer-ceph
+  microceph cluster restore /root/microceph-backup.tar.gz`,
+		RunE: c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdClusterRestore) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	// The daemon only restores archives from its backup directory.
+	backupPath := constants.GetPathConst().BackupPath
+	err = os.MkdirAll(backupPath, constants.PermissionOnlyUserAccess)
+	if err != nil {
+		return err
+	}
+
+	staged := filepath.Join(backupPath, fmt.Sprintf("restore-%d.tar.gz", time.Now().Unix()))
+	err = copyArchive(args[0], staged)
+	if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", args[0], err)
+	}
+	defer os.Remove(staged)
+
+	result, err := client.RestoreCluster(context.Background(), cli, &types.ClusterRestore{Path: staged})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored cluster %s on %s\n", result.FsID, result.Member)
+	if len(result.RemovedMonitors) != 0 {
+		fmt.Printf("Removed monitors: %s\n", strings.Join(result.RemovedMonitors, ", "))
+	}
+
+	tables := make([]string, 0, len(result.Skipped))
+	for table := range result.Skipped {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		fmt.Printf("Skipped %d %s records of other members\n", result.Skipped[table], table)
+	}
+
+	if len(result.NotStarted) != 0 {
+		fmt.Printf("Skipped the records of daemons not started by the restore: %s\n", strings.Join(result.NotStarted, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+// upgradePollInterval is the delay between two checks of a member while
+// waiting on it during an upgrade.
+const upgradePollInterval = 10 * time.Second
+
+type cmdClusterUpgrade struct {
+	common  *CmdControl
+	cluster *cmdCluster
+
+	flagChannel  string
+	flagTolerate []string
+	flagTimeout  int64
+	flagDryRun   bool
+}
+
+func (c *cmdClusterUpgrade) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade --channel <CHANNEL>",
+		Short: "Refresh every cluster member to a snap channel one at a time",
+		Long: `Refresh every cluster member to a snap channel one at a time.
+Members are refreshed in the order Ceph recommends: monitor hosts, then
+manager hosts, then OSD hosts and finally the hosts of other services. Every
+member is put in maintenance mode while it is refreshed. The upgrade stops
+before a member if the cluster is not healthy, and after a member if its
+daemons do not report the Ceph release of the new revision. Once every member is refreshed
+the daemon versions are checked and require-osd-release is raised.
+The daemon of every member needs the snapd-control interface connected. It is
+not connected automatically unless the store grants it, connect it with
+'snap connect microceph:snapd-control' on every member otherwise.`,
+		Example: `  microceph cluster upgrade --channel squid/stable`,
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagChannel, "channel", "", "Snap channel to refresh to.")
+	cmd.Flags().StringSliceVar(&c.flagTolerate, "tolerate", nil, "Health warning codes which do not stop the upgrade.")
+	cmd.Flags().Int64Var(&c.flagTimeout, "timeout", 1800, "Seconds to wait on the cluster health or on a member refresh.")
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, "Only print the order members would be upgraded in.")
+	_ = cmd.MarkFlagRequired("channel")
+	return cmd
+}
+
+func (c *cmdClusterUpgrade) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return fmt.Errorf("unable to configure MicroCeph: %w", err)
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	plan, err := client.GetUpgradePlan(ctx, cli)
+	if err != nil {
+		return err
+	}
+
+	for i, step := range plan.Steps {
+		fmt.Printf("%d. %s (%s)\n", i+1, step.Member, strings.Join(step.Services, ", "))
+	}
+
+	if c.flagDryRun {
+		return nil
+	}
+
+	for i, step := range plan.Steps {
+		fmt.Printf("Upgrading %s (%d of %d)\n", step.Member, i+1, len(plan.Steps))
+		err = c.upgradeMember(ctx, cli, step.Member)
+		if err != nil {
+			return fmt.Errorf("stopped upgrade at %s: %w", step.Member, err)
+		}
+	}
+
+	_, err = c.waitHealthy(ctx, cli, "")
+	if err != nil {
+		return fmt.Errorf("stopped upgrade before finalizing: %w", err)
+	}
+
+	release, err := client.FinalizeUpgrade(ctx, cli)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Every member runs Ceph %s\n", release)
+	return nil
+}
+
+// upgradeMember refreshes a member to the channel within maintenance mode.
+func (c *cmdClusterUpgrade) upgradeMember(ctx context.Context, cli mcTypes.Client, member string) error {
+	before, err := c.waitHealthy(ctx, cli, member)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.EnterMaintenance(ctx, cli, member, false, false, true, false, false, false)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.RefreshMember(ctx, cli, member, c.flagChannel)
+	if err != nil {
+		_, exitErr := client.ExitMaintenance(ctx, cli, member, false, false, false)
+		if exitErr != nil {
+			fmt.Printf("%s is left in maintenance mode: %v\n", member, exitErr)
+		}
+
+		return err
+	}
+
+	after := before
+	if result.Refreshing {
+		after, err = c.waitRefreshed(ctx, cli, member, before.Revision)
+		if err != nil {
+			return fmt.Errorf("%w, %s is left in maintenance mode", err, member)
+		}
+	}
+
+	_, err = client.ExitMaintenance(ctx, cli, member, false, false, false)
+	if err != nil {
+		return err
+	}
+
+	after, err = c.waitReleased(ctx, cli, member)
+	if err != nil {
+		return err
+	}
+
+	if !result.Refreshing {
+		fmt.Printf("%s already runs the latest revision of %s\n", member, c.flagChannel)
+		return nil
+	}
+
+	fmt.Printf("%s refreshed from revision %s (%s) to %s (%s), %d Ceph versions running\n",
+		member, before.Revision, before.Release, after.Revision, after.Release, len(after.Versions))
+	return nil
+}
+
+// waitHealthy waits for the cluster to be healthy enough for an upgrade step.
+func (c *cmdClusterUpgrade) waitHealthy(ctx context.Context, cli mcTypes.Client, member string) (types.UpgradeStatus, error) {
+	deadline := time.Now().Add(time.Duration(c.flagTimeout) * time.Second)
+	for {
+		status, err := client.GetUpgradeStatus(ctx, cli, member, c.flagTolerate)
+		if err != nil {
+			return status, err
+		}
+
+		if len(status.Health) == 0 {
+			return status, nil
+		}
+
+		if time.Now().After(deadline) {
+			return status, fmt.Errorf("cluster not healthy: %s", status.Health)
+		}
+
+		fmt.Printf("Waiting on cluster health: %s\n", status.Health)
+		time.Sleep(upgradePollInterval)
+	}
+}
+
+// waitRefreshed waits for a member to come back on a new snap revision.
+func (c *cmdClusterUpgrade) waitRefreshed(ctx context.Context, cli mcTypes.Client, member string, revision string) (types.UpgradeStatus, error) {
+	deadline := time.Now().Add(time.Duration(c.flagTimeout) * time.Second)
+	for {
+		// The member is unreachable while its daemon restarts.
+		status, err := client.GetUpgradeStatus(ctx, cli, member, c.flagTolerate)
+		if err == nil && status.Revision != revision {
+			return status, nil
+		}
+
+		if time.Now().After(deadline) {
+			return status, fmt.Errorf("%s did not come back on a new revision", member)
+		}
+
+		time.Sleep(upgradePollInterval)
+	}
+}
+
+// waitReleased waits for the daemons of a member to report the Ceph release
+// its snap ships.
+func (c *cmdClusterUpgrade) waitReleased(ctx context.Context, cli mcTypes.Client, member string) (types.UpgradeStatus, error) {
+	deadline := time.Now().Add(time.Duration(c.flagTimeout) * time.Second)
+	for {
+		status, err := client.GetUpgradeStatus(ctx, cli, member, c.flagTolerate)
+		if err == nil && len(status.Outdated) == 0 {
+			return status, nil
+		}
+
+		if time.Now().After(deadline) {
+			if err != nil {
+				return status, err
+			}
+
+			return status, fmt.Errorf("daemons of %s do not run Ceph %s: %s", member, status.Release, strings.Join(status.Outdated, ", "))
+		}
+
+		time.Sleep(upgradePollInterval)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdClusterVersions struct {
+	common  *CmdControl
+	cluster *cmdCluster
+	json    bool
+}
+
+func (c *cmdClusterVersions) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "versions",
+		Short: "Show the snap, MicroCeph and Ceph versions running on every member",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+	return cmd
+}
+
+func (c *cmdClusterVersions) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	report, err := client.GetClusterVersions(context.Background(), cli)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		opStr, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("internal error: unable to encode json output: %w", err)
+		}
+
+		fmt.Printf("%s\n", opStr)
+		return nil
+	}
+
+	printVersionsTable(report)
+	printVersionSkew(os.Stdout, report)
+	return nil
+}
+
+func printVersionsTable(report types.ClusterVersions) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Member", "Revision", "MicroCeph", "Daemon", "Ceph Version"})
+	for _, member := range report.Members {
+		if len(member.Error) != 0 {
+			t.AppendRow(table.Row{member.Member, "-", "-", "-", member.Error})
+			continue
+		}
+
+		if len(member.Daemons) == 0 {
+			t.AppendRow(table.Row{member.Member, member.Revision, member.MicroCephVersion, "-", "-"})
+		}
+
+		for _, daemon := range member.Daemons {
+			version := fmt.Sprintf("%s (%s)", daemon.Version, daemon.Release)
+			if len(daemon.Error) != 0 {
+				version = daemon.Error
+			}
+
+			t.AppendRow(table.Row{member.Member, member.Revision, member.MicroCephVersion, daemon.Name, version})
+		}
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+}
+
+// printVersionSkew prints a warning for every version skew found in a report.
+func printVersionSkew(w io.Writer, report types.ClusterVersions) {
+	if report.MixedSnap {
+		fmt.Fprintln(w, "WARNING: members run different snap revisions")
+	}
+
+	if report.MixedCeph {
+		versions := make([]string, 0, len(report.Versions))
+		for version := range report.Versions {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+
+		fmt.Fprintln(w, "WARNING: daemons run mixed Ceph versions:")
+		for _, version := range versions {
+			fmt.Fprintf(w, "  %d x %s\n", report.Versions[version], version)
+		}
+	}
+
+	if report.OSDReleasePending {
+		fmt.Fprintf(w, "WARNING: every OSD runs %s but require-osd-release is %s\n", report.OSDRelease, report.RequireOSDRelease)
+	}
+}
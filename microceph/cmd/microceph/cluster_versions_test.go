@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+func TestPrintVersionSkew(t *testing.T) {
+	var out bytes.Buffer
+	printVersionSkew(&out, types.ClusterVersions{Versions: map[string]int32{"ceph version 19.2.0 squid": 3}})
+	assert.Empty(t, out.String())
+
+	printVersionSkew(&out, types.ClusterVersions{
+		Versions: map[string]int32{
+			"ceph version 19.2.0 squid": 3,
+			"ceph version 18.2.4 reef":  2,
+		},
+		MixedCeph:         true,
+		MixedSnap:         true,
+		RequireOSDRelease: "reef",
+		OSDRelease:        "squid",
+		OSDReleasePending: true,
+	})
+	assert.Equal(t, `WARNING: members run different snap revisions
+WARNING: daemons run mixed Ceph versions:
+  2 x ceph version 18.2.4 reef
+  3 x ceph version 19.2.0 squid
+WARNING: every OSD runs squid but require-osd-release is reef
+`, out.String())
+}
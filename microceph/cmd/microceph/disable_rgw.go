@@ -6,20 +6,23 @@ import (
 	"github.com/canonical/microcluster/v3/microcluster"
 	"github.com/spf13/cobra"
 
+	"github.com/canonical/microceph/microceph/api/types"
 	"github.com/canonical/microceph/microceph/client"
 )
 
 type cmdDisableRGW struct {
-	common     *CmdControl
-	flagTarget string
+	common      *CmdControl
+	flagGroupID string
+	flagTarget  string
 }
 
 func (c *cmdDisableRGW) Command() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "rgw",
+		Use:   "rgw [--group-id <group-id>] [--target <server>]",
 		Short: "Disable the RGW service on this node",
 		RunE:  c.Run,
 	}
+	cmd.PersistentFlags().StringVar(&c.flagGroupID, "group-id", "", "RGW Group ID (default: the non-grouped RGW service)")
 	cmd.PersistentFlags().StringVar(&c.flagTarget, "target", "", "Server hostname (default: this server)")
 	return cmd
 }
@@ -37,7 +40,12 @@ func (c *cmdDisableRGW) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	err = client.DeleteService(context.Background(), cli, c.flagTarget, "rgw")
+	if len(c.flagGroupID) != 0 {
+		svc := &types.RGWGroupService{GroupID: c.flagGroupID}
+		err = client.DeleteRGWService(context.Background(), cli, c.flagTarget, svc)
+	} else {
+		err = client.DeleteService(context.Background(), cli, c.flagTarget, "rgw")
+	}
 	if err != nil {
 		return err
 	}
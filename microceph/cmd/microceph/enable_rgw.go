@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/canonical/microcluster/v3/microcluster"
 	"github.com/spf13/cobra"
@@ -19,20 +20,40 @@ type cmdEnableRGW struct {
 	flagSSLPort        int
 	flagSSLCertificate string
 	flagSSLPrivateKey  string
+	flagSSLSelfManaged bool
 	flagTarget         string
+	flagGroupID        string
+	flagRealm          string
+	flagZoneGroup      string
+	flagZone           string
 }
 
 func (c *cmdEnableRGW) Command() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "rgw [--port <port>] [--ssl-port <port>] [--ssl-certificate <certificate material>] [--ssl-private-key <private key material>] [--target <server>] [--wait <bool>]",
+		Use:   "rgw [--port <port>] [--ssl-port <port>] [--ssl-certificate <certificate material>] [--ssl-private-key <private key material>] [--ssl-self-managed] [--group-id <group-id> [--realm <realm>] [--zonegroup <zonegroup>] [--zone <zone>]] [--target <server>] [--wait <bool>]",
 		Short: "Enable the RGW service on the --target server (default: this server)",
-		RunE:  c.Run,
+		Long: `Enable the RGW service on the --target server (default: this server).
+
+With --group-id an additional RGW daemon of that group is placed on the server,
+next to the default one and those of other groups. Each group has its own ports
+and certificate, and may serve its own --realm, --zonegroup and --zone.
+
+With --ssl-self-managed the SSL certificate is issued by the cluster-internal CA
+for the server's addresses and renewed ahead of its expiry. The CA private key
+is stored in plaintext in the cluster database, which is replicated to every
+member.`,
+		RunE: c.Run,
 	}
 	// The flagPort has a default value of 0 for the case where both the SSL certificate and private key are provided.
 	cmd.PersistentFlags().IntVar(&c.flagPort, "port", 0, "Service non-SSL port (default: 80 if no SSL certificate and/or private key are provided)")
 	cmd.PersistentFlags().IntVar(&c.flagSSLPort, "ssl-port", 443, "Service SSL port (default: 443)")
 	cmd.PersistentFlags().StringVar(&c.flagSSLCertificate, "ssl-certificate", "", "base64 encoded SSL certificate")
 	cmd.PersistentFlags().StringVar(&c.flagSSLPrivateKey, "ssl-private-key", "", "base64 encoded SSL private key")
+	cmd.PersistentFlags().BoolVar(&c.flagSSLSelfManaged, "ssl-self-managed", false, "Issue the SSL certificate from the cluster-internal CA")
+	cmd.PersistentFlags().StringVar(&c.flagGroupID, "group-id", "", fmt.Sprintf("RGW Group ID (must match regex: '%s')", types.RGWGroupIDRegex.String()))
+	cmd.PersistentFlags().StringVar(&c.flagRealm, "realm", "", "Multisite realm served by the grouped RGW service")
+	cmd.PersistentFlags().StringVar(&c.flagZoneGroup, "zonegroup", "", "Multisite zonegroup served by the grouped RGW service")
+	cmd.PersistentFlags().StringVar(&c.flagZone, "zone", "", "Multisite zone served by the grouped RGW service")
 	cmd.PersistentFlags().StringVar(&c.flagTarget, "target", "", "Server hostname (default: this server)")
 	cmd.Flags().BoolVar(&c.wait, "wait", true, "Wait for rgw service to be up.")
 	return cmd
@@ -40,6 +61,10 @@ func (c *cmdEnableRGW) Command() *cobra.Command {
 
 // Run handles the enable rgw command.
 func (c *cmdEnableRGW) Run(cmd *cobra.Command, args []string) error {
+	if len(c.flagGroupID) != 0 && !types.RGWGroupIDRegex.MatchString(c.flagGroupID) {
+		return fmt.Errorf("please provide a valid group ID using the `--group-id` flag (regex: '%s')", types.RGWGroupIDRegex.String())
+	}
+
 	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
 	if err != nil {
 		return err
@@ -50,7 +75,17 @@ func (c *cmdEnableRGW) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	jsp, err := json.Marshal(ceph.RgwServicePlacement{Port: c.flagPort, SSLPort: c.flagSSLPort, SSLCertificate: c.flagSSLCertificate, SSLPrivateKey: c.flagSSLPrivateKey})
+	jsp, err := json.Marshal(ceph.RgwServicePlacement{
+		Port:           c.flagPort,
+		SSLPort:        c.flagSSLPort,
+		SSLCertificate: c.flagSSLCertificate,
+		SSLPrivateKey:  c.flagSSLPrivateKey,
+		SSLSelfManaged: c.flagSSLSelfManaged,
+		GroupID:        c.flagGroupID,
+		Realm:          c.flagRealm,
+		ZoneGroup:      c.flagZoneGroup,
+		Zone:           c.flagZone,
+	})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type cmdFs struct {
+	common *CmdControl
+}
+
+func (c *cmdFs) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fs",
+		Short: "Manage CephFS file systems",
+	}
+
+	// volume
+	fsVolumeCmd := cmdFsVolume{common: c.common}
+	cmd.AddCommand(fsVolumeCmd.Command())
+
+	// subvolume
+	fsSubvolumeCmd := cmdFsSubvolume{common: c.common}
+	cmd.AddCommand(fsSubvolumeCmd.Command())
+
+	// subvolumegroup
+	fsSubvolumeGroupCmd := cmdFsSubvolumeGroup{common: c.common}
+	cmd.AddCommand(fsSubvolumeGroupCmd.Command())
+
+	// snap-schedule
+	fsSnapScheduleCmd := cmdFsSnapSchedule{common: c.common}
+	cmd.AddCommand(fsSnapScheduleCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
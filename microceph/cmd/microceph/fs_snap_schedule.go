@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdFsSnapSchedule struct {
+	common *CmdControl
+}
+
+func (c *cmdFsSnapSchedule) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snap-schedule",
+		Short: "Manage CephFS snapshot schedules and retention",
+	}
+
+	// add
+	addCmd := cmdFsSnapScheduleAdd{common: c.common}
+	cmd.AddCommand(addCmd.Command())
+
+	// rm
+	removeCmd := cmdFsSnapScheduleRemove{common: c.common}
+	cmd.AddCommand(removeCmd.Command())
+
+	// ls
+	listCmd := cmdFsSnapScheduleList{common: c.common}
+	cmd.AddCommand(listCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+// fsSnapScheduleFlags holds the flags shared by the snap-schedule subcommands.
+type fsSnapScheduleFlags struct {
+	subvolume string
+	group     string
+}
+
+func (f *fsSnapScheduleFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.subvolume, "subvolume", "", "Subvolume to schedule snapshots of, PATH is then relative to it")
+	cmd.Flags().StringVar(&f.group, "group", "", "Subvolume group (default: no group)")
+}
+
+// request builds a snap-schedule request from the <VOLUME> [PATH] arguments.
+func (f *fsSnapScheduleFlags) request(args []string) types.FsSnapScheduleRequest {
+	req := types.FsSnapScheduleRequest{
+		Path:      "/",
+		Subvolume: f.subvolume,
+		Group:     f.group,
+	}
+
+	if len(args) > 1 {
+		req.Path = args[1]
+	}
+
+	return req
+}
+
+type cmdFsSnapScheduleAdd struct {
+	common        *CmdControl
+	flags         fsSnapScheduleFlags
+	flagSchedule  string
+	flagStart     string
+	flagRetention string
+}
+
+func (c *cmdFsSnapScheduleAdd) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <VOLUME> [PATH]",
+		Short: "Add a snapshot schedule and/or retention policy to a CephFS path",
+		RunE:  c.Run,
+	}
+
+	c.flags.register(cmd)
+	cmd.Flags().StringVar(&c.flagSchedule, "schedule", "", "Snapshot interval, e.g. 1h, 1d or 1w")
+	cmd.Flags().StringVar(&c.flagStart, "start", "", "ISO 8601 time the schedule starts from, e.g. 2024-01-01T00:00:00")
+	cmd.Flags().StringVar(&c.flagRetention, "retention", "", "Snapshots to keep, e.g. 24h7d4w keeps 24 hourly, 7 daily and 4 weekly snapshots")
+
+	return cmd
+}
+
+func (c *cmdFsSnapScheduleAdd) Run(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := c.flags.request(args)
+	req.Schedule = c.flagSchedule
+	req.Start = c.flagStart
+	req.Retention = c.flagRetention
+
+	return client.AddFsSnapSchedule(context.Background(), cli, args[0], req)
+}
+
+type cmdFsSnapScheduleRemove struct {
+	common        *CmdControl
+	flags         fsSnapScheduleFlags
+	flagSchedule  string
+	flagStart     string
+	flagRetention string
+}
+
+func (c *cmdFsSnapScheduleRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm <VOLUME> [PATH]",
+		Aliases: []string{"remove"},
+		Short:   "Remove snapshot schedules and/or a retention policy from a CephFS path",
+		Long: "Remove snapshot schedules and/or a retention policy from a CephFS path.\n" +
+			"Without --schedule or --retention, all snapshot schedules on the path are removed.",
+		RunE: c.Run,
+	}
+
+	c.flags.register(cmd)
+	cmd.Flags().StringVar(&c.flagSchedule, "schedule", "", "Only remove the schedule with this interval")
+	cmd.Flags().StringVar(&c.flagStart, "start", "", "Only remove the schedule with this start time")
+	cmd.Flags().StringVar(&c.flagRetention, "retention", "", "Retention policy to remove, e.g. 7d")
+
+	return cmd
+}
+
+func (c *cmdFsSnapScheduleRemove) Run(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := c.flags.request(args)
+	req.Schedule = c.flagSchedule
+	req.Start = c.flagStart
+	req.Retention = c.flagRetention
+
+	return client.RemoveFsSnapSchedule(context.Background(), cli, args[0], req)
+}
+
+type cmdFsSnapScheduleList struct {
+	common *CmdControl
+	flags  fsSnapScheduleFlags
+	json   bool
+}
+
+func (c *cmdFsSnapScheduleList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls <VOLUME> [PATH]",
+		Aliases: []string{"list"},
+		Short:   "List the snapshot schedules of a CephFS path",
+		RunE:    c.Run,
+	}
+
+	c.flags.register(cmd)
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdFsSnapScheduleList) Run(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	schedules, err := client.ListFsSnapSchedules(context.Background(), cli, args[0], c.flags.request(args))
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		opStr, err := json.Marshal(schedules)
+		if err != nil {
+			return fmt.Errorf("internal error: unable to encode json output: %w", err)
+		}
+
+		fmt.Printf("%s\n", opStr)
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Path", "Schedule", "Retention", "Last Snapshot", "Active"})
+	for _, schedule := range schedules {
+		t.AppendRow(table.Row{schedule.Path, schedule.Schedule, formatFsSnapRetention(schedule.Retention), schedule.Last, schedule.Active})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+	return nil
+}
+
+// formatFsSnapRetention renders a retention map back into its compact form, e.g. 24h7d.
+func formatFsSnapRetention(retention map[string]int) string {
+	periods := make([]string, 0, len(retention))
+	for period, count := range retention {
+		periods = append(periods, fmt.Sprintf("%d%s", count, period))
+	}
+
+	sort.Strings(periods)
+	return strings.Join(periods, "")
+}
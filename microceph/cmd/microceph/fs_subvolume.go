@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdFsSubvolume struct {
+	common *CmdControl
+}
+
+func (c *cmdFsSubvolume) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "subvolume",
+		Short: "Manage CephFS subvolumes",
+	}
+
+	// create
+	createCmd := cmdFsSubvolumeCreate{common: c.common}
+	cmd.AddCommand(createCmd.Command())
+
+	// resize
+	resizeCmd := cmdFsSubvolumeResize{common: c.common}
+	cmd.AddCommand(resizeCmd.Command())
+
+	// rm
+	removeCmd := cmdFsSubvolumeRemove{common: c.common}
+	cmd.AddCommand(removeCmd.Command())
+
+	// ls
+	listCmd := cmdFsSubvolumeList{common: c.common}
+	cmd.AddCommand(listCmd.Command())
+
+	// getpath
+	getPathCmd := cmdFsSubvolumeGetPath{common: c.common}
+	cmd.AddCommand(getPathCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdFsSubvolumeCreate struct {
+	common         *CmdControl
+	flagGroup      string
+	flagSize       string
+	flagPoolLayout string
+	flagMode       string
+}
+
+func (c *cmdFsSubvolumeCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <VOLUME> <NAME>",
+		Short: "Create a CephFS subvolume",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagGroup, "group", "", "Subvolume group (default: no group)")
+	cmd.Flags().StringVar(&c.flagSize, "size", "", "Quota of the subvolume, e.g. 10GiB (default: unlimited)")
+	cmd.Flags().StringVar(&c.flagPoolLayout, "pool-layout", "", "Data pool to place the subvolume in")
+	cmd.Flags().StringVar(&c.flagMode, "mode", "", "Octal permissions of the subvolume, e.g. 755")
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeCreate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	size, err := parseFsQuota(c.flagSize)
+	if err != nil {
+		return err
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.FsSubvolumeRequest{
+		Name:       args[1],
+		Group:      c.flagGroup,
+		Size:       size,
+		PoolLayout: c.flagPoolLayout,
+		Mode:       c.flagMode,
+	}
+
+	return client.CreateFsSubvolume(context.Background(), cli, args[0], req)
+}
+
+type cmdFsSubvolumeResize struct {
+	common       *CmdControl
+	flagGroup    string
+	flagNoShrink bool
+}
+
+func (c *cmdFsSubvolumeResize) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resize <VOLUME> <NAME> <SIZE>",
+		Short: "Set the quota of a CephFS subvolume, use 'inf' to remove it",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagGroup, "group", "", "Subvolume group (default: no group)")
+	cmd.Flags().BoolVar(&c.flagNoShrink, "no-shrink", false, "Refuse to shrink the quota below the used size")
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeResize) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 3 {
+		return cmd.Help()
+	}
+
+	size, err := parseFsQuota(args[2])
+	if err != nil {
+		return err
+	}
+
+	if size == 0 && args[2] != "inf" {
+		return fmt.Errorf("invalid size %q, use 'inf' to remove the quota", args[2])
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.FsSubvolumeRequest{
+		Name:      args[1],
+		Group:     c.flagGroup,
+		Size:      size,
+		Unlimited: size == 0,
+		NoShrink:  c.flagNoShrink,
+	}
+
+	return client.ResizeFsSubvolume(context.Background(), cli, args[0], req)
+}
+
+type cmdFsSubvolumeRemove struct {
+	common    *CmdControl
+	flagGroup string
+	flagForce bool
+}
+
+func (c *cmdFsSubvolumeRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm <VOLUME> <NAME>",
+		Aliases: []string{"remove"},
+		Short:   "Remove a CephFS subvolume and its data",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagGroup, "group", "", "Subvolume group (default: no group)")
+	cmd.Flags().BoolVar(&c.flagForce, "force", false, "Remove the subvolume even if it is partially created")
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeRemove) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.FsSubvolumeRequest{
+		Name:      args[1],
+		Group:     c.flagGroup,
+		IsForceOp: c.flagForce,
+	}
+
+	return client.DeleteFsSubvolume(context.Background(), cli, args[0], req)
+}
+
+type cmdFsSubvolumeList struct {
+	common    *CmdControl
+	flagGroup string
+	json      bool
+}
+
+func (c *cmdFsSubvolumeList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls <VOLUME>",
+		Aliases: []string{"list"},
+		Short:   "List the subvolumes of a CephFS volume",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagGroup, "group", "", "Subvolume group (default: no group)")
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeList) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	subvolumes, err := client.ListFsSubvolumes(context.Background(), cli, args[0], c.flagGroup)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		opStr, err := json.Marshal(subvolumes)
+		if err != nil {
+			return fmt.Errorf("internal error: unable to encode json output: %w", err)
+		}
+
+		fmt.Printf("%s\n", opStr)
+		return nil
+	}
+
+	return printFsSubvolumeTable(subvolumes)
+}
+
+type cmdFsSubvolumeGetPath struct {
+	common    *CmdControl
+	flagGroup string
+}
+
+func (c *cmdFsSubvolumeGetPath) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "getpath <VOLUME> <NAME>",
+		Short: "Print the path of a CephFS subvolume, as used for mounts",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagGroup, "group", "", "Subvolume group (default: no group)")
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeGetPath) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	subvolume, err := client.GetFsSubvolume(context.Background(), cli, args[0], args[1], c.flagGroup)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(subvolume.Path)
+	return nil
+}
+
+// parseFsQuota converts a human readable quota into bytes, empty or 'inf' meaning unlimited.
+func parseFsQuota(size string) (int64, error) {
+	if len(size) == 0 || size == "inf" {
+		return 0, nil
+	}
+
+	bytes, err := units.ParseByteSizeString(size)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", size, err)
+	}
+
+	return bytes, nil
+}
+
+func printFsSubvolumeTable(subvolumes types.FsSubvolumes) error {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Name", "Group"})
+	for _, subvolume := range subvolumes {
+		t.AppendRow(table.Row{subvolume.Name, subvolume.Group})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+	return nil
+}
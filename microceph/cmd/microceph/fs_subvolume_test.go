@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFsQuota(t *testing.T) {
+	size, err := parseFsQuota("")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+
+	size, err = parseFsQuota("inf")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+
+	size, err = parseFsQuota("10GiB")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10*1024*1024*1024), size)
+
+	_, err = parseFsQuota("ten gigs")
+	assert.Error(t, err)
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdFsSubvolumeGroup struct {
+	common *CmdControl
+}
+
+func (c *cmdFsSubvolumeGroup) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "subvolumegroup",
+		Short: "Manage CephFS subvolume groups",
+	}
+
+	// create
+	createCmd := cmdFsSubvolumeGroupCreate{common: c.common}
+	cmd.AddCommand(createCmd.Command())
+
+	// resize
+	resizeCmd := cmdFsSubvolumeGroupResize{common: c.common}
+	cmd.AddCommand(resizeCmd.Command())
+
+	// rm
+	removeCmd := cmdFsSubvolumeGroupRemove{common: c.common}
+	cmd.AddCommand(removeCmd.Command())
+
+	// ls
+	listCmd := cmdFsSubvolumeGroupList{common: c.common}
+	cmd.AddCommand(listCmd.Command())
+
+	// getpath
+	getPathCmd := cmdFsSubvolumeGroupGetPath{common: c.common}
+	cmd.AddCommand(getPathCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdFsSubvolumeGroupCreate struct {
+	common         *CmdControl
+	flagSize       string
+	flagPoolLayout string
+	flagMode       string
+}
+
+func (c *cmdFsSubvolumeGroupCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <VOLUME> <NAME>",
+		Short: "Create a CephFS subvolume group",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagSize, "size", "", "Quota of the group, e.g. 1TiB (default: unlimited)")
+	cmd.Flags().StringVar(&c.flagPoolLayout, "pool-layout", "", "Data pool to place the group in")
+	cmd.Flags().StringVar(&c.flagMode, "mode", "", "Octal permissions of the group, e.g. 755")
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeGroupCreate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	size, err := parseFsQuota(c.flagSize)
+	if err != nil {
+		return err
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.FsSubvolumeRequest{
+		Name:       args[1],
+		Size:       size,
+		PoolLayout: c.flagPoolLayout,
+		Mode:       c.flagMode,
+	}
+
+	return client.CreateFsSubvolumeGroup(context.Background(), cli, args[0], req)
+}
+
+type cmdFsSubvolumeGroupResize struct {
+	common       *CmdControl
+	flagNoShrink bool
+}
+
+func (c *cmdFsSubvolumeGroupResize) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resize <VOLUME> <NAME> <SIZE>",
+		Short: "Set the quota of a CephFS subvolume group, use 'inf' to remove it",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagNoShrink, "no-shrink", false, "Refuse to shrink the quota below the used size")
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeGroupResize) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 3 {
+		return cmd.Help()
+	}
+
+	size, err := parseFsQuota(args[2])
+	if err != nil {
+		return err
+	}
+
+	if size == 0 && args[2] != "inf" {
+		return fmt.Errorf("invalid size %q, use 'inf' to remove the quota", args[2])
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.FsSubvolumeRequest{
+		Name:      args[1],
+		Size:      size,
+		Unlimited: size == 0,
+		NoShrink:  c.flagNoShrink,
+	}
+
+	return client.ResizeFsSubvolumeGroup(context.Background(), cli, args[0], req)
+}
+
+type cmdFsSubvolumeGroupRemove struct {
+	common    *CmdControl
+	flagForce bool
+}
+
+func (c *cmdFsSubvolumeGroupRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm <VOLUME> <NAME>",
+		Aliases: []string{"remove"},
+		Short:   "Remove an empty CephFS subvolume group",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagForce, "force", false, "Do not fail if the group does not exist")
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeGroupRemove) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.FsSubvolumeRequest{
+		Name:      args[1],
+		IsForceOp: c.flagForce,
+	}
+
+	return client.DeleteFsSubvolumeGroup(context.Background(), cli, args[0], req)
+}
+
+type cmdFsSubvolumeGroupList struct {
+	common *CmdControl
+	json   bool
+}
+
+func (c *cmdFsSubvolumeGroupList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls <VOLUME>",
+		Aliases: []string{"list"},
+		Short:   "List the subvolume groups of a CephFS volume",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeGroupList) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	groups, err := client.ListFsSubvolumeGroups(context.Background(), cli, args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		opStr, err := json.Marshal(groups)
+		if err != nil {
+			return fmt.Errorf("internal error: unable to encode json output: %w", err)
+		}
+
+		fmt.Printf("%s\n", opStr)
+		return nil
+	}
+
+	for _, group := range groups {
+		fmt.Println(group.Name)
+	}
+
+	return nil
+}
+
+type cmdFsSubvolumeGroupGetPath struct {
+	common *CmdControl
+}
+
+func (c *cmdFsSubvolumeGroupGetPath) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "getpath <VOLUME> <NAME>",
+		Short: "Print the path of a CephFS subvolume group",
+		RunE:  c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdFsSubvolumeGroupGetPath) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	group, err := client.GetFsSubvolumeGroup(context.Background(), cli, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(group.Path)
+	return nil
+}
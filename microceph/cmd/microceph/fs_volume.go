@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type cmdFsVolume struct {
+	common *CmdControl
+}
+
+func (c *cmdFsVolume) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "volume",
+		Short: "Manage CephFS volumes",
+	}
+
+	// create
+	fsVolumeCreateCmd := cmdFsVolumeCreate{common: c.common}
+	cmd.AddCommand(fsVolumeCreateCmd.Command())
+
+	// rm
+	fsVolumeRemoveCmd := cmdFsVolumeRemove{common: c.common}
+	cmd.AddCommand(fsVolumeRemoveCmd.Command())
+
+	// set
+	fsVolumeSetCmd := cmdFsVolumeSet{common: c.common}
+	cmd.AddCommand(fsVolumeSetCmd.Command())
+
+	// list
+	fsVolumeListCmd := cmdFsVolumeList{common: c.common}
+	cmd.AddCommand(fsVolumeListCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
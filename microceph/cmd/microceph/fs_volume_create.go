@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdFsVolumeCreate struct {
+	common        *CmdControl
+	flagEnableMDS bool
+}
+
+func (c *cmdFsVolumeCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <NAME>",
+		Short: "Create a CephFS volume",
+		Long: `Create a CephFS volume along with its data and metadata pools.
+
+A volume needs at least one MDS service in the cluster. If none is placed,
+you are offered to enable one on this server, or pass --enable-mds to do so
+without prompting.`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagEnableMDS, "enable-mds", false, "Enable an MDS on this server if none is placed in the cluster")
+
+	return cmd
+}
+
+func (c *cmdFsVolumeCreate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.FsVolumeCreateRequest{
+		Name:      args[0],
+		EnableMDS: c.flagEnableMDS,
+	}
+
+	if !req.EnableMDS {
+		services, err := client.GetServices(context.Background(), cli)
+		if err != nil {
+			return err
+		}
+
+		if !hasService(services, "mds") {
+			req.EnableMDS, err = c.common.Asker.AskBool("No MDS service is placed in the cluster. Would you like to enable one on this server? (yes/no) [default=yes]: ", "yes")
+			if err != nil {
+				return err
+			}
+
+			if !req.EnableMDS {
+				return fmt.Errorf("volume %s needs an MDS service, enable one with 'microceph enable mds'", req.Name)
+			}
+		}
+	}
+
+	return client.CreateFsVolume(context.Background(), cli, req)
+}
+
+// hasService checks if the named service is placed on any cluster member.
+func hasService(services types.Services, name string) bool {
+	for _, service := range services {
+		if service.Service == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdFsVolumeList struct {
+	common *CmdControl
+	json   bool
+}
+
+func (c *cmdFsVolumeList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List CephFS volumes with their pools",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+	return cmd
+}
+
+func (c *cmdFsVolumeList) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	volumes, err := client.ListFsVolumes(context.Background(), cli)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		opStr, err := json.Marshal(volumes)
+		if err != nil {
+			return fmt.Errorf("internal error: unable to encode json output: %w", err)
+		}
+
+		fmt.Printf("%s\n", opStr)
+		return nil
+	}
+
+	return printFsVolumeTable(volumes)
+}
+
+func printFsVolumeTable(volumes types.FsVolumes) error {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Name", "Metadata Pool", "Data Pools", "Max MDS", "Standby Replay"})
+	for _, volume := range volumes {
+		t.AppendRow(table.Row{volume.Name, volume.MetadataPool, strings.Join(volume.DataPools, ","), volume.MaxMDS, volume.StandbyReplay})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/constants"
+)
+
+type cmdFsVolumeRemove struct {
+	common    *CmdControl
+	flagForce bool
+}
+
+func (c *cmdFsVolumeRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm <NAME>",
+		Aliases: []string{"remove"},
+		Short:   "Remove a CephFS volume and its pools",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagForce, "yes-i-really-mean-it", false, "Force removal of the volume and all its data.")
+
+	return cmd
+}
+
+func (c *cmdFsVolumeRemove) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	if !c.flagForce {
+		return fmt.Errorf("WARNING: this will *PERMANENTLY REMOVE* volume %s and all of its data. %s",
+			args[0], constants.CliForcePrompt)
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	return client.DeleteFsVolume(context.Background(), cli, args[0], types.FsVolumeDeleteRequest{IsForceOp: c.flagForce})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdFsVolumeSet struct {
+	common            *CmdControl
+	flagMaxMDS        int
+	flagStandbyReplay bool
+}
+
+func (c *cmdFsVolumeSet) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <NAME> [--max-mds <N>] [--standby-replay=<bool>]",
+		Short: "Set the MDS properties of a CephFS volume",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().IntVar(&c.flagMaxMDS, "max-mds", 1, "Number of active MDS daemons for the volume")
+	cmd.Flags().BoolVar(&c.flagStandbyReplay, "standby-replay", false, "Allow standby-replay MDS daemons for the volume")
+	cmd.MarkFlagsOneRequired("max-mds", "standby-replay")
+
+	return cmd
+}
+
+func (c *cmdFsVolumeSet) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	// Only send the properties the user asked to change.
+	req := types.FsVolumeSetRequest{}
+	if cmd.Flags().Changed("max-mds") {
+		req.MaxMDS = &c.flagMaxMDS
+	}
+	if cmd.Flags().Changed("standby-replay") {
+		req.StandbyReplay = &c.flagStandbyReplay
+	}
+
+	return client.SetFsVolume(context.Background(), cli, args[0], req)
+}
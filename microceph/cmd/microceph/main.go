@@ -86,6 +86,18 @@ func main() {
 	cmdPool := cmdPool{common: &commonCmd}
 	app.AddCommand(cmdPool.Command())
 
+	cmdFs := cmdFs{common: &commonCmd}
+	app.AddCommand(cmdFs.Command())
+
+	cmdRgw := cmdRgw{common: &commonCmd}
+	app.AddCommand(cmdRgw.Command())
+
+	cmdNfs := cmdNfs{common: &commonCmd}
+	app.AddCommand(cmdNfs.Command())
+
+	cmdAuth := cmdAuth{common: &commonCmd}
+	app.AddCommand(cmdAuth.Command())
+
 	cmdCert := cmdCertificate{common: &commonCmd}
 	app.AddCommand(cmdCert.Command())
 
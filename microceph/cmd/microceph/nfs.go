@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type cmdNfs struct {
+	common *CmdControl
+}
+
+func (c *cmdNfs) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nfs",
+		Short: "Manage NFS clusters",
+	}
+
+	// export
+	nfsExportCmd := cmdNfsExport{common: c.common}
+	cmd.AddCommand(nfsExportCmd.Command())
+
+	// status
+	nfsStatusCmd := cmdNfsStatus{common: c.common}
+	cmd.AddCommand(nfsStatusCmd.Command())
+
+	// repair
+	nfsRepairCmd := cmdNfsRepair{common: c.common}
+	cmd.AddCommand(nfsRepairCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
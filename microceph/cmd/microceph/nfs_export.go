@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+const nfsClientFlagUsage = "Client rule as ADDRESS[,ADDRESS...][=ACCESS[:SQUASH]], may be repeated (e.g. 10.0.0.0/24=RO:root)"
+
+type cmdNfsExport struct {
+	common *CmdControl
+}
+
+func (c *cmdNfsExport) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Manage the CephFS exports of an NFS cluster",
+		Long: `Manage the CephFS exports of an NFS cluster.
+
+Only CephFS volumes can be exported, RGW buckets cannot as the Ganesha RGW FSAL
+is not shipped with MicroCeph.`,
+	}
+
+	// create
+	createCmd := cmdNfsExportCreate{common: c.common}
+	cmd.AddCommand(createCmd.Command())
+
+	// ls
+	listCmd := cmdNfsExportList{common: c.common}
+	cmd.AddCommand(listCmd.Command())
+
+	// update
+	updateCmd := cmdNfsExportUpdate{common: c.common}
+	cmd.AddCommand(updateCmd.Command())
+
+	// rm
+	removeCmd := cmdNfsExportRemove{common: c.common}
+	cmd.AddCommand(removeCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdNfsExportCreate struct {
+	common         *CmdControl
+	flagPseudoPath string
+	flagFsName     string
+	flagPath       string
+	flagAccessType string
+	flagSquash     string
+	flagClients    []string
+	json           bool
+}
+
+func (c *cmdNfsExportCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <CLUSTER_ID>",
+		Short: "Export a CephFS volume directory through an NFS cluster",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagPseudoPath, "pseudo-path", "", "Path of the export in the NFSv4 pseudo filesystem")
+	cmd.Flags().StringVar(&c.flagFsName, "fs-name", "", "CephFS volume to export")
+	cmd.Flags().StringVar(&c.flagPath, "path", "/", "Directory of the CephFS volume to export")
+	cmd.Flags().StringVar(&c.flagAccessType, "access-type", "RW", "Default access type (RW, RO or NONE)")
+	cmd.Flags().StringVar(&c.flagSquash, "squash", "none", "Default squash mode (none, root, root_id or all)")
+	cmd.Flags().StringArrayVar(&c.flagClients, "client", nil, nfsClientFlagUsage)
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+	_ = cmd.MarkFlagRequired("pseudo-path")
+	_ = cmd.MarkFlagRequired("fs-name")
+
+	return cmd
+}
+
+func (c *cmdNfsExportCreate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	clients, err := parseNfsClientFlags(c.flagClients)
+	if err != nil {
+		return err
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	export := types.NFSExport{
+		PseudoPath: c.flagPseudoPath,
+		FsName:     c.flagFsName,
+		Path:       c.flagPath,
+		AccessType: c.flagAccessType,
+		Squash:     c.flagSquash,
+		Clients:    clients,
+	}
+
+	export, err = client.CreateNFSExport(context.Background(), cli, args[0], export)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(export)
+	}
+
+	fmt.Printf("Created export %d at %s\n", export.ExportID, export.PseudoPath)
+	return nil
+}
+
+type cmdNfsExportList struct {
+	common *CmdControl
+	json   bool
+}
+
+func (c *cmdNfsExportList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls <CLUSTER_ID>",
+		Aliases: []string{"list"},
+		Short:   "List the exports of an NFS cluster",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdNfsExportList) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	exports, err := client.ListNFSExports(context.Background(), cli, args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(exports)
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"ID", "Pseudo Path", "Volume", "Path", "Access", "Squash", "Clients"})
+	for _, export := range exports {
+		t.AppendRow(table.Row{export.ExportID, export.PseudoPath, export.FsName, export.Path, export.AccessType, export.Squash, formatNfsClients(export.Clients)})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+
+	return nil
+}
+
+type cmdNfsExportUpdate struct {
+	common         *CmdControl
+	flagPseudoPath string
+	flagPath       string
+	flagAccessType string
+	flagSquash     string
+	flagClients    []string
+}
+
+func (c *cmdNfsExportUpdate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update <CLUSTER_ID> <EXPORT_ID>",
+		Short: "Change the settings of an NFS export",
+		Long: `Change the settings of an NFS export.
+Only the given flags are changed. Passing --client replaces all client rules,
+pass --client "" to remove them.`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagPseudoPath, "pseudo-path", "", "Path of the export in the NFSv4 pseudo filesystem")
+	cmd.Flags().StringVar(&c.flagPath, "path", "", "Directory of the CephFS volume to export")
+	cmd.Flags().StringVar(&c.flagAccessType, "access-type", "", "Default access type (RW, RO or NONE)")
+	cmd.Flags().StringVar(&c.flagSquash, "squash", "", "Default squash mode (none, root, root_id or all)")
+	cmd.Flags().StringArrayVar(&c.flagClients, "client", nil, nfsClientFlagUsage)
+
+	return cmd
+}
+
+func (c *cmdNfsExportUpdate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	exportID, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid export ID %q", args[1])
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	exports, err := client.ListNFSExports(context.Background(), cli, args[0])
+	if err != nil {
+		return err
+	}
+
+	var export *types.NFSExport
+	for i := range exports {
+		if exports[i].ExportID == exportID {
+			export = &exports[i]
+			break
+		}
+	}
+	if export == nil {
+		return fmt.Errorf("export %d not found in NFS cluster %s", exportID, args[0])
+	}
+
+	if cmd.Flags().Changed("pseudo-path") {
+		export.PseudoPath = c.flagPseudoPath
+	}
+	if cmd.Flags().Changed("path") {
+		export.Path = c.flagPath
+	}
+	if cmd.Flags().Changed("access-type") {
+		export.AccessType = c.flagAccessType
+	}
+	if cmd.Flags().Changed("squash") {
+		export.Squash = c.flagSquash
+	}
+	if cmd.Flags().Changed("client") {
+		export.Clients, err = parseNfsClientFlags(c.flagClients)
+		if err != nil {
+			return err
+		}
+	}
+
+	return client.UpdateNFSExport(context.Background(), cli, args[0], *export)
+}
+
+type cmdNfsExportRemove struct {
+	common *CmdControl
+}
+
+func (c *cmdNfsExportRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm <CLUSTER_ID> <EXPORT_ID>",
+		Aliases: []string{"remove"},
+		Short:   "Remove an export from an NFS cluster, the exported data is kept",
+		RunE:    c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdNfsExportRemove) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	exportID, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid export ID %q", args[1])
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	return client.DeleteNFSExport(context.Background(), cli, args[0], exportID)
+}
+
+// parseNfsClientFlags parses --client rules of the form ADDRESS[,ADDRESS...][=ACCESS[:SQUASH]].
+// Empty rules are skipped so that a single empty flag clears the client rules.
+func parseNfsClientFlags(specs []string) ([]types.NFSExportClient, error) {
+	clients := []types.NFSExportClient{}
+	for _, spec := range specs {
+		if len(spec) == 0 {
+			continue
+		}
+
+		addresses, access, _ := strings.Cut(spec, "=")
+		if len(addresses) == 0 {
+			return nil, fmt.Errorf("client rule %q has no addresses", spec)
+		}
+
+		accessType, squash, _ := strings.Cut(access, ":")
+		clients = append(clients, types.NFSExportClient{
+			Addresses:  strings.Split(addresses, ","),
+			AccessType: strings.ToUpper(accessType),
+			Squash:     squash,
+		})
+	}
+
+	return clients, nil
+}
+
+// formatNfsClients renders client rules the way they are passed to --client.
+func formatNfsClients(clients []types.NFSExportClient) string {
+	rules := []string{}
+	for _, client := range clients {
+		rules = append(rules, fmt.Sprintf("%s=%s:%s", strings.Join(client.Addresses, ","), client.AccessType, client.Squash))
+	}
+
+	return strings.Join(rules, "\n")
+}
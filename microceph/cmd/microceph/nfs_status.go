@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdNfsStatus struct {
+	common *CmdControl
+	json   bool
+}
+
+func (c *cmdNfsStatus) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <CLUSTER_ID>",
+		Short: "Show the gateways and grace database of an NFS cluster",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdNfsStatus) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	status, err := client.GetNFSStatus(context.Background(), cli, "", args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(status)
+	}
+
+	interactive := term.IsTerminal(0) && term.IsTerminal(1)
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Member", "Hostname", "Bind Address", "Port", "State"})
+	for _, node := range status.Nodes {
+		t.AppendRow(table.Row{node.Member, node.Hostname, node.BindAddress, node.BindPort, node.State})
+	}
+	if interactive {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+
+	fmt.Printf("\nGrace database: current epoch %d, recovery epoch %d\n", status.Grace.CurrentEpoch, status.Grace.RecoveryEpoch)
+
+	stale := 0
+	t = table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Node", "Needs Grace", "Enforcing", "Stale"})
+	for _, entry := range status.Grace.Entries {
+		t.AppendRow(table.Row{entry.Node, entry.NeedsGrace, entry.Enforcing, entry.Stale})
+		if entry.Stale {
+			stale++
+		}
+	}
+	if interactive {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+
+	for _, node := range status.Nodes {
+		if len(node.Hostname) == 0 {
+			fmt.Printf("\nWarning: the hostname of the gateway on %s is unknown, stale nodes cannot be detected until it is reachable.\n", node.Member)
+			break
+		}
+	}
+
+	if stale != 0 {
+		fmt.Printf("\nWarning: %d stale node(s) may hold the cluster in grace, run 'microceph nfs repair %s' to remove them.\n", stale, args[0])
+	}
+
+	return nil
+}
+
+type cmdNfsRepair struct {
+	common *CmdControl
+}
+
+func (c *cmdNfsRepair) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repair <CLUSTER_ID>",
+		Short: "Remove stale nodes from the grace database of an NFS cluster",
+		Long: `Remove stale nodes from the grace database of an NFS cluster.
+A node is stale when no gateway of the cluster registers under its name, as
+left behind by a failed disable. Stale nodes keep clients waiting in grace.`,
+		RunE: c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdNfsRepair) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	removed, err := client.RepairNFSCluster(context.Background(), cli, args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(removed) == 0 {
+		fmt.Println("No stale nodes found")
+		return nil
+	}
+
+	for _, node := range removed {
+		fmt.Printf("Removed stale node %s\n", node)
+	}
+
+	return nil
+}
@@ -22,6 +22,9 @@ func (c *cmdReplicationConfigure) Command() *cobra.Command {
 	configureRbdCmd := cmdReplicationConfigureRbd{common: c.common}
 	cmd.AddCommand(configureRbdCmd.Command())
 
+	configureCephfsCmd := cmdReplicationConfigureCephfs{common: c.common}
+	cmd.AddCommand(configureCephfsCmd.Command())
+
 	return cmd
 }
 
@@ -85,3 +88,70 @@ func (c *cmdReplicationConfigureRbd) prepareRbdPayload(requestType types.Replica
 
 	return retReq, nil
 }
+
+type cmdReplicationConfigureCephfs struct {
+	common         *CmdControl
+	volume         string
+	dirpath        string
+	subvolume      string
+	subvolumegroup string
+	schedule       string
+	retention      string
+}
+
+func (c *cmdReplicationConfigureCephfs) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cephfs",
+		Short: "Configure snapshot schedule and retention for CephFS resource (Directory or Subvolume)",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.volume, "volume", "", "CephFS volume (aka file-system)")
+	cmd.Flags().StringVar(&c.subvolumegroup, "subvolumegroup", "", "CephFS Subvolume Group")
+	cmd.Flags().StringVar(&c.subvolume, "subvolume", "", "CephFS Subvolume")
+	cmd.Flags().StringVar(&c.dirpath, "dir-path", "", "Directory path relative to file system")
+	cmd.Flags().StringVar(&c.schedule, "schedule", "", "snapshot schedule using m, h, d, w, M or y suffix, e.g. 1h")
+	cmd.Flags().StringVar(&c.retention, "retention", "", "snapshots to keep, e.g. 24h7d keeps 24 hourly and 7 daily snapshots")
+
+	_ = cmd.MarkFlagRequired("volume")
+	cmd.MarkFlagsOneRequired("dir-path", "subvolume")
+	cmd.MarkFlagsOneRequired("schedule", "retention")
+
+	cmd.MarkFlagsMutuallyExclusive("dir-path", "subvolumegroup")
+	cmd.MarkFlagsMutuallyExclusive("dir-path", "subvolume")
+	return cmd
+}
+
+func (c *cmdReplicationConfigureCephfs) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	payload := types.CephfsReplicationRequest{
+		Volume:          c.volume,
+		Subvolume:       c.subvolume,
+		SubvolumeGroup:  c.subvolumegroup,
+		DirPath:         c.dirpath,
+		Schedule:        c.schedule,
+		RetentionPolicy: c.retention,
+		RequestType:     types.ConfigureReplicationRequest,
+		ResourceType:    getCephFSResourceType(c.subvolume, c.dirpath),
+	}
+
+	_, err = client.SendReplicationRequest(context.Background(), cli, payload)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -25,6 +25,12 @@ func (c *cmdReplicationDemote) Command() *cobra.Command {
 	cmd.Flags().StringVar(&c.remoteName, "remote", "", "remote MicroCeph cluster name")
 	cmd.Flags().BoolVar(&c.isForce, "yes-i-really-mean-it", false, "demote cluster irrespective of data loss")
 	_ = cmd.MarkFlagRequired("remote")
+
+	demoteCephfsCmd := cmdReplicationDemoteCephfs{common: c.common}
+	cmd.AddCommand(demoteCephfsCmd.Command())
+
+	demoteRgwCmd := cmdReplicationDemoteRgw{common: c.common}
+	cmd.AddCommand(demoteRgwCmd.Command())
 	return cmd
 }
 
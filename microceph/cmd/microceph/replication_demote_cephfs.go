@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+)
+
+type cmdReplicationDemoteCephfs struct {
+	common     *CmdControl
+	remoteName string
+	volume     string
+	isForce    bool
+}
+
+func (c *cmdReplicationDemoteCephfs) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cephfs",
+		Short: "Demote primary CephFS volumes to non-primary status",
+		Long: "Demote primary CephFS volumes to non-primary status.\n" +
+			"Mirroring to the remote stops, the volumes refuse new client sessions and\n" +
+			"existing clients are evicted, so that the volumes no longer take writes.\n" +
+			"Evicted clients are blocklisted and have to remount after a promotion.",
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.remoteName, "remote", "", "remote MicroCeph cluster name")
+	cmd.Flags().StringVar(&c.volume, "volume", "", "CephFS volume to demote (default: all mirrored volumes)")
+	cmd.Flags().BoolVar(&c.isForce, "yes-i-really-mean-it", false, "demote volumes irrespective of data loss")
+	_ = cmd.MarkFlagRequired("remote")
+	return cmd
+}
+
+func (c *cmdReplicationDemoteCephfs) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	payload := types.CephfsReplicationRequest{
+		Volume:       c.volume,
+		RemoteName:   c.remoteName,
+		RequestType:  types.DemoteReplicationRequest,
+		ResourceType: types.CephfsResourceVolume,
+		IsForceOp:    c.isForce,
+	}
+
+	_, err = client.SendReplicationRequest(context.Background(), cli, payload)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+)
+
+type cmdReplicationDemoteRgw struct {
+	common     *CmdControl
+	remoteName string
+	isForce    bool
+}
+
+func (c *cmdReplicationDemoteRgw) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rgw",
+		Short: "Demote the local RGW zone to read-only",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.remoteName, "remote", "", "remote MicroCeph cluster name")
+	cmd.Flags().BoolVar(&c.isForce, "yes-i-really-mean-it", false, "demote zone irrespective of data loss")
+	_ = cmd.MarkFlagRequired("remote")
+	return cmd
+}
+
+func (c *cmdReplicationDemoteRgw) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	payload := types.RgwReplicationRequest{
+		RemoteName:  c.remoteName,
+		RequestType: types.DemoteReplicationRequest,
+		IsForceOp:   c.isForce,
+	}
+
+	_, err = client.SendReplicationRequest(context.Background(), cli, payload)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -20,5 +20,8 @@ func (c *cmdReplicationDisable) Command() *cobra.Command {
 	disableCephFSCmd := cmdReplicationDisableCephFS{common: c.common}
 	cmd.AddCommand(disableCephFSCmd.Command())
 
+	disableRgwCmd := cmdReplicationDisableRgw{common: c.common}
+	cmd.AddCommand(disableRgwCmd.Command())
+
 	return cmd
 }
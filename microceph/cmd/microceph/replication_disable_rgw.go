@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+)
+
+type cmdReplicationDisableRgw struct {
+	common     *CmdControl
+	remoteName string
+	realm      string
+	isForce    bool
+}
+
+func (c *cmdReplicationDisableRgw) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rgw",
+		Short: "Remove the zone of a remote cluster from the local RGW zonegroup",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.remoteName, "remote", "", "remote MicroCeph cluster name")
+	cmd.Flags().StringVar(&c.realm, "realm", constants.RgwDefaultRealm, "RGW realm replicated within")
+	cmd.Flags().BoolVar(&c.isForce, "yes-i-really-mean-it", false, "stop syncing the remote zone")
+	_ = cmd.MarkFlagRequired("remote")
+	return cmd
+}
+
+func (c *cmdReplicationDisableRgw) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	payload := types.RgwReplicationRequest{
+		Realm:       c.realm,
+		RemoteName:  c.remoteName,
+		RequestType: types.DisableReplicationRequest,
+		IsForceOp:   c.isForce,
+	}
+
+	_, err = client.SendReplicationRequest(context.Background(), cli, payload)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
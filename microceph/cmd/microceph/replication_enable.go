@@ -19,5 +19,8 @@ func (c *cmdReplicationEnable) Command() *cobra.Command {
 
 	enableCephFSCmd := cmdReplicationEnableCephFS{common: c.common}
 	cmd.AddCommand(enableCephFSCmd.Command())
+
+	enableRgwCmd := cmdReplicationEnableRgw{common: c.common}
+	cmd.AddCommand(enableRgwCmd.Command())
 	return cmd
 }
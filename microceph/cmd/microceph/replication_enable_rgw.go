@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+)
+
+type cmdReplicationEnableRgw struct {
+	common          *CmdControl
+	remoteName      string
+	realm           string
+	zonegroup       string
+	endpoints       string
+	remoteEndpoints string
+}
+
+func (c *cmdReplicationEnableRgw) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rgw",
+		Short: "Enable RGW multisite replication with a remote cluster",
+		Long: "Enable RGW multisite replication with a remote cluster.\n" +
+			"The local zone becomes the master zone and the remote cluster joins as a secondary zone.\n" +
+			"The remote cluster records the new zone and restarts its RGW daemons to serve it within a minute.\n" +
+			"When the remote fails to join, running the command again resumes the setup.",
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.remoteName, "remote", "", "remote MicroCeph cluster name")
+	cmd.Flags().StringVar(&c.realm, "realm", constants.RgwDefaultRealm, "RGW realm to replicate within")
+	cmd.Flags().StringVar(&c.zonegroup, "zonegroup", constants.RgwDefaultZoneGroup, "RGW zonegroup to replicate within")
+	cmd.Flags().StringVar(&c.endpoints, "endpoints", "", "comma separated S3 endpoint URLs of the local zone")
+	cmd.Flags().StringVar(&c.remoteEndpoints, "remote-endpoints", "", "comma separated S3 endpoint URLs of the remote zone")
+
+	_ = cmd.MarkFlagRequired("remote")
+	_ = cmd.MarkFlagRequired("endpoints")
+	_ = cmd.MarkFlagRequired("remote-endpoints")
+	return cmd
+}
+
+func (c *cmdReplicationEnableRgw) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		fmt.Println("This command does not expect any positional arguments")
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	payload := types.RgwReplicationRequest{
+		Realm:           c.realm,
+		ZoneGroup:       c.zonegroup,
+		RemoteName:      c.remoteName,
+		Endpoints:       c.endpoints,
+		RemoteEndpoints: c.remoteEndpoints,
+		RequestType:     types.EnableReplicationRequest,
+	}
+
+	_, err = client.SendReplicationRequest(context.Background(), cli, payload)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
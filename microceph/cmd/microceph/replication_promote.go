@@ -25,6 +25,12 @@ func (c *cmdReplicationPromote) Command() *cobra.Command {
 	cmd.Flags().StringVar(&c.remoteName, "remote", "", "remote MicroCeph cluster name")
 	cmd.Flags().BoolVar(&c.isForce, "yes-i-really-mean-it", false, "forcefully promote site to primary")
 	_ = cmd.MarkFlagRequired("remote")
+
+	promoteCephfsCmd := cmdReplicationPromoteCephfs{common: c.common}
+	cmd.AddCommand(promoteCephfsCmd.Command())
+
+	promoteRgwCmd := cmdReplicationPromoteRgw{common: c.common}
+	cmd.AddCommand(promoteRgwCmd.Command())
 	return cmd
 }
 
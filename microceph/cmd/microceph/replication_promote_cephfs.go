@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+)
+
+type cmdReplicationPromoteCephfs struct {
+	common     *CmdControl
+	remoteName string
+	volume     string
+	reverse    bool
+	isForce    bool
+}
+
+func (c *cmdReplicationPromoteCephfs) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cephfs",
+		Short: "Promote non-primary CephFS volumes to primary status",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.remoteName, "remote", "", "remote MicroCeph cluster name")
+	cmd.Flags().StringVar(&c.volume, "volume", "", "CephFS volume to promote (default: all mirrored volumes)")
+	cmd.Flags().BoolVar(&c.reverse, "reverse", false, "mirror the promoted volumes back to the remote cluster")
+	cmd.Flags().BoolVar(&c.isForce, "yes-i-really-mean-it", false, "forcefully promote even if the remote is not demoted or unreachable")
+	_ = cmd.MarkFlagRequired("remote")
+	return cmd
+}
+
+func (c *cmdReplicationPromoteCephfs) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	payload := types.CephfsReplicationRequest{
+		Volume:           c.volume,
+		RemoteName:       c.remoteName,
+		RequestType:      types.PromoteReplicationRequest,
+		ResourceType:     types.CephfsResourceVolume,
+		IsForceOp:        c.isForce,
+		ReverseDirection: c.reverse,
+	}
+
+	_, err = client.SendReplicationRequest(context.Background(), cli, payload)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
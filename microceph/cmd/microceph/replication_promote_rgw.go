@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+)
+
+type cmdReplicationPromoteRgw struct {
+	common     *CmdControl
+	remoteName string
+	isForce    bool
+}
+
+func (c *cmdReplicationPromoteRgw) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rgw",
+		Short: "Promote the local RGW zone to master of the zonegroup",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.remoteName, "remote", "", "remote MicroCeph cluster name")
+	cmd.Flags().BoolVar(&c.isForce, "yes-i-really-mean-it", false, "forcefully promote even if the master zone is not demoted")
+	_ = cmd.MarkFlagRequired("remote")
+	return cmd
+}
+
+func (c *cmdReplicationPromoteRgw) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	payload := types.RgwReplicationRequest{
+		RemoteName:  c.remoteName,
+		RequestType: types.PromoteReplicationRequest,
+		IsForceOp:   c.isForce,
+	}
+
+	_, err = client.SendReplicationRequest(context.Background(), cli, payload)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
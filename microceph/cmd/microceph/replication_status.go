@@ -20,5 +20,8 @@ func (c *cmdReplicationStatus) Command() *cobra.Command {
 	statusCephfsCmd := cmdReplicationStatusCephfs{common: c.common}
 	cmd.AddCommand(statusCephfsCmd.Command())
 
+	statusRgwCmd := cmdReplicationStatusRgw{common: c.common}
+	cmd.AddCommand(statusRgwCmd.Command())
+
 	return cmd
 }
@@ -86,6 +86,9 @@ func (c *cmdReplicationStatusCephfs) printCephfsReplicationStatusTable(apiRespon
 	t_summary.SetOutputMirror(os.Stdout)
 	t_summary.AppendHeader(table.Row{"Summary", "Summary"}, rowConfig)
 	t_summary.AppendRow(table.Row{"Volume", resp.Volume}, rowConfig)
+	if len(resp.Role) != 0 {
+		t_summary.AppendRow(table.Row{"Role", resp.Role}, rowConfig)
+	}
 	t_summary.AppendRow(table.Row{"Resource Count", resp.MirrorResourceCount}, rowConfig)
 	t_summary.AppendRow(table.Row{"Peer Count", len(resp.Peers)}, rowConfig)
 	t_summary.Render()
@@ -96,7 +99,7 @@ func (c *cmdReplicationStatusCephfs) printCephfsReplicationStatusTable(apiRespon
 	rowConfig = table.RowConfig{AutoMergeAlign: text.AlignCenter}
 	t_remotes := table.NewWriter()
 	t_remotes.SetOutputMirror(os.Stdout)
-	t_remotes.AppendHeader(table.Row{"Remote Name", "Resource Path", "State", "Snaps Synced", "Snaps Deleted", "Snaps Renamed"}, rowConfig)
+	t_remotes.AppendHeader(table.Row{"Remote Name", "Resource Path", "State", "Snaps Synced", "Snaps Deleted", "Snaps Renamed", "Last Snapshot"}, rowConfig)
 	for _, peer := range resp.Peers {
 		for resourcePath, status := range peer.MirrorStatus {
 			t_remotes.AppendRow(table.Row{
@@ -106,6 +109,7 @@ func (c *cmdReplicationStatusCephfs) printCephfsReplicationStatusTable(apiRespon
 				status.Synced,
 				status.Deleted,
 				status.Renamed,
+				resp.LastSnapshot[resourcePath],
 			}, rowConfig)
 		}
 	}
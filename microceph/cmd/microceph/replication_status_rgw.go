@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/constants"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+type cmdReplicationStatusRgw struct {
+	common *CmdControl
+	json   bool
+}
+
+func (c *cmdReplicationStatusRgw) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rgw [<realm>]",
+		Short: "Show RGW multisite replication status",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+	return cmd
+}
+
+func (c *cmdReplicationStatusRgw) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	payload := types.RgwReplicationRequest{
+		Realm:       constants.RgwDefaultRealm,
+		RequestType: types.StatusReplicationRequest,
+	}
+	if len(args) == 1 {
+		payload.Realm = args[0]
+	}
+
+	resp, err := client.SendReplicationRequest(context.Background(), cli, payload)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		fmt.Println(resp)
+		return nil
+	}
+
+	var status types.RgwReplicationResponseStatus
+	err = json.Unmarshal([]byte(resp), &status)
+	if err != nil {
+		return err
+	}
+
+	return printRgwReplicationStatusTable(status)
+}
+
+func printRgwReplicationStatusTable(status types.RgwReplicationResponseStatus) error {
+	rowConfig := table.RowConfig{AutoMerge: true, AutoMergeAlign: text.AlignCenter}
+	isTerminal := term.IsTerminal(0) && term.IsTerminal(1)
+
+	// Summary Section.
+	t_summary := table.NewWriter()
+	t_summary.SetOutputMirror(os.Stdout)
+	t_summary.AppendHeader(table.Row{"Summary", "Summary"}, rowConfig)
+	t_summary.AppendRow(table.Row{"Realm", status.Realm}, rowConfig)
+	t_summary.AppendRow(table.Row{"Zonegroup", status.ZoneGroup}, rowConfig)
+	t_summary.AppendRow(table.Row{"Zone", status.Zone}, rowConfig)
+	t_summary.AppendRow(table.Row{"Is Master", status.IsMaster}, rowConfig)
+	t_summary.AppendRow(table.Row{"Caught Up", status.IsCaughtUp()}, rowConfig)
+	if isTerminal {
+		// Set style if interactive shell.
+		t_summary.SetStyle(table.StyleColoredBright)
+	}
+	t_summary.Render()
+	fmt.Println()
+
+	// Zones Section.
+	t_zones := table.NewWriter()
+	t_zones.SetOutputMirror(os.Stdout)
+	t_zones.AppendHeader(table.Row{"Zone", "Master", "Read Only", "Endpoints"})
+	for _, zone := range status.Zones {
+		t_zones.AppendRow(table.Row{zone.Name, zone.IsMaster, zone.ReadOnly, zone.Endpoints})
+	}
+	if isTerminal {
+		// Set style if interactive shell.
+		t_zones.SetStyle(table.StyleColoredBright)
+	}
+	t_zones.Render()
+	fmt.Println()
+
+	return printRgwSyncVerdictTable(status)
+}
+
+// printRgwSyncVerdictTable prints one row per sync relationship of the local zone.
+func printRgwSyncVerdictTable(status types.RgwReplicationResponseStatus) error {
+	t_sync := table.NewWriter()
+	t_sync.SetOutputMirror(os.Stdout)
+	t_sync.AppendHeader(table.Row{"Sync", "Source Zone", "Caught Up", "Behind Shards", "Full Sync Shards", "Note"})
+
+	if status.MetadataSync != nil {
+		master := ""
+		for _, zone := range status.Zones {
+			if zone.IsMaster {
+				master = zone.Name
+			}
+		}
+		t_sync.AppendRow(rgwSyncVerdictRow("metadata", master, *status.MetadataSync))
+	}
+
+	zones := make([]string, 0, len(status.DataSync))
+	for zone := range status.DataSync {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	for _, zone := range zones {
+		t_sync.AppendRow(rgwSyncVerdictRow("data", zone, status.DataSync[zone]))
+	}
+
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t_sync.SetStyle(table.StyleColoredBright)
+	}
+	t_sync.Render()
+	return nil
+}
+
+func rgwSyncVerdictRow(kind string, zone string, verdict types.RgwSyncVerdict) table.Row {
+	note := ""
+	if verdict.PeriodMismatch {
+		note = "period mismatch"
+	} else if verdict.PeerLogUnavailable {
+		note = "source log unavailable"
+	}
+
+	return table.Row{kind, zone, verdict.CaughtUp, verdict.BehindShards, verdict.FullSyncShards, note}
+}
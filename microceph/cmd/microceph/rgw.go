@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type cmdRgw struct {
+	common *CmdControl
+}
+
+func (c *cmdRgw) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rgw",
+		Short: "Manage the RADOS Gateway",
+	}
+
+	// realm
+	rgwRealmCmd := cmdRgwRealm{common: c.common}
+	cmd.AddCommand(rgwRealmCmd.Command())
+
+	// zonegroup
+	rgwZoneGroupCmd := cmdRgwZoneGroup{common: c.common}
+	cmd.AddCommand(rgwZoneGroupCmd.Command())
+
+	// zone
+	rgwZoneCmd := cmdRgwZone{common: c.common}
+	cmd.AddCommand(rgwZoneCmd.Command())
+
+	// sync
+	rgwSyncCmd := cmdRgwSync{common: c.common}
+	cmd.AddCommand(rgwSyncCmd.Command())
+
+	// user
+	rgwUserCmd := cmdRgwUser{common: c.common}
+	cmd.AddCommand(rgwUserCmd.Command())
+
+	// bucket
+	rgwBucketCmd := cmdRgwBucket{common: c.common}
+	cmd.AddCommand(rgwBucketCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
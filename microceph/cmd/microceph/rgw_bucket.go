@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdRgwBucket struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwBucket) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bucket",
+		Short: "Manage RGW buckets",
+	}
+
+	// ls
+	listCmd := cmdRgwBucketList{common: c.common}
+	cmd.AddCommand(listCmd.Command())
+
+	// chown
+	chownCmd := cmdRgwBucketChown{common: c.common}
+	cmd.AddCommand(chownCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdRgwBucketList struct {
+	common    *CmdControl
+	flagOwner string
+	json      bool
+}
+
+func (c *cmdRgwBucketList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List RGW buckets with their usage",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagOwner, "owner", "", "Only list the buckets of this user")
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdRgwBucketList) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	buckets, err := client.ListRgwBuckets(context.Background(), cli, c.flagOwner)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(buckets)
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Bucket", "Owner", "Objects", "Size"})
+	for _, bucket := range buckets {
+		t.AppendRow(table.Row{bucket.Name, bucket.Owner, bucket.NumObjects, units.GetByteSizeStringIEC(bucket.Size, 2)})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+	return nil
+}
+
+type cmdRgwBucketChown struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwBucketChown) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chown <BUCKET> <UID>",
+		Short: "Hand an RGW bucket and its objects over to another user",
+		RunE:  c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdRgwBucketChown) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	return client.SetRgwBucketOwner(context.Background(), cli, args[0], types.RgwBucketSetRequest{Owner: args[1]})
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdRgwRealm struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwRealm) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "realm",
+		Short: "Manage RGW multisite realms",
+	}
+
+	// create
+	createCmd := cmdRgwRealmCreate{common: c.common}
+	cmd.AddCommand(createCmd.Command())
+
+	// pull
+	pullCmd := cmdRgwRealmPull{common: c.common}
+	cmd.AddCommand(pullCmd.Command())
+
+	// commit
+	commitCmd := cmdRgwRealmCommit{common: c.common}
+	cmd.AddCommand(commitCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdRgwRealmCreate struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwRealmCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <REALM>",
+		Short: "Create an RGW realm on the master site",
+		RunE:  c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdRgwRealmCreate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	return client.CreateRgwRealm(context.Background(), cli, types.RgwRealmRequest{Name: args[0]})
+}
+
+type cmdRgwRealmPull struct {
+	common        *CmdControl
+	flagURL       string
+	flagAccessKey string
+	flagSecretKey string
+}
+
+func (c *cmdRgwRealmPull) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull <REALM>",
+		Short: "Pull an RGW realm from the master zone of another site",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagURL, "url", "", "Endpoint URL of the master zone")
+	cmd.Flags().StringVar(&c.flagAccessKey, "access-key", "", "Access key of the master site multisite system user")
+	cmd.Flags().StringVar(&c.flagSecretKey, "secret-key", "", "Secret key of the master site multisite system user")
+	_ = cmd.MarkFlagRequired("url")
+	_ = cmd.MarkFlagRequired("access-key")
+	_ = cmd.MarkFlagRequired("secret-key")
+
+	return cmd
+}
+
+func (c *cmdRgwRealmPull) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.RgwRealmRequest{
+		Name:      args[0],
+		URL:       c.flagURL,
+		AccessKey: c.flagAccessKey,
+		SecretKey: c.flagSecretKey,
+	}
+
+	return client.PullRgwRealm(context.Background(), cli, req)
+}
+
+type cmdRgwRealmCommit struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwRealmCommit) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "commit <REALM>",
+		Short: "Commit the pending period of an RGW realm and restart the local RGW daemons",
+		RunE:  c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdRgwRealmCommit) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	return client.CommitRgwRealm(context.Background(), cli, args[0])
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdRgwSync struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwSync) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Inspect RGW multisite sync",
+	}
+
+	// status
+	statusCmd := cmdRgwSyncStatus{common: c.common}
+	cmd.AddCommand(statusCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdRgwSyncStatus struct {
+	common   *CmdControl
+	flagJson bool
+}
+
+func (c *cmdRgwSyncStatus) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the local zone has caught up with its peers, exits non-zero if it has not",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagJson, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdRgwSyncStatus) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	status, err := client.GetRgwSyncStatus(context.Background(), cli)
+	if err != nil {
+		return err
+	}
+
+	if c.flagJson {
+		opStr, err := json.Marshal(status)
+		if err != nil {
+			return fmt.Errorf("internal error: unable to encode json output: %w", err)
+		}
+
+		fmt.Printf("%s\n", opStr)
+	} else {
+		verdict := "caught up"
+		if !status.IsCaughtUp() {
+			verdict = "not caught up"
+		}
+
+		fmt.Printf("Zone %s of zonegroup %s in realm %s is %s\n", status.Zone, status.ZoneGroup, status.Realm, verdict)
+		err = printRgwSyncVerdictTable(status)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !status.IsCaughtUp() {
+		return fmt.Errorf("zone %s is not caught up", status.Zone)
+	}
+
+	return nil
+}
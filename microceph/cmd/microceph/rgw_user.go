@@ -0,0 +1,629 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+	"github.com/canonical/microceph/microceph/constants"
+)
+
+type cmdRgwUser struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwUser) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage RGW users, their keys and quotas",
+	}
+
+	// create
+	createCmd := cmdRgwUserCreate{common: c.common}
+	cmd.AddCommand(createCmd.Command())
+
+	// ls
+	listCmd := cmdRgwUserList{common: c.common}
+	cmd.AddCommand(listCmd.Command())
+
+	// info
+	infoCmd := cmdRgwUserInfo{common: c.common}
+	cmd.AddCommand(infoCmd.Command())
+
+	// suspend
+	suspendCmd := cmdRgwUserSuspend{common: c.common, suspend: true}
+	cmd.AddCommand(suspendCmd.Command())
+
+	// enable
+	enableCmd := cmdRgwUserSuspend{common: c.common, suspend: false}
+	cmd.AddCommand(enableCmd.Command())
+
+	// set
+	setCmd := cmdRgwUserSet{common: c.common}
+	cmd.AddCommand(setCmd.Command())
+
+	// rm
+	removeCmd := cmdRgwUserRemove{common: c.common}
+	cmd.AddCommand(removeCmd.Command())
+
+	// key
+	keyCmd := cmdRgwUserKey{common: c.common}
+	cmd.AddCommand(keyCmd.Command())
+
+	// quota
+	quotaCmd := cmdRgwUserQuota{common: c.common}
+	cmd.AddCommand(quotaCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdRgwUserCreate struct {
+	common          *CmdControl
+	flagDisplayName string
+	flagEmail       string
+	flagMaxBuckets  int
+	json            bool
+}
+
+func (c *cmdRgwUserCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <UID>",
+		Short: "Create an RGW user along with its first S3 key",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagDisplayName, "display-name", "", "Display name of the user (default: the user ID)")
+	cmd.Flags().StringVar(&c.flagEmail, "email", "", "Email address of the user")
+	cmd.Flags().IntVar(&c.flagMaxBuckets, "max-buckets", 0, "Maximum number of buckets of the user (default: RGW default)")
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdRgwUserCreate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.RgwUserRequest{
+		UserID:      args[0],
+		DisplayName: c.flagDisplayName,
+		Email:       c.flagEmail,
+		MaxBuckets:  c.flagMaxBuckets,
+	}
+
+	user, err := client.CreateRgwUser(context.Background(), cli, req)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(user)
+	}
+
+	return printRgwKeyTable(user.Keys)
+}
+
+type cmdRgwUserList struct {
+	common *CmdControl
+	json   bool
+}
+
+func (c *cmdRgwUserList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List RGW users",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdRgwUserList) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	users, err := client.ListRgwUsers(context.Background(), cli)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(users)
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"User"})
+	for _, user := range users {
+		t.AppendRow(table.Row{user})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+	return nil
+}
+
+type cmdRgwUserInfo struct {
+	common *CmdControl
+	json   bool
+}
+
+func (c *cmdRgwUserInfo) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "info <UID>",
+		Short: "Show an RGW user with its access keys and quotas",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdRgwUserInfo) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	user, err := client.GetRgwUser(context.Background(), cli, args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(user)
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendRow(table.Row{"User", user.UserID})
+	t.AppendRow(table.Row{"Display Name", user.DisplayName})
+	t.AppendRow(table.Row{"Email", user.Email})
+	t.AppendRow(table.Row{"Suspended", user.Suspended})
+	t.AppendRow(table.Row{"Max Buckets", user.MaxBuckets})
+	t.AppendRow(table.Row{"User Quota", formatRgwQuota(user.UserQuota)})
+	t.AppendRow(table.Row{"Bucket Quota", formatRgwQuota(user.BucketQuota)})
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+	fmt.Println()
+
+	kt := table.NewWriter()
+	kt.SetOutputMirror(os.Stdout)
+	kt.AppendHeader(table.Row{"User", "Access Key"})
+	for _, key := range user.Keys {
+		kt.AppendRow(table.Row{key.User, key.AccessKey})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		kt.SetStyle(table.StyleColoredBright)
+	}
+	kt.Render()
+	return nil
+}
+
+type cmdRgwUserSuspend struct {
+	common  *CmdControl
+	suspend bool
+}
+
+func (c *cmdRgwUserSuspend) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable <UID>",
+		Short: "Re-enable a suspended RGW user",
+		RunE:  c.Run,
+	}
+
+	if c.suspend {
+		cmd.Use = "suspend <UID>"
+		cmd.Short = "Suspend an RGW user, denying all of its requests"
+	}
+
+	return cmd
+}
+
+func (c *cmdRgwUserSuspend) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	return client.SetRgwUser(context.Background(), cli, args[0], types.RgwUserSetRequest{Suspended: &c.suspend})
+}
+
+type cmdRgwUserSet struct {
+	common         *CmdControl
+	flagMaxBuckets int
+}
+
+func (c *cmdRgwUserSet) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <UID>",
+		Short: "Update the properties of an RGW user",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().IntVar(&c.flagMaxBuckets, "max-buckets", 0, "Maximum number of buckets of the user")
+
+	return cmd
+}
+
+func (c *cmdRgwUserSet) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	req := types.RgwUserSetRequest{}
+	if cmd.Flags().Changed("max-buckets") {
+		req.MaxBuckets = &c.flagMaxBuckets
+	}
+
+	if req.MaxBuckets == nil {
+		return fmt.Errorf("no property to update, see --help")
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	return client.SetRgwUser(context.Background(), cli, args[0], req)
+}
+
+type cmdRgwUserRemove struct {
+	common        *CmdControl
+	flagPurgeData bool
+	flagForce     bool
+}
+
+func (c *cmdRgwUserRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm <UID>",
+		Aliases: []string{"remove"},
+		Short:   "Remove an RGW user",
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagPurgeData, "purge-data", false, "Remove the buckets and objects of the user as well")
+	cmd.Flags().BoolVar(&c.flagForce, "yes-i-really-mean-it", false, "Force removal of the user data.")
+
+	return cmd
+}
+
+func (c *cmdRgwUserRemove) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	if c.flagPurgeData && !c.flagForce {
+		return fmt.Errorf("WARNING: this will *PERMANENTLY REMOVE* all buckets and objects of user %s. %s",
+			args[0], constants.CliForcePrompt)
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.RgwUserDeleteRequest{
+		PurgeData: c.flagPurgeData,
+		IsForceOp: c.flagForce,
+	}
+
+	return client.DeleteRgwUser(context.Background(), cli, args[0], req)
+}
+
+type cmdRgwUserKey struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwUserKey) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "key",
+		Short: "Manage the S3 keys of an RGW user",
+	}
+
+	// create
+	createCmd := cmdRgwUserKeyCreate{common: c.common}
+	cmd.AddCommand(createCmd.Command())
+
+	// rotate
+	rotateCmd := cmdRgwUserKeyCreate{common: c.common, rotate: true}
+	cmd.AddCommand(rotateCmd.Command())
+
+	// rm
+	removeCmd := cmdRgwUserKeyRemove{common: c.common}
+	cmd.AddCommand(removeCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdRgwUserKeyCreate struct {
+	common *CmdControl
+	rotate bool
+	json   bool
+}
+
+func (c *cmdRgwUserKeyCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <UID>",
+		Short: "Generate a new S3 key for an RGW user",
+		RunE:  c.Run,
+	}
+
+	if c.rotate {
+		cmd.Use = "rotate <UID> <ACCESS_KEY>"
+		cmd.Short = "Replace an S3 key of an RGW user with a newly generated one"
+	}
+
+	cmd.Flags().BoolVar(&c.json, "json", false, "output as json string")
+
+	return cmd
+}
+
+func (c *cmdRgwUserKeyCreate) Run(cmd *cobra.Command, args []string) error {
+	req := types.RgwKeyRequest{}
+	if c.rotate {
+		if len(args) != 2 {
+			return cmd.Help()
+		}
+
+		req.Rotate = args[1]
+	} else if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	key, err := client.CreateRgwUserKey(context.Background(), cli, args[0], req)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printRgwJson(key)
+	}
+
+	return printRgwKeyTable([]types.RgwUserKey{key})
+}
+
+type cmdRgwUserKeyRemove struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwUserKeyRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rm <UID> <ACCESS_KEY>",
+		Aliases: []string{"remove"},
+		Short:   "Remove an S3 key of an RGW user",
+		RunE:    c.Run,
+	}
+
+	return cmd
+}
+
+func (c *cmdRgwUserKeyRemove) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	return client.DeleteRgwUserKey(context.Background(), cli, args[0], args[1])
+}
+
+type cmdRgwUserQuota struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwUserQuota) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quota",
+		Short: "Manage the quotas of an RGW user",
+	}
+
+	// set
+	setCmd := cmdRgwUserQuotaSet{common: c.common}
+	cmd.AddCommand(setCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdRgwUserQuotaSet struct {
+	common         *CmdControl
+	flagScope      string
+	flagMaxSize    string
+	flagMaxObjects int64
+	flagDisable    bool
+}
+
+func (c *cmdRgwUserQuotaSet) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <UID>",
+		Short: "Set and enable the user or per-bucket quota of an RGW user",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagScope, "scope", "user", "Quota scope, either user (total of all buckets) or bucket (each bucket)")
+	cmd.Flags().StringVar(&c.flagMaxSize, "max-size", "", "Maximum size, e.g. 100GiB (default: unlimited)")
+	cmd.Flags().Int64Var(&c.flagMaxObjects, "max-objects", -1, "Maximum number of objects (default: unlimited)")
+	cmd.Flags().BoolVar(&c.flagDisable, "disable", false, "Disable the quota instead")
+
+	return cmd
+}
+
+func (c *cmdRgwUserQuotaSet) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	maxSize := int64(-1)
+	if len(c.flagMaxSize) != 0 && c.flagMaxSize != "inf" {
+		size, err := parseFsQuota(c.flagMaxSize)
+		if err != nil {
+			return err
+		}
+
+		maxSize = size
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.RgwQuotaRequest{
+		Scope: c.flagScope,
+		RgwQuota: types.RgwQuota{
+			Enabled:    !c.flagDisable,
+			MaxSize:    maxSize,
+			MaxObjects: c.flagMaxObjects,
+		},
+	}
+
+	return client.SetRgwUserQuota(context.Background(), cli, args[0], req)
+}
+
+// formatRgwQuota renders a quota as a short human readable string.
+func formatRgwQuota(quota types.RgwQuota) string {
+	if !quota.Enabled {
+		return "disabled"
+	}
+
+	size := "unlimited"
+	if quota.MaxSize >= 0 {
+		size = units.GetByteSizeStringIEC(quota.MaxSize, 2)
+	}
+
+	objects := "unlimited"
+	if quota.MaxObjects >= 0 {
+		objects = fmt.Sprintf("%d", quota.MaxObjects)
+	}
+
+	return fmt.Sprintf("size: %s, objects: %s", size, objects)
+}
+
+func printRgwKeyTable(keys []types.RgwUserKey) error {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"User", "Access Key", "Secret Key"})
+	for _, key := range keys {
+		t.AppendRow(table.Row{key.User, key.AccessKey, key.SecretKey})
+	}
+	if term.IsTerminal(0) && term.IsTerminal(1) {
+		// Set style if interactive shell.
+		t.SetStyle(table.StyleColoredBright)
+	}
+	t.Render()
+	return nil
+}
+
+func printRgwJson(data any) error {
+	opStr, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("internal error: unable to encode json output: %w", err)
+	}
+
+	fmt.Printf("%s\n", opStr)
+	return nil
+}
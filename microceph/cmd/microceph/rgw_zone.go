@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdRgwZone struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwZone) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "zone",
+		Short: "Manage RGW multisite zones",
+	}
+
+	// create
+	createCmd := cmdRgwZoneCreate{common: c.common}
+	cmd.AddCommand(createCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdRgwZoneCreate struct {
+	common        *CmdControl
+	flagZoneGroup string
+	flagEndpoints []string
+	flagMaster    bool
+}
+
+func (c *cmdRgwZoneCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <ZONE>",
+		Short: "Create an RGW zone, commit the period and point the RGW daemons at it",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagZoneGroup, "zonegroup", "", "Zonegroup of the zone (default: the zonegroup created on this cluster)")
+	cmd.Flags().StringSliceVar(&c.flagEndpoints, "endpoints", []string{}, "Comma separated endpoint URLs of the zone")
+	cmd.Flags().BoolVar(&c.flagMaster, "master", false, "Make this the master zone of the zonegroup")
+
+	return cmd
+}
+
+func (c *cmdRgwZoneCreate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.RgwZoneRequest{
+		Name:      args[0],
+		ZoneGroup: c.flagZoneGroup,
+		Endpoints: c.flagEndpoints,
+		IsMaster:  c.flagMaster,
+	}
+
+	return client.CreateRgwZone(context.Background(), cli, req)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+
+	"github.com/canonical/microcluster/v3/microcluster"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microceph/microceph/api/types"
+	"github.com/canonical/microceph/microceph/client"
+)
+
+type cmdRgwZoneGroup struct {
+	common *CmdControl
+}
+
+func (c *cmdRgwZoneGroup) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "zonegroup",
+		Short: "Manage RGW multisite zonegroups",
+	}
+
+	// create
+	createCmd := cmdRgwZoneGroupCreate{common: c.common}
+	cmd.AddCommand(createCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+
+	return cmd
+}
+
+type cmdRgwZoneGroupCreate struct {
+	common        *CmdControl
+	flagRealm     string
+	flagEndpoints []string
+	flagMaster    bool
+}
+
+func (c *cmdRgwZoneGroupCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <ZONEGROUP>",
+		Short: "Create an RGW zonegroup",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringVar(&c.flagRealm, "realm", "", "Realm of the zonegroup (default: the realm created or pulled on this cluster)")
+	cmd.Flags().StringSliceVar(&c.flagEndpoints, "endpoints", []string{}, "Comma separated endpoint URLs of the zonegroup")
+	cmd.Flags().BoolVar(&c.flagMaster, "master", false, "Make this the master zonegroup of the realm")
+
+	return cmd
+}
+
+func (c *cmdRgwZoneGroupCreate) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(microcluster.Args{StateDir: c.common.FlagStateDir})
+	if err != nil {
+		return err
+	}
+
+	cli, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	req := types.RgwZoneGroupRequest{
+		Name:      args[0],
+		Realm:     c.flagRealm,
+		Endpoints: c.flagEndpoints,
+		IsMaster:  c.flagMaster,
+	}
+
+	return client.CreateRgwZoneGroup(context.Background(), cli, req)
+}
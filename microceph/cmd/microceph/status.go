@@ -6,10 +6,12 @@ import (
 	"github.com/canonical/microceph/microceph/clilogger"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/canonical/microcluster/v3/microcluster"
 	"github.com/spf13/cobra"
 
+	"github.com/canonical/microceph/microceph/api/types"
 	"github.com/canonical/microceph/microceph/client"
 )
 
@@ -59,6 +61,17 @@ func (c *cmdStatus) Run(cmd *cobra.Command, args []string) error {
 	}
 	clilogger.Debugf("Members: %+v", clusterMembers)
 
+	// Get the refresh gate decisions, not fatal as they are informational.
+	refreshDecisions := map[string]types.RefreshDecision{}
+	decisions, err := client.GetRefreshDecisions(context.Background(), cli)
+	if err != nil {
+		clilogger.Debugf("failed to fetch refresh decisions: %v", err)
+	}
+
+	for _, decision := range decisions {
+		refreshDecisions[decision.Member] = decision
+	}
+
 	fmt.Println("MicroCeph deployment summary:")
 
 	for _, server := range clusterMembers {
@@ -74,11 +87,16 @@ func (c *cmdStatus) Run(cmd *cobra.Command, args []string) error {
 
 		// Services.
 		srvServices := []string{}
+		hasRgw := false
 		for _, service := range services {
 			if service.Location != server.Name {
 				continue
 			}
 
+			if service.Service == "rgw" {
+				hasRgw = true
+			}
+
 			// grouped service should appear as service.groupId
 			service_name := service.Service
 			if len(service.GroupID) != 0 {
@@ -96,7 +114,37 @@ func (c *cmdStatus) Run(cmd *cobra.Command, args []string) error {
 		fmt.Printf("- %s (%s)\n", server.Name, server.Address.Addr().String())
 		fmt.Printf("  Services: %s\n", strings.Join(srvServices, ", "))
 		fmt.Printf("  Disks: %d\n", diskCount)
+
+		decision, ok := refreshDecisions[server.Name]
+		if ok {
+			fmt.Printf("  Refresh: %s\n", formatRefreshDecision(decision))
+		}
+
+		// Warn ahead of RGW certificate expiry.
+		if hasRgw {
+			for _, warning := range getExpiringCertificateWarnings(context.Background(), cli, server.Name) {
+				fmt.Printf("  Warning: %s\n", warning)
+			}
+		}
 	}
 
 	return nil
 }
+
+// formatRefreshDecision describes the latest refresh gate decision of a member.
+func formatRefreshDecision(decision types.RefreshDecision) string {
+	timestamp := decision.Timestamp.Local().Format(time.RFC3339)
+	if !decision.Allowed {
+		return fmt.Sprintf("refused at %s: %s", timestamp, decision.Reason)
+	}
+
+	if !decision.Completed {
+		return fmt.Sprintf("in progress since %s", timestamp)
+	}
+
+	if len(decision.Reason) > 0 {
+		return fmt.Sprintf("allowed at %s, %s", timestamp, decision.Reason)
+	}
+
+	return fmt.Sprintf("allowed at %s", timestamp)
+}
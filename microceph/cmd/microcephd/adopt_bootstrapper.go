@@ -119,7 +119,7 @@ func (ab *AdoptBootstrapper) Bootstrap(ctx context.Context, state interfaces.Sta
 	}
 
 	// If a public network is already configured, it will be used instead.
-	err = ab.updateCephClusterConfigs()
+	err = ab.updateCephClusterConfigs(ctx, state)
 	if err != nil {
 		logger.Errorf("failed to update ceph cluster configurations: %v", err)
 		return err
@@ -148,7 +148,7 @@ func (ab *AdoptBootstrapper) Bootstrap(ctx context.Context, state interfaces.Sta
 }
 
 // UpdateCephClusterConfigs configures the ceph cluster network parameters.
-func (ab *AdoptBootstrapper) updateCephClusterConfigs() error {
+func (ab *AdoptBootstrapper) updateCephClusterConfigs(ctx context.Context, state interfaces.StateInterface) error {
 	publicNet, err := ceph.GetConfigItem(types.Config{
 		Key: "public_network",
 	})
@@ -180,7 +180,7 @@ func (ab *AdoptBootstrapper) updateCephClusterConfigs() error {
 	cn := clusterNet[0].Value
 	if len(cn) == 0 {
 		// Populate MicroCeph deduced cluster network
-		err = ceph.SetConfigItem(types.Config{
+		err = ceph.SetInternalConfigItem(ctx, state, types.Config{
 			Key:   "cluster_network",
 			Value: ab.ClusterNet,
 		})
@@ -5,11 +5,14 @@ import (
 	"testing"
 
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/microceph/microceph/api/types"
 	"github.com/canonical/microceph/microceph/ceph"
 	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/database"
 	"github.com/canonical/microceph/microceph/interfaces"
 	"github.com/canonical/microceph/microceph/mocks"
 	"github.com/canonical/microceph/microceph/tests"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -35,6 +38,24 @@ func (s *adoptBootstrapSuite) SetupTest() {
 		ClusterName: "foohost",
 	}
 	s.TestStateInterface.On("ClusterState").Return(state).Maybe()
+
+	origRecord := database.RecordConfigChangeDb
+	origGet := database.GetConfigItemDb
+	origSet := database.SetConfigItemDb
+	s.T().Cleanup(func() {
+		database.RecordConfigChangeDb = origRecord
+		database.GetConfigItemDb = origGet
+		database.SetConfigItemDb = origSet
+	})
+	database.RecordConfigChangeDb = func(_ context.Context, _ mcTypes.State, _ types.ConfigChange) error {
+		return nil
+	}
+	database.GetConfigItemDb = func(_ context.Context, _ mcTypes.State, _ string) (string, error) {
+		return "", nil
+	}
+	database.SetConfigItemDb = func(_ context.Context, _ mcTypes.State, _ string, _ string) error {
+		return nil
+	}
 }
 
 // addNetworkExpectations sets up the network mock expectations for AdoptBootstrapper tests
@@ -51,6 +72,9 @@ func addCephConnectivityCheckExpectations(r *mocks.Runner) {
 
 // Expect: ceph config calls
 func addConfigAdoptExpectations(r *mocks.Runner) {
+	// config dump for the config history of the cluster_network
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return("[]", nil).Once()
+
 	// ceph config get public_network should return no config
 	r.On("RunCommand", tests.CmdAny("ceph", 4)...).Return("", nil).Once()
 
@@ -126,13 +126,13 @@ func (sb *SimpleBootstrapper) Bootstrap(ctx context.Context, state interfaces.St
 	}
 
 	// Bring up auto scaling crush rules.
-	err = ceph.BootstrapCrushRules()
+	err = ceph.BootstrapCrushRules(ctx, state)
 	if err != nil {
 		return err
 	}
 
 	// Configure defaults cluster configs for network.
-	err = ceph.BootstrapCephConfigs(sb.ClusterNet, sb.PublicNet)
+	err = ceph.BootstrapCephConfigs(ctx, state, sb.ClusterNet, sb.PublicNet)
 	if err != nil {
 		return err
 	}
@@ -5,11 +5,14 @@ import (
 	"testing"
 
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/microceph/microceph/api/types"
 	"github.com/canonical/microceph/microceph/ceph"
 	"github.com/canonical/microceph/microceph/common"
+	"github.com/canonical/microceph/microceph/database"
 	"github.com/canonical/microceph/microceph/interfaces"
 	"github.com/canonical/microceph/microceph/mocks"
 	"github.com/canonical/microceph/microceph/tests"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -35,6 +38,24 @@ func (s *simpleBootstrapSuite) SetupTest() {
 		ClusterName: "foohost",
 	}
 	s.TestStateInterface.On("ClusterState").Return(state).Maybe()
+
+	origRecord := database.RecordConfigChangeDb
+	origGet := database.GetConfigItemDb
+	origSet := database.SetConfigItemDb
+	s.T().Cleanup(func() {
+		database.RecordConfigChangeDb = origRecord
+		database.GetConfigItemDb = origGet
+		database.SetConfigItemDb = origSet
+	})
+	database.RecordConfigChangeDb = func(_ context.Context, _ mcTypes.State, _ types.ConfigChange) error {
+		return nil
+	}
+	database.GetConfigItemDb = func(_ context.Context, _ mcTypes.State, _ string) (string, error) {
+		return "", nil
+	}
+	database.SetConfigItemDb = func(_ context.Context, _ mcTypes.State, _ string, _ string) error {
+		return nil
+	}
 }
 
 func addNetworkSimpleBootstrapExpectations(nw *mocks.NetworkIntf) {
@@ -83,6 +104,8 @@ func addEnableMsgr2Expectations(r *mocks.Runner) {
 }
 
 func addCrushRuleExpectations(r *mocks.Runner) {
+	// config dump for the config history (default crush rule, cluster network, rbd features)
+	r.On("RunCommand", "ceph", "config", "dump", "-f", "json-pretty").Return("[]", nil).Times(3)
 	// crush rule ls (osd, host, rack)
 	r.On("RunCommand", tests.CmdAny("ceph", 4)...).Return("ok", nil).Times(3)
 	// crush rule create-replicated (osd, host, rack)
@@ -1,5 +1,6 @@
 package constants
 
 const (
-	MgrModuleMirroring = "mirroring"
+	MgrModuleMirroring    = "mirroring"
+	MgrModuleSnapSchedule = "snap_schedule"
 )
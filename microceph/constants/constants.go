@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"time"
 )
 
 // Constants for Size Constraints
@@ -41,6 +42,22 @@ const (
 	AdminKeyringTemplate  = "keyring.client.%s"
 )
 
+// RGW self-managed TLS: config table keys of the cluster-internal CA, and how
+// long before expiry certificates are flagged and self-managed ones renewed.
+const (
+	RgwTLSCACertKey      = "rgw.tls.ca_cert"
+	RgwTLSCAKeyKey       = "rgw.tls.ca_key"
+	RgwCertRenewalWindow = 30 * 24 * time.Hour
+)
+
+// Config table key prefix of the ceph options set through MicroCeph, recorded
+// as <prefix><who>/<option>.
+const CephOptionKeyPrefix = "ceph_option/"
+
+// Config table key prefix of the ceph options MicroCeph sets on its own, as at
+// bootstrap. They are checked for drift but never pruned.
+const CephDefaultKeyPrefix = "ceph_default/"
+
 // Ceph Error Substrings
 const RbdMirrorNonPrimaryPromoteErr = "image is primary within a remote cluster or demotion is not propagated yet"
 
@@ -53,6 +70,7 @@ type PathConst struct {
 	ProcPath     string
 	SSLFilesPath string
 	SnapPath     string
+	BackupPath   string
 }
 
 type PathFileMode map[string]os.FileMode
@@ -67,6 +85,7 @@ var GetPathConst = func() PathConst {
 		ProcPath:     filepath.Join(os.Getenv("TEST_ROOT_PATH"), "/proc"),
 		SSLFilesPath: filepath.Join(os.Getenv("SNAP_COMMON"), "/"),
 		SnapPath:     filepath.Join(os.Getenv("SNAP"), "/"),
+		BackupPath:   filepath.Join(os.Getenv("SNAP_COMMON"), "backups"),
 	}
 }
 
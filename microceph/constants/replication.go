@@ -19,3 +19,33 @@ var (
 	CephFSSubvolumePathTemplate = "/volumes/%s/%s"
 	CephFSSubvolumeNoGroup      = "_nogroup"
 )
+
+// CephFS mirroring roles recorded on failover.
+const (
+	CephFSMirrorRolePrimary   = "primary"
+	CephFSMirrorRoleSecondary = "secondary"
+	// CephFSMirrorRoleKeyTemplate is the config table key holding the failover record of a volume.
+	CephFSMirrorRoleKeyTemplate = "cephfs.mirror.%s"
+)
+
+// RGW multisite defaults.
+const (
+	RgwDefaultRealm     = "microceph"
+	RgwDefaultZoneGroup = "microceph"
+	// RgwDefaultName is the name radosgw-admin gives the zonegroup and zone of a single site gateway.
+	RgwDefaultName = "default"
+	// RgwSystemUserID is the system user whose keys authenticate inter-zone sync.
+	RgwSystemUserID = "microceph-multisite"
+	// RgwClientEntity is the ceph entity the RGW daemons run as.
+	RgwClientEntity = "client.radosgw.gateway"
+)
+
+// RGW multisite config table keys. Realm, zonegroup and zone are rendered into
+// radosgw.conf on every RGW host, the keys are those of the multisite system user.
+const (
+	RgwMultisiteRealmKey     = "rgw.multisite.realm"
+	RgwMultisiteZoneGroupKey = "rgw.multisite.zonegroup"
+	RgwMultisiteZoneKey      = "rgw.multisite.zone"
+	RgwMultisiteAccessKey    = "rgw.multisite.access_key"
+	RgwMultisiteSecretKey    = "rgw.multisite.secret_key"
+)
@@ -0,0 +1,280 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// BackupTables lists the tables a cluster backup holds, in the order they are
+// restored.
+var BackupTables = []string{
+	"config",
+	"remote",
+	"client_config",
+	"placement_policy",
+	"services",
+	"disks",
+	"host_tags",
+	"service_groups",
+	"grouped_services",
+	"config_history",
+	"refresh_decisions",
+}
+
+// TableRows holds the rows of a table keyed by column name. The rows of a
+// table referencing a cluster member by id also hold the name of the member
+// under "member", member ids not being portable across clusters. Tables such
+// as config_history name the member in a plain "member" column instead.
+type TableRows []map[string]any
+
+// GetMemberIDs maps the name of every cluster member to its id.
+func GetMemberIDs(ctx context.Context, tx *sql.Tx) (map[string]int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, name FROM core_cluster_members`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster members: %w", err)
+	}
+	defer rows.Close()
+
+	members := map[string]int64{}
+	for rows.Next() {
+		var id int64
+		var name string
+
+		err := rows.Scan(&id, &name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cluster member: %w", err)
+		}
+
+		members[name] = id
+	}
+
+	return members, rows.Err()
+}
+
+// getTableColumns fetches the column names of a table.
+func getTableColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM %q LIMIT 0`, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	return rows.Columns()
+}
+
+// DumpTables reads every row of the given tables.
+func DumpTables(ctx context.Context, tx *sql.Tx, tables []string) (map[string]TableRows, error) {
+	members, err := GetMemberIDs(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	memberNames := map[int64]string{}
+	for name, id := range members {
+		memberNames[id] = name
+	}
+
+	dump := map[string]TableRows{}
+	for _, table := range tables {
+		dump[table], err = dumpTable(ctx, tx, table, memberNames)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dump, nil
+}
+
+// dumpTable reads every row of a table.
+func dumpTable(ctx context.Context, tx *sql.Tx, table string, memberNames map[int64]string) (TableRows, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT * FROM %q ORDER BY id`, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+
+	dump := TableRows{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		err := rows.Scan(pointers...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row of %s: %w", table, err)
+		}
+
+		row := map[string]any{}
+		for i, column := range columns {
+			value := values[i]
+
+			// Text and blob columns are both text in our schema.
+			bytes, ok := value.([]byte)
+			if ok {
+				value = string(bytes)
+			}
+
+			row[column] = value
+		}
+
+		memberID, ok := row["member_id"].(int64)
+		if ok {
+			row["member"] = memberNames[memberID]
+		}
+
+		dump = append(dump, row)
+	}
+
+	return dump, rows.Err()
+}
+
+// restoreValue converts a value decoded from JSON back to a column value.
+func restoreValue(value any) any {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+
+	integer, err := number.Int64()
+	if err == nil {
+		return integer
+	}
+
+	return number.String()
+}
+
+// RestoreTables replaces the rows of the dumped tables. The rows referencing
+// a member missing from the cluster are skipped, their count per table is
+// returned.
+func RestoreTables(ctx context.Context, tx *sql.Tx, dump map[string]TableRows) (map[string]int, error) {
+	members, err := GetMemberIDs(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := []string{}
+	for _, table := range BackupTables {
+		_, ok := dump[table]
+		if ok {
+			tables = append(tables, table)
+		}
+	}
+
+	if len(tables) != len(dump) {
+		return nil, fmt.Errorf("backup holds unknown tables")
+	}
+
+	// Delete in reverse order so that rows are gone before the rows they reference.
+	for i := len(tables) - 1; i >= 0; i-- {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %q`, tables[i]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to clear %s: %w", tables[i], err)
+		}
+	}
+
+	skipped := map[string]int{}
+	for _, table := range tables {
+		columns, err := getTableColumns(ctx, tx, table)
+		if err != nil {
+			return nil, err
+		}
+
+		known := map[string]bool{}
+		for _, column := range columns {
+			known[column] = true
+		}
+
+		for _, row := range dump[table] {
+			restored := row
+			if known["member_id"] {
+				var ok bool
+				restored, ok = restoreMember(row, members)
+				if !ok {
+					skipped[table]++
+					continue
+				}
+			}
+
+			err := insertRow(ctx, tx, table, restored, known)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return skipped, nil
+}
+
+// restoreMember replaces the member name of a row by the id of the member in
+// the cluster. It returns false when the member is not part of the cluster.
+func restoreMember(row map[string]any, members map[string]int64) (map[string]any, bool) {
+	restored := map[string]any{}
+	for column, value := range row {
+		restored[column] = value
+	}
+
+	member, ok := restored["member"]
+	if !ok {
+		return restored, true
+	}
+
+	// Cluster wide rows, like the global client configs, have no member.
+	delete(restored, "member")
+	name, _ := member.(string)
+	id, ok := members[name]
+	if !ok {
+		return nil, false
+	}
+
+	restored["member_id"] = id
+	return restored, true
+}
+
+// insertRow inserts a row in a table, its columns checked against the table.
+func insertRow(ctx context.Context, tx *sql.Tx, table string, row map[string]any, known map[string]bool) error {
+	columns := ""
+	placeholders := ""
+	values := []any{}
+	for _, column := range sortedColumns(row) {
+		if !known[column] {
+			return fmt.Errorf("unknown column %q in %s", column, table)
+		}
+
+		if len(values) != 0 {
+			columns += ", "
+			placeholders += ", "
+		}
+
+		columns += fmt.Sprintf("%q", column)
+		placeholders += "?"
+		values = append(values, restoreValue(row[column]))
+	}
+
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %q (%s) VALUES (%s)`, table, columns, placeholders), values...)
+	if err != nil {
+		return fmt.Errorf("failed to restore row of %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// sortedColumns returns the columns of a row in a stable order.
+func sortedColumns(row map[string]any) []string {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+
+	sort.Strings(columns)
+	return columns
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupBackupDB creates an in-memory SQLite database with the cluster members
+// table and every schema extension applied.
+func setupBackupDB(t *testing.T, members ...string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = tx.Exec(`CREATE TABLE core_cluster_members (id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL, name TEXT NOT NULL, UNIQUE(name))`)
+	require.NoError(t, err)
+	for _, member := range members {
+		_, err = tx.Exec(`INSERT INTO core_cluster_members (name) VALUES (?)`, member)
+		require.NoError(t, err)
+	}
+
+	for _, update := range SchemaExtensions {
+		require.NoError(t, update(context.Background(), tx))
+	}
+	require.NoError(t, tx.Commit())
+
+	return db
+}
+
+// TestDumpRestoreTables verifies rows survive a JSON round trip into another
+// cluster, rows of members missing from it being skipped.
+func TestDumpRestoreTables(t *testing.T) {
+	source := setupBackupDB(t, "node-a", "node-b")
+	_, err := source.Exec(`
+INSERT INTO config (key, value) VALUES ('fsid', 'abc'), ('public_network', '10.0.0.0/24');
+INSERT INTO services (member_id, service) VALUES (1, 'mon'), (2, 'mon'), (2, 'rgw');
+INSERT INTO client_config (member_id, key, value) VALUES (NULL, 'rbd_cache', 'true'), (1, 'rbd_cache_size', '1024');
+INSERT INTO service_groups (service, group_id, config) VALUES ('nfs', 'nfs1', '{"v4_min_version":1}');
+INSERT INTO grouped_services (service_group_id, member_id, info) VALUES (1, 2, '{"bind_port":2049}');
+UPDATE placement_policy SET active = 1, policy_json = '{"members":{}}' WHERE id = 1;
+INSERT INTO config_history (key, who, advanced, old_value, new_value, member, requester, timestamp) VALUES ('rbd_cache', 'client', 0, NULL, 'true', 'node-a', 'local@node-a', '2026-10-01 10:00:00');
+INSERT INTO refresh_decisions (member_id, allowed, reason, completed, timestamp) VALUES (1, 1, '', 1, '2026-10-01 10:00:00'), (2, 1, '', 0, '2026-10-01 11:00:00');
+`)
+	require.NoError(t, err)
+
+	tx, err := source.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	dump, err := DumpTables(context.Background(), tx, BackupTables)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	assert.Equal(t, "node-b", dump["services"][2]["member"])
+	assert.NotContains(t, dump["client_config"][0], "member")
+
+	encoded, err := json.Marshal(dump)
+	require.NoError(t, err)
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	decoded := map[string]TableRows{}
+	require.NoError(t, decoder.Decode(&decoded))
+
+	// The member ids differ in the target cluster.
+	target := setupBackupDB(t, "node-b", "node-c")
+	tx, err = target.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	skipped, err := RestoreTables(context.Background(), tx, decoded)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, map[string]int{"services": 1, "client_config": 1, "refresh_decisions": 1}, skipped)
+
+	var services []string
+	rows, err := target.Query(`SELECT core_cluster_members.name || '/' || services.service FROM services JOIN core_cluster_members ON services.member_id = core_cluster_members.id ORDER BY services.id`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var service string
+		require.NoError(t, rows.Scan(&service))
+		services = append(services, service)
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, []string{"node-b/mon", "node-b/rgw"}, services)
+
+	var value string
+	require.NoError(t, target.QueryRow(`SELECT value FROM config WHERE key = 'fsid'`).Scan(&value))
+	assert.Equal(t, "abc", value)
+	require.NoError(t, target.QueryRow(`SELECT value FROM client_config WHERE member_id IS NULL`).Scan(&value))
+	assert.Equal(t, "true", value)
+	require.NoError(t, target.QueryRow(`SELECT policy_json FROM placement_policy WHERE id = 1`).Scan(&value))
+	assert.Equal(t, `{"members":{}}`, value)
+	require.NoError(t, target.QueryRow(`SELECT info FROM grouped_services JOIN service_groups ON grouped_services.service_group_id = service_groups.id WHERE service_groups.group_id = 'nfs1'`).Scan(&value))
+	assert.Equal(t, `{"bind_port":2049}`, value)
+
+	// The config history keeps the member name of changes, whichever members
+	// the cluster holds.
+	var timestamp time.Time
+	require.NoError(t, target.QueryRow(`SELECT member, timestamp FROM config_history WHERE key = 'rbd_cache'`).Scan(&value, &timestamp))
+	assert.Equal(t, "node-a", value)
+	assert.Equal(t, time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC), timestamp.UTC())
+	require.NoError(t, target.QueryRow(`SELECT core_cluster_members.name FROM refresh_decisions JOIN core_cluster_members ON refresh_decisions.member_id = core_cluster_members.id`).Scan(&value))
+	assert.Equal(t, "node-b", value)
+}
+
+// TestRestoreTablesUnknownColumn verifies a row cannot name columns the table lacks.
+func TestRestoreTablesUnknownColumn(t *testing.T) {
+	db := setupBackupDB(t, "node-a")
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = RestoreTables(context.Background(), tx, map[string]TableRows{
+		"config": {{"key": "fsid", "value": "abc", "value) VALUES (1); DROP TABLE config; --": "x"}},
+	})
+	assert.ErrorContains(t, err, "unknown column")
+}
@@ -28,6 +28,10 @@ SELECT client_config.id, client_config.key, client_config.value FROM client_conf
   WHERE ( client_config.key = ? AND client_config.member_id IS NULL )
 `)
 
+var globalClientConfigItemDeleteByKey = cluster.RegisterStmt(`
+DELETE FROM client_config WHERE key = ? AND member_id IS NULL
+`)
+
 var globalClientConfigItemCreateOrUpdate = cluster.RegisterStmt(`
 INSERT OR REPLACE INTO client_config (member_id, key, value)
   VALUES (NULL, ?, ?)
@@ -337,5 +341,28 @@ func getOneGlobalConfigByKey(ctx context.Context, tx *sql.Tx, rowFunc query.Dest
 	return nil
 }
 
+// deleteGlobalClientConfigItem removes the global entry of a client config key.
+func deleteGlobalClientConfigItem(ctx context.Context, tx *sql.Tx, key string) error {
+	stmt, err := cluster.Stmt(tx, globalClientConfigItemDeleteByKey)
+	if err != nil {
+		return fmt.Errorf("failed to get global client config delete stmt: %w", err)
+	}
+
+	_, err = stmt.ExecContext(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete from client_config table: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveGlobalClientConfigItemDb removes the global entry of a client config
+// key, leaving its host specific entries in place.
+var RemoveGlobalClientConfigItemDb = func(ctx context.Context, s mcTypes.State, key string) error {
+	return s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		return deleteGlobalClientConfigItem(ctx, tx, key)
+	})
+}
+
 // Singleton for mocker
 var ClientConfigQuery ClientConfigQueryIntf = ClientConfigQueryImpl{}
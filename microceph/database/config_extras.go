@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+)
+
+// SetConfigItemDb creates or updates a config item record.
+var SetConfigItemDb = func(ctx context.Context, s mcTypes.State, key string, value string) error {
+	return s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		exists, err := ConfigItemExists(ctx, tx, key)
+		if err != nil {
+			return fmt.Errorf("failed to check config item %s: %w", key, err)
+		}
+
+		if exists {
+			err = UpdateConfigItem(ctx, tx, key, ConfigItem{Key: key, Value: value})
+		} else {
+			_, err = CreateConfigItem(ctx, tx, ConfigItem{Key: key, Value: value})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record config item %s: %w", key, err)
+		}
+
+		return nil
+	})
+}
+
+// GetConfigItemDb fetches the value of a config item, an empty string is returned if it is not recorded.
+var GetConfigItemDb = func(ctx context.Context, s mcTypes.State, key string) (string, error) {
+	var value string
+	err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		exists, err := ConfigItemExists(ctx, tx, key)
+		if err != nil || !exists {
+			return err
+		}
+
+		item, err := GetConfigItem(ctx, tx, key)
+		if err != nil {
+			return fmt.Errorf("failed to fetch config item %s: %w", key, err)
+		}
+
+		value = item.Value
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// DeleteConfigItemDb removes a config item record, removing an unrecorded item is not an error.
+var DeleteConfigItemDb = func(ctx context.Context, s mcTypes.State, key string) error {
+	return s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		exists, err := ConfigItemExists(ctx, tx, key)
+		if err != nil || !exists {
+			return err
+		}
+
+		err = DeleteConfigItem(ctx, tx, key)
+		if err != nil {
+			return fmt.Errorf("failed to remove config item %s: %w", key, err)
+		}
+
+		return nil
+	})
+}
+
+// InitConfigItemsDb records the given config items unless all of them are
+// already recorded, checking and inserting in one transaction so that
+// concurrent callers agree on a single set of values. The recorded values are
+// returned, whichever caller created them.
+var InitConfigItemsDb = func(ctx context.Context, s mcTypes.State, items map[string]string) (map[string]string, error) {
+	recorded := map[string]string{}
+	err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		for key := range items {
+			exists, err := ConfigItemExists(ctx, tx, key)
+			if err != nil {
+				return fmt.Errorf("failed to check config item %s: %w", key, err)
+			}
+
+			if !exists {
+				continue
+			}
+
+			item, err := GetConfigItem(ctx, tx, key)
+			if err != nil {
+				return fmt.Errorf("failed to fetch config item %s: %w", key, err)
+			}
+
+			recorded[key] = item.Value
+		}
+
+		if len(recorded) == len(items) {
+			return nil
+		}
+
+		for key, value := range items {
+			if _, ok := recorded[key]; ok {
+				err := UpdateConfigItem(ctx, tx, key, ConfigItem{Key: key, Value: value})
+				if err != nil {
+					return fmt.Errorf("failed to record config item %s: %w", key, err)
+				}
+			} else {
+				_, err := CreateConfigItem(ctx, tx, ConfigItem{Key: key, Value: value})
+				if err != nil {
+					return fmt.Errorf("failed to record config item %s: %w", key, err)
+				}
+			}
+
+			recorded[key] = value
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recorded, nil
+}
@@ -0,0 +1,37 @@
+package database
+
+//go:generate -command mapper lxd-generate db mapper -t config_history.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -d github.com/canonical/microcluster/v3/microcluster/db -e ConfigChange objects table=config_history
+//go:generate mapper stmt -d github.com/canonical/microcluster/v3/microcluster/db -e ConfigChange objects-by-ID table=config_history
+//go:generate mapper stmt -d github.com/canonical/microcluster/v3/microcluster/db -e ConfigChange objects-by-Key table=config_history
+//go:generate mapper stmt -d github.com/canonical/microcluster/v3/microcluster/db -e ConfigChange create table=config_history
+//
+//go:generate mapper method -i -d github.com/canonical/microcluster/v3/microcluster/db -e ConfigChange GetMany table=config_history
+//go:generate mapper method -i -d github.com/canonical/microcluster/v3/microcluster/db -e ConfigChange GetOne table=config_history
+//go:generate mapper method -i -d github.com/canonical/microcluster/v3/microcluster/db -e ConfigChange Create table=config_history
+
+import (
+	"time"
+)
+
+// ConfigChange is a cluster config change recorded in the config history. A
+// nil OldValue or NewValue means the key was unset before or after the change.
+type ConfigChange struct {
+	ID        int `db:"primary=yes"`
+	Key       string
+	Who       string
+	Advanced  bool
+	OldValue  *string
+	NewValue  *string
+	Member    string
+	Requester string
+	Timestamp time.Time
+}
+
+// ConfigChangeFilter is a required struct for use with lxd-generate. It is used for filtering fields on database fetches.
+type ConfigChangeFilter struct {
+	ID  *int
+	Key *string
+}
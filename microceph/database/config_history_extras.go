@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"slices"
+
+	"github.com/canonical/lxd/shared/api"
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// newConfigChange translates an API config change to its database entity.
+func newConfigChange(change types.ConfigChange) ConfigChange {
+	return ConfigChange{
+		Key:       change.Key,
+		Who:       change.Who,
+		Advanced:  change.Advanced,
+		OldValue:  change.OldValue,
+		NewValue:  change.NewValue,
+		Member:    change.Member,
+		Requester: change.Requester,
+		Timestamp: change.Timestamp.UTC(),
+	}
+}
+
+// toAPI translates a config change entity to its API type.
+func (c ConfigChange) toAPI() types.ConfigChange {
+	return types.ConfigChange{
+		ID:        int64(c.ID),
+		Key:       c.Key,
+		Who:       c.Who,
+		Advanced:  c.Advanced,
+		OldValue:  c.OldValue,
+		NewValue:  c.NewValue,
+		Member:    c.Member,
+		Requester: c.Requester,
+		Timestamp: c.Timestamp.UTC(),
+	}
+}
+
+// RecordConfigChangeDb appends a change to the config history.
+var RecordConfigChangeDb = func(ctx context.Context, s mcTypes.State, change types.ConfigChange) error {
+	return s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := CreateConfigChange(ctx, tx, newConfigChange(change))
+		return err
+	})
+}
+
+// GetConfigChangesDb fetches the config history of a key, or of all keys if
+// empty, newest first.
+var GetConfigChangesDb = func(ctx context.Context, s mcTypes.State, key string) (types.ConfigChanges, error) {
+	filters := []ConfigChangeFilter{}
+	if len(key) > 0 {
+		filters = append(filters, ConfigChangeFilter{Key: &key})
+	}
+
+	var changes []ConfigChange
+	err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		changes, err = GetConfigChanges(ctx, tx, filters...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(changes)
+	history := make(types.ConfigChanges, 0, len(changes))
+	for _, change := range changes {
+		history = append(history, change.toAPI())
+	}
+
+	return history, nil
+}
+
+// GetConfigChangeDb fetches a single change of the config history.
+var GetConfigChangeDb = func(ctx context.Context, s mcTypes.State, id int64) (types.ConfigChange, error) {
+	var change *ConfigChange
+	err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		change, err = GetConfigChange(ctx, tx, int(id))
+		return err
+	})
+	if api.StatusErrorCheck(err, http.StatusNotFound) {
+		return types.ConfigChange{}, api.StatusErrorf(http.StatusNotFound, "config change %d not found", id)
+	} else if err != nil {
+		return types.ConfigChange{}, err
+	}
+
+	return change.toAPI(), nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	cluster "github.com/canonical/microcluster/v3/microcluster/db"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// setupMapperDB creates an in-memory SQLite database with the cluster members
+// table and every schema extension applied, and prepares the registered
+// statements the generated mappers use.
+func setupMapperDB(t *testing.T, members ...string) *sql.DB {
+	t.Helper()
+	db := setupBackupDB(t, members...)
+
+	// Statements of the internal microcluster tables cannot be prepared here.
+	require.NoError(t, cluster.PrepareStmts(db, true))
+
+	return db
+}
+
+// TestConfigChangesRoundTrip verifies changes are read back in order, with unset values as nil.
+func TestConfigChangesRoundTrip(t *testing.T) {
+	db := setupMapperDB(t)
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+
+	url := "http://keystone:5000"
+	timestamp := time.Unix(1700000000, 0).UTC()
+	id, err := CreateConfigChange(context.Background(), tx, newConfigChange(types.ConfigChange{
+		Key: "rgw_keystone_url", Who: "global", NewValue: &url, Member: "node-a", Requester: "local@node-a", Timestamp: timestamp,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+
+	_, err = CreateConfigChange(context.Background(), tx, newConfigChange(types.ConfigChange{
+		Key: "osd_max_backfills", Who: "osd", Advanced: true, Member: "node-b", Requester: "member@node-a", Timestamp: timestamp,
+	}))
+	require.NoError(t, err)
+
+	changes, err := GetConfigChanges(context.Background(), tx)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "osd_max_backfills", changes[1].Key)
+	assert.True(t, changes[1].Advanced)
+	assert.Nil(t, changes[1].OldValue)
+	assert.Nil(t, changes[1].NewValue)
+
+	key := "rgw_keystone_url"
+	changes, err = GetConfigChanges(context.Background(), tx, ConfigChangeFilter{Key: &key})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, types.ConfigChange{
+		ID: 1, Key: "rgw_keystone_url", Who: "global", NewValue: &url, Member: "node-a", Requester: "local@node-a", Timestamp: timestamp,
+	}, changes[0].toAPI())
+
+	change, err := GetConfigChange(context.Background(), tx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, changes[0].toAPI(), change.toAPI())
+}
+
+// TestGetConfigChangeNotFound verifies a missing change is reported as not found.
+func TestGetConfigChangeNotFound(t *testing.T) {
+	db := setupMapperDB(t)
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = GetConfigChange(context.Background(), tx, 42)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+}
@@ -41,4 +41,13 @@ type GroupedServiceFilter struct {
 type NFSServiceInfo struct {
 	BindAddress string `json:"bind_address"`
 	BindPort    uint   `json:"bind_port"`
+	// Hostname is the node name the gateway registers in the grace database.
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// RGWServiceInfo is a struct containing GroupedService information.
+type RGWServiceInfo struct {
+	Port    int  `json:"port"`
+	SSLPort int  `json:"ssl_port"`
+	SSL     bool `json:"ssl"`
 }
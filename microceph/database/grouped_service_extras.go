@@ -26,6 +26,9 @@ type GroupedServiceQueryIntf interface {
 	// Exists Methods
 	ExistsOnHost(ctx context.Context, s interfaces.StateInterface, service, groupID string) (bool, error)
 
+	// Update Methods
+	UpdateInfoOnHost(ctx context.Context, s interfaces.StateInterface, service, groupID string, serviceInfo any) error
+
 	// Delete Methods
 	RemoveForHost(ctx context.Context, s interfaces.StateInterface, service, groupID string) error
 }
@@ -151,6 +154,28 @@ func (g GroupedServiceQueryImpl) ExistsOnHost(ctx context.Context, s interfaces.
 	return exists, err
 }
 
+// UpdateInfoOnHost replaces the service info of the given service record of this host.
+func (g GroupedServiceQueryImpl) UpdateInfoOnHost(ctx context.Context, s interfaces.StateInterface, service, groupID string, serviceInfo any) error {
+	if s.ClusterState().ServerCert() == nil {
+		return fmt.Errorf("no server certificate")
+	}
+
+	bytes, err := json.Marshal(serviceInfo)
+	if err != nil {
+		return fmt.Errorf("error while marshalling group service info: %w", err)
+	}
+
+	return s.ClusterState().Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		member := s.ClusterState().Name()
+		err := UpdateGroupedService(ctx, tx, service, groupID, member, GroupedService{Member: member, GroupID: groupID, Service: service, Info: string(bytes)})
+		if err != nil {
+			return fmt.Errorf("failed to update grouped service record: %w", err)
+		}
+
+		return nil
+	})
+}
+
 // RemoveForHost deletes the given service record in the grouped_service database, and deletes the
 // service record from the service_groups database if there is no grouped_service referencing it.
 func (g GroupedServiceQueryImpl) RemoveForHost(ctx context.Context, s interfaces.StateInterface, service, groupID string) error {
@@ -0,0 +1,38 @@
+package database
+
+//go:generate -command mapper lxd-generate db mapper -t refresh_decision.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision objects table=refresh_decisions
+//go:generate mapper stmt -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision objects-by-Member table=refresh_decisions
+//go:generate mapper stmt -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision id table=refresh_decisions
+//go:generate mapper stmt -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision create table=refresh_decisions
+//go:generate mapper stmt -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision update table=refresh_decisions
+//
+//go:generate mapper method -i -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision GetMany table=refresh_decisions
+//go:generate mapper method -i -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision GetOne table=refresh_decisions
+//go:generate mapper method -i -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision ID table=refresh_decisions
+//go:generate mapper method -i -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision Exists table=refresh_decisions
+//go:generate mapper method -i -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision Create table=refresh_decisions
+//go:generate mapper method -i -d github.com/canonical/microcluster/v3/microcluster/db -e RefreshDecision Update table=refresh_decisions
+
+import (
+	"time"
+)
+
+// RefreshDecision is the latest decision of the pre-refresh health gate for a
+// cluster member.
+type RefreshDecision struct {
+	ID        int
+	Member    string `db:"primary=yes&join=core_cluster_members.name&joinon=refresh_decisions.member_id"`
+	Allowed   bool
+	Reason    string
+	Completed bool
+	Revision  string
+	Timestamp time.Time
+}
+
+// RefreshDecisionFilter is a required struct for use with lxd-generate. It is used for filtering fields on database fetches.
+type RefreshDecisionFilter struct {
+	Member *string
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	mcTypes "github.com/canonical/microcluster/v3/microcluster/types"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// toAPI translates a refresh decision entity to its API type.
+func (d RefreshDecision) toAPI() types.RefreshDecision {
+	return types.RefreshDecision{
+		Member:    d.Member,
+		Allowed:   d.Allowed,
+		Reason:    d.Reason,
+		Completed: d.Completed,
+		Revision:  d.Revision,
+		Timestamp: d.Timestamp.UTC(),
+	}
+}
+
+// SetRefreshDecision records the latest refresh decision of a member,
+// replacing the previous one.
+func SetRefreshDecision(ctx context.Context, tx *sql.Tx, decision types.RefreshDecision) error {
+	object := RefreshDecision{
+		Member:    decision.Member,
+		Allowed:   decision.Allowed,
+		Reason:    decision.Reason,
+		Completed: decision.Completed,
+		Revision:  decision.Revision,
+		Timestamp: decision.Timestamp.UTC(),
+	}
+
+	exists, err := RefreshDecisionExists(ctx, tx, decision.Member)
+	if err != nil {
+		return fmt.Errorf("failed to record refresh decision of %s: %w", decision.Member, err)
+	}
+
+	if exists {
+		err = UpdateRefreshDecision(ctx, tx, decision.Member, object)
+	} else {
+		_, err = CreateRefreshDecision(ctx, tx, object)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record refresh decision of %s: %w", decision.Member, err)
+	}
+
+	return nil
+}
+
+// getRefreshDecisionsAPI fetches the latest refresh decision of every member,
+// ordered by member name.
+func getRefreshDecisionsAPI(ctx context.Context, tx *sql.Tx) (types.RefreshDecisions, error) {
+	objects, err := GetRefreshDecisions(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := make(types.RefreshDecisions, 0, len(objects))
+	for _, object := range objects {
+		decisions = append(decisions, object.toAPI())
+	}
+
+	sort.Slice(decisions, func(i, j int) bool { return decisions[i].Member < decisions[j].Member })
+	return decisions, nil
+}
+
+// GetRefreshDecisionsDb fetches the latest refresh decision of every member.
+var GetRefreshDecisionsDb = func(ctx context.Context, s mcTypes.State) (types.RefreshDecisions, error) {
+	var decisions types.RefreshDecisions
+	err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		decisions, err = getRefreshDecisionsAPI(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return decisions, nil
+}
+
+// SetRefreshDecisionDb records the latest refresh decision of a member.
+var SetRefreshDecisionDb = func(ctx context.Context, s mcTypes.State, decision types.RefreshDecision) error {
+	return s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		return SetRefreshDecision(ctx, tx, decision)
+	})
+}
+
+// ClaimRefreshDb records an allowed refresh decision unless another member is
+// mid-refresh, that is its refresh was allowed less than ttl ago and has not
+// completed. The decision is then recorded as refused. Checking and recording
+// happen in one transaction so that two members cannot both claim a refresh.
+// It returns the recorded decision.
+var ClaimRefreshDb = func(ctx context.Context, s mcTypes.State, decision types.RefreshDecision, ttl time.Duration) (types.RefreshDecision, error) {
+	err := s.Database().Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		decisions, err := getRefreshDecisionsAPI(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		other := RefreshInProgress(decisions, decision.Member, decision.Timestamp, ttl)
+		if other != nil {
+			decision.Allowed = false
+			decision.Reason = fmt.Sprintf("%s is mid-refresh since %s", other.Member, other.Timestamp.Format(time.RFC3339))
+		}
+
+		return SetRefreshDecision(ctx, tx, decision)
+	})
+
+	return decision, err
+}
+
+// RefreshInProgress returns the decision of a member other than the given one
+// which is mid-refresh at a point in time, nil if there is none.
+func RefreshInProgress(decisions types.RefreshDecisions, member string, now time.Time, ttl time.Duration) *types.RefreshDecision {
+	for i, decision := range decisions {
+		if decision.Member == member || !decision.Allowed || decision.Completed {
+			continue
+		}
+
+		if now.Sub(decision.Timestamp) < ttl {
+			return &decisions[i]
+		}
+	}
+
+	return nil
+}
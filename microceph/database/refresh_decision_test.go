@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/microceph/microceph/api/types"
+)
+
+// TestRefreshDecisionsRoundTrip verifies a member keeps only its latest decision.
+func TestRefreshDecisionsRoundTrip(t *testing.T) {
+	db := setupMapperDB(t, "node-a", "node-b")
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+
+	timestamp := time.Unix(1700000000, 0).UTC()
+	err = SetRefreshDecision(context.Background(), tx, types.RefreshDecision{Member: "node-b", Reason: "cluster is HEALTH_WARN: PG_DEGRADED", Timestamp: timestamp})
+	require.NoError(t, err)
+	err = SetRefreshDecision(context.Background(), tx, types.RefreshDecision{Member: "node-a", Allowed: true, Timestamp: timestamp})
+	require.NoError(t, err)
+	err = SetRefreshDecision(context.Background(), tx, types.RefreshDecision{Member: "node-a", Allowed: true, Completed: true, Timestamp: timestamp.Add(time.Minute)})
+	require.NoError(t, err)
+
+	decisions, err := getRefreshDecisionsAPI(context.Background(), tx)
+	require.NoError(t, err)
+	assert.Equal(t, types.RefreshDecisions{
+		{Member: "node-a", Allowed: true, Completed: true, Timestamp: timestamp.Add(time.Minute)},
+		{Member: "node-b", Reason: "cluster is HEALTH_WARN: PG_DEGRADED", Timestamp: timestamp},
+	}, decisions)
+}
+
+// TestRefreshInProgress verifies only recent uncompleted refreshes of other members count.
+func TestRefreshInProgress(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	decisions := types.RefreshDecisions{
+		{Member: "node-a", Allowed: true, Timestamp: now.Add(-time.Minute)},
+		{Member: "node-b", Allowed: true, Completed: true, Timestamp: now.Add(-time.Minute)},
+		{Member: "node-c", Reason: "refused", Timestamp: now.Add(-time.Minute)},
+		{Member: "node-d", Allowed: true, Timestamp: now.Add(-2 * time.Hour)},
+	}
+
+	assert.Equal(t, &decisions[0], RefreshInProgress(decisions, "node-b", now, time.Hour))
+	assert.Nil(t, RefreshInProgress(decisions, "node-a", now, time.Hour))
+}
@@ -22,6 +22,8 @@ var SchemaExtensions = []cluster.Update{
 	schemaUpdate7,
 	schemaUpdate8,
 	schemaUpdate9,
+	schemaUpdate10,
+	schemaUpdate11,
 }
 
 // getClusterTableName returns the name of the table that holds the record of cluster members from sqlite_master.
@@ -297,3 +299,52 @@ INSERT INTO placement_policy (id) VALUES (1);
 
 	return err
 }
+
+// schemaUpdate10 adds the config_history table, an append-only record of the
+// cluster config changes made through MicroCeph. A NULL old_value or
+// new_value means the key was unset before or after the change. member is
+// kept as a name rather than a reference so the history survives member
+// removal.
+func schemaUpdate10(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE config_history (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+  key        TEXT    NOT NULL,
+  who        TEXT    NOT NULL,
+  advanced   INTEGER NOT NULL DEFAULT 0,
+  old_value  TEXT,
+  new_value  TEXT,
+  member     TEXT    NOT NULL,
+  requester  TEXT    NOT NULL,
+  timestamp  DATETIME NOT NULL
+);
+CREATE INDEX config_history_key ON config_history (key);
+  `
+	_, err := tx.ExecContext(ctx, stmt)
+
+	return err
+}
+
+// schemaUpdate11 adds the refresh_decisions table, holding the latest decision
+// of the pre-refresh health gate for every member along with the snap revision
+// it ran. A member whose refresh was allowed and has not completed yet is
+// mid-refresh. Decisions are
+// cascade-deleted when the associated cluster member is removed.
+func schemaUpdate11(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE refresh_decisions (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+  member_id  INTEGER NOT NULL,
+  allowed    INTEGER NOT NULL DEFAULT 0,
+  reason     TEXT    NOT NULL,
+  completed  INTEGER NOT NULL DEFAULT 0,
+  revision   TEXT    NOT NULL DEFAULT '',
+  timestamp  DATETIME NOT NULL,
+  FOREIGN KEY (member_id) REFERENCES "core_cluster_members" (id) ON DELETE CASCADE,
+  UNIQUE(member_id)
+);
+  `
+	_, err := tx.ExecContext(ctx, stmt)
+
+	return err
+}
@@ -37,3 +37,10 @@ type ServiceGroupFilter struct {
 type NFSServiceGroupConfig struct {
 	V4MinVersion uint `json:"v4_min_version"`
 }
+
+// RGWServiceGroupConfig is a struct containing a ServiceGroup's configuration.
+type RGWServiceGroupConfig struct {
+	Realm     string `json:"realm"`
+	ZoneGroup string `json:"zonegroup"`
+	Zone      string `json:"zone"`
+}
@@ -140,6 +140,24 @@ func (_m *GroupedServiceQueryIntf) RemoveForHost(ctx context.Context, s interfac
 	return r0
 }
 
+// UpdateInfoOnHost provides a mock function with given fields: ctx, s, service, groupID, serviceInfo
+func (_m *GroupedServiceQueryIntf) UpdateInfoOnHost(ctx context.Context, s interfaces.StateInterface, service string, groupID string, serviceInfo interface{}) error {
+	ret := _m.Called(ctx, s, service, groupID, serviceInfo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateInfoOnHost")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, interfaces.StateInterface, string, string, interface{}) error); ok {
+		r0 = rf(ctx, s, service, groupID, serviceInfo)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewGroupedServiceQueryIntf creates a new instance of GroupedServiceQueryIntf. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewGroupedServiceQueryIntf(t interface {